@@ -17,6 +17,7 @@ package sapmexporter
 import (
 	"errors"
 	"net/url"
+	"time"
 
 	sapmclient "github.com/signalfx/sapm-proto/client"
 	"go.opentelemetry.io/collector/config/configmodels"
@@ -28,6 +29,11 @@ import (
 const (
 	defaultEndpointScheme = "https"
 	defaultNumWorkers     = 8
+
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+
+	defaultErrorTracePriorityHighWatermark = 0.8
 )
 
 // Config defines configuration for SAPM exporter.
@@ -38,6 +44,13 @@ type Config struct {
 	// It must be a full URL and include the scheme, port and path e.g, https://ingest.signalfx.com/v2/trace
 	Endpoint string `mapstructure:"endpoint"`
 
+	// FailoverEndpoints are used, in order, when Endpoint is unhealthy. Endpoint remains the preferred
+	// destination and traffic falls back to it as soon as it is healthy again.
+	FailoverEndpoints []string `mapstructure:"failover_endpoints"`
+
+	// HealthCheck configures the probes used to track the health of the endpoints when failover is enabled.
+	HealthCheck HealthCheckSettings `mapstructure:"health_check"`
+
 	// AccessToken is the authentication token provided by SignalFx.
 	AccessToken string `mapstructure:"access_token"`
 
@@ -51,6 +64,10 @@ type Config struct {
 	// Disable GZip compression.
 	DisableCompression bool `mapstructure:"disable_compression"`
 
+	// ErrorTracePriority drops healthy traces when the sending queue is near capacity, so that traces containing
+	// error spans survive congestion.
+	ErrorTracePriority ErrorTracePrioritySettings `mapstructure:"error_trace_priority"`
+
 	splunk.AccessTokenPassthroughConfig `mapstructure:",squash"`
 
 	exporterhelper.TimeoutSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
@@ -58,26 +75,83 @@ type Config struct {
 	exporterhelper.RetrySettings   `mapstructure:"retry_on_failure"`
 }
 
+// HealthCheckSettings defines how the health of the endpoints is probed.
+type HealthCheckSettings struct {
+	// Interval between two probes of the endpoints. Defaults to 30s.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout of a single probe. Defaults to 5s.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Path probed on each endpoint, which is then healthy only if it answers with a 2xx status code. When empty, the
+	// endpoint URL itself is probed and any answer but a 5xx status code is healthy, as ingest URLs only accept POST
+	// requests.
+	Path string `mapstructure:"path"`
+}
+
+// ErrorTracePrioritySettings defines how traces are prioritized when the sending queue is near capacity.
+type ErrorTracePrioritySettings struct {
+	// Enabled prioritizes traces containing error spans. Requires the sending queue. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// HighWatermark is the fraction of the queue size from which healthy traces are dropped, with a probability
+	// growing from 0 at the watermark to 1 when the queue is full. Defaults to 0.8.
+	HighWatermark float64 `mapstructure:"high_watermark"`
+}
+
 func (c *Config) validate() error {
 	if c.Endpoint == "" {
 		return errors.New("`endpoint` not specified")
 	}
 
-	e, err := url.Parse(c.Endpoint)
+	endpoint, err := normalizeEndpoint(c.Endpoint)
 	if err != nil {
 		return err
 	}
+	c.Endpoint = endpoint
+
+	for i, failover := range c.FailoverEndpoints {
+		if failover == "" {
+			return errors.New("`failover_endpoints` must not contain empty endpoints")
+		}
+		if c.FailoverEndpoints[i], err = normalizeEndpoint(failover); err != nil {
+			return err
+		}
+	}
+
+	if len(c.FailoverEndpoints) > 0 && c.HealthCheck.Interval <= 0 {
+		return errors.New("`health_check.interval` must be positive when `failover_endpoints` are set")
+	}
+	if len(c.FailoverEndpoints) > 0 && c.HealthCheck.Timeout <= 0 {
+		return errors.New("`health_check.timeout` must be positive when `failover_endpoints` are set")
+	}
+
+	if c.ErrorTracePriority.Enabled {
+		if !c.QueueSettings.Enabled || c.QueueSettings.QueueSize <= 0 {
+			return errors.New("`error_trace_priority` requires an enabled `sending_queue`")
+		}
+		if c.ErrorTracePriority.HighWatermark <= 0 || c.ErrorTracePriority.HighWatermark >= 1 {
+			return errors.New("`error_trace_priority.high_watermark` must be between 0 and 1 exclusive")
+		}
+	}
+	return nil
+}
+
+func normalizeEndpoint(endpoint string) (string, error) {
+	e, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
 
 	if e.Scheme == "" {
 		e.Scheme = defaultEndpointScheme
 	}
-	c.Endpoint = e.String()
-	return nil
+	return e.String(), nil
 }
 
-func (c *Config) clientOptions() []sapmclient.Option {
+func (c *Config) clientOptions(endpoint string) []sapmclient.Option {
 	opts := []sapmclient.Option{
-		sapmclient.WithEndpoint(c.Endpoint),
+		sapmclient.WithEndpoint(endpoint),
 	}
 	if c.NumWorkers > 0 {
 		opts = append(opts, sapmclient.WithWorkers(c.NumWorkers))
@@ -50,11 +50,21 @@ func TestLoadConfig(t *testing.T) {
 	r1 := cfg.Exporters["sapm/customname"].(*Config)
 	assert.Equal(t, r1,
 		&Config{
-			ExporterSettings: configmodels.ExporterSettings{TypeVal: configmodels.Type(typeStr), NameVal: "sapm/customname"},
-			Endpoint:         "test-endpoint",
-			AccessToken:      "abcd1234",
-			NumWorkers:       3,
-			MaxConnections:   45,
+			ExporterSettings:  configmodels.ExporterSettings{TypeVal: configmodels.Type(typeStr), NameVal: "sapm/customname"},
+			Endpoint:          "test-endpoint",
+			FailoverEndpoints: []string{"test-failover-endpoint"},
+			HealthCheck: HealthCheckSettings{
+				Interval: 10 * time.Second,
+				Timeout:  2 * time.Second,
+				Path:     "/healthz",
+			},
+			AccessToken:    "abcd1234",
+			NumWorkers:     3,
+			MaxConnections: 45,
+			ErrorTracePriority: ErrorTracePrioritySettings{
+				Enabled:       true,
+				HighWatermark: 0.9,
+			},
 			AccessTokenPassthroughConfig: splunk.AccessTokenPassthroughConfig{
 				AccessTokenPassthrough: false,
 			},
@@ -92,4 +102,32 @@ func TestInvalidConfig(t *testing.T) {
 	}
 	invalidURLErr := invalid.validate()
 	require.Error(t, invalidURLErr)
+
+	invalid = Config{
+		Endpoint:          "test-endpoint",
+		FailoverEndpoints: []string{":123:456"},
+		HealthCheck:       HealthCheckSettings{Interval: time.Second},
+	}
+	require.Error(t, invalid.validate())
+
+	invalid = Config{
+		Endpoint:          "test-endpoint",
+		FailoverEndpoints: []string{"test-failover-endpoint"},
+	}
+	require.Error(t, invalid.validate())
+
+	invalid.HealthCheck = HealthCheckSettings{Interval: time.Second}
+	assert.EqualError(t, invalid.validate(), "`health_check.timeout` must be positive when `failover_endpoints` are set")
+	invalid.HealthCheck.Timeout = -time.Second
+	require.Error(t, invalid.validate())
+
+	invalid = Config{
+		Endpoint:           "test-endpoint",
+		ErrorTracePriority: ErrorTracePrioritySettings{Enabled: true, HighWatermark: 0.8},
+	}
+	require.Error(t, invalid.validate())
+
+	invalid.QueueSettings = exporterhelper.QueueSettings{Enabled: true, QueueSize: 10}
+	invalid.ErrorTracePriority.HighWatermark = 1
+	require.Error(t, invalid.validate())
 }
@@ -40,13 +40,18 @@ type baseTracesExporter struct {
 
 // sapmExporter is a wrapper struct of SAPM exporter
 type sapmExporter struct {
-	client *sapmclient.Client
+	client *failoverClient
 	logger *zap.Logger
 	config *Config
 }
 
+func (se *sapmExporter) Start(context.Context, component.Host) error {
+	se.client.start()
+	return nil
+}
+
 func (se *sapmExporter) Shutdown(context.Context) error {
-	se.client.Stop()
+	se.client.stop()
 	return nil
 }
 
@@ -56,7 +61,7 @@ func newSAPMExporter(cfg *Config, params component.ExporterCreateParams) (sapmEx
 		return sapmExporter{}, err
 	}
 
-	client, err := sapmclient.New(cfg.clientOptions()...)
+	client, err := newFailoverClient(cfg, params.Logger)
 	if err != nil {
 		return sapmExporter{}, err
 	}
@@ -78,6 +83,7 @@ func newSAPMTraceExporter(cfg *Config, params component.ExporterCreateParams) (c
 		cfg,
 		params.Logger,
 		se.pushTraceData,
+		exporterhelper.WithStart(se.Start),
 		exporterhelper.WithShutdown(se.Shutdown),
 		exporterhelper.WithQueue(cfg.QueueSettings),
 		exporterhelper.WithRetry(cfg.RetrySettings),
@@ -88,6 +94,10 @@ func newSAPMTraceExporter(cfg *Config, params component.ExporterCreateParams) (c
 		return nil, err
 	}
 
+	if cfg.ErrorTracePriority.Enabled {
+		te = newPriorityExporter(cfg, params.Logger, te)
+	}
+
 	// If AccessTokenPassthrough enabled, split the incoming Traces data by splunk.SFxAccessTokenLabel,
 	// this ensures that we get batches of data for the same token when pushing to the backend.
 	if cfg.AccessTokenPassthrough {
@@ -102,6 +112,7 @@ func newSAPMTraceExporter(cfg *Config, params component.ExporterCreateParams) (c
 // pushTraceData exports traces in SAPM proto by associated SFx access token and returns number of dropped spans
 // and the last experienced error if any translation or export failed
 func (se *sapmExporter) pushTraceData(ctx context.Context, td pdata.Traces) error {
+	dequeued(ctx)
 	rss := td.ResourceSpans()
 	if rss.Len() == 0 {
 		return nil
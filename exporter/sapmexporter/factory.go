@@ -17,6 +17,7 @@ package sapmexporter
 import (
 	"context"
 
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -31,6 +32,8 @@ const (
 
 // NewFactory creates a factory for SAPM exporter.
 func NewFactory() component.ExporterFactory {
+	view.Register(MetricViews()...)
+
 	return exporterhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
@@ -44,6 +47,13 @@ func createDefaultConfig() configmodels.Exporter {
 			NameVal: typeStr,
 		},
 		NumWorkers: defaultNumWorkers,
+		HealthCheck: HealthCheckSettings{
+			Interval: defaultHealthCheckInterval,
+			Timeout:  defaultHealthCheckTimeout,
+		},
+		ErrorTracePriority: ErrorTracePrioritySettings{
+			HighWatermark: defaultErrorTracePriorityHighWatermark,
+		},
 		AccessTokenPassthroughConfig: splunk.AccessTokenPassthroughConfig{
 			AccessTokenPassthrough: true,
 		},
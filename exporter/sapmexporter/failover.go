@@ -0,0 +1,189 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	sapmclient "github.com/signalfx/sapm-proto/client"
+	"go.uber.org/zap"
+)
+
+// sapmEndpoint is a single ingest endpoint along with its last known health.
+type sapmEndpoint struct {
+	url      string
+	probeURL string
+	client   *sapmclient.Client
+	healthy  bool
+}
+
+// failoverClient sends traces to the first healthy endpoint in configuration order. The primary endpoint is
+// sticky: it is always preferred while healthy, and traffic falls back to it as soon as a probe succeeds again.
+type failoverClient struct {
+	logger     *zap.Logger
+	httpClient *http.Client
+	interval   time.Duration
+	// healthCheckPath is the path probed on each endpoint, or empty to probe the endpoint URL itself.
+	healthCheckPath string
+
+	mu        sync.RWMutex
+	endpoints []*sapmEndpoint
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newFailoverClient(cfg *Config, logger *zap.Logger) (*failoverClient, error) {
+	fc := &failoverClient{
+		logger:     logger,
+		httpClient: &http.Client{Timeout: cfg.HealthCheck.Timeout},
+		interval:   cfg.HealthCheck.Interval,
+		done:       make(chan struct{}),
+
+		healthCheckPath: cfg.HealthCheck.Path,
+	}
+	for _, endpoint := range append([]string{cfg.Endpoint}, cfg.FailoverEndpoints...) {
+		probeURL, err := healthCheckURL(endpoint, cfg.HealthCheck.Path)
+		if err != nil {
+			fc.stop()
+			return nil, err
+		}
+		client, err := sapmclient.New(cfg.clientOptions(endpoint)...)
+		if err != nil {
+			fc.stop()
+			return nil, err
+		}
+		fc.endpoints = append(fc.endpoints, &sapmEndpoint{url: endpoint, probeURL: probeURL, client: client, healthy: true})
+	}
+	return fc, nil
+}
+
+// candidates returns the healthy endpoints in priority order. If none is healthy all endpoints are returned,
+// so that data is still attempted rather than dropped while the probes catch up.
+func (fc *failoverClient) candidates() []*sapmEndpoint {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	var healthy []*sapmEndpoint
+	for _, e := range fc.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		return fc.endpoints
+	}
+	return healthy
+}
+
+func (fc *failoverClient) setHealthy(e *sapmEndpoint, healthy bool) {
+	fc.mu.Lock()
+	changed := e.healthy != healthy
+	e.healthy = healthy
+	fc.mu.Unlock()
+	if changed {
+		fc.logger.Info("SAPM endpoint health changed", zap.String("endpoint", e.url), zap.Bool("healthy", healthy))
+	}
+}
+
+func (fc *failoverClient) ExportWithAccessToken(ctx context.Context, batches []*model.Batch, accessToken string) error {
+	var err error
+	for _, e := range fc.candidates() {
+		err = e.client.ExportWithAccessToken(ctx, batches, accessToken)
+		if err == nil {
+			return nil
+		}
+		// Permanent errors are caused by the data itself, another endpoint would reject it as well.
+		if sendErr, ok := err.(*sapmclient.ErrSend); ok && sendErr.Permanent {
+			return err
+		}
+		if len(fc.endpoints) > 1 {
+			fc.setHealthy(e, false)
+		}
+	}
+	return err
+}
+
+// start probes the endpoints periodically, only needed when failover endpoints are configured.
+func (fc *failoverClient) start() {
+	if len(fc.endpoints) < 2 {
+		return
+	}
+	fc.wg.Add(1)
+	go func() {
+		defer fc.wg.Done()
+		ticker := time.NewTicker(fc.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fc.probeAll()
+			case <-fc.done:
+				return
+			}
+		}
+	}()
+}
+
+func (fc *failoverClient) probeAll() {
+	for _, e := range fc.endpoints {
+		fc.setHealthy(e, fc.probe(e.probeURL))
+	}
+}
+
+// probe considers an endpoint healthy if its health check path answers with a 2xx status code or, without health
+// check path, if the endpoint answers with anything but a server error: ingest URLs reject GET requests.
+func (fc *failoverClient) probe(probeURL string) bool {
+	resp, err := fc.httpClient.Get(probeURL)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	if fc.healthCheckPath == "" {
+		return resp.StatusCode < http.StatusInternalServerError
+	}
+	return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+}
+
+// healthCheckURL returns the URL probed for the health of the endpoint: the endpoint with the given path, or the
+// endpoint itself if the path is empty.
+func healthCheckURL(endpoint string, path string) (string, error) {
+	if path == "" {
+		return endpoint, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+func (fc *failoverClient) stop() {
+	select {
+	case <-fc.done:
+	default:
+		close(fc.done)
+	}
+	fc.wg.Wait()
+	for _, e := range fc.endpoints {
+		e.client.Stop()
+	}
+}
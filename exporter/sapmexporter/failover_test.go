@@ -0,0 +1,181 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/translator/trace/jaeger"
+	"go.uber.org/zap"
+)
+
+type switchableServer struct {
+	*httptest.Server
+	status   int32
+	requests int32
+}
+
+func newSwitchableServer(status int) *switchableServer {
+	s := &switchableServer{status: int32(status)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&s.requests, 1)
+		}
+		w.WriteHeader(int(atomic.LoadInt32(&s.status)))
+	}))
+	return s
+}
+
+func TestFailoverStickyPrimary(t *testing.T) {
+	primary := newSwitchableServer(http.StatusServiceUnavailable)
+	defer primary.Close()
+	secondary := newSwitchableServer(http.StatusOK)
+	defer secondary.Close()
+
+	cfg := &Config{
+		Endpoint:          primary.URL,
+		FailoverEndpoints: []string{secondary.URL},
+		HealthCheck:       HealthCheckSettings{Interval: time.Hour, Timeout: time.Second},
+	}
+	require.NoError(t, cfg.validate())
+	fc, err := newFailoverClient(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer fc.stop()
+
+	batches := buildTestBatches(t)
+
+	// Primary is failing, data goes to the secondary and the primary is marked unhealthy.
+	require.NoError(t, fc.ExportWithAccessToken(context.Background(), batches, ""))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&primary.requests))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&secondary.requests))
+
+	// While unhealthy the primary is skipped.
+	require.NoError(t, fc.ExportWithAccessToken(context.Background(), batches, ""))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&primary.requests))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&secondary.requests))
+
+	// Once the probe succeeds traffic falls back to the primary.
+	atomic.StoreInt32(&primary.status, http.StatusOK)
+	fc.probeAll()
+	require.NoError(t, fc.ExportWithAccessToken(context.Background(), batches, ""))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&primary.requests))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&secondary.requests))
+}
+
+func TestFailoverAllUnhealthy(t *testing.T) {
+	primary := newSwitchableServer(http.StatusServiceUnavailable)
+	defer primary.Close()
+	secondary := newSwitchableServer(http.StatusServiceUnavailable)
+	defer secondary.Close()
+
+	cfg := &Config{
+		Endpoint:          primary.URL,
+		FailoverEndpoints: []string{secondary.URL},
+		HealthCheck:       HealthCheckSettings{Interval: time.Hour, Timeout: time.Second},
+	}
+	require.NoError(t, cfg.validate())
+	fc, err := newFailoverClient(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer fc.stop()
+
+	fc.probeAll()
+	assert.Len(t, fc.candidates(), 2)
+	require.Error(t, fc.ExportWithAccessToken(context.Background(), buildTestBatches(t), ""))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&primary.requests))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&secondary.requests))
+}
+
+func TestFailoverPermanentError(t *testing.T) {
+	primary := newSwitchableServer(http.StatusBadRequest)
+	defer primary.Close()
+	secondary := newSwitchableServer(http.StatusOK)
+	defer secondary.Close()
+
+	cfg := &Config{
+		Endpoint:          primary.URL,
+		FailoverEndpoints: []string{secondary.URL},
+		HealthCheck:       HealthCheckSettings{Interval: time.Hour, Timeout: time.Second},
+	}
+	require.NoError(t, cfg.validate())
+	fc, err := newFailoverClient(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer fc.stop()
+
+	require.Error(t, fc.ExportWithAccessToken(context.Background(), buildTestBatches(t), ""))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&secondary.requests))
+	assert.Len(t, fc.candidates(), 2)
+}
+
+func TestFailoverProbe(t *testing.T) {
+	server := newSwitchableServer(http.StatusMethodNotAllowed)
+	defer server.Close()
+
+	cfg := &Config{
+		Endpoint:          server.URL + "/v2/trace",
+		FailoverEndpoints: []string{server.URL + "/v2/trace"},
+		HealthCheck:       HealthCheckSettings{Interval: time.Hour, Timeout: time.Second},
+	}
+	require.NoError(t, cfg.validate())
+	fc, err := newFailoverClient(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer fc.stop()
+
+	// Without health check path, the ingest URL rejecting GET requests is healthy.
+	assert.Equal(t, server.URL+"/v2/trace", fc.endpoints[0].probeURL)
+	assert.True(t, fc.probe(fc.endpoints[0].probeURL))
+	atomic.StoreInt32(&server.status, http.StatusBadGateway)
+	assert.False(t, fc.probe(fc.endpoints[0].probeURL))
+}
+
+func TestFailoverProbeHealthCheckPath(t *testing.T) {
+	var paths []string
+	status := int32(http.StatusNoContent)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		Endpoint:          server.URL + "/v2/trace",
+		FailoverEndpoints: []string{server.URL + "/v2/trace"},
+		HealthCheck:       HealthCheckSettings{Interval: time.Hour, Timeout: time.Second, Path: "/healthz"},
+	}
+	require.NoError(t, cfg.validate())
+	fc, err := newFailoverClient(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer fc.stop()
+
+	assert.True(t, fc.probe(fc.endpoints[0].probeURL))
+	for _, code := range []int{http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusInternalServerError} {
+		atomic.StoreInt32(&status, int32(code))
+		assert.False(t, fc.probe(fc.endpoints[0].probeURL), "status %d", code)
+	}
+	assert.Equal(t, []string{"/healthz", "/healthz", "/healthz", "/healthz"}, paths)
+}
+
+func buildTestBatches(t *testing.T) []*model.Batch {
+	batches, err := jaeger.InternalTracesToJaegerProto(buildTestTraces(false))
+	require.NoError(t, err)
+	return batches
+}
@@ -0,0 +1,66 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+var (
+	tagKeyExporter = tag.MustNewKey(obsreport.ExporterKey)
+
+	mShedSpans = stats.Int64(
+		"exporter/sapm/shed_spans",
+		"Number of spans of healthy traces dropped because the sending queue was near capacity",
+		stats.UnitDimensionless)
+	mPrioritizedSpans = stats.Int64(
+		"exporter/sapm/prioritized_spans",
+		"Number of spans of error traces kept while healthy traces were dropped",
+		stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views of the exporter.
+func MetricViews() []*view.View {
+	var views []*view.View
+	for _, m := range []*stats.Int64Measure{mShedSpans, mPrioritizedSpans} {
+		views = append(views, &view.View{
+			Name:        m.Name(),
+			Measure:     m,
+			Description: m.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyExporter},
+		})
+	}
+	return views
+}
+
+func recordPriority(ctx context.Context, exporterName string, shed int, prioritized int) {
+	var measurements []stats.Measurement
+	if shed > 0 {
+		measurements = append(measurements, mShedSpans.M(int64(shed)))
+	}
+	if prioritized > 0 {
+		measurements = append(measurements, mPrioritizedSpans.M(int64(prioritized)))
+	}
+	if len(measurements) == 0 {
+		return
+	}
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(tagKeyExporter, exporterName)}, measurements...)
+}
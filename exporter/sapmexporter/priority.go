@@ -0,0 +1,181 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+// queueTicketKey is the context key of the queueTicket of a request.
+type queueTicketKey struct{}
+
+// queueTicket tracks a request in the sending queue until a consumer picks it up.
+type queueTicket struct {
+	once   sync.Once
+	queued *int64
+}
+
+func (t *queueTicket) dequeue() {
+	t.once.Do(func() {
+		atomic.AddInt64(t.queued, -1)
+	})
+}
+
+// dequeued records that the request of the context left the sending queue. Retries of the request are ignored.
+func dequeued(ctx context.Context) {
+	if t, ok := ctx.Value(queueTicketKey{}).(*queueTicket); ok {
+		t.dequeue()
+	}
+}
+
+// priorityExporter counts the requests waiting in the sending queue of the exporter it wraps, and drops healthy
+// traces with a growing probability once the queue is filled above the high watermark. Traces containing error
+// spans are always kept, unless the queue is full.
+type priorityExporter struct {
+	component.TracesExporter
+	name          string
+	logger        *zap.Logger
+	capacity      int
+	highWatermark float64
+	queued        int64
+}
+
+func newPriorityExporter(cfg *Config, logger *zap.Logger, next component.TracesExporter) *priorityExporter {
+	return &priorityExporter{
+		TracesExporter: next,
+		name:           cfg.Name(),
+		logger:         logger,
+		capacity:       cfg.QueueSettings.QueueSize,
+		highWatermark:  cfg.ErrorTracePriority.HighWatermark,
+	}
+}
+
+func (pe *priorityExporter) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	if p := pe.dropProbability(); p > 0 {
+		var shed, prioritized int
+		td, shed, prioritized = shedHealthyTraces(td, p)
+		recordPriority(ctx, pe.name, shed, prioritized)
+		if shed > 0 {
+			pe.logger.Debug("Dropped healthy traces as the sending queue is near capacity",
+				zap.Int("spans", shed), zap.Float64("probability", p))
+		}
+		if td.SpanCount() == 0 {
+			return nil
+		}
+	}
+
+	atomic.AddInt64(&pe.queued, 1)
+	ticket := &queueTicket{queued: &pe.queued}
+	err := pe.TracesExporter.ConsumeTraces(context.WithValue(ctx, queueTicketKey{}, ticket), td)
+	if err != nil {
+		// The request was not queued.
+		ticket.dequeue()
+	}
+	return err
+}
+
+// dropProbability returns the probability with which healthy traces are dropped, given the occupancy of the queue.
+func (pe *priorityExporter) dropProbability() float64 {
+	occupancy := float64(atomic.LoadInt64(&pe.queued)) / float64(pe.capacity)
+	if occupancy < pe.highWatermark {
+		return 0
+	}
+	return math.Min(1, (occupancy-pe.highWatermark)/(1-pe.highWatermark))
+}
+
+// shedHealthyTraces returns a copy of td without the traces containing no error span that were drawn with the
+// probability p, along with the number of spans dropped and of error spans kept. The draw depends on the trace id
+// only, so that all the spans of a trace share the same fate at the same probability.
+func shedHealthyTraces(td pdata.Traces, p float64) (pdata.Traces, int, int) {
+	errorTraces := map[[16]byte]bool{}
+	forEachSpan(td, func(span pdata.Span) {
+		if span.Status().Code() == pdata.StatusCodeError {
+			errorTraces[span.TraceID().Bytes()] = true
+		}
+	})
+	var shed, prioritized int
+	keep := func(span pdata.Span) bool {
+		traceID := span.TraceID().Bytes()
+		if errorTraces[traceID] {
+			prioritized++
+			return true
+		}
+		if float64(binary.BigEndian.Uint64(traceID[8:]))/math.MaxUint64 >= p {
+			return true
+		}
+		shed++
+		return false
+	}
+
+	kept := pdata.NewTraces()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		var keptRS pdata.ResourceSpans
+		hasRS := false
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			ils := ilss.At(j)
+			var keptILS pdata.InstrumentationLibrarySpans
+			hasILS := false
+			spans := ils.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				if !keep(span) {
+					continue
+				}
+				if !hasRS {
+					hasRS = true
+					kept.ResourceSpans().Resize(kept.ResourceSpans().Len() + 1)
+					keptRS = kept.ResourceSpans().At(kept.ResourceSpans().Len() - 1)
+					rs.Resource().CopyTo(keptRS.Resource())
+				}
+				if !hasILS {
+					hasILS = true
+					keptRS.InstrumentationLibrarySpans().Resize(keptRS.InstrumentationLibrarySpans().Len() + 1)
+					keptILS = keptRS.InstrumentationLibrarySpans().At(keptRS.InstrumentationLibrarySpans().Len() - 1)
+					ils.InstrumentationLibrary().CopyTo(keptILS.InstrumentationLibrary())
+				}
+				keptILS.Spans().Resize(keptILS.Spans().Len() + 1)
+				span.CopyTo(keptILS.Spans().At(keptILS.Spans().Len() - 1))
+			}
+		}
+	}
+	if shed == 0 {
+		return td, 0, 0
+	}
+	return kept, shed, prioritized
+}
+
+func forEachSpan(td pdata.Traces, f func(pdata.Span)) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				f(spans.At(k))
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sapmexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+type recordingTracesExporter struct {
+	component.Component
+	traces []pdata.Traces
+	err    error
+	ctx    context.Context
+}
+
+func (e *recordingTracesExporter) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	e.ctx = ctx
+	if e.err != nil {
+		return e.err
+	}
+	e.traces = append(e.traces, td)
+	return nil
+}
+
+// newPriorityTraces returns traces with a healthy and an error trace for each of the given trace id bytes.
+func newPriorityTraces(ids ...byte) pdata.Traces {
+	td := pdata.NewTraces()
+	td.ResourceSpans().Resize(1)
+	rs := td.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("service.name", "svc")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	spans.Resize(2 * len(ids))
+	for i, id := range ids {
+		healthy := spans.At(2 * i)
+		healthy.SetName("healthy")
+		healthy.SetTraceID(pdata.NewTraceID([16]byte{8: 0xff, 15: id}))
+		failed := spans.At(2*i + 1)
+		failed.SetName("failed")
+		failed.SetTraceID(pdata.NewTraceID([16]byte{0: 1, 15: id}))
+		failed.Status().SetCode(pdata.StatusCodeError)
+	}
+	return td
+}
+
+func TestShedHealthyTraces(t *testing.T) {
+	td := newPriorityTraces(1, 2)
+
+	kept, shed, prioritized := shedHealthyTraces(td, 1)
+	assert.Equal(t, 2, shed)
+	assert.Equal(t, 2, prioritized)
+	require.Equal(t, 2, kept.SpanCount())
+	rs := kept.ResourceSpans().At(0)
+	assert.Equal(t, td.ResourceSpans().At(0).Resource(), rs.Resource())
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	for i := 0; i < spans.Len(); i++ {
+		assert.Equal(t, "failed", spans.At(i).Name())
+	}
+	// The incoming traces are left unchanged.
+	assert.Equal(t, 4, td.SpanCount())
+
+	// Traces whose id is drawn above the probability are kept.
+	kept, shed, prioritized = shedHealthyTraces(td, 0.5)
+	assert.Equal(t, 0, shed)
+	assert.Equal(t, 0, prioritized)
+	assert.Equal(t, td, kept)
+}
+
+func TestPriorityExporter(t *testing.T) {
+	next := &recordingTracesExporter{}
+	cfg := &Config{
+		ErrorTracePriority: ErrorTracePrioritySettings{Enabled: true, HighWatermark: 0.5},
+	}
+	cfg.QueueSettings.QueueSize = 4
+	pe := newPriorityExporter(cfg, zap.NewNop(), next)
+
+	// Up to the high watermark, all traces are queued.
+	require.NoError(t, pe.ConsumeTraces(context.Background(), newPriorityTraces(1)))
+	require.NoError(t, pe.ConsumeTraces(context.Background(), newPriorityTraces(2)))
+	assert.Equal(t, 0.0, pe.dropProbability())
+	require.NoError(t, pe.ConsumeTraces(context.Background(), newPriorityTraces(3)))
+	assert.Equal(t, 0.5, pe.dropProbability())
+	require.Len(t, next.traces, 3)
+	assert.Equal(t, 2, next.traces[2].SpanCount())
+
+	// Above it, healthy traces are dropped with a growing probability.
+	require.NoError(t, pe.ConsumeTraces(context.Background(), newPriorityTraces(4)))
+	assert.Equal(t, 1.0, pe.dropProbability())
+	require.NoError(t, pe.ConsumeTraces(context.Background(), newPriorityTraces(5)))
+	require.Len(t, next.traces, 5)
+	assert.Equal(t, 2, next.traces[3].SpanCount())
+	assert.Equal(t, 1, next.traces[4].SpanCount())
+
+	// Requests leave the queue once, whatever their number of retries.
+	dequeued(next.ctx)
+	dequeued(next.ctx)
+	assert.EqualValues(t, 4, pe.queued)
+
+	// Requests failing to be queued are not counted.
+	next.err = errors.New("sending_queue is full")
+	assert.Error(t, pe.ConsumeTraces(context.Background(), newPriorityTraces(6)))
+	assert.EqualValues(t, 4, pe.queued)
+}
@@ -68,6 +68,11 @@ type Config struct {
 
 	splunk.AccessTokenPassthroughConfig `mapstructure:",squash"`
 
+	// AccessTokenMetricPrefixes prepends a prefix to the name of the metrics sent with given access tokens, either
+	// passed through or the access_token above, so that metrics of several organizations or tenants remain
+	// distinguishable in shared dashboards.
+	AccessTokenMetricPrefixes []AccessTokenMetricPrefix `mapstructure:"access_token_metric_prefixes"`
+
 	// TranslationRules defines a set of rules how to translate metrics to a SignalFx compatible format
 	// Rules defined in translation/constants.go are used by default.
 	TranslationRules []translation.Rule `mapstructure:"translation_rules"`
@@ -96,12 +101,72 @@ type Config struct {
 	// See ./translation/default_metrics.go for a list of metrics that are dropped by default.
 	IncludeMetrics []dpfilters.MetricFilter `mapstructure:"include_metrics"`
 
+	// EventResourceProperties converts the given resource attributes to properties of the events sent from log
+	// records, so that events carry the context of their source, e.g. host or pod, for correlation.
+	EventResourceProperties []translation.EventResourceProperty `mapstructure:"event_resource_properties"`
+
 	// Correlation configuration for syncing traces service and environment to metrics.
 	Correlation *correlation.Config `mapstructure:"correlation"`
 
 	// NonAlphanumericDimensionChars is a list of allowable characters, in addition to alphanumeric ones,
 	// to be used in a dimension key.
 	NonAlphanumericDimensionChars string `mapstructure:"nonalphanumeric_dimension_chars"`
+
+	// DropNaNValues drops the datapoints whose value is not a number rather than sending them. Defaults to false.
+	DropNaNValues bool `mapstructure:"drop_nan_values"`
+
+	// ShutdownFlush bounds the time spent sending the datapoints still queued when the exporter shuts down.
+	ShutdownFlush ShutdownFlushSettings `mapstructure:"shutdown_flush"`
+
+	// DimensionClient defines the limits of the client sending dimension property updates, which has its own
+	// connection pool so that bursts of updates never delay datapoints.
+	DimensionClient DimensionClientSettings `mapstructure:"dimension_client"`
+
+	// Preflight validates the access token against the ingest endpoint of the realm on start, so that a token of
+	// another realm is diagnosed before datapoints are dropped.
+	Preflight PreflightSettings `mapstructure:"preflight"`
+}
+
+// PreflightSettings defines how the access token and realm are validated on start.
+type PreflightSettings struct {
+	// Mode is "warn" to log the diagnostics of a failed validation, "required" to fail starting instead, or
+	// "disabled". Unless disabled, starting the exporter sends a request to the ingest endpoint. Defaults to "warn".
+	Mode string `mapstructure:"mode"`
+}
+
+// DimensionClientSettings defines the limits of the client sending dimension property updates.
+type DimensionClientSettings struct {
+	// MaxBuffered is the maximum number of dimension updates waiting to be sent. Further updates are dropped.
+	// Defaults to 10000 when 0, like MaxConnsPerHost and Timeout below.
+	MaxBuffered int `mapstructure:"max_buffered"`
+
+	// SendDelay is how long updates wait before being sent, so that several updates of the same dimension within
+	// that time are merged into one request.
+	SendDelay time.Duration `mapstructure:"send_delay"`
+
+	// MaxConnsPerHost is the maximum number of concurrent requests, and so connections, to the API.
+	MaxConnsPerHost int `mapstructure:"max_conns_per_host"`
+
+	// Timeout of the requests to the API.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// ShutdownFlushSettings defines how the datapoints still queued on shutdown are sent.
+type ShutdownFlushSettings struct {
+	// Enabled sends the datapoints still queued on shutdown until Timeout, instead of abandoning them right away.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Timeout after which the datapoints still queued are abandoned. 0 waits for all of them to be sent.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// AccessTokenMetricPrefix associates a metric name prefix with an access token.
+type AccessTokenMetricPrefix struct {
+	// AccessToken the metrics are sent with.
+	AccessToken string `mapstructure:"access_token"`
+
+	// Prefix prepended to the name of the metrics, after they are translated.
+	Prefix string `mapstructure:"prefix"`
 }
 
 func (cfg *Config) getOptionsFromConfig() (*exporterOptions, error) {
@@ -152,6 +217,44 @@ func (cfg *Config) validateConfig() error {
 		return errors.New("cannot have a negative \"timeout\"")
 	}
 
+	if cfg.ShutdownFlush.Timeout < 0 {
+		return errors.New("cannot have a negative \"shutdown_flush.timeout\"")
+	}
+
+	if dc := cfg.DimensionClient; dc.MaxBuffered < 0 || dc.SendDelay < 0 || dc.MaxConnsPerHost < 0 || dc.Timeout < 0 {
+		return errors.New("cannot have negative \"dimension_client\" settings")
+	}
+
+	switch cfg.Preflight.Mode {
+	case "", preflightDisabled, preflightWarn, preflightRequired:
+	default:
+		return fmt.Errorf("unknown \"preflight.mode\" %q, must be %q, %q or %q",
+			cfg.Preflight.Mode, preflightDisabled, preflightWarn, preflightRequired)
+	}
+
+	properties := map[string]bool{}
+	for i, rp := range cfg.EventResourceProperties {
+		if rp.Attribute == "" {
+			return fmt.Errorf("requires a non-empty \"attribute\" in \"event_resource_properties[%d]\"", i)
+		}
+		key := rp.PropertyKey()
+		if properties[key] {
+			return fmt.Errorf("duplicate property %q in \"event_resource_properties[%d]\"", key, i)
+		}
+		properties[key] = true
+	}
+
+	tokens := map[string]bool{}
+	for i, prefix := range cfg.AccessTokenMetricPrefixes {
+		if prefix.AccessToken == "" || prefix.Prefix == "" {
+			return fmt.Errorf("requires a non-empty \"access_token\" and \"prefix\" in \"access_token_metric_prefixes[%d]\"", i)
+		}
+		if tokens[prefix.AccessToken] {
+			return fmt.Errorf("duplicate \"access_token\" in \"access_token_metric_prefixes[%d]\"", i)
+		}
+		tokens[prefix.AccessToken] = true
+	}
+
 	return nil
 }
 
@@ -89,6 +89,13 @@ func TestLoadConfig(t *testing.T) {
 		}, AccessTokenPassthroughConfig: splunk.AccessTokenPassthroughConfig{
 			AccessTokenPassthrough: false,
 		},
+		AccessTokenMetricPrefixes: []AccessTokenMetricPrefix{
+			{AccessToken: "tenant1token", Prefix: "tenant1."},
+		},
+		EventResourceProperties: []translation.EventResourceProperty{
+			{Attribute: "host.name"},
+			{Attribute: "k8s.pod.name", Property: "kubernetes_pod_name"},
+		},
 		TranslationRules: []translation.Rule{
 			{
 				Action: translation.ActionRenameDimensionKeys,
@@ -184,6 +191,19 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 		NonAlphanumericDimensionChars: "_-.",
+		ShutdownFlush: ShutdownFlushSettings{
+			Enabled: true,
+			Timeout: 30 * time.Second,
+		},
+		DimensionClient: DimensionClientSettings{
+			MaxBuffered:     1000,
+			SendDelay:       5 * time.Second,
+			MaxConnsPerHost: 5,
+			Timeout:         20 * time.Second,
+		},
+		Preflight: PreflightSettings{
+			Mode: "required",
+		},
 	}
 	assert.Equal(t, &expectedCfg, e1)
 
@@ -340,3 +360,80 @@ func TestConfig_getOptionsFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_accessTokenMetricPrefixes(t *testing.T) {
+	cfg := &Config{
+		AccessToken:               "access_token",
+		Realm:                     "us0",
+		DeltaTranslationTTL:       3600,
+		AccessTokenMetricPrefixes: []AccessTokenMetricPrefix{{AccessToken: "token"}},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `requires a non-empty "access_token" and "prefix" in "access_token_metric_prefixes[0]"`)
+
+	cfg.AccessTokenMetricPrefixes = []AccessTokenMetricPrefix{
+		{AccessToken: "token", Prefix: "tenant1."},
+		{AccessToken: "token", Prefix: "tenant2."},
+	}
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `duplicate "access_token" in "access_token_metric_prefixes[1]"`)
+
+	cfg.AccessTokenMetricPrefixes = cfg.AccessTokenMetricPrefixes[:1]
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_eventResourceProperties(t *testing.T) {
+	cfg := &Config{
+		AccessToken:             "access_token",
+		Realm:                   "us0",
+		DeltaTranslationTTL:     3600,
+		EventResourceProperties: []translation.EventResourceProperty{{Property: "host"}},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `requires a non-empty "attribute" in "event_resource_properties[0]"`)
+
+	cfg.EventResourceProperties = []translation.EventResourceProperty{
+		{Attribute: "host.name", Property: "host"},
+		{Attribute: "host"},
+	}
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `duplicate property "host" in "event_resource_properties[1]"`)
+
+	cfg.EventResourceProperties = cfg.EventResourceProperties[:1]
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_shutdownFlushTimeout(t *testing.T) {
+	cfg := &Config{
+		AccessToken:         "access_token",
+		Realm:               "us0",
+		DeltaTranslationTTL: 3600,
+		ShutdownFlush:       ShutdownFlushSettings{Enabled: true, Timeout: -time.Second},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `cannot have a negative "shutdown_flush.timeout"`)
+}
+
+func TestConfig_dimensionClient(t *testing.T) {
+	cfg := &Config{
+		AccessToken:         "access_token",
+		Realm:               "us0",
+		DeltaTranslationTTL: 3600,
+		DimensionClient:     DimensionClientSettings{MaxConnsPerHost: -1},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `cannot have negative "dimension_client" settings`)
+}
+
+func TestConfig_preflightMode(t *testing.T) {
+	cfg := &Config{
+		AccessToken:         "access_token",
+		Realm:               "us0",
+		DeltaTranslationTTL: 3600,
+		Preflight:           PreflightSettings{Mode: "strict"},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unknown "preflight.mode" "strict", must be "disabled", "warn" or "required"`)
+}
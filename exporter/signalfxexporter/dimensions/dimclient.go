@@ -75,25 +75,49 @@ type DimensionClient struct {
 	metricsConverter             translation.MetricsConverter
 }
 
+const (
+	defaultPropertiesMaxBuffered = 10000
+	defaultMaxConnsPerHost       = 20
+	defaultTimeout               = 10 * time.Second
+)
+
 type queuedDimension struct {
 	*DimensionUpdate
 	TimeToSend time.Time
 }
 
 type DimensionClientOptions struct {
-	Token                 string
-	APIURL                *url.URL
-	LogUpdates            bool
-	Logger                *zap.Logger
-	SendDelay             int
+	Token      string
+	APIURL     *url.URL
+	LogUpdates bool
+	Logger     *zap.Logger
+	SendDelay  time.Duration
+	// PropertiesMaxBuffered bounds the number of updates waiting to be sent. Defaults to 10000.
 	PropertiesMaxBuffered int
-	MetricsConverter      translation.MetricsConverter
+	// MaxConnsPerHost bounds the number of concurrent requests, and so connections, to the API. Defaults to 20.
+	MaxConnsPerHost int
+	// Timeout of requests to the API. Defaults to 10s.
+	Timeout          time.Duration
+	MetricsConverter translation.MetricsConverter
 }
 
-// NewDimensionClient returns a new client
+// NewDimensionClient returns a new client. Its requests go through their own connection pool, bounded by
+// MaxConnsPerHost, so that bursts of dimension updates never compete with the datapoint client for connections.
 func NewDimensionClient(ctx context.Context, options DimensionClientOptions) *DimensionClient {
+	maxConns := options.MaxConnsPerHost
+	if maxConns <= 0 {
+		maxConns = defaultMaxConnsPerHost
+	}
+	maxBuffered := options.PropertiesMaxBuffered
+	if maxBuffered <= 0 {
+		maxBuffered = defaultPropertiesMaxBuffered
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout: timeout,
 		Transport: &http.Transport{
 			Proxy: http.ProxyFromEnvironment,
 			DialContext: (&net.Dialer{
@@ -101,21 +125,22 @@ func NewDimensionClient(ctx context.Context, options DimensionClientOptions) *Di
 				KeepAlive: 30 * time.Second,
 				DualStack: true,
 			}).DialContext,
-			MaxIdleConns:        20,
-			MaxIdleConnsPerHost: 20,
+			MaxIdleConns:        maxConns,
+			MaxIdleConnsPerHost: maxConns,
+			MaxConnsPerHost:     maxConns,
 			IdleConnTimeout:     30 * time.Second,
 			TLSHandshakeTimeout: 10 * time.Second,
 		},
 	}
-	sender := NewReqSender(ctx, client, 20, map[string]string{"client": "dimension"})
+	sender := NewReqSender(ctx, client, uint(maxConns), map[string]string{"client": "dimension"})
 
 	return &DimensionClient{
 		ctx:              ctx,
 		Token:            options.Token,
 		APIURL:           options.APIURL,
-		sendDelay:        time.Duration(options.SendDelay) * time.Second,
+		sendDelay:        options.SendDelay,
 		delayedSet:       make(map[DimensionKey]*DimensionUpdate),
-		delayedQueue:     make(chan *queuedDimension, options.PropertiesMaxBuffered),
+		delayedQueue:     make(chan *queuedDimension, maxBuffered),
 		requestSender:    sender,
 		client:           client,
 		now:              time.Now,
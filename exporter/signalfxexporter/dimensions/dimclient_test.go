@@ -117,7 +117,7 @@ func setup(t *testing.T) (*DimensionClient, chan dim, *atomic.Value, context.Can
 		APIURL:                serverURL,
 		LogUpdates:            true,
 		Logger:                zap.NewNop(),
-		SendDelay:             1,
+		SendDelay:             time.Second,
 		PropertiesMaxBuffered: 10,
 	})
 	client.Start()
@@ -401,3 +401,52 @@ func newString(s string) *string {
 	out := s
 	return &out
 }
+
+func TestMaxConnsPerHost(t *testing.T) {
+	var inFlight, maxInFlight int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			max := atomic.LoadInt64(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, n) {
+				break
+			}
+		}
+		<-release
+		rw.WriteHeader(200)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := NewDimensionClient(ctx, DimensionClientOptions{
+		APIURL:          serverURL,
+		Logger:          zap.NewNop(),
+		MaxConnsPerHost: 2,
+	})
+	client.Start()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, client.acceptDimension(&DimensionUpdate{
+			Name:       "pod_uid",
+			Value:      strconv.Itoa(i),
+			Properties: map[string]*string{"index": newString(strconv.Itoa(i))},
+		}))
+	}
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&inFlight) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	// Give the other updates a chance to exceed the limit.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&client.requestSender.TotalRequestsCompleted) == 10
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, int64(2), atomic.LoadInt64(&maxInFlight))
+}
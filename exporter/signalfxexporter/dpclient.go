@@ -20,11 +20,14 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
 	"go.opentelemetry.io/collector/consumer/consumererror"
@@ -40,6 +43,8 @@ type sfxClientBase struct {
 	headers   map[string]string
 	client    *http.Client
 	zippers   sync.Pool
+	// redactor redacts the access tokens from logs and errors.
+	redactor *splunk.TokenRedactor
 }
 
 // avoid attempting to compress things that fit into a single ethernet frame
@@ -64,9 +69,18 @@ func (s *sfxClientBase) getReader(b []byte) (io.Reader, bool, error) {
 // sfxDPClient sends the data to the SignalFx backend.
 type sfxDPClient struct {
 	sfxClientBase
+	exporterName           string
 	logger                 *zap.Logger
 	accessTokenPassthrough bool
 	converter              *translation.MetricsConverter
+	// defaultAccessToken is the access token data points are sent with unless passed through.
+	defaultAccessToken string
+	// metricPrefixes are the prefixes of the names of the metrics sent with each access token.
+	metricPrefixes map[string]string
+	// dropNaNValues drops the datapoints whose value is not a number.
+	dropNaNValues bool
+	// retryOnFailure tells whether requests failing with retryable errors are retried, or else dropped.
+	retryOnFailure bool
 }
 
 func (s *sfxDPClient) pushMetricsData(
@@ -82,17 +96,68 @@ func (s *sfxDPClient) pushMetricsData(
 	metricToken := s.retrieveAccessToken(rms.At(0))
 
 	var sfxDataPoints []*sfxpb.DataPoint
+	var dropped translation.DroppedDataPoints
 
 	for i := 0; i < rms.Len(); i++ {
-		sfxDataPoints = append(sfxDataPoints, s.converter.MetricDataToSignalFxV2(rms.At(i))...)
+		dps, rmDropped := s.converter.MetricDataToSignalFxV2WithDrops(rms.At(i))
+		sfxDataPoints = append(sfxDataPoints, dps...)
+		dropped.Add(rmDropped)
 	}
+	recordConversionDrops(ctx, s.exporterName, dropped)
+	if s.dropNaNValues {
+		var nan int
+		sfxDataPoints, nan = dropNaNDataPoints(sfxDataPoints)
+		recordDroppedDataPoints(ctx, s.exporterName, dropReasonNaN, nan)
+	}
+	s.prefixMetricNames(sfxDataPoints, metricToken)
 
 	return s.pushMetricsDataForToken(ctx, sfxDataPoints, metricToken)
 }
 
+// dropNaNDataPoints removes the datapoints whose value is not a number, returning the datapoints left and how many
+// were removed.
+func dropNaNDataPoints(sfxDataPoints []*sfxpb.DataPoint) ([]*sfxpb.DataPoint, int) {
+	kept := sfxDataPoints[:0]
+	for _, dp := range sfxDataPoints {
+		if dp.Value.DoubleValue != nil && math.IsNaN(*dp.Value.DoubleValue) {
+			continue
+		}
+		kept = append(kept, dp)
+	}
+	return kept, len(sfxDataPoints) - len(kept)
+}
+
+// prefixMetricNames prepends the prefix associated with the access token of the data points to their metric names.
+func (s *sfxDPClient) prefixMetricNames(sfxDataPoints []*sfxpb.DataPoint, accessToken string) {
+	if accessToken == "" {
+		accessToken = s.defaultAccessToken
+	}
+	prefix := s.metricPrefixes[accessToken]
+	if prefix == "" {
+		return
+	}
+	for _, dp := range sfxDataPoints {
+		dp.Metric = prefix + dp.Metric
+	}
+}
+
+// metricPrefixesByToken returns the metric name prefixes by access token.
+func metricPrefixesByToken(prefixes []AccessTokenMetricPrefix) map[string]string {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	byToken := make(map[string]string, len(prefixes))
+	for _, prefix := range prefixes {
+		byToken[prefix.AccessToken] = prefix.Prefix
+	}
+	return byToken
+}
+
 func (s *sfxDPClient) pushMetricsDataForToken(ctx context.Context, sfxDataPoints []*sfxpb.DataPoint, accessToken string) (int, error) {
+	orderDataPoints(sfxDataPoints, time.Now())
 	body, compressed, err := s.encodeBody(sfxDataPoints)
 	if err != nil {
+		recordDroppedDataPoints(ctx, s.exporterName, dropReasonSerialization, len(sfxDataPoints))
 		return len(sfxDataPoints), consumererror.Permanent(err)
 	}
 
@@ -122,7 +187,11 @@ func (s *sfxDPClient) pushMetricsDataForToken(ctx context.Context, sfxDataPoints
 	// error for metrics is available.
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return len(sfxDataPoints), err
+		// Retried failures are only counted as dropped by the standard exporter metrics once retries are exhausted.
+		if !s.retryOnFailure {
+			recordDroppedDataPoints(ctx, s.exporterName, dropReasonTransport, len(sfxDataPoints))
+		}
+		return len(sfxDataPoints), s.redactor.RedactError(err)
 	}
 
 	io.Copy(ioutil.Discard, resp.Body)
@@ -130,11 +199,29 @@ func (s *sfxDPClient) pushMetricsDataForToken(ctx context.Context, sfxDataPoints
 
 	err = splunk.HandleHTTPCode(resp)
 	if err != nil {
+		// Retried failures are only counted as dropped by the standard exporter metrics once retries are exhausted.
+		if consumererror.IsPermanent(err) || !s.retryOnFailure {
+			recordDroppedDataPoints(ctx, s.exporterName, httpDropReason(resp.StatusCode), len(sfxDataPoints))
+		}
 		return len(sfxDataPoints), err
 	}
 	return 0, nil
 }
 
+// orderDataPoints sets the timestamp of the datapoints missing one to the given send time, and sorts them by
+// timestamp, since ingest handles unordered or zero timestamps poorly, causing gaps in charts.
+func orderDataPoints(dps []*sfxpb.DataPoint, now time.Time) {
+	sendTime := now.UnixNano() / int64(time.Millisecond)
+	for _, dp := range dps {
+		if dp.Timestamp == 0 {
+			dp.Timestamp = sendTime
+		}
+	}
+	sort.SliceStable(dps, func(i, j int) bool {
+		return dps[i].Timestamp < dps[j].Timestamp
+	})
+}
+
 func buildHeaders(config *Config) map[string]string {
 	headers := map[string]string{
 		"Connection":   "keep-alive",
@@ -175,6 +262,7 @@ func (s *sfxDPClient) retrieveAccessToken(md pdata.ResourceMetrics) string {
 
 	attrs := md.Resource().Attributes()
 	if accessToken, ok := attrs.Get(splunk.SFxAccessTokenLabel); ok {
+		s.redactor.AddPassthrough(accessToken.StringVal())
 		return accessToken.StringVal()
 	}
 	return ""
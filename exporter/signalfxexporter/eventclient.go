@@ -36,6 +36,7 @@ type sfxEventClient struct {
 	sfxClientBase
 	logger                 *zap.Logger
 	accessTokenPassthrough bool
+	resourceProperties     []translation.EventResourceProperty
 }
 
 func (s *sfxEventClient) pushLogsData(ctx context.Context, ld pdata.Logs) (int, error) {
@@ -54,7 +55,7 @@ func (s *sfxEventClient) pushLogsData(ctx context.Context, ld pdata.Logs) (int,
 		ills := rl.InstrumentationLibraryLogs()
 		for j := 0; j < ills.Len(); j++ {
 			ill := ills.At(j)
-			events, dropped := translation.LogSliceToSignalFxV2(s.logger, ill.Logs(), rl.Resource().Attributes())
+			events, dropped := translation.LogSliceToSignalFxV2(s.logger, ill.Logs(), rl.Resource().Attributes(), s.resourceProperties)
 			sfxEvents = append(sfxEvents, events...)
 			numDroppedLogRecords += dropped
 		}
@@ -88,7 +89,7 @@ func (s *sfxEventClient) pushLogsData(ctx context.Context, ld pdata.Logs) (int,
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return ld.LogRecordCount(), err
+		return ld.LogRecordCount(), s.redactor.RedactError(err)
 	}
 
 	defer func() {
@@ -118,6 +119,9 @@ func (s *sfxEventClient) encodeBody(events []*sfxpb.Event) (bodyReader io.Reader
 func (s *sfxEventClient) retrieveAccessToken(rl pdata.ResourceLogs) string {
 	attrs := rl.Resource().Attributes()
 	if accessToken, ok := attrs.Get(splunk.SFxAccessTokenLabel); ok && accessToken.Type() == pdata.AttributeValueSTRING {
+		if s.accessTokenPassthrough {
+			s.redactor.AddPassthrough(accessToken.StringVal())
+		}
 		return accessToken.StringVal()
 	}
 	return ""
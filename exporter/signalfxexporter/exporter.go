@@ -26,12 +26,14 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/dimensions"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/hostmetadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/translation"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
 )
 
@@ -49,19 +51,30 @@ type baseLogsExporter struct {
 
 type signalfMetadataExporter struct {
 	component.MetricsExporter
-	pushMetadata func(metadata []*metadata.MetadataUpdate) error
+	pushMetadata  func(metadata []*metadata.MetadataUpdate) error
+	shutdownFlush *shutdownFlush
 }
 
 func (sme *signalfMetadataExporter) ConsumeMetadata(metadata []*metadata.MetadataUpdate) error {
 	return sme.pushMetadata(metadata)
 }
 
+// Shutdown starts the deadline of the datapoints still queued before the queue is drained.
+func (sme *signalfMetadataExporter) Shutdown(ctx context.Context) error {
+	sme.shutdownFlush.begin()
+	err := sme.MetricsExporter.Shutdown(ctx)
+	sme.shutdownFlush.end()
+	return err
+}
+
 type signalfxExporter struct {
 	logger             *zap.Logger
 	pushMetricsData    func(ctx context.Context, md pdata.Metrics) (droppedTimeSeries int, err error)
 	pushMetadata       func(metadata []*metadata.MetadataUpdate) error
 	pushLogsData       func(ctx context.Context, ld pdata.Logs) (droppedLogRecords int, err error)
 	hostMetadataSyncer *hostmetadata.Syncer
+	shutdownFlush      *shutdownFlush
+	preflight          *preflight
 }
 
 type exporterOptions struct {
@@ -88,6 +101,8 @@ func newSignalFxExporter(
 			fmt.Errorf("failed to process %q config: %v", config.Name(), err)
 	}
 
+	redactor := newTokenRedactor(config)
+	logger = redactor.Logger(logger)
 	headers := buildHeaders(config)
 
 	converter, err := translation.NewMetricsConverter(logger, options.metricTranslator, config.ExcludeMetrics, config.IncludeMetrics, config.NonAlphanumericDimensionChars)
@@ -104,27 +119,30 @@ func newSignalFxExporter(
 				//  Or what others change from default values?
 				Timeout: config.Timeout,
 			},
-			zippers: newGzipPool(),
+			zippers:  newGzipPool(),
+			redactor: redactor,
 		},
+		exporterName:           config.Name(),
 		logger:                 logger,
 		accessTokenPassthrough: config.AccessTokenPassthrough,
 		converter:              converter,
+		defaultAccessToken:     config.AccessToken,
+		metricPrefixes:         metricPrefixesByToken(config.AccessTokenMetricPrefixes),
+		dropNaNValues:          config.DropNaNValues,
+		retryOnFailure:         config.RetrySettings.Enabled,
 	}
 
 	dimClient := dimensions.NewDimensionClient(
 		context.Background(),
 		dimensions.DimensionClientOptions{
-			Token:      options.token,
-			APIURL:     options.apiURL,
-			LogUpdates: options.logDimUpdate,
-			Logger:     logger,
-			// Duration to wait between property updates. This might be worth
-			// being made configurable.
-			SendDelay: 10,
-			// In case of having issues sending dimension updates to SignalFx,
-			// buffer a fixed number of updates. Might also be a good candidate
-			// to make configurable.
-			PropertiesMaxBuffered: 10000,
+			Token:                 options.token,
+			APIURL:                options.apiURL,
+			LogUpdates:            options.logDimUpdate,
+			Logger:                logger,
+			SendDelay:             config.DimensionClient.SendDelay,
+			PropertiesMaxBuffered: config.DimensionClient.MaxBuffered,
+			MaxConnsPerHost:       config.DimensionClient.MaxConnsPerHost,
+			Timeout:               config.DimensionClient.Timeout,
 			MetricsConverter:      *converter,
 		})
 	dimClient.Start()
@@ -139,9 +157,21 @@ func newSignalFxExporter(
 		pushMetricsData:    dpClient.pushMetricsData,
 		pushMetadata:       dimClient.PushMetadata,
 		hostMetadataSyncer: hms,
+		shutdownFlush:      newShutdownFlush(config.Name(), config.ShutdownFlush, logger),
+		preflight:          newPreflight(config, options.ingestURL, headers, logger),
 	}, nil
 }
 
+// newTokenRedactor returns a redactor of the access tokens of the config. Access tokens passed through are added as
+// they are seen.
+func newTokenRedactor(config *Config) *splunk.TokenRedactor {
+	tokens := []string{config.AccessToken}
+	for _, prefix := range config.AccessTokenMetricPrefixes {
+		tokens = append(tokens, prefix.AccessToken)
+	}
+	return splunk.NewTokenRedactor(tokens...)
+}
+
 func newGzipPool() sync.Pool {
 	return sync.Pool{New: func() interface{} {
 		return gzip.NewWriter(nil)
@@ -159,6 +189,8 @@ func newEventExporter(config *Config, logger *zap.Logger) (*signalfxExporter, er
 			fmt.Errorf("failed to process %q config: %v", config.Name(), err)
 	}
 
+	redactor := newTokenRedactor(config)
+	logger = redactor.Logger(logger)
 	headers := buildHeaders(config)
 
 	eventClient := &sfxEventClient{
@@ -170,19 +202,25 @@ func newEventExporter(config *Config, logger *zap.Logger) (*signalfxExporter, er
 				//  Or what others change from default values?
 				Timeout: config.Timeout,
 			},
-			zippers: newGzipPool(),
+			zippers:  newGzipPool(),
+			redactor: redactor,
 		},
 		logger:                 logger,
 		accessTokenPassthrough: config.AccessTokenPassthrough,
+		resourceProperties:     config.EventResourceProperties,
 	}
 
 	return &signalfxExporter{
 		logger:       logger,
 		pushLogsData: eventClient.pushLogsData,
+		preflight:    newPreflight(config, options.ingestURL, headers, logger),
 	}, nil
 }
 
 func (se *signalfxExporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
+	if se.shutdownFlush.abandon(ctx, md) {
+		return consumererror.Permanent(errAbandonedOnShutdown)
+	}
 	_, err := se.pushMetricsData(ctx, md)
 	if err == nil && se.hostMetadataSyncer != nil {
 		se.hostMetadataSyncer.Sync(md)
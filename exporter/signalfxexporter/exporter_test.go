@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -27,18 +28,22 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/dimensions"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/translation"
@@ -431,6 +436,7 @@ func TestConsumeMetricsWithAccessTokenPassthrough(t *testing.T) {
 			cfg.Headers["test_header_"] = tt.name
 			cfg.AccessToken = fromHeaders
 			cfg.AccessTokenPassthrough = tt.accessTokenPassthrough
+			cfg.Preflight.Mode = preflightDisabled
 			sfxExp, err := NewFactory().CreateMetricsExporter(context.Background(), component.ExporterCreateParams{Logger: zap.NewNop()}, cfg)
 			require.NoError(t, err)
 			require.NoError(t, sfxExp.Start(context.Background(), componenttest.NewNopHost()))
@@ -688,6 +694,7 @@ func TestConsumeLogsDataWithAccessTokenPassthrough(t *testing.T) {
 			cfg.Headers["test_header_"] = tt.name
 			cfg.AccessToken = fromHeaders
 			cfg.AccessTokenPassthrough = tt.accessTokenPassthrough
+			cfg.Preflight.Mode = preflightDisabled
 			sfxExp, err := NewFactory().CreateLogsExporter(context.Background(), component.ExporterCreateParams{Logger: zap.NewNop()}, cfg)
 			require.NoError(t, err)
 			require.NoError(t, sfxExp.Start(context.Background(), componenttest.NewNopHost()))
@@ -1073,3 +1080,176 @@ func TestSignalFxExporterConsumeMetadata(t *testing.T) {
 	require.True(t, ok, "SignalFx exporter does not implement metadata.MetadataExporter")
 	require.NotNil(t, kme)
 }
+
+func TestDroppedDataPointsMetric(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	status := int32(http.StatusBadRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	c, err := translation.NewMetricsConverter(zap.NewNop(), nil, []dpfilters.MetricFilter{{MetricName: "filtered"}}, nil, "")
+	require.NoError(t, err)
+	dpClient := &sfxDPClient{
+		sfxClientBase: sfxClientBase{
+			ingestURL: serverURL,
+			client:    &http.Client{Timeout: 1 * time.Second},
+			zippers:   newGzipPool(),
+		},
+		exporterName:   "signalfx/drops",
+		logger:         zap.NewNop(),
+		converter:      c,
+		dropNaNValues:  true,
+		retryOnFailure: true,
+	}
+
+	md := pdata.NewMetrics()
+	md.ResourceMetrics().Resize(1)
+	md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Resize(1)
+	ms := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	for _, name := range []string{"kept", "filtered"} {
+		m := pdata.NewMetric()
+		m.SetName(name)
+		m.SetDataType(pdata.MetricDataTypeIntGauge)
+		m.IntGauge().DataPoints().Resize(1)
+		ms.Append(m)
+	}
+	nan := pdata.NewMetric()
+	nan.SetName("nan")
+	nan.SetDataType(pdata.MetricDataTypeDoubleGauge)
+	nan.DoubleGauge().DataPoints().Resize(1)
+	nan.DoubleGauge().DataPoints().At(0).SetValue(math.NaN())
+	ms.Append(nan)
+
+	dropped := func() map[string]float64 {
+		rows, err := view.RetrieveData(mDroppedDataPoints.Name())
+		require.NoError(t, err)
+		got := map[string]float64{}
+		for _, row := range rows {
+			var exporterName, reason string
+			for _, tg := range row.Tags {
+				switch tg.Key {
+				case tagKeyExporter:
+					exporterName = tg.Value
+				case tagKeyReason:
+					reason = tg.Value
+				}
+			}
+			if exporterName == "signalfx/drops" {
+				got[reason] = row.Data.(*view.SumData).Value
+			}
+		}
+		return got
+	}
+
+	numDropped, err := dpClient.pushMetricsData(context.Background(), md)
+	require.Error(t, err)
+	assert.Equal(t, 1, numDropped)
+	assert.Equal(t, map[string]float64{dropReasonFiltered: 1, dropReasonNaN: 1, dropReasonHTTPClientError: 1}, dropped())
+
+	// Retried failures are not counted as dropped.
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	_, err = dpClient.pushMetricsData(context.Background(), md)
+	require.Error(t, err)
+	assert.NotContains(t, dropped(), dropReasonHTTPServerError)
+
+	// Failures are final without retries.
+	dpClient.retryOnFailure = false
+	_, err = dpClient.pushMetricsData(context.Background(), md)
+	require.Error(t, err)
+	assert.Equal(t, float64(1), dropped()[dropReasonHTTPServerError])
+
+	server.Close()
+	_, err = dpClient.pushMetricsData(context.Background(), md)
+	require.Error(t, err)
+	assert.Equal(t, float64(1), dropped()[dropReasonTransport])
+}
+
+func TestOrderDataPoints(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	dps := []*sfxpb.DataPoint{
+		{Metric: "late", Timestamp: 3000},
+		{Metric: "missing"},
+		{Metric: "early", Timestamp: 1000},
+		{Metric: "late_too", Timestamp: 3000},
+	}
+	orderDataPoints(dps, now)
+
+	var names []string
+	for _, dp := range dps {
+		names = append(names, dp.Metric)
+	}
+	assert.Equal(t, []string{"early", "late", "late_too", "missing"}, names)
+	assert.Equal(t, int64(1600000000000), dps[3].Timestamp)
+}
+
+func TestPrefixMetricNames(t *testing.T) {
+	client := &sfxDPClient{
+		defaultAccessToken: "default",
+		metricPrefixes: metricPrefixesByToken([]AccessTokenMetricPrefix{
+			{AccessToken: "default", Prefix: "org0."},
+			{AccessToken: "passed", Prefix: "org1."},
+		}),
+	}
+	newDataPoints := func() []*sfxpb.DataPoint {
+		return []*sfxpb.DataPoint{{Metric: "cpu.utilization"}, {Metric: "memory.utilization"}}
+	}
+	metricNames := func(dps []*sfxpb.DataPoint) []string {
+		var names []string
+		for _, dp := range dps {
+			names = append(names, dp.Metric)
+		}
+		return names
+	}
+
+	dps := newDataPoints()
+	client.prefixMetricNames(dps, "")
+	assert.Equal(t, []string{"org0.cpu.utilization", "org0.memory.utilization"}, metricNames(dps))
+
+	dps = newDataPoints()
+	client.prefixMetricNames(dps, "passed")
+	assert.Equal(t, []string{"org1.cpu.utilization", "org1.memory.utilization"}, metricNames(dps))
+
+	dps = newDataPoints()
+	client.prefixMetricNames(dps, "other")
+	assert.Equal(t, []string{"cpu.utilization", "memory.utilization"}, metricNames(dps))
+}
+
+func TestTokenRedaction(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	config := &Config{
+		AccessToken:                  "config-secret",
+		AccessTokenPassthroughConfig: splunk.AccessTokenPassthroughConfig{AccessTokenPassthrough: true},
+		// Some proxies authenticate with the token in the query string, which errors then hold.
+		IngestURL:       "http://127.0.0.1:1/?token=config-secret",
+		APIURL:          "http://127.0.0.1:1",
+		TimeoutSettings: exporterhelper.TimeoutSettings{Timeout: time.Second},
+	}
+
+	dpExp, err := newSignalFxExporter(config, zap.New(core))
+	require.NoError(t, err)
+	md := pdata.NewMetrics()
+	md.ResourceMetrics().Resize(1)
+	md.ResourceMetrics().At(0).Resource().Attributes().InsertString(splunk.SFxAccessTokenLabel, "passthrough-secret")
+	err = dpExp.pushMetrics(context.Background(), md)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token=<redacted token>")
+	assert.NotContains(t, err.Error(), "secret")
+	dpExp.logger.Info("Sent with passthrough-secret")
+
+	eventExp, err := newEventExporter(config, zap.New(core))
+	require.NoError(t, err)
+	err = eventExp.pushLogs(context.Background(), makeSampleResourceLogs())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+	eventExp.logger.Info("Sent with config-secret")
+
+	require.Equal(t, 2, logs.Len())
+	assert.NotContains(t, fmt.Sprint(logs.All()), "secret")
+}
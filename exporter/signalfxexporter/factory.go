@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	otelconfig "go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configmodels"
@@ -40,10 +41,19 @@ const (
 	typeStr = "signalfx"
 
 	defaultHTTPTimeout = time.Second * 5
+
+	defaultShutdownFlushTimeout = time.Second * 10
+
+	defaultDimMaxBuffered     = 10000
+	defaultDimSendDelay       = time.Second * 10
+	defaultDimMaxConnsPerHost = 20
+	defaultDimTimeout         = time.Second * 10
 )
 
 // NewFactory creates a factory for SignalFx exporter.
 func NewFactory() component.ExporterFactory {
+	view.Register(MetricViews()...)
+
 	return exporterhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
@@ -69,6 +79,19 @@ func createDefaultConfig() configmodels.Exporter {
 		DeltaTranslationTTL:           3600,
 		Correlation:                   correlation.DefaultConfig(),
 		NonAlphanumericDimensionChars: "_-.",
+		ShutdownFlush: ShutdownFlushSettings{
+			Enabled: true,
+			Timeout: defaultShutdownFlushTimeout,
+		},
+		DimensionClient: DimensionClientSettings{
+			MaxBuffered:     defaultDimMaxBuffered,
+			SendDelay:       defaultDimSendDelay,
+			MaxConnsPerHost: defaultDimMaxConnsPerHost,
+			Timeout:         defaultDimTimeout,
+		},
+		Preflight: PreflightSettings{
+			Mode: preflightWarn,
+		},
 	}
 }
 
@@ -145,6 +168,8 @@ func createMetricsExporter(
 		exp.pushMetrics,
 		// explicitly disable since we rely on http.Client timeout logic.
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		exporterhelper.WithStart(exp.preflight.start),
+		exporterhelper.WithShutdown(exp.preflight.shutdown),
 		exporterhelper.WithRetry(expCfg.RetrySettings),
 		exporterhelper.WithQueue(expCfg.QueueSettings))
 
@@ -164,6 +189,7 @@ func createMetricsExporter(
 	return &signalfMetadataExporter{
 		MetricsExporter: me,
 		pushMetadata:    exp.pushMetadata,
+		shutdownFlush:   exp.shutdownFlush,
 	}, nil
 }
 
@@ -225,6 +251,8 @@ func createLogsExporter(
 		exp.pushLogs,
 		// explicitly disable since we rely on http.Client timeout logic.
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		exporterhelper.WithStart(exp.preflight.start),
+		exporterhelper.WithShutdown(exp.preflight.shutdown),
 		exporterhelper.WithRetry(expCfg.RetrySettings),
 		exporterhelper.WithQueue(expCfg.QueueSettings))
 
@@ -15,6 +15,7 @@ require (
 	github.com/signalfx/signalfx-agent/pkg/apm v0.0.0-20201202163743-65b4fa925fc8
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.7.0
+	go.opencensus.io v0.23.0
 	go.opentelemetry.io/collector v0.22.1-0.20210323150444-0c6757ec71a5
 	go.uber.org/multierr v1.6.0 // indirect
 	go.uber.org/zap v1.16.0
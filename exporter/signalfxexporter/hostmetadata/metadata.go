@@ -15,9 +15,11 @@
 package hostmetadata
 
 import (
+	"strings"
 	"sync"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/translator/conventions"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/dimensions"
@@ -65,6 +67,9 @@ func (s *Syncer) syncOnResource(res pdata.Resource) {
 	}
 
 	props := s.scrapeHostProperties()
+	for k, v := range cloudHierarchyProperties(res) {
+		props[k] = v
+	}
 	if len(props) == 0 {
 		// do not retry if scraping failed.
 		s.logger.Error("Failed to fetch system properties. Host metadata synchronization skipped")
@@ -123,3 +128,32 @@ func (s *Syncer) scrapeHostProperties() map[string]string {
 
 	return props
 }
+
+// cloudHierarchyLevels are the resource attributes describing the cloud hierarchy of a host, from the provider
+// down to the availability zone, and the properties they are synced as.
+var cloudHierarchyLevels = []struct {
+	attribute string
+	property  string
+}{
+	{conventions.AttributeCloudProvider, "cloud_provider"},
+	{conventions.AttributeCloudAccount, "cloud_account_id"},
+	{conventions.AttributeCloudRegion, "cloud_region"},
+	{conventions.AttributeCloudZone, "cloud_availability_zone"},
+}
+
+// cloudHierarchyProperties returns the cloud hierarchy of the resource as properties, along with an sf_hierarchy
+// property joining its levels, so that hosts are grouped without enabling the cloud integrations.
+func cloudHierarchyProperties(res pdata.Resource) map[string]string {
+	props := map[string]string{}
+	var hierarchy []string
+	for _, level := range cloudHierarchyLevels {
+		if v, ok := res.Attributes().Get(level.attribute); ok && v.StringVal() != "" {
+			props[level.property] = v.StringVal()
+			hierarchy = append(hierarchy, v.StringVal())
+		}
+	}
+	if len(hierarchy) > 0 {
+		props["sf_hierarchy"] = strings.Join(hierarchy, "/")
+	}
+	return props
+}
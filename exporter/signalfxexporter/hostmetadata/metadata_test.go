@@ -211,7 +211,10 @@ func TestSyncMetadata(t *testing.T) {
 					ResourceID:    "1234_i-abc",
 					MetadataDelta: metadata.MetadataDelta{
 						MetadataToUpdate: map[string]string{
-							"host_mem_total": "2",
+							"host_mem_total":   "2",
+							"cloud_provider":   "gcp",
+							"cloud_account_id": "1234",
+							"sf_hierarchy":     "gcp/1234",
 						},
 					},
 				},
@@ -221,6 +224,43 @@ func TestSyncMetadata(t *testing.T) {
 				"Failed to scrape host hostOS metadata",
 			},
 		},
+		{
+			name:        "cloud_hierarchy_on_aws",
+			cpuStat:     cpu.InfoStat{},
+			cpuStatErr:  errors.New("failed"),
+			memStat:     mem.VirtualMemoryStat{},
+			memStatErr:  errors.New("failed"),
+			hostStat:    host.InfoStat{},
+			hostStatErr: errors.New("failed"),
+			pushFail:    false,
+			metricsData: generateSampleMetricsData(map[string]string{
+				conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAWS,
+				conventions.AttributeCloudAccount:  "1234",
+				conventions.AttributeCloudRegion:   "us-east-1",
+				conventions.AttributeCloudZone:     "us-east-1a",
+				conventions.AttributeHostID:        "i-abc",
+			}),
+			wantMetadataUpdate: []*metadata.MetadataUpdate{
+				{
+					ResourceIDKey: string(splunk.HostIDKeyAWS),
+					ResourceID:    "i-abc_us-east-1_1234",
+					MetadataDelta: metadata.MetadataDelta{
+						MetadataToUpdate: map[string]string{
+							"cloud_provider":          "aws",
+							"cloud_account_id":        "1234",
+							"cloud_region":            "us-east-1",
+							"cloud_availability_zone": "us-east-1a",
+							"sf_hierarchy":            "aws/1234/us-east-1/us-east-1a",
+						},
+					},
+				},
+			},
+			wantLogs: []string{
+				"Failed to scrape host hostCPU metadata",
+				"Failed to scrape host memory metadata",
+				"Failed to scrape host hostOS metadata",
+			},
+		},
 		{
 			name:     "no_host_id_attrs",
 			cpuStat:  cpu.InfoStat{},
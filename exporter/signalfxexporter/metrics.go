@@ -0,0 +1,85 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"context"
+	"net/http"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/obsreport"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter/translation"
+)
+
+// Reasons for which datapoints are dropped.
+const (
+	dropReasonUnsupported     = "unsupported"
+	dropReasonFiltered        = "filtered"
+	dropReasonNaN             = "nan"
+	dropReasonSerialization   = "serialization"
+	dropReasonTransport       = "transport"
+	dropReasonHTTPClientError = "http_4xx"
+	dropReasonHTTPServerError = "http_5xx"
+	dropReasonShutdown        = "shutdown"
+)
+
+var (
+	tagKeyExporter = tag.MustNewKey(obsreport.ExporterKey)
+	tagKeyReason   = tag.MustNewKey("reason")
+
+	mDroppedDataPoints = stats.Int64(
+		"exporter/signalfx/dropped_datapoints",
+		"Number of datapoints dropped by the exporter, by reason",
+		stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views of the exporter.
+func MetricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mDroppedDataPoints.Name(),
+			Measure:     mDroppedDataPoints,
+			Description: mDroppedDataPoints.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyExporter, tagKeyReason},
+		},
+	}
+}
+
+func recordDroppedDataPoints(ctx context.Context, exporterName string, reason string, count int) {
+	if count == 0 {
+		return
+	}
+	_ = stats.RecordWithTags(
+		ctx,
+		[]tag.Mutator{tag.Upsert(tagKeyExporter, exporterName), tag.Upsert(tagKeyReason, reason)},
+		mDroppedDataPoints.M(int64(count)))
+}
+
+func recordConversionDrops(ctx context.Context, exporterName string, dropped translation.DroppedDataPoints) {
+	recordDroppedDataPoints(ctx, exporterName, dropReasonUnsupported, dropped.Unsupported)
+	recordDroppedDataPoints(ctx, exporterName, dropReasonFiltered, dropped.Filtered)
+}
+
+// httpDropReason classifies a failed response by status code class.
+func httpDropReason(statusCode int) string {
+	if statusCode >= http.StatusInternalServerError {
+		return dropReasonHTTPServerError
+	}
+	return dropReasonHTTPClientError
+}
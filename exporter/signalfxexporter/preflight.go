@@ -0,0 +1,155 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	preflightDisabled = "disabled"
+	preflightWarn     = "warn"
+	preflightRequired = "required"
+)
+
+// preflight validates the access token against the ingest endpoint on start, by sending an empty datapoint upload
+// message that is only accepted with a token of the organization of the realm. In warn mode, the validation runs in
+// the background until done or shut down. A nil preflight validates nothing.
+type preflight struct {
+	mode      string
+	ingestURL *url.URL
+	// realm is the configured realm, empty if the ingest URL was set explicitly.
+	realm   string
+	headers map[string]string
+	client  *http.Client
+	logger  *zap.Logger
+
+	// cancel stops the validation running in the background, and done is closed once it stopped.
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newPreflight(config *Config, ingestURL *url.URL, headers map[string]string, logger *zap.Logger) *preflight {
+	if config.Preflight.Mode == preflightDisabled {
+		return nil
+	}
+	mode := config.Preflight.Mode
+	if mode == "" {
+		mode = preflightWarn
+	}
+	realm := config.Realm
+	if config.IngestURL != "" {
+		realm = ""
+	}
+	return &preflight{
+		mode:      mode,
+		ingestURL: ingestURL,
+		realm:     realm,
+		headers:   headers,
+		client:    &http.Client{Timeout: config.Timeout},
+		logger:    logger,
+	}
+}
+
+// start validates the access token. A failed validation fails starting in required mode, otherwise the validation
+// runs in the background and only logs its diagnostics, not to delay the start of the pipelines.
+func (p *preflight) start(ctx context.Context, _ component.Host) error {
+	if p == nil {
+		return nil
+	}
+	if p.mode == preflightRequired {
+		if err := p.validate(ctx); err != nil {
+			return fmt.Errorf("preflight validation of the access token failed: %w", err)
+		}
+		return nil
+	}
+	var validateCtx context.Context
+	validateCtx, p.cancel = context.WithCancel(context.Background())
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		if err := p.validate(validateCtx); err != nil && validateCtx.Err() == nil {
+			p.logger.Warn("Preflight validation of the access token failed, datapoints are likely to be dropped",
+				zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// shutdown stops the validation running in the background, if any, and waits for it to return.
+func (p *preflight) shutdown(ctx context.Context) error {
+	if p == nil || p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	p.cancel = nil
+	return nil
+}
+
+// validate sends an empty datapoint upload message and returns the diagnostics of its failure, if any.
+func (p *preflight) validate(ctx context.Context) error {
+	datapointURL := *p.ingestURL
+	if !strings.HasSuffix(datapointURL.Path, "v2/datapoint") {
+		datapointURL.Path = path.Join(datapointURL.Path, "v2/datapoint")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, datapointURL.String(), http.NoBody)
+	if err != nil {
+		return err
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if p.realm != "" {
+			return fmt.Errorf("could not reach %s, check that %q is an existing realm: %w", &datapointURL, p.realm, err)
+		}
+		return fmt.Errorf("could not reach %s: %w", &datapointURL, err)
+	}
+	if err := splunk.DrainAndClose(resp.Body); err != nil {
+		// The status code is all the validation needs, the connection is just not reused.
+		p.logger.Debug("Failed to read the rest of the preflight response", zap.Error(err))
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		if p.realm != "" {
+			return fmt.Errorf("the access token was rejected by %s with status %d, check that the token belongs to "+
+				"an organization of realm %q, as shown on the profile page of the organization", &datapointURL, resp.StatusCode, p.realm)
+		}
+		return fmt.Errorf("the access token was rejected by %s with status %d, check that the token belongs to "+
+			"the organization of the ingest URL", &datapointURL, resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, &datapointURL)
+	}
+	p.logger.Debug("Preflight validation of the access token succeeded", zap.Stringer("url", &datapointURL))
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newPreflightServer returns an ingest server accepting datapoints with the given token only.
+func newPreflightServer(t *testing.T, token string, requests chan<- *http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			requests <- r
+		}
+		if r.Header.Get("X-Sf-Token") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func newTestPreflight(t *testing.T, serverURL string, mode string, token string, logger *zap.Logger) *preflight {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AccessToken = token
+	cfg.Realm = "us1"
+	cfg.Preflight.Mode = mode
+	ingestURL, err := url.Parse(serverURL)
+	require.NoError(t, err)
+	return newPreflight(cfg, ingestURL, buildHeaders(cfg), logger)
+}
+
+func TestPreflightDisabled(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Preflight.Mode = preflightDisabled
+	p := newPreflight(cfg, &url.URL{}, nil, zap.NewNop())
+	assert.Nil(t, p)
+	assert.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, p.shutdown(context.Background()))
+}
+
+func TestPreflightRequired(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := newPreflightServer(t, "good", requests)
+	defer server.Close()
+
+	p := newTestPreflight(t, server.URL, preflightRequired, "good", zap.NewNop())
+	require.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	r := <-requests
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "/v2/datapoint", r.URL.Path)
+	assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+}
+
+func TestPreflightRequiredWrongRealm(t *testing.T) {
+	server := newPreflightServer(t, "good", nil)
+	defer server.Close()
+
+	p := newTestPreflight(t, server.URL, preflightRequired, "other-realm", zap.NewNop())
+	err := p.start(context.Background(), componenttest.NewNopHost())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "preflight validation of the access token failed")
+	assert.Contains(t, err.Error(), "with status 401")
+}
+
+func TestPreflightRealmDiagnostics(t *testing.T) {
+	server := newPreflightServer(t, "good", nil)
+	defer server.Close()
+
+	p := newTestPreflight(t, server.URL, preflightRequired, "other-realm", zap.NewNop())
+	err := p.validate(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `check that the token belongs to an organization of realm "us1"`)
+
+	server.Close()
+	err = p.validate(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `check that "us1" is an existing realm`)
+}
+
+func TestPreflightUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	p := newTestPreflight(t, server.URL, preflightRequired, "good", zap.NewNop())
+	assert.EqualError(t, p.validate(context.Background()), "unexpected status 503 from "+server.URL+"/v2/datapoint")
+}
+
+func TestPreflightWarn(t *testing.T) {
+	server := newPreflightServer(t, "good", nil)
+	defer server.Close()
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	p := newTestPreflight(t, server.URL, preflightWarn, "other-realm", zap.New(core))
+	// Starting never fails in warn mode, the diagnostics are logged instead.
+	require.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return logs.FilterMessage("Preflight validation of the access token failed, datapoints are likely to be dropped").Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPreflightWarnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response hangs until the validation is cancelled.
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	p := newTestPreflight(t, server.URL, preflightWarn, "good", zap.New(core))
+	p.client.Timeout = 0
+	require.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.shutdown(ctx))
+	// A validation cancelled by shutting down is not reported as failed.
+	assert.Equal(t, 0, logs.Len())
+	assert.NoError(t, p.shutdown(context.Background()))
+}
@@ -0,0 +1,88 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+var errAbandonedOnShutdown = errors.New("datapoints abandoned on shutdown")
+
+// shutdownFlush bounds the time spent sending the datapoints still queued when the exporter shuts down, and
+// accounts for the datapoints abandoned past it. A nil shutdownFlush never abandons datapoints.
+type shutdownFlush struct {
+	exporterName string
+	settings     ShutdownFlushSettings
+	logger       *zap.Logger
+	// deadline is the time in nanoseconds since epoch after which datapoints are abandoned, 0 until shutdown begins.
+	deadline int64
+	// abandoned counts the datapoints abandoned since shutdown began.
+	abandoned int64
+}
+
+func newShutdownFlush(exporterName string, settings ShutdownFlushSettings, logger *zap.Logger) *shutdownFlush {
+	return &shutdownFlush{exporterName: exporterName, settings: settings, logger: logger}
+}
+
+// begin starts the deadline of the datapoints still queued.
+func (f *shutdownFlush) begin() {
+	if f == nil {
+		return
+	}
+	now := time.Now()
+	deadline := now.UnixNano()
+	switch {
+	case !f.settings.Enabled:
+	case f.settings.Timeout == 0:
+		deadline = math.MaxInt64
+	default:
+		deadline = now.Add(f.settings.Timeout).UnixNano()
+	}
+	atomic.StoreInt64(&f.deadline, deadline)
+}
+
+// abandon returns whether the datapoints must be abandoned rather than sent, accounting for them if so.
+func (f *shutdownFlush) abandon(ctx context.Context, md pdata.Metrics) bool {
+	if f == nil {
+		return false
+	}
+	deadline := atomic.LoadInt64(&f.deadline)
+	if deadline == 0 || time.Now().UnixNano() < deadline {
+		return false
+	}
+	_, count := md.MetricAndDataPointCount()
+	atomic.AddInt64(&f.abandoned, int64(count))
+	recordDroppedDataPoints(ctx, f.exporterName, dropReasonShutdown, count)
+	return true
+}
+
+// end logs how many datapoints were abandoned, once the queue is drained.
+func (f *shutdownFlush) end() {
+	if f == nil {
+		return
+	}
+	if abandoned := atomic.LoadInt64(&f.abandoned); abandoned > 0 {
+		f.logger.Warn("Abandoned queued datapoints on shutdown",
+			zap.Int64("abandoned_datapoints", abandoned),
+			zap.Duration("shutdown_flush_timeout", f.settings.Timeout))
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func testShutdownMetrics(numDataPoints int) pdata.Metrics {
+	md := pdata.NewMetrics()
+	md.ResourceMetrics().Resize(1)
+	md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Resize(1)
+	ms := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	ms.Resize(1)
+	ms.At(0).SetName("gauge")
+	ms.At(0).SetDataType(pdata.MetricDataTypeIntGauge)
+	ms.At(0).IntGauge().DataPoints().Resize(numDataPoints)
+	return md
+}
+
+func TestShutdownFlush_abandon(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings ShutdownFlushSettings
+		begin    bool
+		want     bool
+	}{
+		{
+			name:     "before shutdown",
+			settings: ShutdownFlushSettings{Enabled: false},
+			want:     false,
+		},
+		{
+			name:     "disabled",
+			settings: ShutdownFlushSettings{Enabled: false},
+			begin:    true,
+			want:     true,
+		},
+		{
+			name:     "within timeout",
+			settings: ShutdownFlushSettings{Enabled: true, Timeout: time.Minute},
+			begin:    true,
+			want:     false,
+		},
+		{
+			name:     "past timeout",
+			settings: ShutdownFlushSettings{Enabled: true, Timeout: time.Nanosecond},
+			begin:    true,
+			want:     true,
+		},
+		{
+			name:     "no timeout",
+			settings: ShutdownFlushSettings{Enabled: true},
+			begin:    true,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShutdownFlush("signalfx", tt.settings, zap.NewNop())
+			if tt.begin {
+				f.begin()
+				time.Sleep(time.Millisecond)
+			}
+			assert.Equal(t, tt.want, f.abandon(context.Background(), testShutdownMetrics(1)))
+		})
+	}
+
+	var f *shutdownFlush
+	f.begin()
+	assert.False(t, f.abandon(context.Background(), testShutdownMetrics(1)))
+	f.end()
+}
+
+func TestShutdownFlush_abandonedDataPoints(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	flush := newShutdownFlush("signalfx/shutdown", ShutdownFlushSettings{Enabled: false}, zap.New(core))
+	pushed := 0
+	se := &signalfxExporter{
+		pushMetricsData: func(context.Context, pdata.Metrics) (int, error) {
+			pushed++
+			return 0, nil
+		},
+		shutdownFlush: flush,
+	}
+	require.NoError(t, se.pushMetrics(context.Background(), testShutdownMetrics(2)))
+
+	// The queue is drained by the wrapped exporter's Shutdown, after the deadline has begun.
+	var drainErr error
+	sme := &signalfMetadataExporter{
+		MetricsExporter: &drainingExporter{drain: func() {
+			drainErr = se.pushMetrics(context.Background(), testShutdownMetrics(3))
+		}},
+		shutdownFlush: flush,
+	}
+	require.NoError(t, sme.Shutdown(context.Background()))
+	require.Error(t, drainErr)
+	assert.True(t, consumererror.IsPermanent(drainErr))
+	assert.Equal(t, 1, pushed)
+
+	rows, err := view.RetrieveData(mDroppedDataPoints.Name())
+	require.NoError(t, err)
+	got := map[string]float64{}
+	for _, row := range rows {
+		var exporterName, reason string
+		for _, tg := range row.Tags {
+			switch tg.Key {
+			case tagKeyExporter:
+				exporterName = tg.Value
+			case tagKeyReason:
+				reason = tg.Value
+			}
+		}
+		if exporterName == "signalfx/shutdown" {
+			got[reason] = row.Data.(*view.SumData).Value
+		}
+	}
+	assert.Equal(t, map[string]float64{dropReasonShutdown: 3}, got)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, int64(3), logs.All()[0].ContextMap()["abandoned_datapoints"])
+}
+
+type drainingExporter struct {
+	component.MetricsExporter
+	drain func()
+}
+
+func (e *drainingExporter) Shutdown(context.Context) error {
+	e.drain()
+	return nil
+}
@@ -70,35 +70,58 @@ func NewMetricsConverter(
 	return &MetricsConverter{logger: logger, metricTranslator: t, filterSet: fs, nonAlphanumericDimChars: nonAlphanumericDimChars}, nil
 }
 
-// MetricDataToSignalFxV2 converts the passed in MetricsData to SFx datapoints,
-// returning those datapoints and the number of time series that had to be
-// dropped because of errors or warnings.
+// DroppedDataPoints counts the datapoints dropped during a conversion, by reason.
+type DroppedDataPoints struct {
+	// Unsupported is the number of metrics dropped because their type cannot be converted.
+	Unsupported int
+	// Filtered is the number of datapoints dropped by the exclude/include filters.
+	Filtered int
+}
+
+// Add accumulates the counts of other into d.
+func (d *DroppedDataPoints) Add(other DroppedDataPoints) {
+	d.Unsupported += other.Unsupported
+	d.Filtered += other.Filtered
+}
+
+// MetricDataToSignalFxV2 converts the passed in MetricsData to SFx datapoints.
 func (c *MetricsConverter) MetricDataToSignalFxV2(rm pdata.ResourceMetrics) []*sfxpb.DataPoint {
+	sfxDatapoints, _ := c.MetricDataToSignalFxV2WithDrops(rm)
+	return sfxDatapoints
+}
+
+// MetricDataToSignalFxV2WithDrops converts the passed in MetricsData to SFx datapoints,
+// returning those datapoints and the number of datapoints that had to be dropped.
+func (c *MetricsConverter) MetricDataToSignalFxV2WithDrops(rm pdata.ResourceMetrics) ([]*sfxpb.DataPoint, DroppedDataPoints) {
 	var sfxDatapoints []*sfxpb.DataPoint
+	var dropped DroppedDataPoints
 
 	extraDimensions := resourceToDimensions(rm.Resource())
 
 	for j := 0; j < rm.InstrumentationLibraryMetrics().Len(); j++ {
 		ilm := rm.InstrumentationLibraryMetrics().At(j)
 		for k := 0; k < ilm.Metrics().Len(); k++ {
-			dps := c.metricToSfxDataPoints(ilm.Metrics().At(k), extraDimensions)
+			dps, metricDropped := c.metricToSfxDataPoints(ilm.Metrics().At(k), extraDimensions)
 			sfxDatapoints = append(sfxDatapoints, dps...)
+			dropped.Add(metricDropped)
 		}
 	}
 	c.sanitizeDataPointDimensions(sfxDatapoints)
-	return sfxDatapoints
+	return sfxDatapoints, dropped
 }
 
-func (c *MetricsConverter) metricToSfxDataPoints(metric pdata.Metric, extraDimensions []*sfxpb.Dimension) []*sfxpb.DataPoint {
+func (c *MetricsConverter) metricToSfxDataPoints(metric pdata.Metric, extraDimensions []*sfxpb.Dimension) ([]*sfxpb.DataPoint, DroppedDataPoints) {
 	// TODO: Figure out some efficient way to know how many datapoints there
 	// will be in the given metric.
 	var dps []*sfxpb.DataPoint
+	var dropped DroppedDataPoints
 
 	basePoint := makeBaseDataPoint(metric)
 
 	switch metric.DataType() {
 	case pdata.MetricDataTypeNone:
-		return nil
+		dropped.Unsupported++
+		return nil, dropped
 	case pdata.MetricDataTypeIntGauge:
 		dps = convertIntDatapoints(metric.IntGauge().DataPoints(), basePoint, extraDimensions)
 	case pdata.MetricDataTypeIntSum:
@@ -123,7 +146,10 @@ func (c *MetricsConverter) metricToSfxDataPoints(metric pdata.Metric, extraDimen
 	// list. This will help to include metrics that are excluded by default.
 	resultSliceLen := 0
 	for i, dp := range dps {
-		if !c.filterSet.Matches(dp) {
+		switch {
+		case c.filterSet.Matches(dp):
+			dropped.Filtered++
+		default:
 			if resultSliceLen < i {
 				dps[resultSliceLen] = dp
 			}
@@ -132,7 +158,7 @@ func (c *MetricsConverter) metricToSfxDataPoints(metric pdata.Metric, extraDimen
 	}
 	dps = dps[:resultSliceLen]
 
-	return dps
+	return dps, dropped
 }
 
 func labelsToDimensions(labels pdata.StringMap, extraDims []*sfxpb.Dimension) []*sfxpb.Dimension {
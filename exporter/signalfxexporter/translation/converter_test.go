@@ -970,3 +970,32 @@ func TestMetricsConverter_ConvertDimension(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricDataToSignalFxV2WithDrops(t *testing.T) {
+	rm := pdata.NewResourceMetrics()
+	rm.InstrumentationLibraryMetrics().Resize(1)
+	ms := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+
+	unsupported := pdata.NewMetric()
+	unsupported.SetName("unsupported")
+	ms.Append(unsupported)
+
+	for _, name := range []string{"kept", "filtered"} {
+		m := pdata.NewMetric()
+		m.SetName(name)
+		m.SetDataType(pdata.MetricDataTypeDoubleGauge)
+		m.DoubleGauge().DataPoints().Resize(2)
+		m.DoubleGauge().DataPoints().At(0).SetValue(1)
+		m.DoubleGauge().DataPoints().At(1).SetValue(math.NaN())
+		ms.Append(m)
+	}
+
+	c, err := NewMetricsConverter(zap.NewNop(), nil, []dpfilters.MetricFilter{{MetricName: "filtered"}}, nil, "")
+	require.NoError(t, err)
+	dps, dropped := c.MetricDataToSignalFxV2WithDrops(rm)
+	// NaN values are sent as is.
+	require.Len(t, dps, 2)
+	assert.Equal(t, "kept", dps[0].Metric)
+	assert.Equal(t, "kept", dps[1].Metric)
+	assert.Equal(t, DroppedDataPoints{Unsupported: 1, Filtered: 2}, dropped)
+}
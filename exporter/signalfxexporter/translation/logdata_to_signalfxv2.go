@@ -24,17 +24,36 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
 
+// EventResourceProperty converts a resource attribute to a property of the events of its log records, so that
+// events carry the context of their source, e.g. their host or pod.
+type EventResourceProperty struct {
+	// Attribute is the key of the resource attribute.
+	Attribute string `mapstructure:"attribute"`
+
+	// Property is the key of the event property. Defaults to the key of the attribute.
+	Property string `mapstructure:"property"`
+}
+
+// PropertyKey returns the key of the event property.
+func (p EventResourceProperty) PropertyKey() string {
+	if p.Property == "" {
+		return p.Attribute
+	}
+	return p.Property
+}
+
 func LogSliceToSignalFxV2(
 	logger *zap.Logger,
 	logs pdata.LogSlice,
 	resourceAttrs pdata.AttributeMap,
+	resourceProperties []EventResourceProperty,
 ) ([]*sfxpb.Event, int) {
 	events := make([]*sfxpb.Event, 0, logs.Len())
 	numDroppedLogRecords := 0
 
 	for i := 0; i < logs.Len(); i++ {
 		lr := logs.At(i)
-		event, ok := convertLogRecord(lr, resourceAttrs, logger)
+		event, ok := convertLogRecord(lr, resourceAttrs, resourceProperties, logger)
 		if !ok {
 			numDroppedLogRecords++
 			continue
@@ -45,7 +64,12 @@ func LogSliceToSignalFxV2(
 	return events, numDroppedLogRecords
 }
 
-func convertLogRecord(lr pdata.LogRecord, resourceAttrs pdata.AttributeMap, logger *zap.Logger) (*sfxpb.Event, bool) {
+func convertLogRecord(
+	lr pdata.LogRecord,
+	resourceAttrs pdata.AttributeMap,
+	resourceProperties []EventResourceProperty,
+	logger *zap.Logger,
+) (*sfxpb.Event, bool) {
 	attrs := lr.Attributes()
 
 	categoryVal, ok := attrs.Get(splunk.SFxEventCategoryKey)
@@ -77,6 +101,24 @@ func convertLogRecord(lr pdata.LogRecord, resourceAttrs pdata.AttributeMap, logg
 	}
 	attrs.Delete(splunk.SFxEventPropertiesKey)
 
+	for _, rp := range resourceProperties {
+		key := rp.PropertyKey()
+		v, ok := resourceAttrs.Get(rp.Attribute)
+		// Never send the SignalFX token, and the properties of the log record take priority
+		if !ok || rp.Attribute == splunk.SFxAccessTokenLabel || hasProperty(event.Properties, key) {
+			continue
+		}
+		val, err := attributeValToPropertyVal(v)
+		if err != nil {
+			logger.Debug("Failed to convert resource attribute value to SignalFx property value", zap.Error(err), zap.String("key", rp.Attribute))
+			continue
+		}
+		event.Properties = append(event.Properties, &sfxpb.Property{
+			Key:   key,
+			Value: val,
+		})
+	}
+
 	// keep a record of Resource attributes to add as dimensions
 	// so as not to modify LogRecord attributes
 	resourceAttrsForDimensions := pdata.NewAttributeMap()
@@ -88,6 +130,10 @@ func convertLogRecord(lr pdata.LogRecord, resourceAttrs pdata.AttributeMap, logg
 	})
 
 	addDimension := func(k string, v pdata.AttributeValue) {
+		// Never send the SignalFX token
+		if k == splunk.SFxAccessTokenLabel {
+			return
+		}
 		if v.Type() != pdata.AttributeValueSTRING {
 			logger.Debug("Failed to convert log record or resource attribute value to SignalFx property value, key is not a string", zap.String("key", k))
 			return
@@ -110,6 +156,15 @@ func convertLogRecord(lr pdata.LogRecord, resourceAttrs pdata.AttributeMap, logg
 	return &event, true
 }
 
+func hasProperty(properties []*sfxpb.Property, key string) bool {
+	for _, p := range properties {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
 func attributeValToPropertyVal(v pdata.AttributeValue) (*sfxpb.PropertyValue, error) {
 	var val sfxpb.PropertyValue
 	switch v.Type() {
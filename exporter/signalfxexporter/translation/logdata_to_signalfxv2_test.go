@@ -119,13 +119,22 @@ func TestLogDataToSignalFxEvents(t *testing.T) {
 				return logs
 			}(),
 		},
+		{
+			name:      "access token",
+			sfxEvents: []*sfxpb.Event{buildDefaultSFxEvent()},
+			logData: func() pdata.Logs {
+				logs := buildDefaultLogs()
+				logs.ResourceLogs().At(0).Resource().Attributes().InsertString("com.splunk.signalfx.access_token", "secret")
+				return logs
+			}(),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			resource := tt.logData.ResourceLogs().At(0).Resource()
 			logSlice := tt.logData.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
-			events, dropped := LogSliceToSignalFxV2(zap.NewNop(), logSlice, resource.Attributes())
+			events, dropped := LogSliceToSignalFxV2(zap.NewNop(), logSlice, resource.Attributes(), nil)
 			for i := 0; i < logSlice.Len(); i++ {
 				logSlice.At(i).Attributes().Sort()
 			}
@@ -144,6 +153,46 @@ func TestLogDataToSignalFxEvents(t *testing.T) {
 	}
 }
 
+func TestLogDataToSignalFxEventsResourceProperties(t *testing.T) {
+	resource := pdata.NewResource()
+	resource.Attributes().InsertString("host.name", "myhost")
+	resource.Attributes().InsertString("k8s.pod.name", "mypod")
+	resource.Attributes().InsertInt("cpu.count", 4)
+	resource.Attributes().InsertString("env", "should use event property value instead")
+	resource.Attributes().InsertString("com.splunk.signalfx.access_token", "secret")
+	resource.Attributes().Insert("labels", pdata.NewAttributeValueMap())
+
+	logSlice := pdata.NewLogSlice()
+	logSlice.Resize(1)
+	l := logSlice.At(0)
+	l.SetName("deploy")
+	l.Attributes().InsertInt("com.splunk.signalfx.event_category", int64(sfxpb.EventCategory_USER_DEFINED))
+	propMapVal := pdata.NewAttributeValueMap()
+	propMapVal.MapVal().InsertString("env", "prod")
+	l.Attributes().Insert("com.splunk.signalfx.event_properties", propMapVal)
+
+	events, dropped := LogSliceToSignalFxV2(zap.NewNop(), logSlice, resource.Attributes(), []EventResourceProperty{
+		{Attribute: "host.name"},
+		{Attribute: "k8s.pod.name", Property: "kubernetes_pod_name"},
+		{Attribute: "cpu.count"},
+		{Attribute: "env"},
+		{Attribute: "com.splunk.signalfx.access_token"},
+		{Attribute: "labels"},
+		{Attribute: "missing"},
+	})
+	assert.Equal(t, 0, dropped)
+	assert.Len(t, events, 1)
+	sort.Slice(events[0].Properties, func(i, j int) bool {
+		return events[0].Properties[i].Key < events[0].Properties[j].Key
+	})
+	assert.Equal(t, mapToEventProps(map[string]interface{}{
+		"cpu.count":           4,
+		"env":                 "prod",
+		"host.name":           "myhost",
+		"kubernetes_pod_name": "mypod",
+	}), events[0].Properties)
+}
+
 func mapToEventProps(m map[string]interface{}) []*sfxpb.Property {
 	var out []*sfxpb.Property
 	for k, v := range m {
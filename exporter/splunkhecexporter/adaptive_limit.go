@@ -0,0 +1,73 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// minAdaptiveContentLength is the size below which the adaptive request size limit is never shrunk.
+const minAdaptiveContentLength = 1024
+
+// adaptiveLimit tracks the request size limit, halving it when the endpoint rejects a request as too large or
+// times out, and growing it back by a quarter after each successful request up to max_content_length.
+type adaptiveLimit struct {
+	max    int
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	current int
+}
+
+func newAdaptiveLimit(config *Config, logger *zap.Logger) *adaptiveLimit {
+	if !config.AdaptiveContentLength {
+		return nil
+	}
+	return &adaptiveLimit{
+		max:     int(config.MaxContentLength),
+		logger:  logger,
+		current: int(config.MaxContentLength),
+	}
+}
+
+func (l *adaptiveLimit) get() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
+
+func (l *adaptiveLimit) shrink() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	shrunk := l.current / 2
+	if shrunk < minAdaptiveContentLength {
+		shrunk = minAdaptiveContentLength
+	}
+	if shrunk < l.current {
+		l.current = shrunk
+		l.logger.Info("Reduced HEC request size limit", zap.Int("max_content_length", shrunk))
+	}
+}
+
+func (l *adaptiveLimit) grow() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.current += l.current / 4
+	if l.current > l.max {
+		l.current = l.max
+	}
+}
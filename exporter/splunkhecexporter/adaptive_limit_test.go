@@ -0,0 +1,103 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	assert.Nil(t, newAdaptiveLimit(&Config{MaxContentLength: 4096}, zap.NewNop()))
+
+	l := newAdaptiveLimit(&Config{MaxContentLength: 4096, AdaptiveContentLength: true}, zap.NewNop())
+	assert.Equal(t, 4096, l.get())
+	l.shrink()
+	assert.Equal(t, 2048, l.get())
+	l.shrink()
+	l.shrink()
+	assert.Equal(t, minAdaptiveContentLength, l.get())
+	l.grow()
+	assert.Equal(t, 1280, l.get())
+	for i := 0; i < 10; i++ {
+		l.grow()
+	}
+	assert.Equal(t, 4096, l.get())
+}
+
+func TestAdaptiveContentLength(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	var readErrs []error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		sizes = append(sizes, len(body))
+		if err != nil {
+			readErrs = append(readErrs, err)
+		}
+		mu.Unlock()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(body) > 1500 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.MaxContentLength = 4000
+	config.AdaptiveContentLength = true
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	ld := createLogData(20)
+	for attempt := 0; attempt < 3; attempt++ {
+		err = c.pushLogData(context.Background(), ld)
+		if err == nil {
+			break
+		}
+		partialErr, ok := err.(consumererror.PartialError)
+		require.True(t, ok)
+		ld = partialErr.GetLogs()
+	}
+	require.NoError(t, err)
+	assert.Less(t, c.contentLength(), 4000)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Empty(t, readErrs)
+	require.Greater(t, len(sizes), 2)
+	for _, size := range sizes[2:] {
+		assert.LessOrEqual(t, size, 1500)
+	}
+}
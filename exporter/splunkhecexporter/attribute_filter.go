@@ -0,0 +1,132 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+const (
+	// matchTypeStrict matches attribute keys exactly.
+	matchTypeStrict = "strict"
+	// matchTypeRegexp matches attribute keys with regular expressions.
+	matchTypeRegexp = "regexp"
+)
+
+// attributeFilter decides which attributes are sent to Splunk. A nil filter keeps all attributes.
+type attributeFilter struct {
+	include   keyMatcher
+	exclude   keyMatcher
+	dropEmpty bool
+}
+
+// keyMatcher matches attribute keys against a list of exact keys or regular expressions.
+type keyMatcher struct {
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+func newAttributeFilter(settings AttributesFilterSettings) (*attributeFilter, error) {
+	if len(settings.Include) == 0 && len(settings.Exclude) == 0 && !settings.DropEmpty {
+		return nil, nil
+	}
+	include, err := newKeyMatcher(settings.MatchType, settings.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newKeyMatcher(settings.MatchType, settings.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &attributeFilter{include: include, exclude: exclude, dropEmpty: settings.DropEmpty}, nil
+}
+
+func newKeyMatcher(matchType string, keys []string) (keyMatcher, error) {
+	var m keyMatcher
+	switch matchType {
+	case "", matchTypeStrict:
+		m.keys = make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			m.keys[key] = struct{}{}
+		}
+	case matchTypeRegexp:
+		for _, key := range keys {
+			pattern, err := regexp.Compile(key)
+			if err != nil {
+				return m, fmt.Errorf("invalid pattern %q: %v", key, err)
+			}
+			m.patterns = append(m.patterns, pattern)
+		}
+	default:
+		return m, fmt.Errorf("unsupported match_type %q, must be %q or %q", matchType, matchTypeStrict, matchTypeRegexp)
+	}
+	return m, nil
+}
+
+func (m keyMatcher) empty() bool {
+	return len(m.keys) == 0 && len(m.patterns) == 0
+}
+
+func (m keyMatcher) matches(key string) bool {
+	if _, ok := m.keys[key]; ok {
+		return true
+	}
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// keep returns whether the attribute with the given key is sent: it must match the include list, if any,
+// and must not match the exclude list.
+func (f *attributeFilter) keep(key string) bool {
+	if f == nil {
+		return true
+	}
+	if !f.include.empty() && !f.include.matches(key) {
+		return false
+	}
+	return !f.exclude.matches(key)
+}
+
+// keepAttribute returns whether the attribute is sent: its key must be kept, and its value must not be null or an
+// empty string if empty values are dropped.
+func (f *attributeFilter) keepAttribute(key string, value pdata.AttributeValue) bool {
+	if f != nil && f.dropEmpty {
+		switch value.Type() {
+		case pdata.AttributeValueNULL:
+			return false
+		case pdata.AttributeValueSTRING:
+			if value.StringVal() == "" {
+				return false
+			}
+		}
+	}
+	return f.keep(key)
+}
+
+// keepLabel returns whether the metric label is sent: its key must be kept, and its value must not be empty if
+// empty values are dropped.
+func (f *attributeFilter) keepLabel(key string, value string) bool {
+	if f != nil && f.dropEmpty && value == "" {
+		return false
+	}
+	return f.keep(key)
+}
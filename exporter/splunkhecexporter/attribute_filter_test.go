@@ -0,0 +1,90 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+func TestAttributeFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings AttributesFilterSettings
+		kept     []string
+		dropped  []string
+	}{
+		{
+			name:     "no lists",
+			settings: AttributesFilterSettings{},
+			kept:     []string{"k8s.pod.name", "user.email"},
+		},
+		{
+			name:     "strict exclude",
+			settings: AttributesFilterSettings{Exclude: []string{"user.email"}},
+			kept:     []string{"k8s.pod.name", "user.email.verified"},
+			dropped:  []string{"user.email"},
+		},
+		{
+			name: "regexp include and exclude",
+			settings: AttributesFilterSettings{
+				MatchType: "regexp",
+				Include:   []string{`^k8s\.`, `^host\.name$`},
+				Exclude:   []string{`\.uid$`},
+			},
+			kept:    []string{"k8s.pod.name", "host.name"},
+			dropped: []string{"k8s.pod.uid", "user.email", "host.name.alias"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newAttributeFilter(tt.settings)
+			require.NoError(t, err)
+			for _, key := range tt.kept {
+				assert.True(t, filter.keep(key), key)
+			}
+			for _, key := range tt.dropped {
+				assert.False(t, filter.keep(key), key)
+			}
+		})
+	}
+}
+
+func TestAttributeFilterDropEmpty(t *testing.T) {
+	var filter *attributeFilter
+	assert.True(t, filter.keepAttribute("user.email", pdata.NewAttributeValueString("")))
+	assert.True(t, filter.keepLabel("user.email", ""))
+
+	filter, err := newAttributeFilter(AttributesFilterSettings{Exclude: []string{"user.email"}, DropEmpty: true})
+	require.NoError(t, err)
+	assert.True(t, filter.keepAttribute("k8s.pod.name", pdata.NewAttributeValueString("pod")))
+	assert.True(t, filter.keepAttribute("retries", pdata.NewAttributeValueInt(0)))
+	assert.False(t, filter.keepAttribute("k8s.pod.name", pdata.NewAttributeValueString("")))
+	assert.False(t, filter.keepAttribute("k8s.pod.name", pdata.NewAttributeValueNull()))
+	assert.False(t, filter.keepAttribute("user.email", pdata.NewAttributeValueString("a@b.c")))
+	assert.True(t, filter.keepLabel("path", "/"))
+	assert.False(t, filter.keepLabel("path", ""))
+}
+
+func TestAttributeFilterInvalid(t *testing.T) {
+	_, err := newAttributeFilter(AttributesFilterSettings{MatchType: "regexp", Exclude: []string{"("}})
+	assert.EqualError(t, err, "invalid pattern \"(\": error parsing regexp: missing closing ): `(`")
+
+	_, err = newAttributeFilter(AttributesFilterSettings{MatchType: "glob", Exclude: []string{"*"}})
+	assert.EqualError(t, err, `unsupported match_type "glob", must be "strict" or "regexp"`)
+}
@@ -0,0 +1,225 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	authToken                   = "token"
+	authBasic                   = "basic"
+	authOAuth2ClientCredentials = "oauth2_client_credentials"
+
+	// oauth2RefreshMargin is how long before their expiry OAuth2 access tokens are refreshed, so that requests are
+	// not sent with a token expiring in flight.
+	oauth2RefreshMargin = 30 * time.Second
+	// defaultOAuth2TokenLifetime is the lifetime of the OAuth2 access tokens whose expiry is not told.
+	defaultOAuth2TokenLifetime = time.Hour
+	// maxOAuth2ResponseSize is the maximum size of the responses of the token endpoint that are read.
+	maxOAuth2ResponseSize = 1 << 20
+)
+
+// The sources of the secrets redacted from the logs, each replacing the previous secret of its source.
+const (
+	redactionSourceBasicAuth          = "basic_auth_password"
+	redactionSourceOAuth2ClientSecret = "oauth2_client_secret"
+	redactionSourceOAuth2AccessToken  = "oauth2_access_token"
+	redactionSourceTokenFile          = "token_file"
+)
+
+// Authenticator authenticates the requests to HEC. The built-in strategies are selected by the auth settings, and
+// Go programs embedding the exporter can plug in their own with the Authenticator field of Config.
+type Authenticator interface {
+	// Authenticate sets the credentials of the request, before the static headers are set. Requests that cannot be
+	// authenticated fail with the error, and are retried.
+	Authenticate(req *http.Request) error
+}
+
+// credentialsInvalidator is implemented by the authenticators whose credentials can be renewed once rejected.
+type credentialsInvalidator interface {
+	// invalidate discards the credentials, renewed for the next requests.
+	invalidate()
+}
+
+// newAuthenticator returns the authenticator of the requests: the one of the config if any, or else the built-in
+// strategy of the auth settings.
+func newAuthenticator(config *Config, tokenFile *tokenFile, redactor *splunk.TokenRedactor) Authenticator {
+	if config.Authenticator != nil {
+		return config.Authenticator
+	}
+	switch config.Auth.Type {
+	case authBasic:
+		redactor.Set(redactionSourceBasicAuth, config.Auth.Password)
+		return &basicAuthenticator{username: config.Auth.Username, password: config.Auth.Password}
+	case authOAuth2ClientCredentials:
+		redactor.Set(redactionSourceOAuth2ClientSecret, config.Auth.OAuth2.ClientSecret)
+		return &oauth2Authenticator{
+			settings: config.Auth.OAuth2,
+			client: &http.Client{
+				Timeout: config.Timeout,
+				Transport: &http.Transport{
+					Proxy:               http.ProxyFromEnvironment,
+					TLSHandshakeTimeout: tlsHandshakeTimeout,
+					TLSClientConfig:     newTLSConfig(config),
+				},
+			},
+			redactor: redactor,
+			now:      time.Now,
+		}
+	}
+	return &tokenAuthenticator{token: config.Token, file: tokenFile}
+}
+
+// tokenAuthenticator sends the HEC token, either static or read from token_file.
+type tokenAuthenticator struct {
+	token string
+	file  *tokenFile
+}
+
+func (a *tokenAuthenticator) Authenticate(req *http.Request) error {
+	token := a.token
+	if a.file != nil {
+		token = a.file.get()
+	}
+	if token != "" {
+		req.Header.Set("Authorization", splunk.HECTokenHeader+" "+token)
+	}
+	return nil
+}
+
+// basicAuthenticator sends a username and password with HTTP basic authentication.
+type basicAuthenticator struct {
+	username string
+	password string
+}
+
+func (a *basicAuthenticator) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+// oauth2Authenticator sends an OAuth2 bearer access token obtained with the client credentials grant. The token is
+// reused until shortly before it expires, or until HEC rejects it.
+type oauth2Authenticator struct {
+	settings OAuth2Settings
+	client   *http.Client
+	// redactor redacts the access tokens from logs and errors.
+	redactor *splunk.TokenRedactor
+	now      func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time
+}
+
+func (a *oauth2Authenticator) Authenticate(req *http.Request) error {
+	token, err := a.get(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// get returns the current access token, requesting a new one if there is none or it is about to expire. Concurrent
+// requests wait for a single token request.
+func (a *oauth2Authenticator) get(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && a.now().Before(a.refresh) {
+		return a.token, nil
+	}
+	token, lifetime, err := a.request(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get an OAuth2 access token: %w", err)
+	}
+	a.redactor.Set(redactionSourceOAuth2AccessToken, token)
+	margin := oauth2RefreshMargin
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	a.token = token
+	a.refresh = a.now().Add(lifetime - margin)
+	return token, nil
+}
+
+func (a *oauth2Authenticator) invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+}
+
+// oauth2TokenResponse is the successful response of a token endpoint, as defined by RFC 6749 section 5.1.
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// request requests an access token with the client credentials grant, and returns it along with its lifetime.
+func (a *oauth2Authenticator) request(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.settings.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.settings.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.settings.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// The client credentials are sent with basic authentication, as recommended by RFC 6749 section 2.3.1.
+	req.SetBasicAuth(url.QueryEscape(a.settings.ClientID), url.QueryEscape(a.settings.ClientSecret))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxOAuth2ResponseSize))
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("the token endpoint answered HTTP %d", resp.StatusCode)
+	}
+	var token oauth2TokenResponse
+	if err = json.Unmarshal(body, &token); err != nil {
+		return "", 0, fmt.Errorf("invalid response of the token endpoint: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", 0, errors.New("the token endpoint answered no access token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", token.TokenType)
+	}
+	lifetime := defaultOAuth2TokenLifetime
+	if token.ExpiresIn > 0 {
+		lifetime = time.Duration(token.ExpiresIn) * time.Second
+	}
+	return token.AccessToken, lifetime, nil
+}
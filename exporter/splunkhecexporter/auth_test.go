@@ -0,0 +1,266 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// tokenServer is an OAuth2 token endpoint issuing access-token-1, access-token-2...
+type tokenServer struct {
+	*httptest.Server
+	expiresIn int
+
+	mu       sync.Mutex
+	requests int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	s := &tokenServer{expiresIn: expiresIn}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		// The client credentials are form-encoded, as required by RFC 6749 section 2.3.1.
+		id, _ = url.QueryUnescape(id)
+		assert.Equal(t, "my id", id)
+		assert.Equal(t, "s3cr3t-client-secret", secret)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "hec.write hec.read", r.PostForm.Get("scope"))
+
+		s.mu.Lock()
+		s.requests++
+		n := s.requests
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"access-token-%d","token_type":"Bearer","expires_in":%d}`, n, s.expiresIn)
+	}))
+	return s
+}
+
+func (s *tokenServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func oauth2Config(tokenURL string) *Config {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Auth = AuthSettings{
+		Type: "oauth2_client_credentials",
+		OAuth2: OAuth2Settings{
+			TokenURL:     tokenURL,
+			ClientID:     "my id",
+			ClientSecret: "s3cr3t-client-secret",
+			Scopes:       []string{"hec.write", "hec.read"},
+		},
+	}
+	return config
+}
+
+func authorization(t *testing.T, auth Authenticator) string {
+	req, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.NoError(t, err)
+	require.NoError(t, auth.Authenticate(req))
+	return req.Header.Get("Authorization")
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	auth := newAuthenticator(&Config{Token: "1234"}, nil, nil)
+	assert.Equal(t, "Splunk 1234", authorization(t, auth))
+
+	auth = newAuthenticator(&Config{}, nil, nil)
+	assert.Equal(t, "", authorization(t, auth))
+}
+
+func TestBasicAuthenticator(t *testing.T) {
+	redactor := splunk.NewTokenRedactor()
+	auth := newAuthenticator(&Config{Auth: AuthSettings{Type: "basic", Username: "user", Password: "hunter2-password"}}, nil, redactor)
+	assert.Equal(t, "Basic dXNlcjpodW50ZXIyLXBhc3N3b3Jk", authorization(t, auth))
+	assert.Equal(t, "the password is <redacted token>", redactor.Redact("the password is hunter2-password"))
+}
+
+func TestCustomAuthenticator(t *testing.T) {
+	custom := &basicAuthenticator{username: "custom"}
+	assert.Same(t, custom, newAuthenticator(&Config{Token: "1234", Authenticator: custom}, nil, nil))
+}
+
+func TestOAuth2Authenticator(t *testing.T) {
+	server := newTokenServer(t, 120)
+	defer server.Close()
+
+	redactor := splunk.NewTokenRedactor()
+	auth := newAuthenticator(oauth2Config(server.URL), nil, redactor).(*oauth2Authenticator)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	assert.Equal(t, "Bearer access-token-1", authorization(t, auth))
+	assert.Equal(t, "Bearer access-token-1", authorization(t, auth))
+	assert.Equal(t, 1, server.count())
+	assert.Equal(t, "<redacted token> <redacted token>", redactor.Redact("access-token-1 s3cr3t-client-secret"))
+
+	// The token is refreshed 30 seconds before it expires.
+	now = now.Add(89 * time.Second)
+	assert.Equal(t, "Bearer access-token-1", authorization(t, auth))
+	now = now.Add(time.Second)
+	assert.Equal(t, "Bearer access-token-2", authorization(t, auth))
+	assert.Equal(t, 2, server.count())
+	// The refreshed token replaces the previous one in the redactor.
+	assert.Equal(t, "access-token-1 <redacted token>", redactor.Redact("access-token-1 access-token-2"))
+
+	auth.invalidate()
+	assert.Equal(t, "Bearer access-token-3", authorization(t, auth))
+	assert.Equal(t, 3, server.count())
+}
+
+func TestOAuth2AuthenticatorShortLifetime(t *testing.T) {
+	server := newTokenServer(t, 10)
+	defer server.Close()
+
+	auth := newAuthenticator(oauth2Config(server.URL), nil, nil).(*oauth2Authenticator)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	// Tokens expiring within the refresh margin are reused for half their lifetime.
+	assert.Equal(t, "Bearer access-token-1", authorization(t, auth))
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, "Bearer access-token-1", authorization(t, auth))
+	now = now.Add(time.Second)
+	assert.Equal(t, "Bearer access-token-2", authorization(t, auth))
+}
+
+func TestOAuth2AuthenticatorErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		err    string
+	}{
+		{
+			name:   "error status",
+			status: http.StatusUnauthorized,
+			body:   `{"error":"invalid_client"}`,
+			err:    "failed to get an OAuth2 access token: the token endpoint answered HTTP 401",
+		},
+		{
+			name:   "invalid json",
+			status: http.StatusOK,
+			body:   `access_token=abc`,
+			err:    "failed to get an OAuth2 access token: invalid response of the token endpoint: invalid character 'a' looking for beginning of value",
+		},
+		{
+			name:   "no access token",
+			status: http.StatusOK,
+			body:   `{"token_type":"Bearer"}`,
+			err:    "failed to get an OAuth2 access token: the token endpoint answered no access token",
+		},
+		{
+			name:   "unsupported token type",
+			status: http.StatusOK,
+			body:   `{"access_token":"abc","token_type":"mac"}`,
+			err:    `failed to get an OAuth2 access token: unsupported token type "mac"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			auth := newAuthenticator(oauth2Config(server.URL), nil, nil)
+			req, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+			require.NoError(t, err)
+			assert.EqualError(t, auth.Authenticate(req), tt.err)
+			assert.Empty(t, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestClientOAuth2(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	defer tokens.Close()
+
+	var mu sync.Mutex
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		// The first token is revoked.
+		if r.Header.Get("Authorization") == "Bearer access-token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	config := oauth2Config(tokens.URL)
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), nil))
+	defer func() { assert.NoError(t, c.stop(context.Background())) }()
+
+	assert.Error(t, c.pushLogData(context.Background(), createLogData(1)))
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Bearer access-token-1", "Bearer access-token-2", "Bearer access-token-2"}, authorizations)
+	assert.Equal(t, 2, tokens.count())
+}
+
+type failingAuthenticator struct{}
+
+func (failingAuthenticator) Authenticate(*http.Request) error {
+	return errors.New("no credentials")
+}
+
+func TestClientAuthenticatorFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Endpoint = server.URL
+	config.Authenticator = failingAuthenticator{}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), nil))
+	defer func() { assert.NoError(t, c.stop(context.Background())) }()
+
+	err = c.pushLogData(context.Background(), createLogData(1))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no credentials")
+}
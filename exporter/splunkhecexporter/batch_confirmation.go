@@ -0,0 +1,52 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// BatchConfirmation describes a batch of records accepted by HEC.
+type BatchConfirmation struct {
+	// Signal is the signal of the records, "logs", "metrics" or "traces".
+	Signal string
+	// Records is the number of log records, metrics or spans of the batch.
+	Records int
+	// Events is the number of HEC events the records were sent as.
+	Events int
+	// Bytes is the size in bytes of the uncompressed payload of the batch.
+	Bytes int
+}
+
+// BatchConfirmationFunc is called with each batch of records accepted by HEC. The context is the one of the records
+// being exported, or a background context for the logs flushed by logs_buffer.
+type BatchConfirmationFunc func(ctx context.Context, confirmation BatchConfirmation)
+
+// confirm reports the pending records from one cursor up to the other, excluded, accepted by HEC in a payload of the
+// given size to the OnBatchAccepted callback, if any. Payloads only captured by a dry run were not accepted.
+func (s *chunkSender) confirm(ctx context.Context, from splunk.ChunkCursor, to splunk.ChunkCursor, size int) {
+	onAccepted := s.client.config.OnBatchAccepted
+	if onAccepted == nil || (s.client.capturer != nil && s.client.config.PayloadCapture.DryRun) {
+		return
+	}
+	onAccepted(ctx, BatchConfirmation{
+		Signal:  s.signal,
+		Records: int(to - from),
+		Events:  s.chunk.EventCount(from, to),
+		Bytes:   size,
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOnBatchAccepted(t *testing.T) {
+	var requests int32
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sizes = append(sizes, len(body))
+		// The third request fails.
+		if atomic.AddInt32(&requests, 1) == 3 {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.MaxEventCount = 2
+	var confirmations []BatchConfirmation
+	config.OnBatchAccepted = func(_ context.Context, confirmation BatchConfirmation) {
+		confirmations = append(confirmations, confirmation)
+	}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	assert.Error(t, c.pushLogData(context.Background(), createLogData(6)))
+	require.Len(t, sizes, 3)
+	assert.Equal(t, []BatchConfirmation{
+		{Signal: "logs", Records: 2, Events: 2, Bytes: sizes[0]},
+		{Signal: "logs", Records: 2, Events: 2, Bytes: sizes[1]},
+	}, confirmations)
+}
+
+func TestOnBatchAcceptedDryRun(t *testing.T) {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "http://localhost:0"
+	config.PayloadCapture = PayloadCaptureSettings{Enabled: true, DryRun: true}
+	config.OnBatchAccepted = func(context.Context, BatchConfirmation) {
+		t.Error("dry runs must not be confirmed")
+	}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	assert.NoError(t, c.pushLogData(context.Background(), createLogData(2)))
+}
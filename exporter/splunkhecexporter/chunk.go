@@ -0,0 +1,481 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// eventIndex locates a log record, metric or span within its pdata structure.
+type eventIndex struct {
+	resource int
+	library  int
+	record   int
+}
+
+// relativeTo returns the index of the record within the copy of its batch from the given index onwards, as made by
+// subLogs, subMetrics and subTraces.
+func (i eventIndex) relativeTo(from eventIndex) eventIndex {
+	switch {
+	case i.resource != from.resource:
+		return eventIndex{resource: i.resource - from.resource, library: i.library, record: i.record}
+	case i.library != from.library:
+		return eventIndex{library: i.library - from.library, record: i.record}
+	default:
+		return eventIndex{record: i.record - from.record}
+	}
+}
+
+// chunkSender accumulates the serialized events of consecutive records and posts them as a request whenever
+// the next record would exceed max_content_length or max_event_count.
+type chunkSender struct {
+	client *client
+	// signal is the signal of the records, "logs", "metrics" or "traces".
+	signal string
+	// chunk holds the serialized records pending to be posted.
+	chunk  splunk.Chunker
+	record *bytes.Buffer
+	// first is the index of the first pending record, only meaningful when the chunk is not empty.
+	first eventIndex
+	// indexes holds the index of each pending record.
+	indexes []eventIndex
+	// times holds the time of the first event of each pending record, in seconds since epoch, NaN for records whose
+	// first event has no time.
+	times         []float64
+	permanentErrs []error
+	// drops counts the dropped records by reason.
+	drops map[string]*droppedRecords
+	// retried is whether the records of failed chunks are retried, otherwise they are counted as dropped.
+	retried bool
+	// prechunked is whether the records were batched upstream for a single request, and are only split when exceeding
+	// max_content_length or max_event_count.
+	prechunked bool
+	// marked holds the indexes of the records of the batch whose send failed before, as marked by the retry tracker.
+	marked map[eventIndex]struct{}
+	// retrying holds whether each pending record is sent again after a failed request.
+	retrying []bool
+}
+
+// chunkSenders holds the released senders, whose buffers grew up to the size of a request, so that the following
+// batches reuse them rather than allocating new ones.
+var chunkSenders = sync.Pool{New: func() interface{} { return &chunkSender{record: new(bytes.Buffer)} }}
+
+func newChunkSender(c *client, signal string) *chunkSender {
+	s := chunkSenders.Get().(*chunkSender)
+	s.client = c
+	s.signal = signal
+	s.retried = c.config.RetrySettings.Enabled
+	return s
+}
+
+// release returns the sender to the pool once its batch was pushed. Neither the sender nor the payloads it handed
+// out may be used afterwards.
+func (s *chunkSender) release() {
+	s.reset()
+	s.record.Reset()
+	s.client = nil
+	s.first = eventIndex{}
+	s.permanentErrs = nil
+	s.drops = nil
+	s.prechunked = false
+	s.marked = nil
+	chunkSenders.Put(s)
+}
+
+// add appends the events of the record at the given index. Records that cannot be serialized or do not fit
+// in a request on their own are dropped. If a chunk had to be posted and failed, the records from
+// unsent() onwards were not sent.
+func (s *chunkSender) add(ctx context.Context, index eventIndex, events []*splunk.Event) error {
+	s.record.Reset()
+	events = s.handleNonFiniteValues(events)
+	if len(events) == 0 {
+		return nil
+	}
+	s.client.config.semanticConventions.apply(events)
+	s.client.config.redactor.redact(events)
+	events = splitEvents(events, int(s.client.config.MaxEventFields), s.client.config.DeterministicOutput)
+	if err := encodeEventsTo(s.record, events, s.client.config.eventSeparator()); err != nil {
+		s.drop(dropReasonSerializationFailed, err.Error())
+		s.permanentErrs = append(s.permanentErrs, consumererror.Permanent(fmt.Errorf("dropped record: %w", err)))
+		return nil
+	}
+
+	maxLength := int(s.client.config.MaxContentLength)
+	if !(splunk.ChunkBudget{MaxContentLength: maxLength}).Admits(s.record.Len(), len(events)) {
+		s.drop(dropReasonTooLarge, fmt.Sprintf("%d bytes", s.record.Len()))
+		s.permanentErrs = append(s.permanentErrs, consumererror.Permanent(fmt.Errorf(
+			"dropped record: size of %d bytes is larger than max_content_length of %d bytes", s.record.Len(), maxLength)))
+		return nil
+	}
+	maxCount := int(s.client.config.MaxEventCount)
+	if !(splunk.ChunkBudget{MaxEventCount: maxCount}).Admits(s.record.Len(), len(events)) {
+		s.drop(dropReasonTooLarge, fmt.Sprintf("%d events", len(events)))
+		s.permanentErrs = append(s.permanentErrs, consumererror.Permanent(fmt.Errorf(
+			"dropped record: %d events are more than max_event_count of %d", len(events), maxCount)))
+		return nil
+	}
+	budget := splunk.ChunkBudget{
+		MaxContentLength: s.client.contentLength(),
+		MaxEventCount:    maxCount,
+		SplitByMetadata:  s.client.config.SplitByMetadata && !s.prechunked,
+	}
+	metadata := splunk.EventChunkMetadata(events[0])
+	if !s.chunk.Fits(budget, s.record.Len(), len(events), metadata) {
+		if s.prechunked {
+			s.client.logger.Debug("Splitting pre-chunked records exceeding the limits of a request", zap.Int("records", s.chunk.Len()))
+		}
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.chunk.Len() == 0 {
+		s.first = index
+	}
+	s.chunk.Add(s.record.Bytes(), len(events), metadata)
+	s.indexes = append(s.indexes, index)
+	_, retrying := s.marked[index]
+	s.retrying = append(s.retrying, retrying)
+	if events[0].Time != nil {
+		s.times = append(s.times, *events[0].Time)
+	} else {
+		s.times = append(s.times, math.NaN())
+	}
+	return nil
+}
+
+// drop counts a record dropped for the given reason, keeping the first example of each reason.
+func (s *chunkSender) drop(reason string, example string) {
+	s.dropRecords(reason, 1, example)
+}
+
+// dropRecords counts several records dropped for the given reason.
+func (s *chunkSender) dropRecords(reason string, count int, example string) {
+	if s.drops == nil {
+		s.drops = map[string]*droppedRecords{}
+	}
+	d, ok := s.drops[reason]
+	if !ok {
+		d = &droppedRecords{example: example}
+		s.drops[reason] = d
+	}
+	d.count += count
+}
+
+// flush posts the pending chunk, if any. If the chunk was partly sent before failing, the records that were not sent
+// are kept pending.
+func (s *chunkSender) flush(ctx context.Context) error {
+	if s.chunk.Len() == 0 {
+		return nil
+	}
+	sent, err := s.post(ctx, 0, splunk.ChunkCursor(s.chunk.Len()))
+	if err != nil {
+		s.discard(sent)
+		if !s.retried || consumererror.IsPermanent(err) {
+			s.dropRecords(sendFailureReason(err), s.chunk.Len(), err.Error())
+		} else {
+			// The pending records are sent again, either with the next flush of the log buffer or with the batch that
+			// the exporter helper pushes again.
+			for i := range s.retrying {
+				s.retrying[i] = true
+			}
+		}
+		return err
+	}
+	s.reset()
+	return nil
+}
+
+// post posts the pending records from the given position up to the other, excluded, and returns how many of them
+// were sent or dropped. Records whose compressed payload exceeds the content length, e.g. incompressible data, are
+// split in halves posted separately, rather than sending a request bound to be rejected, and a single record is
+// dropped.
+func (s *chunkSender) post(ctx context.Context, from splunk.ChunkCursor, to splunk.ChunkCursor) (int, error) {
+	chunk := bytes.NewBuffer(s.chunk.Payload(from, to))
+	size := chunk.Len()
+	err := s.client.postEvents(ctx, chunk)
+	if err == errCompressedTooLarge && to-from == 1 {
+		s.drop(dropReasonTooLarge, fmt.Sprintf("%d bytes", size))
+		s.permanentErrs = append(s.permanentErrs, consumererror.Permanent(fmt.Errorf(
+			"dropped record: compressed payload of %d bytes is larger than the content length of %d bytes",
+			size, s.client.contentLength())))
+		return 1, nil
+	}
+	if err == errCompressedTooLarge {
+		s.client.logger.Debug("Splitting the records of a compressed payload larger than the content length",
+			zap.Int("records", int(to-from)), zap.Int("uncompressed_size", chunk.Len()))
+		middle := from + (to-from)/2
+		sent, err := s.post(ctx, from, middle)
+		if err != nil {
+			return sent, err
+		}
+		more, err := s.post(ctx, middle, to)
+		return sent + more, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	recordEventLatencies(ctx, s.client.config.Name(), s.signal, now, s.times[from:to])
+	s.recordSent(ctx, from, to)
+	s.confirm(ctx, from, to, size)
+	return int(to - from), nil
+}
+
+// recordSent counts the events of the given records as sent, either for the first time or as retried.
+func (s *chunkSender) recordSent(ctx context.Context, from splunk.ChunkCursor, to splunk.ChunkCursor) {
+	first, retried := s.chunk.EventCount(from, to), 0
+	for i := from; i < to; i++ {
+		if s.retrying[i] {
+			events := s.chunk.EventCount(i, i+1)
+			first -= events
+			retried += events
+		}
+	}
+	recordSentEvents(ctx, s.client.config.Name(), s.signal, attemptFirst, first)
+	recordSentEvents(ctx, s.client.config.Name(), s.signal, attemptRetry, retried)
+}
+
+// discard removes the given number of records from the start of the pending chunk.
+func (s *chunkSender) discard(records int) {
+	if records == 0 {
+		return
+	}
+	if records == s.chunk.Len() {
+		s.reset()
+		return
+	}
+	s.chunk.Continue(splunk.ChunkCursor(records))
+	s.indexes = s.indexes[:copy(s.indexes, s.indexes[records:])]
+	s.times = s.times[:copy(s.times, s.times[records:])]
+	s.retrying = s.retrying[:copy(s.retrying, s.retrying[records:])]
+	s.first = s.indexes[0]
+}
+
+// reset discards the pending chunk.
+func (s *chunkSender) reset() {
+	s.chunk.Reset()
+	s.indexes = s.indexes[:0]
+	s.times = s.times[:0]
+	s.retrying = s.retrying[:0]
+}
+
+// unsent returns the index of the first record that has not been sent.
+func (s *chunkSender) unsent() eventIndex {
+	return s.first
+}
+
+// err reports the records dropped while all chunks were sent.
+func (s *chunkSender) err() error {
+	return consumererror.CombineErrors(s.permanentErrs)
+}
+
+// failedLogs returns the error reporting the log records of ld from the first pending one onwards as failed, and marks
+// the pending records, whose send failed, on the batch that the exporter helper pushes again.
+func (s *chunkSender) failedLogs(err error, ld pdata.Logs) error {
+	from := s.unsent()
+	err = partialLogsError(err, ld, from)
+	if partial, ok := err.(consumererror.PartialError); ok {
+		s.markRetried(err, partial.GetLogs(), from)
+	} else {
+		s.markRetried(err, ld, from)
+	}
+	return err
+}
+
+// failedMetrics returns the error reporting the metrics of md from the first pending one onwards as failed, and
+// marks the pending records, whose send failed, on the batch that the exporter helper pushes again.
+func (s *chunkSender) failedMetrics(err error, md pdata.Metrics) error {
+	from := s.unsent()
+	err = partialMetricsError(err, md, from)
+	if partial, ok := err.(consumererror.PartialError); ok {
+		s.markRetried(err, partial.GetMetrics(), from)
+	} else {
+		s.markRetried(err, md, from)
+	}
+	return err
+}
+
+// failedTraces returns the error reporting the spans of td from the first pending one onwards as failed, and marks
+// the pending records, whose send failed, on the batch that the exporter helper pushes again.
+func (s *chunkSender) failedTraces(err error, td pdata.Traces) error {
+	from := s.unsent()
+	err = partialTracesError(err, td, from)
+	if partial, ok := err.(consumererror.PartialError); ok {
+		s.markRetried(err, partial.GetTraces(), from)
+	} else {
+		s.markRetried(err, td, from)
+	}
+	return err
+}
+
+// markRetried marks the pending records on the batch pushed again after the given error, whose first record is the
+// one at the given index of the failed batch, unless the batch is not retried.
+func (s *chunkSender) markRetried(err error, batch interface{}, from eventIndex) {
+	if s.client.retries == nil || consumererror.IsPermanent(err) || s.chunk.Len() == 0 {
+		return
+	}
+	records := make([]eventIndex, len(s.indexes))
+	for i, index := range s.indexes {
+		records[i] = index.relativeTo(from)
+	}
+	s.client.retries.failed(batch, records, time.Now())
+}
+
+// partialLogsError reports the log records from the given index onwards as failed, unless retrying is pointless.
+func partialLogsError(err error, ld pdata.Logs, from eventIndex) error {
+	if consumererror.IsPermanent(err) || keepThrottled(err, from) {
+		return err
+	}
+	return consumererror.PartialLogsError(err, subLogs(ld, from))
+}
+
+// partialMetricsError reports the metrics from the given index onwards as failed, unless retrying is pointless.
+func partialMetricsError(err error, md pdata.Metrics, from eventIndex) error {
+	if consumererror.IsPermanent(err) || keepThrottled(err, from) {
+		return err
+	}
+	return consumererror.PartialMetricsError(err, subMetrics(md, from))
+}
+
+// partialTracesError reports the spans from the given index onwards as failed, unless retrying is pointless.
+func partialTracesError(err error, td pdata.Traces, from eventIndex) error {
+	if consumererror.IsPermanent(err) || keepThrottled(err, from) {
+		return err
+	}
+	return consumererror.PartialTracesError(err, subTraces(td, from))
+}
+
+// keepThrottled tells whether a ThrottledError is returned as is, rather than as a partial error which hides it from
+// errors.As, because nothing was sent and the whole batch failed.
+func keepThrottled(err error, from eventIndex) bool {
+	return from == eventIndex{} && isThrottled(err)
+}
+
+// subLogs returns a copy of the log records of ld from the given index onwards.
+func subLogs(ld pdata.Logs, from eventIndex) pdata.Logs {
+	sub := pdata.NewLogs()
+	rls := ld.ResourceLogs()
+	sub.ResourceLogs().Resize(rls.Len() - from.resource)
+	for i := from.resource; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		subRL := sub.ResourceLogs().At(i - from.resource)
+		rl.Resource().CopyTo(subRL.Resource())
+
+		ills := rl.InstrumentationLibraryLogs()
+		firstLibrary := 0
+		if i == from.resource {
+			firstLibrary = from.library
+		}
+		subRL.InstrumentationLibraryLogs().Resize(ills.Len() - firstLibrary)
+		for j := firstLibrary; j < ills.Len(); j++ {
+			ill := ills.At(j)
+			subILL := subRL.InstrumentationLibraryLogs().At(j - firstLibrary)
+			ill.InstrumentationLibrary().CopyTo(subILL.InstrumentationLibrary())
+
+			logs := ill.Logs()
+			firstRecord := 0
+			if i == from.resource && j == from.library {
+				firstRecord = from.record
+			}
+			subILL.Logs().Resize(logs.Len() - firstRecord)
+			for k := firstRecord; k < logs.Len(); k++ {
+				logs.At(k).CopyTo(subILL.Logs().At(k - firstRecord))
+			}
+		}
+	}
+	return sub
+}
+
+// subMetrics returns a copy of the metrics of md from the given index onwards.
+func subMetrics(md pdata.Metrics, from eventIndex) pdata.Metrics {
+	sub := pdata.NewMetrics()
+	rms := md.ResourceMetrics()
+	sub.ResourceMetrics().Resize(rms.Len() - from.resource)
+	for i := from.resource; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		subRM := sub.ResourceMetrics().At(i - from.resource)
+		rm.Resource().CopyTo(subRM.Resource())
+
+		ilms := rm.InstrumentationLibraryMetrics()
+		firstLibrary := 0
+		if i == from.resource {
+			firstLibrary = from.library
+		}
+		subRM.InstrumentationLibraryMetrics().Resize(ilms.Len() - firstLibrary)
+		for j := firstLibrary; j < ilms.Len(); j++ {
+			ilm := ilms.At(j)
+			subILM := subRM.InstrumentationLibraryMetrics().At(j - firstLibrary)
+			ilm.InstrumentationLibrary().CopyTo(subILM.InstrumentationLibrary())
+
+			metrics := ilm.Metrics()
+			firstRecord := 0
+			if i == from.resource && j == from.library {
+				firstRecord = from.record
+			}
+			subILM.Metrics().Resize(metrics.Len() - firstRecord)
+			for k := firstRecord; k < metrics.Len(); k++ {
+				metrics.At(k).CopyTo(subILM.Metrics().At(k - firstRecord))
+			}
+		}
+	}
+	return sub
+}
+
+// subTraces returns a copy of the spans of td from the given index onwards.
+func subTraces(td pdata.Traces, from eventIndex) pdata.Traces {
+	sub := pdata.NewTraces()
+	rss := td.ResourceSpans()
+	sub.ResourceSpans().Resize(rss.Len() - from.resource)
+	for i := from.resource; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		subRS := sub.ResourceSpans().At(i - from.resource)
+		rs.Resource().CopyTo(subRS.Resource())
+
+		ilss := rs.InstrumentationLibrarySpans()
+		firstLibrary := 0
+		if i == from.resource {
+			firstLibrary = from.library
+		}
+		subRS.InstrumentationLibrarySpans().Resize(ilss.Len() - firstLibrary)
+		for j := firstLibrary; j < ilss.Len(); j++ {
+			ils := ilss.At(j)
+			subILS := subRS.InstrumentationLibrarySpans().At(j - firstLibrary)
+			ils.InstrumentationLibrary().CopyTo(subILS.InstrumentationLibrary())
+
+			spans := ils.Spans()
+			firstRecord := 0
+			if i == from.resource && j == from.library {
+				firstRecord = from.record
+			}
+			subILS.Spans().Resize(spans.Len() - firstRecord)
+			for k := firstRecord; k < spans.Len(); k++ {
+				spans.At(k).CopyTo(subILS.Spans().At(k - firstRecord))
+			}
+		}
+	}
+	return sub
+}
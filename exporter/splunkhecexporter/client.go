@@ -18,14 +18,18 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/andybalholm/brotli"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/pdata"
@@ -36,15 +40,43 @@ import (
 
 // client sends the data to the splunk backend.
 type client struct {
-	config  *Config
-	url     *url.URL
-	client  *http.Client
-	logger  *zap.Logger
-	zippers sync.Pool
-	wg      sync.WaitGroup
-	headers map[string]string
+	config    *Config
+	url       *url.URL
+	client    *http.Client
+	logger    *zap.Logger
+	zippers   sync.Pool
+	wg        sync.WaitGroup
+	headers   map[string]string
+	capturer  *payloadCapturer
+	logBuffer *logBuffer
+	limit     *adaptiveLimit
+	resets    *counterResetDetector
+	deltas    *deltaConverter
+	throttle  *throttleTracker
+	// redactor redacts the tokens from logs, errors and diagnostics.
+	redactor *splunk.TokenRedactor
+	// tokenFile holds the token of token_file, if any.
+	tokenFile *tokenFile
+	// auth authenticates the requests.
+	auth Authenticator
+	// retries tracks the records of failed requests until they are retried, if retries are enabled.
+	retries *retryTracker
+	// dns rotates the connections once the addresses of the endpoint changed, if enabled.
+	dns *dnsRefresher
+	// diagnostics reports the dropped records to the diagnostics exporter, if any.
+	diagnostics *diagnostics
+	// socketPath is the unix domain socket requests are sent to, if any.
+	socketPath string
+	// devModeDone stops the dev mode warnings.
+	devModeDone chan struct{}
+	// cancelWarmup stops warming up the endpoint, and warmupDone is closed once it stopped.
+	cancelWarmup context.CancelFunc
+	warmupDone   chan struct{}
 }
 
+// devModeWarningInterval is the interval of the warnings logged while dev_mode is enabled.
+const devModeWarningInterval = 5 * time.Minute
+
 func (c *client) pushMetricsData(
 	ctx context.Context,
 	md pdata.Metrics,
@@ -52,47 +84,50 @@ func (c *client) pushMetricsData(
 	c.wg.Add(1)
 	defer c.wg.Done()
 
-	splunkDataPoints, _ := metricDataToSplunk(c.logger, md, c.config)
-	if len(splunkDataPoints) == 0 {
-		return nil
-	}
-
-	body, compressed, err := encodeBody(&c.zippers, splunkDataPoints, c.config.DisableCompression)
-	if err != nil {
-		return consumererror.Permanent(err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.url.String(), body)
-	if err != nil {
-		return consumererror.Permanent(err)
-	}
-
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
-	}
-
-	if compressed {
-		req.Header.Set("Content-Encoding", "gzip")
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return err
+	sender := newChunkSender(c, "metrics")
+	sender.marked = c.retries.retried(md, time.Now())
+	defer sender.release()
+	defer func() { c.reportDrops(ctx, "metrics", sender.drops) }()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		meta := newMetricResourceMetadata(rm.Resource(), c.config, c.logger)
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			var pending []metricEvent
+			for k := 0; k < metrics.Len(); k++ {
+				events, supported := mapMetricToSplunkEvent(meta, metrics.At(k), c.logger)
+				if !supported {
+					sender.drop(dropReasonUnsupportedMetricType, metrics.At(k).Name())
+					continue
+				}
+				events = c.deltas.convert(meta, metrics.At(k), events)
+				events = append(events, c.resets.detect(meta, metrics.At(k))...)
+				index := eventIndex{resource: i, library: j, record: k}
+				if c.config.UseMultiMetricFormat {
+					for _, event := range events {
+						pending = append(pending, metricEvent{index: index, event: event})
+					}
+					continue
+				}
+				if err := sender.add(ctx, index, events); err != nil {
+					return sender.failedMetrics(err, md)
+				}
+			}
+			// Multi-metric events hold data points of several metrics, which are all retried from the first one
+			// if the event fails to be sent.
+			for _, e := range mergeMetricEvents(pending) {
+				if err := sender.add(ctx, e.index, []*splunk.Event{e.event}); err != nil {
+					return sender.failedMetrics(err, md)
+				}
+			}
+		}
 	}
-
-	io.Copy(ioutil.Discard, resp.Body)
-	resp.Body.Close()
-
-	// Splunk accepts all 2XX codes.
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		err = fmt.Errorf(
-			"HTTP %d %q",
-			resp.StatusCode,
-			http.StatusText(resp.StatusCode))
-		return err
+	if err := sender.flush(ctx); err != nil {
+		return sender.failedMetrics(err, md)
 	}
-
-	return nil
+	return sender.err()
 }
 
 func (c *client) pushTraceData(
@@ -102,96 +137,301 @@ func (c *client) pushTraceData(
 	c.wg.Add(1)
 	defer c.wg.Done()
 
-	splunkEvents, _ := traceDataToSplunk(c.logger, td, c.config)
-	if len(splunkEvents) == 0 {
-		return nil
+	sender := newChunkSender(c, "traces")
+	sender.marked = c.retries.retried(td, time.Now())
+	defer sender.release()
+	defer func() { c.reportDrops(ctx, "traces", sender.drops) }()
+	filter := newSpanFilter(td, c.config.ErrorSpansOnly)
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		meta := newSpanResourceMetadata(rs.Resource(), c.config, c.logger)
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			libraryMeta := withInstrumentationLibrary(meta, ilss.At(j).InstrumentationLibrary())
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				if !filter.keep(spans.At(k)) {
+					continue
+				}
+				events := mapSpanToSplunkEvents(libraryMeta, spans.At(k), c.config, c.logger)
+				if err := sender.add(ctx, eventIndex{resource: i, library: j, record: k}, events); err != nil {
+					return sender.failedTraces(err, td)
+				}
+			}
+		}
 	}
+	if err := sender.flush(ctx); err != nil {
+		return sender.failedTraces(err, td)
+	}
+	return sender.err()
+}
+
+func (c *client) pushLogData(ctx context.Context, ld pdata.Logs) error {
+	c.wg.Add(1)
+	defer c.wg.Done()
 
-	return c.sendSplunkEvents(ctx, splunkEvents)
+	prechunked := isPrechunked(ld)
+	// Pre-chunked batches are sent right away, rather than waiting for more events to be buffered.
+	if c.logBuffer != nil && !prechunked {
+		return c.logBuffer.push(ctx, ld)
+	}
+
+	sender := newChunkSender(c, "logs")
+	sender.prechunked = prechunked
+	sender.marked = c.retries.retried(ld, time.Now())
+	defer sender.release()
+	defer func() { c.reportDrops(ctx, "logs", sender.drops) }()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				event := mapLogRecordToSplunkEvent(rl.Resource(), logs.At(k), c.config, c.logger)
+				if err := sender.add(ctx, eventIndex{resource: i, library: j, record: k}, []*splunk.Event{event}); err != nil {
+					return sender.failedLogs(err, ld)
+				}
+			}
+		}
+	}
+	if err := sender.flush(ctx); err != nil {
+		return sender.failedLogs(err, ld)
+	}
+	return sender.err()
 }
 
-func (c *client) sendSplunkEvents(ctx context.Context, splunkEvents []*splunk.Event) error {
-	body, compressed, err := encodeBodyEvents(&c.zippers, splunkEvents, c.config.DisableCompression)
-	if err != nil {
-		return consumererror.Permanent(err)
+// isPrechunked tells whether all the resources of the batch are marked as already batched for a single request.
+func isPrechunked(ld pdata.Logs) bool {
+	rls := ld.ResourceLogs()
+	if rls.Len() == 0 {
+		return false
+	}
+	for i := 0; i < rls.Len(); i++ {
+		v, ok := rls.At(i).Resource().Attributes().Get(splunk.HecPrechunkedLabel)
+		if !ok || v.Type() != pdata.AttributeValueBOOL || !v.BoolVal() {
+			return false
+		}
 	}
+	return true
+}
+
+// reportDrops records the records of the signal dropped while pushing a batch, and reports them to the diagnostics
+// exporter, if any.
+func (c *client) reportDrops(ctx context.Context, signal string, drops map[string]*droppedRecords) {
+	for reason, d := range drops {
+		recordDroppedRecords(ctx, c.config.Name(), signal, reason, d.count)
+		d.example = c.redactor.Redact(d.example)
+	}
+	c.diagnostics.report(ctx, signal, drops)
+}
+
+// errCompressedTooLarge is returned by postEvents, without sending the request, when the compressed payload
+// exceeds the content length.
+var errCompressedTooLarge = errors.New("compressed payload larger than the content length")
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.url.String(), body)
+// postEvents sends a chunk of serialized events to the HEC endpoint. A compressed payload exceeding the content
+// length is not sent, and errCompressedTooLarge is returned so that its records are split, or dropped when single.
+func (c *client) postEvents(ctx context.Context, buf *bytes.Buffer) error {
+	body, compressed, err := getReader(&c.zippers, buf, c.config.DisableCompression)
 	if err != nil {
 		return consumererror.Permanent(err)
 	}
+	payload := &requestBody{refs: 1}
+	if compressed {
+		payload.pooled = body
+	}
+	defer payload.release()
+	if limit := c.contentLength(); compressed && limit > 0 && body.Len() > limit {
+		return errCompressedTooLarge
+	}
 
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
+	if c.capturer != nil {
+		c.capturer.capture(buf.Bytes())
+		if c.config.PayloadCapture.DryRun {
+			return nil
+		}
+	}
+	if compressed && c.config.VerifyCompression {
+		if err = verifyCompressed(c.config.contentEncoding(), body.Bytes(), buf.Bytes()); err != nil {
+			c.logger.Error("Compressed payload failed verification, sending it uncompressed", zap.Error(err))
+			body, compressed = buf, false
+		}
 	}
 
-	if compressed {
-		req.Header.Set("Content-Encoding", "gzip")
+	if !compressed {
+		// The uncompressed payload is the buffer of the chunk sender, reused by the next batch once this one returns
+		// while the transport may still be writing it, so the request gets its own copy.
+		if payload.pooled != nil {
+			releaseBodyBuffer(payload.pooled)
+		}
+		body = bodyBuffers.Get().(*bytes.Buffer)
+		body.Write(buf.Bytes())
+		payload.pooled = body
 	}
 
-	resp, err := c.client.Do(req)
+	requestID := newCorrelationID()
+	payload.data = body.Bytes()
+	resp, err := c.send(ctx, payload, compressed, requestID)
 	if err != nil {
-		return err
+		var netErr net.Error
+		if c.limit != nil && errors.As(err, &netErr) && netErr.Timeout() {
+			c.limit.shrink()
+		}
+		// The errors are logged by the exporter helper, out of reach of the redacting logger of the client.
+		return c.redactor.RedactError(err)
 	}
 
-	io.Copy(ioutil.Discard, resp.Body)
-	resp.Body.Close()
+	_ = splunk.DrainAndClose(resp.Body)
 
 	// Splunk accepts all 2XX codes.
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		err = fmt.Errorf(
-			"HTTP %d %q",
-			resp.StatusCode,
-			http.StatusText(resp.StatusCode))
-		return err
+		if c.limit != nil && resp.StatusCode == http.StatusRequestEntityTooLarge {
+			c.limit.shrink()
+		}
+		if invalidator, ok := c.auth.(credentialsInvalidator); ok && resp.StatusCode == http.StatusUnauthorized {
+			invalidator.invalidate()
+		}
+		return c.throttle.check(resp, &httpStatusError{statusCode: resp.StatusCode, requestID: requestID})
+	}
+	_ = c.throttle.check(resp, nil)
+	if c.limit != nil {
+		c.limit.grow()
 	}
 	return nil
 }
 
-func (c *client) pushLogData(ctx context.Context, ld pdata.Logs) error {
-	c.wg.Add(1)
-	defer c.wg.Done()
+// setHeaders authenticates a request to HEC and sets its headers.
+func (c *client) setHeaders(req *http.Request) error {
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return err
+		}
+	}
+	// The static headers take precedence over the credentials.
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	return nil
+}
+
+// requestIDHeader is the header holding the id of each request, logged by splunkd.
+const requestIDHeader = "X-Request-Id"
 
-	splunkEvents := logDataToSplunk(c.logger, ld, c.config)
-	if len(splunkEvents) == 0 {
-		return nil
+// httpStatusError is the error of requests answered with a non-2XX status code.
+type httpStatusError struct {
+	statusCode int
+	// requestID is the id of the request, if any.
+	requestID string
+}
+
+func (e *httpStatusError) Error() string {
+	if e.requestID != "" {
+		return fmt.Sprintf("HTTP %d %q (request id %s)", e.statusCode, http.StatusText(e.statusCode), e.requestID)
 	}
+	return fmt.Sprintf("HTTP %d %q", e.statusCode, http.StatusText(e.statusCode))
+}
 
-	return c.sendSplunkEvents(ctx, splunkEvents)
+// sendFailureReason returns the reason records are dropped for when sending them failed with the given error.
+func sendFailureReason(err error) string {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return dropReasonHTTPError
+	}
+	return dropReasonTransportError
 }
 
-func encodeBodyEvents(zippers *sync.Pool, evs []*splunk.Event, disableCompression bool) (bodyReader io.Reader, compressed bool, err error) {
-	buf := new(bytes.Buffer)
-	encoder := json.NewEncoder(buf)
+// contentLength returns the current maximum size of a request body, 0 if unlimited.
+func (c *client) contentLength() int {
+	if c.limit != nil {
+		return c.limit.get()
+	}
+	return int(c.config.MaxContentLength)
+}
+
+// encodeEventsTo serializes the events into the uncompressed HEC wire format. Maps are serialized with sorted keys, as
+// deterministic_output relies on. Each event is followed by the separator.
+func encodeEventsTo(buf *bytes.Buffer, evs []*splunk.Event, separator string) error {
+	bPtr := encodingBuffers.Get().(*[]byte)
+	defer encodingBuffers.Put(bPtr)
 	for _, e := range evs {
-		err := encoder.Encode(e)
+		b, err := appendEvent((*bPtr)[:0], e)
+		*bPtr = b
 		if err != nil {
-			return nil, false, err
+			return err
 		}
-		buf.WriteString("\r\n\r\n")
+		buf.Write(b)
+		buf.WriteString(separator)
 	}
-	return getReader(zippers, buf, disableCompression)
+	return nil
 }
 
-func encodeBody(zippers *sync.Pool, dps []*splunk.Event, disableCompression bool) (bodyReader io.Reader, compressed bool, err error) {
-	buf := new(bytes.Buffer)
-	encoder := json.NewEncoder(buf)
-	for _, e := range dps {
-		err := encoder.Encode(e)
-		if err != nil {
-			return nil, false, err
+// compressor is implemented by the gzip and brotli writers.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// newCompressor returns the constructor of the writers compressing requests with the given content-encoding.
+func newCompressor(compression string) func() interface{} {
+	if compression == compressionBrotli {
+		return func() interface{} {
+			return brotli.NewWriter(nil)
 		}
-		buf.WriteString("\r\n\r\n")
 	}
-	return getReader(zippers, buf, disableCompression)
+	return func() interface{} {
+		return gzip.NewWriter(nil)
+	}
 }
 
-// avoid attempting to compress things that fit into a single ethernet frame
-func getReader(zippers *sync.Pool, b *bytes.Buffer, disableCompression bool) (io.Reader, bool, error) {
+// bodyBuffers holds the buffers of the bodies of the requests that were sent.
+var bodyBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+func releaseBodyBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	bodyBuffers.Put(buf)
+}
+
+// requestBody is the payload of a request, shared by its attempts. Its buffer taken from bodyBuffers is only
+// released once the client and the transport closed all the readers of the attempts, as the transport may still be
+// writing the body after the response was received.
+type requestBody struct {
+	data   []byte
+	pooled *bytes.Buffer
+	refs   int32
+}
+
+// reader returns a reader of the payload, holding it until closed.
+func (b *requestBody) reader() io.ReadCloser {
+	atomic.AddInt32(&b.refs, 1)
+	return &requestBodyReader{Reader: bytes.NewReader(b.data), body: b}
+}
+
+func (b *requestBody) release() {
+	if atomic.AddInt32(&b.refs, -1) == 0 && b.pooled != nil {
+		releaseBodyBuffer(b.pooled)
+	}
+}
+
+type requestBodyReader struct {
+	*bytes.Reader
+	body *requestBody
+	once sync.Once
+}
+
+func (r *requestBodyReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
+
+// avoid attempting to compress things that fit into a single ethernet frame. Compressed bodies are taken from
+// bodyBuffers, to be released once sent.
+func getReader(zippers *sync.Pool, b *bytes.Buffer, disableCompression bool) (*bytes.Buffer, bool, error) {
 	var err error
 	if !disableCompression && b.Len() > 1500 {
-		buf := new(bytes.Buffer)
-		w := zippers.Get().(*gzip.Writer)
+		buf := bodyBuffers.Get().(*bytes.Buffer)
+		w := zippers.Get().(compressor)
 		defer zippers.Put(w)
 		w.Reset(buf)
 		_, err = w.Write(b.Bytes())
@@ -201,15 +441,156 @@ func getReader(zippers *sync.Pool, b *bytes.Buffer, disableCompression bool) (io
 				return buf, true, nil
 			}
 		}
+		releaseBodyBuffer(buf)
 	}
 	return b, false, err
 }
 
-func (c *client) stop(context context.Context) error {
-	c.wg.Wait()
+// verifyCompressed checks that the compressed payload decompresses to the original one. The gzip reader also
+// checks the CRC-32 and size recorded in the gzip trailer.
+func verifyCompressed(compression string, compressed []byte, original []byte) error {
+	var r io.Reader
+	if compression == compressionBrotli {
+		r = brotli.NewReader(bytes.NewReader(compressed))
+	} else {
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return err
+		}
+		r = zr
+	}
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(decompressed, original) {
+		return fmt.Errorf("decompressed payload of %d bytes differs from the original payload of %d bytes", len(decompressed), len(original))
+	}
+	return nil
+}
+
+// flush immediately sends the buffered log events.
+func (c *client) flush(ctx context.Context) error {
+	if c.logBuffer != nil {
+		return c.logBuffer.flush(ctx)
+	}
 	return nil
 }
 
-func (c *client) start(context.Context, component.Host) (err error) {
+func (c *client) stop(ctx context.Context) error {
+	sent := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(sent)
+	}()
+	var errs []error
+	if err := c.drain(ctx, sent); err != nil {
+		errs = append(errs, err)
+	}
+	if c.devModeDone != nil {
+		close(c.devModeDone)
+		c.devModeDone = nil
+	}
+	if c.cancelWarmup != nil {
+		c.cancelWarmup()
+		<-c.warmupDone
+		c.cancelWarmup = nil
+	}
+	select {
+	case <-sent:
+		if err := c.closeShared(); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		// The batches still being sent use the payload capture, the token file and the connections, which are
+		// closed once they are done rather than under them.
+		go func() {
+			<-sent
+			if err := c.closeShared(); err != nil {
+				c.logger.Warn("Failed to close the payload capture file", zap.Error(err))
+			}
+		}()
+	}
+	return consumererror.CombineErrors(errs)
+}
+
+// closeShared releases the resources shared by the batches being sent, once none is.
+func (c *client) closeShared() error {
+	c.tokenFile.stop()
+	c.dns.stop()
+	if c.capturer != nil {
+		return c.capturer.close()
+	}
 	return nil
 }
+
+// drain waits for the batches being sent, until sent is closed, and sends the buffered events, for at most
+// drain_timeout. It returns an error holding the number of buffered events that could not be sent.
+func (c *client) drain(ctx context.Context, sent <-chan struct{}) error {
+	if c.config != nil && c.config.DrainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.DrainTimeout)
+		defer cancel()
+	}
+
+	var errs []error
+	select {
+	case <-sent:
+	case <-ctx.Done():
+		// The batches still being sent fail with the context of their own, and are retried or dropped as usual.
+		errs = append(errs, fmt.Errorf("batches were still being sent when draining stopped: %w", ctx.Err()))
+	}
+
+	if c.logBuffer != nil {
+		if unsent, err := c.logBuffer.drain(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%d buffered events could not be sent: %w", unsent, err))
+		}
+	}
+	return consumererror.CombineErrors(errs)
+}
+
+func (c *client) start(_ context.Context, host component.Host) (err error) {
+	if err := c.tokenFile.start(); err != nil {
+		return err
+	}
+	if c.diagnostics != nil {
+		if err := c.diagnostics.start(host); err != nil {
+			return err
+		}
+	}
+	if c.config != nil && c.config.DevMode {
+		c.devModeDone = make(chan struct{})
+		go c.warnDevMode(c.devModeDone)
+	}
+	c.dns.start()
+	if c.config != nil && c.config.Warmup.Enabled {
+		// Warming up runs in the background so that it does not delay the start of the pipelines.
+		var ctx context.Context
+		ctx, c.cancelWarmup = context.WithCancel(context.Background())
+		c.warmupDone = make(chan struct{})
+		go func() {
+			defer close(c.warmupDone)
+			c.warmUp(ctx)
+		}()
+	}
+	if c.capturer != nil {
+		return c.capturer.open()
+	}
+	return nil
+}
+
+// warnDevMode logs a warning every devModeWarningInterval until done is closed, so that dev mode configurations
+// do not go unnoticed.
+func (c *client) warnDevMode(done <-chan struct{}) {
+	ticker := time.NewTicker(devModeWarningInterval)
+	defer ticker.Stop()
+	for {
+		c.logger.Warn("Splunk HEC exporter is running in dev mode, do not use it in production",
+			zap.Bool("insecure_skip_verify", c.config.InsecureSkipVerify))
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
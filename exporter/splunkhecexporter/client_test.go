@@ -14,24 +14,36 @@
 package splunkhecexporter
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
 	"math"
+	"math/rand"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/translator/conventions"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
@@ -258,11 +270,11 @@ func runLogExport(disableCompression bool, numberOfLogs int, t *testing.T) (stri
 func TestReceiveTraces(t *testing.T) {
 	actual, err := runTraceExport(true, 3, t)
 	assert.NoError(t, err)
-	expected := `{"time":1,"host":"unknown","event":{"trace_id":"01010101010101010101010101010101","span_id":"0000000000000001","parent_span_id":"0102030405060708","name":"root","end_time":2000000000,"kind":"SPAN_KIND_UNSPECIFIED","status":{"message":"ok","code":"STATUS_CODE_OK"},"start_time":1000000000},"fields":{"resource":"R1"}}`
+	expected := `{"time":1,"host":"unknown","event":{"trace_id":"01010101010101010101010101010101","span_id":"0000000000000001","trace_state":"foo","parent_span_id":"0102030405060708","name":"root","end_time":2000000000,"kind":"SPAN_KIND_UNSPECIFIED","status":{"message":"ok","code":"STATUS_CODE_OK"},"start_time":1000000000},"fields":{"resource":"R1","trace_state":"foo"}}`
 	expected += "\n\r\n\r\n"
-	expected += `{"time":2,"host":"unknown","event":{"trace_id":"01010101010101010101010101010101","span_id":"0000000000000001","parent_span_id":"","name":"root","end_time":3000000000,"kind":"SPAN_KIND_UNSPECIFIED","status":{"message":"","code":"STATUS_CODE_UNSET"},"start_time":2000000000},"fields":{"resource":"R1"}}`
+	expected += `{"time":2,"host":"unknown","event":{"trace_id":"01010101010101010101010101010101","span_id":"0000000000000001","trace_state":"foo","parent_span_id":"","name":"root","end_time":3000000000,"kind":"SPAN_KIND_UNSPECIFIED","status":{"message":"","code":"STATUS_CODE_UNSET"},"start_time":2000000000},"fields":{"resource":"R1","trace_state":"foo"}}`
 	expected += "\n\r\n\r\n"
-	expected += `{"time":3,"host":"unknown","event":{"trace_id":"01010101010101010101010101010101","span_id":"0000000000000001","parent_span_id":"0102030405060708","name":"root","end_time":4000000000,"kind":"SPAN_KIND_UNSPECIFIED","status":{"message":"ok","code":"STATUS_CODE_OK"},"start_time":3000000000},"fields":{"resource":"R1"}}`
+	expected += `{"time":3,"host":"unknown","event":{"trace_id":"01010101010101010101010101010101","span_id":"0000000000000001","trace_state":"foo","parent_span_id":"0102030405060708","name":"root","end_time":4000000000,"kind":"SPAN_KIND_UNSPECIFIED","status":{"message":"ok","code":"STATUS_CODE_OK"},"start_time":3000000000},"fields":{"resource":"R1","trace_state":"foo"}}`
 	expected += "\n\r\n\r\n"
 	assert.Equal(t, expected, actual)
 }
@@ -348,7 +360,8 @@ func TestErrorReceived(t *testing.T) {
 	case <-time.After(5 * time.Second):
 		t.Fatal("Should have received request")
 	}
-	assert.EqualError(t, err, "HTTP 500 \"Internal Server Error\"")
+	require.Error(t, err)
+	assert.Regexp(t, `^HTTP 500 "Internal Server Error" \(request id [0-9a-f]{32}\)$`, err.Error())
 }
 
 func TestInvalidTraces(t *testing.T) {
@@ -396,17 +409,14 @@ func TestInvalidJson(t *testing.T) {
 	badEvent := badJSON{
 		Foo: math.Inf(1),
 	}
-	syncPool := sync.Pool{New: func() interface{} {
-		return gzip.NewWriter(nil)
-	}}
 	evs := []*splunk.Event{
 		{
 			Event: badEvent,
 		},
 		nil,
 	}
-	reader, _, err := encodeBodyEvents(&syncPool, evs, false)
-	assert.Error(t, err, reader)
+	err := encodeEventsTo(new(bytes.Buffer), evs, "\n\r\n\r\n")
+	assert.Error(t, err)
 }
 
 func TestStartAlwaysReturnsNil(t *testing.T) {
@@ -416,15 +426,8 @@ func TestStartAlwaysReturnsNil(t *testing.T) {
 }
 
 func TestInvalidJsonClient(t *testing.T) {
-	badEvent := badJSON{
-		Foo: math.Inf(1),
-	}
-	evs := []*splunk.Event{
-		{
-			Event: badEvent,
-		},
-		nil,
-	}
+	logRecord := pdata.NewLogRecord()
+	logRecord.Body().SetDoubleVal(math.Inf(1))
 	c := client{
 		url: nil,
 		zippers: sync.Pool{New: func() interface{} {
@@ -432,8 +435,8 @@ func TestInvalidJsonClient(t *testing.T) {
 		}},
 		config: &Config{},
 	}
-	err := c.sendSplunkEvents(context.Background(), evs)
-	assert.EqualError(t, err, "Permanent error: json: unsupported value: +Inf")
+	err := c.pushLogData(context.Background(), makeLog(logRecord))
+	assert.EqualError(t, err, "Permanent error: dropped record: json: unsupported value: +Inf")
 }
 
 func TestInvalidURLClient(t *testing.T) {
@@ -444,6 +447,903 @@ func TestInvalidURLClient(t *testing.T) {
 		}},
 		config: &Config{},
 	}
-	err := c.sendSplunkEvents(context.Background(), []*splunk.Event{})
+	err := c.postEvents(context.Background(), new(bytes.Buffer))
 	assert.EqualError(t, err, "Permanent error: parse \"//in%20va%20lid\": invalid URL escape \"%20\"")
 }
+
+func TestPayloadCaptureDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "splunkhec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	capturePath := filepath.Join(dir, "payloads.json")
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	// Nothing listens on this endpoint, a request would fail.
+	config.Endpoint = "http://localhost:0/services/collector"
+	config.PayloadCapture = PayloadCaptureSettings{Enabled: true, Path: capturePath, DryRun: true}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	require.NoError(t, c.stop(context.Background()))
+
+	captured, err := ioutil.ReadFile(capturePath)
+	require.NoError(t, err)
+	expected := `{"host":"myhost","source":"myapp","sourcetype":"myapp-type","index":"myindex","event":"mylog","fields":{"custom":"custom","host.name":"myhost","service.name":"myapp"}}`
+	expected += "\n\r\n\r\n"
+	assert.Equal(t, expected, string(captured))
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	capture := func() string {
+		dir, err := ioutil.TempDir("", "splunkhec")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		capturePath := filepath.Join(dir, "payloads.json")
+
+		config := NewFactory().CreateDefaultConfig().(*Config)
+		config.Token = "1234-1234"
+		config.Endpoint = "http://localhost:0/services/collector"
+		config.PayloadCapture = PayloadCaptureSettings{Enabled: true, Path: capturePath, DryRun: true}
+		config.MaxEventFields = 3
+		config.DeterministicOutput = true
+		options, err := config.getOptionsFromConfig()
+		require.NoError(t, err)
+
+		c := buildClient(options, config, zap.NewNop())
+		require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+		logs := createLogData(1)
+		attrs := logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Attributes()
+		nested := pdata.NewAttributeValueMap()
+		nested.MapVal().InsertString("z", "1")
+		nested.MapVal().InsertString("a", "2")
+		attrs.Insert("nested", nested)
+		require.NoError(t, c.pushLogData(context.Background(), logs))
+		require.NoError(t, c.stop(context.Background()))
+
+		captured, err := ioutil.ReadFile(capturePath)
+		require.NoError(t, err)
+		return string(captured)
+	}
+
+	payload := capture()
+	assert.Equal(t, payload, capture())
+	lines := strings.Split(strings.TrimSpace(payload), "\n\r\n\r\n")
+	require.Len(t, lines, 2)
+	var event splunk.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	id := event.Fields[correlationIDField].(string)
+	expected := `{"host":"myhost","source":"myapp","sourcetype":"myapp-type","index":"myindex","event":"mylog",` +
+		`"fields":{"custom":"custom","event_correlation_id":"` + id + `","host.name":"myhost"}}`
+	assert.Equal(t, expected, lines[0])
+	expected = `{"host":"myhost","source":"myapp","sourcetype":"myapp-type","index":"myindex","event":"fields extension",` +
+		`"fields":{"event_correlation_id":"` + id + `","nested":{"a":"2","z":"1"},"service.name":"myapp"}}`
+	assert.Equal(t, expected, lines[1])
+}
+
+func TestNewlineDelimitedEvents(t *testing.T) {
+	var payload string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		payload = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.NewlineDelimitedEvents = true
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(3)))
+
+	assert.NotContains(t, payload, "\r")
+	lines := strings.Split(payload, "\n")
+	require.Len(t, lines, 4)
+	assert.Empty(t, lines[3])
+	for _, line := range lines[:3] {
+		var event splunk.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.Equal(t, "mylog", event.Event)
+	}
+}
+
+func TestPooledBuffers(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		payload, err := ioutil.ReadAll(zr)
+		require.NoError(t, err)
+		mu.Lock()
+		payloads = append(payloads, string(payload))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	// Batches of decreasing sizes are sent with the buffers of the previous ones, which must not leak into them.
+	for _, n := range []int{40, 30, 20} {
+		require.NoError(t, c.pushLogData(context.Background(), createLogData(n)))
+	}
+	require.Len(t, payloads, 3)
+	for i, n := range []int{40, 30, 20} {
+		assert.Equal(t, n, strings.Count(payloads[i], "\r\n\r\n"))
+		assert.True(t, strings.HasSuffix(payloads[i], "\r\n\r\n"))
+	}
+}
+
+func TestChunkSenderRelease(t *testing.T) {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "http://localhost:0/services/collector"
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	sender := newChunkSender(c, "logs")
+	require.NoError(t, sender.add(context.Background(), eventIndex{record: 1}, []*splunk.Event{{Event: "log"}}))
+	sender.drop(dropReasonTooLarge, "example")
+	sender.release()
+	assert.Nil(t, sender.client)
+	assert.Equal(t, 0, sender.chunk.Len())
+	assert.Equal(t, 0, sender.record.Len())
+	assert.Empty(t, sender.indexes)
+	assert.Empty(t, sender.times)
+	assert.Nil(t, sender.drops)
+	assert.Equal(t, eventIndex{}, sender.first)
+}
+
+func TestDevModeWarning(t *testing.T) {
+	core, observed := observer.New(zap.WarnLevel)
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "https://example.com:8088"
+	config.DevMode = true
+	config.InsecureSkipVerify = true
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+
+	c := buildClient(options, config, zap.New(core))
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return observed.FilterMessageSnippet("dev mode").Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.stop(context.Background()))
+}
+
+func TestWarmup(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var newConns int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			newConns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.Warmup = WarmupSettings{Enabled: true, Connections: 2}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+	<-c.warmupDone
+	mu.Lock()
+	assert.Equal(t, []string{healthPath, healthPath}, paths)
+	assert.Equal(t, 2, newConns)
+	mu.Unlock()
+
+	// The first request reuses a connection opened on start.
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	mu.Lock()
+	assert.Equal(t, 2, newConns)
+	mu.Unlock()
+	require.NoError(t, c.stop(context.Background()))
+}
+
+func TestPayloadCaptureToLogger(t *testing.T) {
+	core, observed := observer.New(zap.DebugLevel)
+	receivedRequest := make(chan string)
+	capture := CapturingData{testing: t, receivedRequest: receivedRequest, statusCode: 200}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &http.Server{
+		Handler: &capture,
+	}
+	go func() {
+		panic(s.Serve(listener))
+	}()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "http://" + listener.Addr().String() + "/services/collector"
+	config.PayloadCapture.Enabled = true
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+
+	c := buildClient(options, config, zap.New(core))
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	require.NoError(t, c.stop(context.Background()))
+
+	request := <-receivedRequest
+	entries := observed.FilterMessage("Captured HEC payload").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, request, entries[0].ContextMap()["payload"])
+}
+
+func TestUnixSocketEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "splunkhec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "hec.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	receivedRequest := make(chan string)
+	capture := CapturingData{testing: t, receivedRequest: receivedRequest, statusCode: 200}
+	s := &http.Server{
+		Handler: &capture,
+	}
+	go s.Serve(listener)
+	defer s.Close()
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "http+unix://" + socketPath
+	cfg.DisableCompression = true
+	cfg.Token = "1234-1234"
+
+	params := component.ExporterCreateParams{Logger: zap.NewNop()}
+	exporter, err := factory.CreateLogsExporter(context.Background(), params, cfg)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Start(context.Background(), componenttest.NewNopHost()))
+	defer exporter.Shutdown(context.Background())
+
+	require.NoError(t, exporter.ConsumeLogs(context.Background(), createLogData(1)))
+	select {
+	case request := <-receivedRequest:
+		assert.Contains(t, request, `"event":"mylog"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should have received request")
+	}
+}
+
+// newChunkTestClient returns a client sending chunks of at most maxContentLength bytes to a server that accepts the first
+// request and fails all following ones.
+func newChunkTestClient(t *testing.T, maxContentLength uint) (*client, func()) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.MaxContentLength = maxContentLength
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	return buildClient(options, config, zap.NewNop()), server.Close
+}
+
+func TestPartialErrors(t *testing.T) {
+	t.Run("logs", func(t *testing.T) {
+		c, closeServer := newChunkTestClient(t, 300)
+		defer closeServer()
+
+		err := c.pushLogData(context.Background(), createLogData(3))
+		require.Error(t, err)
+		partialErr, ok := err.(consumererror.PartialError)
+		require.True(t, ok)
+		failed := partialErr.GetLogs()
+		assert.Equal(t, 2, failed.LogRecordCount())
+		assert.Equal(t, pdata.Timestamp(time.Millisecond), failed.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Timestamp())
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		c, closeServer := newChunkTestClient(t, 300)
+		defer closeServer()
+
+		err := c.pushMetricsData(context.Background(), createMetricsData(3))
+		require.Error(t, err)
+		partialErr, ok := err.(consumererror.PartialError)
+		require.True(t, ok)
+		failed := partialErr.GetMetrics()
+		assert.Equal(t, 2, failed.MetricCount())
+		rm := failed.ResourceMetrics().At(0)
+		v0, _ := rm.Resource().Attributes().Get("k0")
+		assert.Equal(t, "v0", v0.StringVal())
+		assert.Equal(t, 2, rm.InstrumentationLibraryMetrics().Len())
+	})
+
+	t.Run("traces", func(t *testing.T) {
+		c, closeServer := newChunkTestClient(t, 400)
+		defer closeServer()
+
+		err := c.pushTraceData(context.Background(), createTraceData(3))
+		require.Error(t, err)
+		partialErr, ok := err.(consumererror.PartialError)
+		require.True(t, ok)
+		failed := partialErr.GetTraces()
+		assert.Equal(t, 2, failed.SpanCount())
+		assert.Equal(t, pdata.Timestamp(2e9), failed.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).StartTime())
+	})
+}
+
+func TestMaxContentLength(t *testing.T) {
+	receivedRequest := make(chan string)
+	capture := CapturingData{testing: t, receivedRequest: receivedRequest, statusCode: 200}
+	server := httptest.NewServer(&capture)
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.MaxContentLength = 300
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	done := make(chan error)
+	go func() {
+		done <- c.pushLogData(context.Background(), createLogData(3))
+	}()
+	for i := 0; i < 3; i++ {
+		select {
+		case request := <-receivedRequest:
+			assert.LessOrEqual(t, len(request), 300)
+			assert.Equal(t, 1, strings.Count(request, `"event":"mylog"`))
+		case <-time.After(5 * time.Second):
+			t.Fatal("Should have received request")
+		}
+	}
+	assert.NoError(t, <-done)
+
+	// A record larger than the limit on its own is dropped.
+	config.MaxContentLength = 10
+	err = c.pushLogData(context.Background(), createLogData(1))
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+func TestReceiveLogsWithBrotli(t *testing.T) {
+	receivedRequest := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "br", r.Header.Get("Content-Encoding"))
+		body, err := ioutil.ReadAll(brotli.NewReader(r.Body))
+		assert.NoError(t, err)
+		receivedRequest <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.Compression = compressionBrotli
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(100)))
+	assert.Equal(t, 100, strings.Count(string(<-receivedRequest), `"event":"mylog"`))
+}
+
+func TestVerifyCompressed(t *testing.T) {
+	original := bytes.Repeat([]byte(`{"event":"mylog"}`), 100)
+	compress := func(compression string) []byte {
+		buf := new(bytes.Buffer)
+		w := newCompressor(compression)().(compressor)
+		w.Reset(buf)
+		_, err := w.Write(original)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	gzipped := compress(compressionGzip)
+
+	assert.NoError(t, verifyCompressed(compressionGzip, gzipped, original))
+	assert.NoError(t, verifyCompressed(compressionBrotli, compress(compressionBrotli), original))
+	assert.Error(t, verifyCompressed(compressionGzip, gzipped[:len(gzipped)-8], original))
+	assert.EqualError(t, verifyCompressed(compressionGzip, gzipped, original[1:]),
+		"decompressed payload of 1700 bytes differs from the original payload of 1699 bytes")
+}
+
+// truncatingCompressor emulates a misused pooled writer losing the end of the compressed payload.
+type truncatingCompressor struct {
+	*gzip.Writer
+	buf *bytes.Buffer
+}
+
+func (c *truncatingCompressor) Reset(w io.Writer) {
+	c.buf = w.(*bytes.Buffer)
+	c.Writer.Reset(w)
+}
+
+func (c *truncatingCompressor) Close() error {
+	err := c.Writer.Close()
+	c.buf.Truncate(c.buf.Len() - 8)
+	return err
+}
+
+func TestVerifyCompression(t *testing.T) {
+	receivedRequest := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get("Content-Encoding"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		receivedRequest <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.VerifyCompression = true
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	c.zippers = sync.Pool{New: func() interface{} {
+		return &truncatingCompressor{Writer: gzip.NewWriter(nil)}
+	}}
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(100)))
+	assert.Equal(t, 100, strings.Count(string(<-receivedRequest), `"event":"mylog"`))
+}
+
+func TestIPv6Endpoint(t *testing.T) {
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 loopback is unavailable:", err)
+	}
+	var hosts []string
+	var mu sync.Mutex
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	mu.Lock()
+	assert.Equal(t, []string{listener.Addr().String()}, hosts)
+	mu.Unlock()
+}
+
+func TestIPv6EndpointBehindProxy(t *testing.T) {
+	type proxied struct {
+		method     string
+		host       string
+		requestURI string
+	}
+	var requests []proxied
+	var mu sync.Mutex
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, proxied{r.Method, r.Host, r.RequestURI})
+		mu.Unlock()
+		if r.Method == http.MethodConnect {
+			// Refuse the tunnel, only the requested address matters.
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		endpoint string
+		want     proxied
+		wantErr  bool
+	}{
+		{
+			endpoint: "http://[2001:db8::1]:8088",
+			want:     proxied{http.MethodPost, "[2001:db8::1]:8088", "http://[2001:db8::1]:8088/services/collector"},
+		},
+		{
+			endpoint: "http://[2001:db8::1]/services/collector",
+			want:     proxied{http.MethodPost, "[2001:db8::1]", "http://[2001:db8::1]/services/collector"},
+		},
+		{
+			endpoint: "https://[2001:db8::1]:8088",
+			want:     proxied{http.MethodConnect, "[2001:db8::1]:8088", "[2001:db8::1]:8088"},
+			wantErr:  true,
+		},
+		{
+			endpoint: "https://[2001:db8::1]",
+			want:     proxied{http.MethodConnect, "[2001:db8::1]:443", "[2001:db8::1]:443"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+
+			config := NewFactory().CreateDefaultConfig().(*Config)
+			config.Token = "1234-1234"
+			config.Endpoint = tt.endpoint
+			options, err := config.getOptionsFromConfig()
+			require.NoError(t, err)
+
+			c := buildClient(options, config, zap.NewNop())
+			c.client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+			err = c.pushLogData(context.Background(), createLogData(1))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mu.Lock()
+			assert.Equal(t, []proxied{tt.want}, requests)
+			mu.Unlock()
+		})
+	}
+}
+
+func TestResponseDrainingConnectionReuse(t *testing.T) {
+	var bodySize int64
+	var newConns int64
+	var mu sync.Mutex
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		size := bodySize
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bytes.Repeat([]byte("a"), int(size)))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			newConns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	send := func(size int64) int64 {
+		mu.Lock()
+		bodySize = size
+		newConns = 0
+		mu.Unlock()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return newConns
+	}
+
+	// Small bodies are drained, and their connection reused.
+	send(0)
+	assert.EqualValues(t, 0, send(splunk.MaxDrainedBodySize))
+	// The connection of larger bodies is closed instead: the idle connection is used by the first request only.
+	assert.EqualValues(t, 2, send(1024*1024))
+}
+
+func TestCompressedPayloadLargerThanMaxContentLength(t *testing.T) {
+	type request struct {
+		encoding string
+		records  int
+	}
+	var mu sync.Mutex
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		payload, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		mu.Lock()
+		requests = append(requests, request{encoding: r.Header.Get("Content-Encoding"), records: strings.Count(string(payload), "\r\n\r\n")})
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.MaxContentLength = 0
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	// Random letters barely compress, each record of 2000 bytes being about 1500 bytes once compressed.
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	random := rand.New(rand.NewSource(1))
+	randomEvent := func() *splunk.Event {
+		b := make([]byte, 2000)
+		for i := range b {
+			b[i] = letters[random.Intn(len(letters))]
+		}
+		return &splunk.Event{Event: string(b)}
+	}
+
+	sender := newChunkSender(c, "logs")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sender.add(context.Background(), eventIndex{record: i}, []*splunk.Event{randomEvent()}))
+	}
+	// The limit is lowered once the records are held, as when the adaptive limit shrinks, so that the compressed
+	// payload of the chunk exceeds it.
+	config.MaxContentLength = 2500
+	require.NoError(t, sender.flush(context.Background()))
+	assert.Equal(t, []request{{"gzip", 1}, {"gzip", 1}, {"gzip", 1}}, requests)
+	assert.Equal(t, 0, sender.chunk.Len())
+
+	// A single record exceeding the limit once compressed is dropped, rather than sent uncompressed.
+	requests = nil
+	config.MaxContentLength = 0
+	for i := 0; i < 2; i++ {
+		require.NoError(t, sender.add(context.Background(), eventIndex{record: i}, []*splunk.Event{randomEvent()}))
+	}
+	config.MaxContentLength = 1000
+	require.NoError(t, sender.flush(context.Background()))
+	assert.Empty(t, requests)
+	assert.Equal(t, 0, sender.chunk.Len())
+	assert.Equal(t, 2, sender.drops[dropReasonTooLarge].count)
+	require.Len(t, sender.permanentErrs, 2)
+	assert.True(t, consumererror.IsPermanent(sender.err()))
+	assert.Contains(t, sender.permanentErrs[0].Error(), "larger than the content length of 1000 bytes")
+}
+
+func TestMaxEventCount(t *testing.T) {
+	var mu sync.Mutex
+	var counts []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		counts = append(counts, strings.Count(string(payload), `"event":`))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.MaxEventCount = 4
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	sender := newChunkSender(c, "logs")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, sender.add(context.Background(), eventIndex{record: i},
+			[]*splunk.Event{{Event: "first"}, {Event: "second"}}))
+	}
+	// A record with more events than the limit on its own is dropped.
+	require.NoError(t, sender.add(context.Background(), eventIndex{record: 5},
+		[]*splunk.Event{{Event: "1"}, {Event: "2"}, {Event: "3"}, {Event: "4"}, {Event: "5"}}))
+	require.NoError(t, sender.flush(context.Background()))
+
+	assert.Equal(t, []int{4, 4, 2}, counts)
+	require.Len(t, sender.permanentErrs, 1)
+	assert.EqualError(t, sender.permanentErrs[0], "Permanent error: dropped record: 5 events are more than max_event_count of 4")
+	assert.Equal(t, 1, sender.drops[dropReasonTooLarge].count)
+}
+
+func TestStopDrainTimeout(t *testing.T) {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Endpoint = "http://localhost:0"
+	config.Token = "1234-1234"
+	config.DrainTimeout = 50 * time.Millisecond
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	dir, err := ioutil.TempDir("", "splunkhec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	c.capturer = newPayloadCapturer(PayloadCaptureSettings{Enabled: true, Path: filepath.Join(dir, "payloads")}, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+
+	// A batch that is still being sent.
+	c.wg.Add(1)
+	start := time.Now()
+	err = c.stop(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "batches were still being sent when draining stopped")
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+
+	// The payload capture is closed once the batch is done, rather than under it.
+	c.capturer.mu.Lock()
+	assert.NotNil(t, c.capturer.file)
+	c.capturer.mu.Unlock()
+	c.wg.Done()
+	assert.Eventually(t, func() bool {
+		c.capturer.mu.Lock()
+		defer c.capturer.mu.Unlock()
+		return c.capturer.file == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSplitByMetadata(t *testing.T) {
+	var mu sync.Mutex
+	var indexes [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requestIndexes []string
+		decoder := json.NewDecoder(r.Body)
+		for decoder.More() {
+			var event splunk.Event
+			require.NoError(t, decoder.Decode(&event))
+			requestIndexes = append(requestIndexes, event.Index)
+		}
+		mu.Lock()
+		indexes = append(indexes, requestIndexes)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.SplitByMetadata = true
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	sender := newChunkSender(c, "logs")
+	for i, index := range []string{"tenant1", "tenant1", "tenant2", "tenant1"} {
+		require.NoError(t, sender.add(context.Background(), eventIndex{record: i},
+			[]*splunk.Event{{Event: "log", Index: index}}))
+	}
+	require.NoError(t, sender.flush(context.Background()))
+
+	assert.Equal(t, [][]string{{"tenant1", "tenant1"}, {"tenant2"}, {"tenant1"}}, indexes)
+}
+
+func TestPrechunkedLogs(t *testing.T) {
+	receivedRequest := make(chan string, 10)
+	capture := CapturingData{testing: t, receivedRequest: receivedRequest, statusCode: 200}
+	server := httptest.NewServer(&capture)
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.SplitByMetadata = true
+	config.LogsBuffer = LogsBufferSettings{Enabled: true, FlushInterval: time.Hour}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	logs := createLogData(2)
+	logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(1).Attributes().UpdateString(splunk.IndexLabel, "otherindex")
+	assert.False(t, isPrechunked(logs))
+	logs.ResourceLogs().At(0).Resource().Attributes().InsertBool(splunk.HecPrechunkedLabel, true)
+	assert.True(t, isPrechunked(logs))
+
+	// The batch is neither buffered nor split by index.
+	require.NoError(t, c.pushLogData(context.Background(), logs))
+	select {
+	case request := <-receivedRequest:
+		assert.Equal(t, 2, strings.Count(request, `"event":"mylog"`))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should have received request")
+	}
+	assert.Equal(t, 0, c.logBuffer.sender.chunk.Len())
+
+	// Batches with unmarked resources are chunked as usual.
+	logs.ResourceLogs().Resize(2)
+	assert.False(t, isPrechunked(logs))
+}
+
+func TestRequestBodyReleasedOnceClosed(t *testing.T) {
+	buf := bodyBuffers.Get().(*bytes.Buffer)
+	buf.WriteString("payload")
+	body := &requestBody{data: buf.Bytes(), pooled: buf, refs: 1}
+
+	first := body.reader()
+	second := body.reader()
+	body.release()
+	read, err := ioutil.ReadAll(first)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(read))
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close())
+	// The transport still holds the second reader, e.g. of a retry.
+	assert.Equal(t, "payload", buf.String())
+
+	require.NoError(t, second.Close())
+	assert.Equal(t, 0, buf.Len())
+}
+
+// bodyHoldingTransport answers requests without reading their body, which it keeps, as a transport still writing the
+// body after the response was received.
+type bodyHoldingTransport struct {
+	bodies []io.ReadCloser
+}
+
+func (t *bodyHoldingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.bodies = append(t.bodies, req.Body)
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestUncompressedBodyOutlivesChunk(t *testing.T) {
+	transport := &bodyHoldingTransport{}
+	c := client{
+		url:     &url.URL{Scheme: "http", Host: "localhost"},
+		client:  &http.Client{Transport: transport},
+		zippers: sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }},
+		config:  &Config{DisableCompression: true},
+		logger:  zap.NewNop(),
+	}
+	buf := bytes.NewBufferString("payload")
+	require.NoError(t, c.postEvents(context.Background(), buf))
+
+	// The next batch reuses the buffer of the chunk.
+	buf.Reset()
+	buf.WriteString("overwritten")
+	require.Len(t, transport.bodies, 1)
+	read, err := ioutil.ReadAll(transport.bodies[0])
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(read))
+	require.NoError(t, transport.bodies[0].Close())
+}
@@ -0,0 +1,99 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// toCompactSpan returns the event of a span in the terse layout of the "compact" span_format, cutting the size of
+// payloads for high-volume tracing workloads. Its keys are:
+//
+//	t   trace ID
+//	s   span ID
+//	p   parent span ID, omitted for root spans
+//	n   name
+//	k   kind, e.g. "server", omitted if unspecified
+//	ts  start time, in microseconds since epoch
+//	d   duration, in microseconds
+//	c   status code, "ok" or "error", omitted if unset
+//	m   status message, omitted if empty
+//	w   W3C tracestate, omitted if empty
+//	a   attributes, omitted if empty
+//	e   events, each with its n, ts and a, omitted if empty
+//	l   links, each with its t, s, w and a, omitted if empty
+//
+// Maps and slices of interface{} are used, rather than structs, so that the redaction rules apply to attributes.
+func toCompactSpan(span pdata.Span, hecSpan HecSpan) map[string]interface{} {
+	event := map[string]interface{}{
+		"t":  hecSpan.TraceID,
+		"s":  hecSpan.SpanID,
+		"n":  hecSpan.Name,
+		"ts": int64(span.StartTime()) / 1e3,
+		"d":  int64(0),
+	}
+	if span.EndTime() > span.StartTime() {
+		event["d"] = int64(span.EndTime()-span.StartTime()) / 1e3
+	}
+	if hecSpan.ParentSpan != "" {
+		event["p"] = hecSpan.ParentSpan
+	}
+	if span.Kind() != pdata.SpanKindUNSPECIFIED {
+		event["k"] = strings.ToLower(strings.TrimPrefix(hecSpan.Kind, "SPAN_KIND_"))
+	}
+	switch span.Status().Code() {
+	case pdata.StatusCodeOk:
+		event["c"] = "ok"
+	case pdata.StatusCodeError:
+		event["c"] = "error"
+	}
+	if hecSpan.Status.Message != "" {
+		event["m"] = hecSpan.Status.Message
+	}
+	if hecSpan.TraceState != "" {
+		event["w"] = string(hecSpan.TraceState)
+	}
+	if len(hecSpan.Attributes) > 0 {
+		event["a"] = hecSpan.Attributes
+	}
+	if len(hecSpan.Events) > 0 {
+		events := make([]interface{}, len(hecSpan.Events))
+		for i, e := range hecSpan.Events {
+			compact := map[string]interface{}{"n": e.Name, "ts": int64(e.Timestamp) / 1e3}
+			if len(e.Attributes) > 0 {
+				compact["a"] = e.Attributes
+			}
+			events[i] = compact
+		}
+		event["e"] = events
+	}
+	if len(hecSpan.Links) > 0 {
+		links := make([]interface{}, len(hecSpan.Links))
+		for i, l := range hecSpan.Links {
+			compact := map[string]interface{}{"t": l.TraceID, "s": l.SpanID}
+			if l.TraceState != "" {
+				compact["w"] = string(l.TraceState)
+			}
+			if len(l.Attributes) > 0 {
+				compact["a"] = l.Attributes
+			}
+			links[i] = compact
+		}
+		event["l"] = links
+	}
+	return event
+}
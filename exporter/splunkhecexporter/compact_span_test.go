@@ -0,0 +1,108 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func newCompactTestTraces() pdata.Traces {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	spans.Resize(2)
+	root := spans.At(0)
+	root.SetName("GET /users")
+	root.SetTraceID(pdata.NewTraceID([16]byte{1}))
+	root.SetSpanID(pdata.NewSpanID([8]byte{2}))
+	root.SetKind(pdata.SpanKindSERVER)
+	root.SetStartTime(pdata.Timestamp(1_000_000_000))
+	root.SetEndTime(pdata.Timestamp(1_012_500_000))
+	root.Status().SetCode(pdata.StatusCodeOk)
+	root.Attributes().InsertString("http.method", "GET")
+	child := spans.At(1)
+	child.SetName("SELECT users")
+	child.SetTraceID(pdata.NewTraceID([16]byte{1}))
+	child.SetSpanID(pdata.NewSpanID([8]byte{3}))
+	child.SetParentSpanID(pdata.NewSpanID([8]byte{2}))
+	child.SetTraceState("rojo=00f067aa0ba902b7")
+	child.SetStartTime(pdata.Timestamp(1_001_000_000))
+	child.SetEndTime(pdata.Timestamp(1_002_000_000))
+	child.Status().SetCode(pdata.StatusCodeError)
+	child.Status().SetMessage("timeout")
+	child.Events().Resize(1)
+	child.Events().At(0).SetName("exception")
+	child.Events().At(0).SetTimestamp(pdata.Timestamp(1_001_500_000))
+	child.Events().At(0).Attributes().InsertString("exception.type", "Timeout")
+	child.Links().Resize(1)
+	child.Links().At(0).SetTraceID(pdata.NewTraceID([16]byte{4}))
+	child.Links().At(0).SetSpanID(pdata.NewSpanID([8]byte{5}))
+	return traces
+}
+
+func Test_traceDataToSplunk_compactSpanFormat(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.SpanFormat = spanFormatCompact
+	events, _ := traceDataToSplunk(zap.NewNop(), newCompactTestTraces(), config)
+	require.Len(t, events, 2)
+	assert.Equal(t, map[string]interface{}{
+		"t":  "01000000000000000000000000000000",
+		"s":  "0200000000000000",
+		"n":  "GET /users",
+		"k":  "server",
+		"ts": int64(1_000_000),
+		"d":  int64(12_500),
+		"c":  "ok",
+		"a":  map[string]interface{}{"http.method": "GET"},
+	}, events[0].Event)
+	assert.Equal(t, map[string]interface{}{
+		"t":  "01000000000000000000000000000000",
+		"s":  "0300000000000000",
+		"p":  "0200000000000000",
+		"n":  "SELECT users",
+		"ts": int64(1_001_000),
+		"d":  int64(1_000),
+		"c":  "error",
+		"m":  "timeout",
+		"w":  "rojo=00f067aa0ba902b7",
+		"e": []interface{}{
+			map[string]interface{}{"n": "exception", "ts": int64(1_001_500), "a": map[string]interface{}{"exception.type": "Timeout"}},
+		},
+		"l": []interface{}{
+			map[string]interface{}{"t": "04000000000000000000000000000000", "s": "0500000000000000"},
+		},
+	}, events[1].Event)
+}
+
+func Test_traceDataToSplunk_compactSpanFormatSize(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	otlpEvents, _ := traceDataToSplunk(zap.NewNop(), newCompactTestTraces(), config)
+	config.SpanFormat = spanFormatCompact
+	compactEvents, _ := traceDataToSplunk(zap.NewNop(), newCompactTestTraces(), config)
+
+	otlp := new(bytes.Buffer)
+	require.NoError(t, encodeEventsTo(otlp, otlpEvents, "\n\r\n\r\n"))
+	compact := new(bytes.Buffer)
+	require.NoError(t, encodeEventsTo(compact, compactEvents, "\n\r\n\r\n"))
+	assert.Less(t, float64(compact.Len()), 0.7*float64(otlp.Len()), "compact: %s\notlp: %s", compact, otlp)
+}
@@ -17,16 +17,58 @@ package splunkhecexporter
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"path"
+	"strings"
+	"text/template"
+	"time"
 
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
 
 const (
 	// hecPath is the default HEC path on the Splunk instance.
 	hecPath = "services/collector"
+	// unixScheme is the endpoint scheme used to send data over a unix domain socket, e.g. http+unix:///var/run/splunk-hec.sock.
+	unixScheme = "http+unix"
+	// compressionGzip is the default content-encoding of compressed requests.
+	compressionGzip = "gzip"
+	// compressionBrotli is the content-encoding of brotli compressed requests.
+	compressionBrotli = "br"
+	// placementFields sends attributes as indexed HEC fields.
+	placementFields = "fields"
+	// placementEvent embeds attributes in the event payload.
+	placementEvent = "event"
+	// placementBoth sends attributes both as indexed HEC fields and in the event payload.
+	placementBoth = "both"
+	// spanFormatOTLP sends spans with the field layout of the OpenTelemetry protocol.
+	spanFormatOTLP = "otlp"
+	// spanFormatAPM sends spans with the flat field layout expected by Splunk APM and IT Service Intelligence.
+	spanFormatAPM = "apm"
+	// spanFormatCompact sends spans with terse field names to cut the size of payloads.
+	spanFormatCompact = "compact"
+	// timestampPrecisionSecond sends the time of events as whole seconds since epoch.
+	timestampPrecisionSecond = "s"
+	// timestampPrecisionMillisecond sends the time of events as seconds since epoch with millisecond precision.
+	timestampPrecisionMillisecond = "ms"
+	// timestampPrecisionMicrosecond sends the time of events as seconds since epoch with microsecond precision, the
+	// finest a JSON number holds exactly for current dates.
+	timestampPrecisionMicrosecond = "us"
+	// timestampPrecisionNanosecond sends the time of events as seconds since epoch with nanosecond precision, written
+	// as whole seconds and 9 fractional digits rather than as a float64, which cannot hold them.
+	timestampPrecisionNanosecond = "ns"
+	// nonFiniteDrop silently drops non-finite values.
+	nonFiniteDrop = "drop"
+	// nonFiniteDropAndCount drops non-finite values, reporting them as dropped records.
+	nonFiniteDropAndCount = "drop_and_count"
+	// nonFiniteConvert replaces non-finite values with a sentinel value, flagging the event.
+	nonFiniteConvert = "convert"
+	// defaultNonFiniteFlagField is the field flagging events whose non-finite values were converted.
+	defaultNonFiniteFlagField = "non_finite_value"
 )
 
 // Config defines configuration for Splunk exporter.
@@ -39,43 +81,546 @@ type Config struct {
 	// HEC Token is the authentication token provided by Splunk: https://docs.splunk.com/Documentation/Splunk/latest/Data/UsetheHTTPEventCollector.
 	Token string `mapstructure:"token"`
 
+	// TokenFile is the path of a file holding the HEC token, used instead of Token. The file is re-read every 10
+	// seconds and on SIGHUP, so that tokens rotated by secret managers are picked up without restarting the collector.
+	TokenFile string `mapstructure:"token_file"`
+
+	// Auth selects how the requests to HEC are authenticated. Defaults to the HEC token.
+	Auth AuthSettings `mapstructure:"auth"`
+
+	// Authenticator authenticates the requests to HEC instead of Auth, so that Go programs embedding the exporter can
+	// plug in their own strategy. It can only be set programmatically.
+	Authenticator Authenticator `mapstructure:"-"`
+
 	// URL is the Splunk HEC endpoint where data is going to be sent to.
 	Endpoint string `mapstructure:"endpoint"`
 
+	// LogsEndpoint, MetricsEndpoint and TracesEndpoint are the Splunk HEC endpoints of logs, metrics and traces, e.g.
+	// when metric indexes are on another HEC input. Endpoint is used for the signals whose endpoint is empty.
+	LogsEndpoint    string `mapstructure:"logs_endpoint"`
+	MetricsEndpoint string `mapstructure:"metrics_endpoint"`
+	TracesEndpoint  string `mapstructure:"traces_endpoint"`
+
 	// Optional Splunk source: https://docs.splunk.com/Splexicon:Source.
-	// Sources identify the incoming data.
-	Source string `mapstructure:"source"`
+	// Sources identify the incoming data. Can be a Go template rendered with the attributes of each resource and
+	// log record, whose keys have dots replaced by underscores, e.g. "otel/{{.service_name}}/{{.k8s_container_name}}".
+	// A template takes precedence over the attribute mapped to the source by HecToOtelAttrs.
+	Source         string `mapstructure:"source"`
+	sourceTemplate *template.Template
 
 	// Optional Splunk source type: https://docs.splunk.com/Splexicon:Sourcetype.
-	SourceType string `mapstructure:"sourcetype"`
+	// Can be a Go template like Source.
+	SourceType         string `mapstructure:"sourcetype"`
+	sourceTypeTemplate *template.Template
 
 	// Splunk index, optional name of the Splunk index.
 	Index string `mapstructure:"index"`
 
+	// LogsIndex, MetricsIndex and TracesIndex are the indexes of log, metric and span events, so that one exporter
+	// sends each signal to its own index. Index is used for the signals whose index is empty.
+	LogsIndex    string `mapstructure:"logs_index"`
+	MetricsIndex string `mapstructure:"metrics_index"`
+	TracesIndex  string `mapstructure:"traces_index"`
+
+	// HecToOtelAttrs maps attributes of resources or log records to the HEC metadata of their events, e.g. to route
+	// events to the index named by the k8s.namespace.name attribute. Log record attributes take precedence over
+	// resource attributes, and the static source, sourcetype and index above are used when the attribute is missing.
+	// The com.splunk.source, com.splunk.sourcetype and com.splunk.index attributes override the mapped values.
+	HecToOtelAttrs splunk.HecToOtelAttrs `mapstructure:"hec_metadata_to_otel_attrs"`
+
+	// TimestampPrecision is the precision of the time of events, "s", "ms", "us" or "ns", to match the TIME_FORMAT of
+	// the Splunk sourcetype. Defaults to "ms".
+	TimestampPrecision string `mapstructure:"timestamp_precision"`
+
+	// UseMultiMetricFormat merges the data points sharing their time, HEC metadata and dimensions into multi-metric
+	// events, as supported by Splunk 8.0 and later, to reduce the number of events indexed. Defaults to false.
+	UseMultiMetricFormat bool `mapstructure:"use_multi_metric_format"`
+
+	// IncludeResourceAttributes sends resource attributes as dimensions of metric events. Defaults to true.
+	IncludeResourceAttributes bool `mapstructure:"include_resource_attributes"`
+
+	// ResourceAttributesAllowList restricts the resource attributes sent as dimensions of metric events to the listed
+	// ones, since sending all resource attributes explodes cardinality in metric indexes. All are sent if empty.
+	ResourceAttributesAllowList []string `mapstructure:"resource_attributes_allow_list"`
+
+	// MetricUnitField is the field holding the unit of metrics, e.g. "metric_unit", so that dashboards can render
+	// correct axes. Metrics without a unit have no such field. Disabled if empty.
+	MetricUnitField string `mapstructure:"metric_unit_field"`
+
+	// NonFiniteValues defines how NaN and infinite values, which cannot be encoded in JSON, are handled.
+	NonFiniteValues NonFiniteValuesSettings `mapstructure:"non_finite_values"`
+
+	// MetricPrefix is prepended to the name of all metrics, after they are renamed, e.g. "otel.".
+	MetricPrefix string `mapstructure:"metric_prefix"`
+
+	// MetricRenames renames metrics to match existing Splunk metric naming conventions. The first matching rule
+	// applies.
+	MetricRenames []MetricRenameSettings `mapstructure:"metric_renames"`
+	metricNamer   *metricNamer
+
+	// Dimensions renames, drops and adds metric dimensions, e.g. to reconcile the OpenTelemetry semantic conventions
+	// with existing Splunk dimension names.
+	Dimensions      DimensionsSettings `mapstructure:"dimensions"`
+	dimensionMapper *dimensionMapper
+
+	// MapSemanticConventions renames the fields named after the OpenTelemetry semantic conventions to the names
+	// conventionally used in Splunk, e.g. os.type to os and k8s.pod.name to pod, and drops the fields of the attributes
+	// sent as the host and source of events, e.g. host.name and service.name, without hand-written attribute
+	// processors. Defaults to false.
+	MapSemanticConventions bool `mapstructure:"map_semantic_conventions"`
+	semanticConventions    *semanticConventionMapper
+
 	// MaxConnections is used to set a limit to the maximum idle HTTP connection the exporter can keep open. Defaults to 100.
 	MaxConnections uint `mapstructure:"max_connections"`
 
 	// Disable GZip compression. Defaults to false.
 	DisableCompression bool `mapstructure:"disable_compression"`
 
-	// insecure_skip_verify skips checking the certificate of the HEC endpoint when sending data over HTTPS. Defaults to false.
+	// Compression is the content-encoding of compressed requests, "gzip" or "br". Splunk does not accept brotli,
+	// which is only meant for deployments where a fronting proxy decompresses requests. Defaults to "gzip".
+	Compression string `mapstructure:"compression"`
+
+	// VerifyCompression decompresses each compressed request and compares it with the original payload before
+	// sending it, falling back to sending the payload uncompressed if they differ. Meant for debugging as it costs
+	// CPU. Defaults to false.
+	VerifyCompression bool `mapstructure:"verify_compression"`
+
+	// MaxContentLength is the maximum size in bytes of the uncompressed body of a request. Larger batches are split
+	// into several requests, and a failed request only causes the data it and the following requests hold to be retried.
+	// 0 disables splitting. Defaults to 2 MiB.
+	MaxContentLength uint `mapstructure:"max_content_length"`
+
+	// MaxEventCount is the maximum number of events of a request, e.g. the per-request event limit of managed HEC
+	// deployments. Larger batches are split like with MaxContentLength. 0 disables the limit. Defaults to 0.
+	MaxEventCount uint `mapstructure:"max_event_count"`
+
+	// SplitByMetadata sends the events of different indexes, sourcetypes or hosts in separate requests, so that a
+	// request never mixes tenants. Records are never split, their metadata is the one of their first event.
+	SplitByMetadata bool `mapstructure:"split_by_metadata"`
+
+	// MaxEventFields is the maximum number of fields of an event, e.g. the indexed fields limit of HEC. The excess
+	// fields of larger events are sent in extension events sharing a correlation id with them. 0 disables splitting.
+	// Defaults to 0.
+	MaxEventFields uint `mapstructure:"max_event_fields"`
+
+	// DeterministicOutput makes the payloads of the same data byte for byte identical across runs and collector
+	// versions, so that payload diffs are reviewable and golden file tests are stable: fields and the maps they hold
+	// are serialized with sorted keys, and the events split because of max_event_fields are linked by an id derived
+	// from their content rather than a random one. Defaults to false.
+	DeterministicOutput bool `mapstructure:"deterministic_output"`
+
+	// NewlineDelimitedEvents separates the events of a request with a single newline rather than with blank lines,
+	// which saves bytes and is required by HEC-compatible endpoints rejecting blank lines. Defaults to false.
+	NewlineDelimitedEvents bool `mapstructure:"newline_delimited_events"`
+
+	// AdaptiveContentLength shrinks the request size limit when the endpoint answers 413 or times out, and grows it
+	// back to max_content_length as requests succeed. Defaults to false.
+	AdaptiveContentLength bool `mapstructure:"adaptive_content_length"`
+
+	// DevMode allows insecure settings meant for lab testing, and periodically logs a warning while enabled so that
+	// such configurations are not used in production unnoticed. Defaults to false.
+	DevMode bool `mapstructure:"dev_mode"`
+
+	// insecure_skip_verify skips checking the certificate of the HEC endpoint when sending data over HTTPS. Requires
+	// dev_mode. Defaults to false.
 	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+
+	// FIPSTLS restricts TLS to TLS 1.2 with FIPS 140-2 approved cipher suites and curves, e.g. to send data to FedRAMP
+	// Splunk environments. The endpoints must be HTTPS, and their certificate checked. Defaults to false.
+	FIPSTLS bool `mapstructure:"fips_tls"`
+
+	// Warmup resolves the endpoint and opens connections to it on start, so that the first requests do not wait
+	// for DNS resolution and TLS handshakes.
+	Warmup WarmupSettings `mapstructure:"warmup"`
+
+	// DNSRefreshInterval is the interval at which the host of the endpoint is resolved again, the connections to it
+	// being rotated once its addresses changed, so that requests are balanced over the new addresses of a load
+	// balancer rather than kept on connections to former ones. 0 disables it. Defaults to 0.
+	DNSRefreshInterval time.Duration `mapstructure:"dns_refresh_interval"`
+
+	// FastRetry retries the requests whose connection failed within the client, before the error reaches the queued
+	// retry, so that blips such as connections reset by a load balancer do not delay data by a whole retry interval.
+	FastRetry FastRetrySettings `mapstructure:"fast_retry"`
+
+	// Throttling defines when requests answered with 429 or 503 fail with a ThrottledError, signaling backpressure.
+	Throttling ThrottlingSettings `mapstructure:"throttling"`
+
+	// UserAgent overrides the User-Agent header sent with each request.
+	UserAgent string `mapstructure:"user_agent"`
+
+	// Headers are additional static HTTP headers sent with each request, e.g. X-Forwarded-For or tenant headers.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// LogsBuffer accumulates log events across batches to reduce the number of requests sent by chatty pipelines.
+	LogsBuffer LogsBufferSettings `mapstructure:"logs_buffer"`
+
+	// DrainTimeout is the maximum duration of shutting down, spent waiting for the batches being sent and sending the
+	// buffered events. 0 only bounds it by the shutdown deadline of the collector. Defaults to 10s.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+
+	// StringifyStructuredLogBodies sends map and array log bodies as a JSON string instead of a nested JSON event.
+	// Nested events let Splunk automatically extract their keys. Defaults to false.
+	StringifyStructuredLogBodies bool `mapstructure:"stringify_structured_log_bodies"`
+
+	// RawLogBody sends the event of log records as the exact string of their body, with no wrapping JSON object, so
+	// that props and transforms written for plain text logs keep working. Map and array bodies are serialized to JSON.
+	// Cannot be combined with log_template, and requires log record attributes to be placed in fields.
+	// Defaults to false.
+	RawLogBody bool `mapstructure:"raw_log_body"`
+
+	// LogTemplate is an optional Go template shaping the event of log records, e.g. "[{{.severity}}] {{.body}}".
+	// The record is available as body, severity, severity_number, name, trace_id, span_id and attributes.
+	LogTemplate string `mapstructure:"log_template"`
+	logTemplate *template.Template
+
+	// AttributesFilter drops attributes before they are serialized, e.g. high-cardinality, sensitive or empty ones.
+	AttributesFilter AttributesFilterSettings `mapstructure:"attributes_filter"`
+	attributeFilter  *attributeFilter
+
+	// AttributesPlacement defines whether attributes are sent as indexed HEC fields, in the event payload or both.
+	AttributesPlacement AttributesPlacementSettings `mapstructure:"attributes_placement"`
+
+	// SpanFormat is the field layout of span events: "otlp" follows the OpenTelemetry protocol, "apm" sends the
+	// trace_id, span_id and parent_id, the duration in milliseconds and the service attributes of the resource at the
+	// top level of the event, as expected by Splunk APM and IT Service Intelligence, "compact" uses terse field names
+	// to cut the size of payloads. Defaults to "otlp".
+	SpanFormat string `mapstructure:"span_format"`
+
+	// SeparateSpanEventsAndLinks sends each event and link of spans as its own HEC event, correlated with the span by
+	// its trace_id and span_id, instead of embedding them in the span. Defaults to false.
+	SeparateSpanEventsAndLinks bool `mapstructure:"separate_span_events_and_links"`
+
+	// SpanSourceFromServiceName sets the source of span events to the service.name of their resource, and their host
+	// to its host.name, whatever hec_metadata_to_otel_attrs maps for other signals. Defaults to false.
+	SpanSourceFromServiceName bool `mapstructure:"span_source_from_service_name"`
+
+	// ErrorSpansOnly only sends the spans with an error status, e.g. when full-fidelity traces already go to another
+	// backend.
+	ErrorSpansOnly ErrorSpansOnlySettings `mapstructure:"error_spans_only"`
+
+	// SpanIndexRouting picks the index of span events from an attribute, e.g. to send production and staging traces
+	// to different indexes.
+	SpanIndexRouting SpanIndexRoutingSettings `mapstructure:"span_index_routing"`
+
+	// SpanStatusMapping sends the status of spans as HEC fields with custom names and values, e.g. to match existing
+	// Splunk trace dashboards.
+	SpanStatusMapping SpanStatusMappingSettings `mapstructure:"span_status_mapping"`
+
+	// LogSeverity maps the severity of log records to HEC fields and sourcetypes, so that searches and alerts can
+	// filter by level without parsing the event.
+	LogSeverity LogSeveritySettings `mapstructure:"log_severity"`
+
+	// CounterResets emits an annotation event when a cumulative monotonic sum resets, to explain sudden dips of
+	// rate() in Splunk.
+	CounterResets CounterResetsSettings `mapstructure:"counter_resets"`
+
+	// Exemplars sends the latest exemplar of each histogram bucket as fields of its metric event, so that Splunk
+	// users can pivot from a latency spike to the traces.
+	Exemplars ExemplarsSettings `mapstructure:"exemplars"`
+
+	// CumulativeToDelta sends the difference between consecutive data points of cumulative monotonic sums instead of
+	// their cumulative value, so that they can be summed over time in Splunk. The first data point of each series is
+	// dropped, and a restarted counter is detected by a lower value or a later start time. Defaults to false.
+	CumulativeToDelta bool `mapstructure:"cumulative_to_delta"`
+
+	// DiagnosticsExporter is the name of a logs exporter, configured in a logs pipeline, the exporter sends a log
+	// record to whenever it drops records, with the reason, the number of dropped records and an example of them.
+	// Diagnostics are only logged if empty.
+	DiagnosticsExporter string `mapstructure:"diagnostics_exporter"`
+
+	// PayloadCapture records the serialized HEC payloads, e.g. to validate the index/source/sourcetype mapping before going live.
+	PayloadCapture PayloadCaptureSettings `mapstructure:"payload_capture"`
+
+	// Redaction replaces the parts of field values matching patterns just before events are serialized, as a last
+	// line of defense for secrets and personal data missed by processors.
+	Redaction []RedactionRule `mapstructure:"redaction"`
+	redactor  *redactor
+
+	// OnBatchAccepted is called after each batch of records is accepted by HEC, i.e. answered with a 2XX status
+	// code, so that Go programs embedding the exporter can checkpoint their upstream offsets. The batches of each
+	// exported pdata structure are confirmed in the order of their records, and the callback should return quickly as
+	// it delays sending. It can only be set programmatically.
+	OnBatchAccepted BatchConfirmationFunc `mapstructure:"-"`
+}
+
+// RedactionRule defines the values redacted from the fields of events.
+type RedactionRule struct {
+	// Field is a regular expression matching the names of the fields the rule applies to. The rule applies to all
+	// fields if empty. The body of events that is not a map is matched as the "event" field.
+	Field string `mapstructure:"field"`
+
+	// Pattern is a regular expression matching the parts of values to redact.
+	Pattern string `mapstructure:"pattern"`
+
+	// Replacement of the matched parts of values, which may refer to submatches, e.g. "${1}****".
+	// Defaults to "****".
+	Replacement string `mapstructure:"replacement"`
+}
+
+// MetricRenameSettings defines a metric rename rule.
+type MetricRenameSettings struct {
+	// MatchType is how names are matched: "strict" for an exact name or "regexp" for a regular expression matching
+	// the whole name. Defaults to "strict".
+	MatchType string `mapstructure:"match_type"`
+
+	// Name is the name, or the regular expression, of the metrics renamed.
+	Name string `mapstructure:"name"`
+
+	// NewName is the name the metrics are sent as. Can reference the submatches of a regular expression, e.g. "$1",
+	// written "$$1" in configuration files to escape environment variable expansion.
+	NewName string `mapstructure:"new_name"`
+}
+
+// NonFiniteValuesSettings defines how NaN and infinite values are handled.
+type NonFiniteValuesSettings struct {
+	// Action is "drop" to silently drop the values, "drop_and_count" to also report them as dropped records, or
+	// "convert" to replace them with SentinelValue. Events left without a metric value are dropped. Defaults to "drop".
+	Action string `mapstructure:"action"`
+
+	// SentinelValue replaces non-finite values with the "convert" action. Defaults to 0.
+	SentinelValue float64 `mapstructure:"sentinel_value"`
+
+	// FlagField is the field holding the original value, e.g. "NaN" or "+Inf", of events whose values were
+	// converted. Defaults to "non_finite_value".
+	FlagField string `mapstructure:"flag_field"`
+}
+
+// DimensionsSettings defines how the dimensions of metric events are mapped. Dimensions are dropped, then renamed,
+// then added.
+type DimensionsSettings struct {
+	// Rename maps dimension names to the names they are sent as.
+	Rename map[string]string `mapstructure:"rename"`
+
+	// Drop lists the dimensions not sent.
+	Drop []string `mapstructure:"drop"`
+
+	// Add maps the names of static dimensions added to all metric events to their values. They override dimensions of
+	// the same name.
+	Add map[string]string `mapstructure:"add"`
+}
+
+// LogsBufferSettings defines how log events are accumulated across batches.
+type LogsBufferSettings struct {
+	// Enabled turns on buffering of log events across batches. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// FlushInterval is the maximum duration log events are buffered before being sent. Defaults to 1s.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// IdleTimeout sends the buffered events once no batch was received for this duration, reducing the latency of
+	// the last events before a quiet period. 0 disables it. Defaults to 0.
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+}
+
+// ThrottlingSettings defines when HEC is considered saturated.
+type ThrottlingSettings struct {
+	// Window is how long HEC must keep answering requests with 429 Too Many Requests or 503 Service Unavailable for
+	// them to fail with a ThrottledError. 0 reports the first such response as throttled. Defaults to 30s.
+	Window time.Duration `mapstructure:"window"`
+}
+
+// AuthSettings defines how the requests to HEC are authenticated.
+type AuthSettings struct {
+	// Type is "token" to send the HEC token of token or token_file, "basic" for HTTP basic authentication, or
+	// "oauth2_client_credentials" for an OAuth2 access token obtained with the client credentials grant, e.g. for
+	// HEC-compatible gateways. Defaults to "token".
+	Type string `mapstructure:"type"`
+
+	// Username of basic authentication.
+	Username string `mapstructure:"username"`
+
+	// Password of basic authentication.
+	Password string `mapstructure:"password"`
+
+	// OAuth2 defines the client credentials grant of oauth2_client_credentials.
+	OAuth2 OAuth2Settings `mapstructure:"oauth2"`
+}
+
+// OAuth2Settings defines how OAuth2 access tokens are obtained with the client credentials grant. The tokens are
+// refreshed shortly before they expire, or once rejected.
+type OAuth2Settings struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string `mapstructure:"token_url"`
+
+	// ClientID and ClientSecret are the credentials of the client, sent with basic authentication.
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+
+	// Scopes requested, if any.
+	Scopes []string `mapstructure:"scopes"`
+}
+
+// FastRetrySettings defines how requests whose connection failed are retried within the client.
+type FastRetrySettings struct {
+	// MaxRetries is the number of times a request is retried. 0 disables fast retries. Defaults to 1.
+	MaxRetries uint `mapstructure:"max_retries"`
+
+	// Backoff is the wait before each retry. Defaults to 10ms.
+	Backoff time.Duration `mapstructure:"backoff"`
+}
+
+// WarmupSettings defines how the endpoint is warmed up on start.
+type WarmupSettings struct {
+	// Enabled turns on resolving the host of the endpoint in the background on start. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Connections is the number of connections opened to the endpoint on start, by requesting its health endpoint,
+	// and kept idle for the first requests. Limited by max_connections. 0 only resolves the endpoint. Defaults to 0.
+	Connections uint `mapstructure:"connections"`
+}
+
+// AttributesFilterSettings defines the resource attributes, log record and span attributes and metric labels
+// sent to Splunk. They are still used to set the HEC metadata of events.
+type AttributesFilterSettings struct {
+	// MatchType is how keys are matched: "strict" for exact keys or "regexp" for regular expressions. Defaults to "strict".
+	MatchType string `mapstructure:"match_type"`
+
+	// Include lists the keys of the only attributes sent. All attributes are sent if empty.
+	Include []string `mapstructure:"include"`
+
+	// Exclude lists the keys of attributes never sent. Takes precedence over Include.
+	Exclude []string `mapstructure:"exclude"`
+
+	// DropEmpty drops the attributes and labels whose value is null or an empty string, since empty indexed fields
+	// inflate payloads and confuse field extraction. Defaults to false.
+	DropEmpty bool `mapstructure:"drop_empty"`
+}
+
+// ErrorSpansOnlySettings defines how spans are filtered by status.
+type ErrorSpansOnlySettings struct {
+	// Enabled only sends the spans with an error status. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// IncludeRootSpans also sends the root spans of the traces with an error span in the same batch, for the
+	// context of the request that failed. Defaults to false.
+	IncludeRootSpans bool `mapstructure:"include_root_spans"`
+}
+
+// SpanIndexRoutingSettings defines how the index of span events is picked from an attribute.
+type SpanIndexRoutingSettings struct {
+	// Attribute is the span attribute whose value picks the index, e.g. deployment.environment. The attribute of the
+	// resource is used for spans that do not have it. Routing is disabled if empty.
+	Attribute string `mapstructure:"attribute"`
+
+	// Indexes maps the values of the attribute to indexes. Spans with other values are sent to the default index. The
+	// value of the attribute is used as the index if empty.
+	Indexes map[string]string `mapstructure:"indexes"`
+}
+
+// SpanStatusMappingSettings defines the HEC fields the status of spans is sent as.
+type SpanStatusMappingSettings struct {
+	// ErrorField is the name of the field set to true for spans with an error status, and false otherwise, e.g.
+	// "error". Not sent if empty.
+	ErrorField string `mapstructure:"error_field"`
+
+	// CodeField is the name of the field the status code of spans is sent as. Not sent if empty.
+	CodeField string `mapstructure:"code_field"`
+
+	// Codes maps the status codes of spans, "unset", "ok" or "error", to the values of CodeField. Unmapped codes are
+	// sent as their OpenTelemetry name, e.g. "STATUS_CODE_ERROR".
+	Codes map[string]string `mapstructure:"codes"`
+
+	// MessageField is the name of the field the status message of spans is sent as, e.g. "otel.status_description".
+	// Not sent if empty or if the span has no status message.
+	MessageField string `mapstructure:"message_field"`
+}
+
+// AttributesPlacementSettings defines where the attributes of log records and spans are sent: "fields", "event" or "both".
+type AttributesPlacementSettings struct {
+	// Logs is the placement of log record attributes. Defaults to "fields".
+	Logs string `mapstructure:"logs"`
+
+	// Spans is the placement of span attributes. Defaults to "event".
+	Spans string `mapstructure:"spans"`
+}
+
+// LogSeveritySettings defines how the severity of log records is sent.
+type LogSeveritySettings struct {
+	// TextField is the name of the field the severity text of log records is sent as. Not sent if empty.
+	TextField string `mapstructure:"text_field"`
+
+	// NumberField is the name of the field the severity number of log records is sent as. Not sent if empty.
+	NumberField string `mapstructure:"number_field"`
+
+	// SourceTypes maps the lowercase severity text of log records, or the level of their severity number (trace,
+	// debug, info, warn, error or fatal), to the sourcetype of their events.
+	SourceTypes map[string]string `mapstructure:"sourcetypes"`
+}
+
+// CounterResetsSettings defines how counter resets are reported.
+type CounterResetsSettings struct {
+	// Enabled turns on the detection of counter resets, by a lower value or a later start time of a series.
+	// Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// SourceType is the sourcetype of the annotation events. The sourcetype of the metric is used if empty.
+	// Defaults to "otel:counter_reset".
+	SourceType string `mapstructure:"sourcetype"`
+}
+
+// ExemplarsSettings defines the fields the exemplars of histogram buckets are sent as. The trace and span IDs of
+// exemplars are read from their trace_id and span_id filtered labels.
+type ExemplarsSettings struct {
+	// Enabled turns on sending exemplars. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// TraceIDField is the name of the field the trace ID of exemplars is sent as. Not sent if empty.
+	// Defaults to "trace_id".
+	TraceIDField string `mapstructure:"trace_id_field"`
+
+	// SpanIDField is the name of the field the span ID of exemplars is sent as. Not sent if empty.
+	// Defaults to "span_id".
+	SpanIDField string `mapstructure:"span_id_field"`
+
+	// ValueField is the name of the field the value of exemplars is sent as. Not sent if empty.
+	// Defaults to "exemplar_value".
+	ValueField string `mapstructure:"value_field"`
+}
+
+// PayloadCaptureSettings defines how serialized HEC payloads are captured for debugging.
+type PayloadCaptureSettings struct {
+	// Enabled turns on payload capture. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path of a file the uncompressed payloads are appended to. Payloads are written to the debug logger if empty.
+	Path string `mapstructure:"path"`
+
+	// DryRun skips posting the payloads to the HEC endpoint once captured. Defaults to false.
+	DryRun bool `mapstructure:"dry_run"`
 }
 
 func (cfg *Config) getOptionsFromConfig() (*exporterOptions, error) {
+	return cfg.getSignalOptions("")
+}
+
+// getSignalOptions returns the options of the exporter of a signal whose endpoint is the given one, endpoint being
+// used if empty.
+func (cfg *Config) getSignalOptions(signalEndpoint string) (*exporterOptions, error) {
 	if err := cfg.validateConfig(); err != nil {
 		return nil, err
 	}
 
-	url, err := cfg.getURL()
+	if signalEndpoint == "" {
+		signalEndpoint = cfg.Endpoint
+	}
+	// The endpoints were checked by validateConfig.
+	endpoint, err := getURL(signalEndpoint)
 	if err != nil {
-		return nil, fmt.Errorf(`invalid "endpoint": %v`, err)
+		return nil, err
 	}
 
-	return &exporterOptions{
-		url:   url,
+	options := &exporterOptions{
+		url:   endpoint,
 		token: cfg.Token,
-	}, nil
+	}
+
+	if endpoint.Scheme == unixScheme {
+		// The socket is dialed directly, requests themselves target the default HEC path.
+		options.socketPath = endpoint.Path
+		options.url = &url.URL{Scheme: "http", Host: "localhost", Path: "/" + hecPath}
+	}
+
+	return options, nil
 }
 
 func (cfg *Config) validateConfig() error {
@@ -83,22 +628,312 @@ func (cfg *Config) validateConfig() error {
 		return errors.New(`requires a non-empty "endpoint"`)
 	}
 
-	if cfg.Token == "" {
-		return errors.New(`requires a non-empty "token"`)
+	for _, endpoint := range []struct{ key, value string }{
+		{"endpoint", cfg.Endpoint},
+		{"logs_endpoint", cfg.LogsEndpoint},
+		{"metrics_endpoint", cfg.MetricsEndpoint},
+		{"traces_endpoint", cfg.TracesEndpoint},
+	} {
+		if endpoint.value == "" {
+			continue
+		}
+		u, err := getURL(endpoint.value)
+		if err != nil {
+			return fmt.Errorf(`invalid %q: %v`, endpoint.key, err)
+		}
+		if cfg.FIPSTLS && u.Scheme != "https" {
+			return fmt.Errorf(`"fips_tls" requires an https %q`, endpoint.key)
+		}
+	}
+
+	switch cfg.Auth.Type {
+	case "", authToken:
+		if cfg.Token == "" && cfg.TokenFile == "" && cfg.Authenticator == nil {
+			return errors.New(`requires a non-empty "token" or "token_file"`)
+		}
+	case authBasic:
+		if cfg.Auth.Username == "" {
+			return errors.New(`"auth.type" "basic" requires a non-empty "auth.username"`)
+		}
+	case authOAuth2ClientCredentials:
+		oauth2 := cfg.Auth.OAuth2
+		if oauth2.TokenURL == "" || oauth2.ClientID == "" || oauth2.ClientSecret == "" {
+			return errors.New(`"auth.type" "oauth2_client_credentials" requires a non-empty "auth.oauth2.token_url", ` +
+				`"auth.oauth2.client_id" and "auth.oauth2.client_secret"`)
+		}
+		if u, err := url.Parse(oauth2.TokenURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf(`invalid "auth.oauth2.token_url" %q, must be an http or https URL`, oauth2.TokenURL)
+		}
+	default:
+		return fmt.Errorf(`unsupported "auth.type" %q, must be %q, %q or %q`, cfg.Auth.Type,
+			authToken, authBasic, authOAuth2ClientCredentials)
+	}
+	if cfg.Auth.Type != "" && cfg.Auth.Type != authToken && (cfg.Token != "" || cfg.TokenFile != "") {
+		return fmt.Errorf(`cannot have "token" or "token_file" with "auth.type" %q`, cfg.Auth.Type)
+	}
+	if cfg.Token != "" && cfg.TokenFile != "" {
+		return errors.New(`cannot have both "token" and "token_file"`)
+	}
+
+	if cfg.FastRetry.Backoff < 0 {
+		return errors.New(`cannot have a negative "fast_retry.backoff"`)
+	}
+
+	if cfg.InsecureSkipVerify && !cfg.DevMode {
+		return errors.New(`"insecure_skip_verify" requires "dev_mode"`)
+	}
+	if cfg.InsecureSkipVerify && cfg.FIPSTLS {
+		return errors.New(`cannot have both "insecure_skip_verify" and "fips_tls"`)
+	}
+
+	if cfg.Compression != "" && cfg.Compression != compressionGzip && cfg.Compression != compressionBrotli {
+		return fmt.Errorf(`unsupported "compression" %q, must be %q or %q`, cfg.Compression, compressionGzip, compressionBrotli)
+	}
+
+	switch cfg.TimestampPrecision {
+	case "", timestampPrecisionSecond, timestampPrecisionMillisecond, timestampPrecisionMicrosecond,
+		timestampPrecisionNanosecond:
+	default:
+		return fmt.Errorf(`unsupported "timestamp_precision" %q, must be %q, %q, %q or %q`, cfg.TimestampPrecision,
+			timestampPrecisionSecond, timestampPrecisionMillisecond, timestampPrecisionMicrosecond,
+			timestampPrecisionNanosecond)
+	}
+
+	switch cfg.NonFiniteValues.Action {
+	case "", nonFiniteDrop, nonFiniteDropAndCount, nonFiniteConvert:
+	default:
+		return fmt.Errorf(`unsupported "non_finite_values.action" %q, must be %q, %q or %q`, cfg.NonFiniteValues.Action,
+			nonFiniteDrop, nonFiniteDropAndCount, nonFiniteConvert)
+	}
+
+	switch cfg.SpanFormat {
+	case "", spanFormatOTLP, spanFormatAPM, spanFormatCompact:
+	default:
+		return fmt.Errorf(`unsupported "span_format" %q, must be %q, %q or %q`, cfg.SpanFormat,
+			spanFormatOTLP, spanFormatAPM, spanFormatCompact)
+	}
+
+	for code := range cfg.SpanStatusMapping.Codes {
+		switch code {
+		case "unset", "ok", "error":
+		default:
+			return fmt.Errorf(`unsupported status code %q in "span_status_mapping.codes", must be "unset", "ok" or "error"`, code)
+		}
+	}
+
+	for name, placement := range map[string]string{"logs": cfg.AttributesPlacement.Logs, "spans": cfg.AttributesPlacement.Spans} {
+		if placement != "" && placement != placementFields && placement != placementEvent && placement != placementBoth {
+			return fmt.Errorf(`unsupported "attributes_placement.%s" %q, must be %q, %q or %q`,
+				name, placement, placementFields, placementEvent, placementBoth)
+		}
+	}
+
+	filter, err := newAttributeFilter(cfg.AttributesFilter)
+	if err != nil {
+		return fmt.Errorf(`invalid "attributes_filter": %v`, err)
+	}
+	cfg.attributeFilter = filter
+
+	redactor, err := newRedactor(cfg.Redaction)
+	if err != nil {
+		return err
+	}
+	cfg.redactor = redactor
+	cfg.semanticConventions = newSemanticConventionMapper(cfg)
+
+	namer, err := newMetricNamer(cfg.MetricPrefix, cfg.MetricRenames)
+	if err != nil {
+		return fmt.Errorf(`invalid "metric_renames": %v`, err)
+	}
+	cfg.metricNamer = namer
+
+	mapper, err := newDimensionMapper(cfg.Dimensions)
+	if err != nil {
+		return fmt.Errorf(`invalid "dimensions": %v`, err)
+	}
+	cfg.dimensionMapper = mapper
+
+	if cfg.MaxEventFields == 1 {
+		// Extension events hold at least the correlation id and another field.
+		return errors.New(`"max_event_fields" must be 0 or at least 2`)
+	}
+
+	if cfg.AdaptiveContentLength && cfg.MaxContentLength == 0 {
+		return errors.New(`"adaptive_content_length" requires a non-zero "max_content_length"`)
+	}
+
+	if cfg.LogsBuffer.Enabled && cfg.LogsBuffer.FlushInterval <= 0 {
+		return errors.New(`"logs_buffer.flush_interval" must be positive`)
+	}
+
+	if cfg.LogsBuffer.IdleTimeout < 0 {
+		return errors.New(`"logs_buffer.idle_timeout" must not be negative`)
+	}
+
+	if cfg.Throttling.Window < 0 {
+		return errors.New(`"throttling.window" must not be negative`)
+	}
+
+	if cfg.DrainTimeout < 0 {
+		return errors.New(`"drain_timeout" must not be negative`)
+	}
+
+	if cfg.sourceTemplate, err = parseMetadataTemplate("source", cfg.Source); err != nil {
+		return err
+	}
+	if cfg.sourceTypeTemplate, err = parseMetadataTemplate("sourcetype", cfg.SourceType); err != nil {
+		return err
+	}
+
+	if cfg.LogTemplate != "" {
+		tmpl, err := template.New("log_template").Parse(cfg.LogTemplate)
+		if err != nil {
+			return fmt.Errorf(`invalid "log_template": %v`, err)
+		}
+		cfg.logTemplate = tmpl
+	}
+
+	if cfg.RawLogBody && cfg.LogTemplate != "" {
+		return errors.New(`"raw_log_body" cannot be used with "log_template"`)
+	}
+
+	if cfg.RawLogBody && cfg.logAttributesPlacement() != placementFields {
+		return errors.New(`"raw_log_body" requires "attributes_placement.logs" to be "fields"`)
+	}
+
+	if cfg.DiagnosticsExporter != "" && cfg.DiagnosticsExporter == cfg.Name() {
+		return errors.New(`"diagnostics_exporter" cannot be the exporter itself`)
+	}
+
+	if cfg.PayloadCapture.DryRun && !cfg.PayloadCapture.Enabled {
+		return errors.New(`"payload_capture.dry_run" requires "payload_capture.enabled"`)
 	}
 
 	return nil
 }
 
-func (cfg *Config) getURL() (out *url.URL, err error) {
+// parseMetadataTemplate parses the template of a HEC metadata field, returning nil for static values.
+func parseMetadataTemplate(name string, value string) (*template.Template, error) {
+	if !strings.Contains(value, "{{") {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid %q template: %v`, name, err)
+	}
+	return tmpl, nil
+}
+
+// contentEncoding returns the content-encoding of compressed requests.
+func (cfg *Config) contentEncoding() string {
+	if cfg.Compression == "" {
+		return compressionGzip
+	}
+	return cfg.Compression
+}
+
+// eventSeparator returns what follows each event in requests.
+func (cfg *Config) eventSeparator() string {
+	if cfg.NewlineDelimitedEvents {
+		return "\n"
+	}
+	return "\n\r\n\r\n"
+}
+
+// signalIndex returns the index of the events of a signal whose index is the given one.
+func (cfg *Config) signalIndex(index string) string {
+	if index == "" {
+		return cfg.Index
+	}
+	return index
+}
+
+// metadataMapping returns the attributes mapped to the HEC metadata of events. The source and sourcetype rendered from
+// templates are not mapped, the templates taking precedence.
+func (cfg *Config) metadataMapping() splunk.HecToOtelAttrs {
+	mapping := cfg.HecToOtelAttrs
+	if cfg.sourceTemplate != nil {
+		mapping.Source = ""
+	}
+	if cfg.sourceTypeTemplate != nil {
+		mapping.SourceType = ""
+	}
+	return mapping
+}
+
+// timestampPrecision returns the precision of the time of events.
+func (cfg *Config) timestampPrecision() string {
+	if cfg.TimestampPrecision == "" {
+		return timestampPrecisionMillisecond
+	}
+	return cfg.TimestampPrecision
+}
+
+// nonFiniteFlagField returns the field flagging events whose non-finite values were converted.
+func (cfg *Config) nonFiniteFlagField() string {
+	if cfg.NonFiniteValues.FlagField == "" {
+		return defaultNonFiniteFlagField
+	}
+	return cfg.NonFiniteValues.FlagField
+}
 
-	out, err = url.Parse(cfg.Endpoint)
+// logAttributesPlacement returns where log record attributes are sent.
+func (cfg *Config) logAttributesPlacement() string {
+	if cfg.AttributesPlacement.Logs == "" {
+		return placementFields
+	}
+	return cfg.AttributesPlacement.Logs
+}
+
+// spanAttributesPlacement returns where span attributes are sent.
+func (cfg *Config) spanAttributesPlacement() string {
+	if cfg.AttributesPlacement.Spans == "" {
+		return placementEvent
+	}
+	return cfg.AttributesPlacement.Spans
+}
+
+// getURL parses a HEC endpoint, defaulting its path to the HEC path.
+func getURL(endpoint string) (out *url.URL, err error) {
+	out, err = url.Parse(endpoint)
 	if err != nil {
 		return out, err
 	}
-	if out.Path == "" || out.Path == "/" {
+	if out.Scheme == unixScheme && (out.Path == "" || out.Path == "/") {
+		return nil, errors.New("missing unix socket path")
+	}
+	if out.Scheme != unixScheme {
+		if err = validateHost(out); err != nil {
+			return nil, err
+		}
+	}
+	if out.Scheme != unixScheme && (out.Path == "" || out.Path == "/") {
 		out.Path = path.Join(out.Path, hecPath)
 	}
 
 	return
 }
+
+// validateHost checks the host of the endpoint, in particular that IPv6 literals are enclosed in brackets: without
+// them, the last group of the address would be taken as the port, and both the Host header and the address sent in
+// the CONNECT request of a proxy would be mangled.
+func validateHost(endpoint *url.URL) error {
+	if endpoint.Host == "" {
+		return errors.New("missing host")
+	}
+	if strings.HasPrefix(endpoint.Host, "[") {
+		// url.Parse already rejects unterminated brackets, only the address itself is left to check.
+		host := endpoint.Hostname()
+		if i := strings.LastIndex(host, "%"); i >= 0 {
+			host = host[:i]
+		}
+		if net.ParseIP(host) == nil || !strings.Contains(host, ":") {
+			return fmt.Errorf("invalid IPv6 address %q", endpoint.Hostname())
+		}
+		return nil
+	}
+	if strings.Count(endpoint.Host, ":") > 1 {
+		return fmt.Errorf(`IPv6 address in %q must be enclosed in brackets, e.g. "https://[::1]:8088"`, endpoint.Host)
+	}
+	return nil
+}
@@ -29,6 +29,8 @@ import (
 	"go.opentelemetry.io/collector/config/configtest"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -58,12 +60,97 @@ func TestLoadConfig(t *testing.T) {
 			TypeVal: configmodels.Type(typeStr),
 			NameVal: expectedName,
 		},
-		Token:          "00000000-0000-0000-0000-0000000000000",
-		Endpoint:       "https://splunk:8088/services/collector",
-		Source:         "otel",
-		SourceType:     "otel",
-		Index:          "metrics",
-		MaxConnections: 100,
+		Token:                       "00000000-0000-0000-0000-0000000000000",
+		Endpoint:                    "https://splunk:8088/services/collector",
+		MetricsEndpoint:             "https://splunk-metrics:8088/services/collector",
+		Source:                      "otel",
+		SourceType:                  "otel",
+		Index:                       "metrics",
+		LogsIndex:                   "logs",
+		TracesIndex:                 "traces",
+		TimestampPrecision:          "s",
+		UseMultiMetricFormat:        true,
+		IncludeResourceAttributes:   true,
+		ResourceAttributesAllowList: []string{"k8s.pod.name", "k8s.namespace.name"},
+		MetricUnitField:             "metric_unit",
+		NonFiniteValues: NonFiniteValuesSettings{
+			Action:        "convert",
+			SentinelValue: -1,
+		},
+		MetricPrefix: "otel.",
+		MetricRenames: []MetricRenameSettings{
+			{Name: "system.cpu.time", NewName: "cpu.time"},
+			{MatchType: "regexp", Name: `system\.memory\.(.*)`, NewName: "mem.$1"},
+		},
+		Dimensions: DimensionsSettings{
+			Rename: map[string]string{"k8s.node.name": "kubernetes_node"},
+			Drop:   []string{"host.id"},
+			Add:    map[string]string{"env": "prod"},
+		},
+		MapSemanticConventions: true,
+		MaxConnections:         100,
+		MaxContentLength:       1048576,
+		MaxEventCount:          1000,
+		SplitByMetadata:        true,
+		MaxEventFields:         100,
+		DeterministicOutput:    true,
+		NewlineDelimitedEvents: true,
+		FIPSTLS:                true,
+		Warmup: WarmupSettings{
+			Enabled:     true,
+			Connections: 2,
+		},
+		DNSRefreshInterval: 5 * time.Minute,
+		FastRetry: FastRetrySettings{
+			MaxRetries: 2,
+			Backoff:    50 * time.Millisecond,
+		},
+		Throttling: ThrottlingSettings{
+			Window: time.Minute,
+		},
+		UserAgent: "my-collector/1.0",
+		Headers:   map[string]string{"x-tenant": "tenant-1"},
+		LogsBuffer: LogsBufferSettings{
+			Enabled:       true,
+			FlushInterval: 2 * time.Second,
+		},
+		DrainTimeout: 30 * time.Second,
+		LogSeverity: LogSeveritySettings{
+			TextField:   "severity",
+			SourceTypes: map[string]string{"error": "otel:error"},
+		},
+		CounterResets: CounterResetsSettings{
+			Enabled:    true,
+			SourceType: "otel:reset",
+		},
+		Exemplars: ExemplarsSettings{
+			Enabled:      true,
+			TraceIDField: "trace.id",
+			SpanIDField:  "span_id",
+			ValueField:   "exemplar_value",
+		},
+		CumulativeToDelta: true,
+		SpanIndexRouting: SpanIndexRoutingSettings{
+			Attribute: "deployment.environment",
+			Indexes:   map[string]string{"production": "traces_prod", "staging": "traces_staging"},
+		},
+		SpanStatusMapping: SpanStatusMappingSettings{
+			ErrorField:   "error",
+			CodeField:    "status",
+			Codes:        map[string]string{"ok": "success", "error": "failure"},
+			MessageField: "otel.status_description",
+		},
+		DiagnosticsExporter: "logging",
+		Redaction: []RedactionRule{
+			{Field: "^(http.url|db.statement)$", Pattern: `password=[^&\s]*`, Replacement: "password=****"},
+			{Pattern: `\b\d{4}-\d{4}-\d{4}-\d{4}\b`},
+		},
+		HecToOtelAttrs: splunk.HecToOtelAttrs{
+			Source:     "service.name",
+			SourceType: "com.splunk.sourcetype",
+			Index:      "k8s.namespace.name",
+			Host:       "k8s.node.name",
+		},
 		TimeoutSettings: exporterhelper.TimeoutSettings{
 			Timeout: 10 * time.Second,
 		},
@@ -85,6 +172,20 @@ func TestLoadConfig(t *testing.T) {
 	te, err := factory.CreateMetricsExporter(context.Background(), params, e1)
 	require.NoError(t, err)
 	require.NotNil(t, te)
+
+	e2 := cfg.Exporters["splunk_hec/oauth2"].(*Config)
+	assert.Equal(t, "https://hec-gateway:443/services/collector", e2.Endpoint)
+	assert.Equal(t, AuthSettings{
+		Type: "oauth2_client_credentials",
+		OAuth2: OAuth2Settings{
+			TokenURL:     "https://auth.example.com/oauth2/token",
+			ClientID:     "otel-collector",
+			ClientSecret: "s3cr3t",
+			Scopes:       []string{"hec.write"},
+		},
+	}, e2.Auth)
+	_, err = e2.getOptionsFromConfig()
+	assert.NoError(t, err)
 }
 
 func TestConfig_getOptionsFromConfig(t *testing.T) {
@@ -134,6 +235,32 @@ func TestConfig_getOptionsFromConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Test unix socket URL",
+			fields: fields{
+				Token:    "1234",
+				Endpoint: "http+unix:///var/run/splunk-hec.sock",
+			},
+			want: &exporterOptions{
+				token: "1234",
+				url: &url.URL{
+					Scheme: "http",
+					Host:   "localhost",
+					Path:   "/services/collector",
+				},
+				socketPath: "/var/run/splunk-hec.sock",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Test unix socket URL without path",
+			fields: fields{
+				Token:    "1234",
+				Endpoint: "http+unix://",
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "Test empty config",
 			want:    nil,
@@ -159,3 +286,456 @@ func TestConfig_getOptionsFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_ipv6Endpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  string
+	}{
+		{endpoint: "https://[::1]:8088", want: "https://[::1]:8088/services/collector"},
+		{endpoint: "https://[2001:db8::1]:8088/services/collector", want: "https://[2001:db8::1]:8088/services/collector"},
+		{endpoint: "https://[2001:db8::1]/services/collector", want: "https://[2001:db8::1]/services/collector"},
+		{endpoint: "https://[fe80::1%25eth0]:8088", want: "https://[fe80::1%25eth0]:8088/services/collector"},
+		{endpoint: "https://[::ffff:192.0.2.1]:8088", want: "https://[::ffff:192.0.2.1]:8088/services/collector"},
+		{
+			endpoint: "https://2001:db8::1:8088",
+			wantErr:  `invalid "endpoint": IPv6 address in "2001:db8::1:8088" must be enclosed in brackets, e.g. "https://[::1]:8088"`,
+		},
+		// Depending on the Go version, url.Parse may already reject these.
+		{endpoint: "https://[192.0.2.1]:8088", wantErr: `invalid "endpoint": `},
+		{endpoint: "https://[splunk]:8088", wantErr: `invalid "endpoint": `},
+		{endpoint: "splunk:8088", wantErr: `invalid "endpoint": missing host`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			cfg := &Config{Token: "1234", Endpoint: tt.endpoint}
+			options, err := cfg.getOptionsFromConfig()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, options.url.String())
+		})
+	}
+}
+
+func TestConfig_dryRunRequiresPayloadCapture(t *testing.T) {
+	cfg := &Config{
+		Token:          "1234",
+		Endpoint:       "https://example.com:8088",
+		PayloadCapture: PayloadCaptureSettings{DryRun: true},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"payload_capture.dry_run" requires "payload_capture.enabled"`)
+
+	cfg.PayloadCapture.Enabled = true
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_insecureSkipVerifyRequiresDevMode(t *testing.T) {
+	cfg := &Config{
+		Token:              "1234",
+		Endpoint:           "https://example.com:8088",
+		InsecureSkipVerify: true,
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"insecure_skip_verify" requires "dev_mode"`)
+
+	cfg.DevMode = true
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_fipsTLS(t *testing.T) {
+	cfg := &Config{
+		Token:              "1234",
+		Endpoint:           "https://example.com:8088",
+		InsecureSkipVerify: true,
+		DevMode:            true,
+		FIPSTLS:            true,
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `cannot have both "insecure_skip_verify" and "fips_tls"`)
+
+	cfg.InsecureSkipVerify = false
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+
+	cfg.MetricsEndpoint = "http://example.com:8088"
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"fips_tls" requires an https "metrics_endpoint"`)
+
+	cfg.MetricsEndpoint = ""
+	cfg.Endpoint = "http+unix:///var/run/splunk-hec.sock"
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"fips_tls" requires an https "endpoint"`)
+}
+
+func TestConfig_compression(t *testing.T) {
+	cfg := &Config{
+		Token:       "1234",
+		Endpoint:    "https://example.com:8088",
+		Compression: "zstd",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unsupported "compression" "zstd", must be "gzip" or "br"`)
+
+	cfg.Compression = compressionBrotli
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "br", cfg.contentEncoding())
+
+	cfg.Compression = ""
+	assert.Equal(t, "gzip", cfg.contentEncoding())
+}
+
+func TestConfig_metadataMapping(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, splunk.HecToOtelAttrs{
+		Source:     "service.name",
+		SourceType: "com.splunk.sourcetype",
+		Index:      "com.splunk.index",
+		Host:       "host.name",
+	}, cfg.metadataMapping())
+
+	// Setting the keys to "" disables the mapping.
+	cfg.HecToOtelAttrs = splunk.HecToOtelAttrs{}
+	assert.Equal(t, splunk.HecToOtelAttrs{}, cfg.metadataMapping())
+
+	cfg.HecToOtelAttrs = splunk.HecToOtelAttrs{Index: "k8s.namespace.name"}
+	assert.Equal(t, splunk.HecToOtelAttrs{Index: "k8s.namespace.name"}, cfg.metadataMapping())
+}
+
+func TestConfig_timestampPrecision(t *testing.T) {
+	cfg := &Config{
+		Token:              "1234",
+		Endpoint:           "https://example.com:8088",
+		TimestampPrecision: "ps",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unsupported "timestamp_precision" "ps", must be "s", "ms", "us" or "ns"`)
+
+	cfg.TimestampPrecision = ""
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "ms", cfg.timestampPrecision())
+
+	cfg.TimestampPrecision = "ns"
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_nonFiniteValues(t *testing.T) {
+	cfg := &Config{
+		Token:           "1234",
+		Endpoint:        "https://example.com:8088",
+		NonFiniteValues: NonFiniteValuesSettings{Action: "keep"},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unsupported "non_finite_values.action" "keep", must be "drop", "drop_and_count" or "convert"`)
+
+	cfg.NonFiniteValues.Action = ""
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "non_finite_value", cfg.nonFiniteFlagField())
+}
+
+func TestConfig_attributesPlacement(t *testing.T) {
+	cfg := &Config{
+		Token:               "1234",
+		Endpoint:            "https://example.com:8088",
+		AttributesPlacement: AttributesPlacementSettings{Spans: "body"},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unsupported "attributes_placement.spans" "body", must be "fields", "event" or "both"`)
+
+	cfg.AttributesPlacement.Spans = ""
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "fields", cfg.logAttributesPlacement())
+	assert.Equal(t, "event", cfg.spanAttributesPlacement())
+}
+
+func TestConfig_spanFormat(t *testing.T) {
+	cfg := &Config{
+		Token:      "1234",
+		Endpoint:   "https://example.com:8088",
+		SpanFormat: "zipkin",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unsupported "span_format" "zipkin", must be "otlp", "apm" or "compact"`)
+
+	cfg.SpanFormat = "apm"
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_spanStatusMapping(t *testing.T) {
+	cfg := &Config{
+		Token:             "1234",
+		Endpoint:          "https://example.com:8088",
+		SpanStatusMapping: SpanStatusMappingSettings{CodeField: "status", Codes: map[string]string{"failed": "1"}},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `unsupported status code "failed" in "span_status_mapping.codes", must be "unset", "ok" or "error"`)
+
+	cfg.SpanStatusMapping.Codes = map[string]string{"error": "1"}
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_redaction(t *testing.T) {
+	cfg := &Config{
+		Token:     "1234",
+		Endpoint:  "https://example.com:8088",
+		Redaction: []RedactionRule{{Field: "password"}},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `requires a non-empty "pattern" in "redaction[0]"`)
+
+	cfg.Redaction[0].Pattern = "("
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, "invalid \"pattern\" in \"redaction[0]\": error parsing regexp: missing closing ): `(`")
+
+	cfg.Redaction[0].Pattern = ".+"
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.redactor)
+}
+
+func TestConfig_attributesFilter(t *testing.T) {
+	cfg := &Config{
+		Token:            "1234",
+		Endpoint:         "https://example.com:8088",
+		AttributesFilter: AttributesFilterSettings{MatchType: "regex", Exclude: []string{"user.email"}},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `invalid "attributes_filter": unsupported match_type "regex", must be "strict" or "regexp"`)
+
+	cfg.AttributesFilter.MatchType = "regexp"
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+	assert.False(t, cfg.attributeFilter.keep("user.email"))
+}
+
+func TestConfig_logsBuffer(t *testing.T) {
+	cfg := &Config{
+		Token:      "1234",
+		Endpoint:   "https://example.com:8088",
+		LogsBuffer: LogsBufferSettings{Enabled: true},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"logs_buffer.flush_interval" must be positive`)
+
+	cfg.LogsBuffer.FlushInterval = time.Second
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+
+	cfg.LogsBuffer.IdleTimeout = -time.Second
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"logs_buffer.idle_timeout" must not be negative`)
+}
+
+func TestConfig_throttlingWindow(t *testing.T) {
+	cfg := &Config{
+		Token:      "1234",
+		Endpoint:   "https://example.com:8088",
+		Throttling: ThrottlingSettings{Window: -time.Second},
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"throttling.window" must not be negative`)
+}
+
+func TestConfig_drainTimeout(t *testing.T) {
+	cfg := &Config{
+		Token:        "1234",
+		Endpoint:     "https://example.com:8088",
+		DrainTimeout: -time.Second,
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"drain_timeout" must not be negative`)
+}
+
+func TestConfig_adaptiveContentLength(t *testing.T) {
+	cfg := &Config{
+		Token:                 "1234",
+		Endpoint:              "https://example.com:8088",
+		AdaptiveContentLength: true,
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"adaptive_content_length" requires a non-zero "max_content_length"`)
+
+	cfg.MaxContentLength = defaultMaxContentLength
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_maxEventFields(t *testing.T) {
+	cfg := &Config{
+		Token:          "1234",
+		Endpoint:       "https://example.com:8088",
+		MaxEventFields: 1,
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"max_event_fields" must be 0 or at least 2`)
+
+	cfg.MaxEventFields = 2
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_metadataTemplates(t *testing.T) {
+	cfg := &Config{
+		Token:      "1234",
+		Endpoint:   "https://example.com:8088",
+		Source:     "otel/{{.service_name",
+		SourceType: "otel",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid "source" template`)
+
+	cfg.Source = "otel/{{.service_name}}"
+	_, err = cfg.getOptionsFromConfig()
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.sourceTemplate)
+	assert.Nil(t, cfg.sourceTypeTemplate)
+}
+
+func TestConfig_rawLogBody(t *testing.T) {
+	cfg := &Config{
+		Token:       "1234",
+		Endpoint:    "https://example.com:8088",
+		RawLogBody:  true,
+		LogTemplate: "{{.body}}",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"raw_log_body" cannot be used with "log_template"`)
+
+	cfg.LogTemplate = ""
+	cfg.AttributesPlacement.Logs = placementBoth
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `"raw_log_body" requires "attributes_placement.logs" to be "fields"`)
+
+	cfg.AttributesPlacement.Logs = ""
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
+
+func TestConfig_invalidLogTemplate(t *testing.T) {
+	cfg := &Config{
+		Token:       "1234",
+		Endpoint:    "https://example.com:8088",
+		LogTemplate: "{{.body",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid "log_template"`)
+}
+
+func TestConfig_auth(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "no token",
+			cfg:  Config{},
+			err:  `requires a non-empty "token" or "token_file"`,
+		},
+		{
+			name: "authenticator",
+			cfg:  Config{Authenticator: &basicAuthenticator{}},
+		},
+		{
+			name: "basic",
+			cfg:  Config{Auth: AuthSettings{Type: "basic", Username: "user", Password: "pass"}},
+		},
+		{
+			name: "basic without username",
+			cfg:  Config{Auth: AuthSettings{Type: "basic"}},
+			err:  `"auth.type" "basic" requires a non-empty "auth.username"`,
+		},
+		{
+			name: "basic with token",
+			cfg:  Config{Token: "1234", Auth: AuthSettings{Type: "basic", Username: "user"}},
+			err:  `cannot have "token" or "token_file" with "auth.type" "basic"`,
+		},
+		{
+			name: "oauth2",
+			cfg: Config{Auth: AuthSettings{Type: "oauth2_client_credentials", OAuth2: OAuth2Settings{
+				TokenURL: "https://auth.example.com/token", ClientID: "id", ClientSecret: "secret",
+			}}},
+		},
+		{
+			name: "oauth2 without secret",
+			cfg: Config{Auth: AuthSettings{Type: "oauth2_client_credentials", OAuth2: OAuth2Settings{
+				TokenURL: "https://auth.example.com/token", ClientID: "id",
+			}}},
+			err: `"auth.type" "oauth2_client_credentials" requires a non-empty "auth.oauth2.token_url", ` +
+				`"auth.oauth2.client_id" and "auth.oauth2.client_secret"`,
+		},
+		{
+			name: "oauth2 invalid token url",
+			cfg: Config{Auth: AuthSettings{Type: "oauth2_client_credentials", OAuth2: OAuth2Settings{
+				TokenURL: "auth.example.com/token", ClientID: "id", ClientSecret: "secret",
+			}}},
+			err: `invalid "auth.oauth2.token_url" "auth.example.com/token", must be an http or https URL`,
+		},
+		{
+			name: "unsupported",
+			cfg:  Config{Auth: AuthSettings{Type: "kerberos"}},
+			err:  `unsupported "auth.type" "kerberos", must be "token", "basic" or "oauth2_client_credentials"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Endpoint = "https://example.com:8088"
+			_, err := cfg.getOptionsFromConfig()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfig_signalEndpoints(t *testing.T) {
+	cfg := &Config{
+		Token:           "1234",
+		Endpoint:        "https://example.com:8088",
+		MetricsEndpoint: "https://metrics.example.com:8088",
+	}
+	options, err := cfg.getSignalOptions(cfg.MetricsEndpoint)
+	require.NoError(t, err)
+	assert.Equal(t, "https://metrics.example.com:8088/services/collector", options.url.String())
+
+	options, err = cfg.getSignalOptions(cfg.LogsEndpoint)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:8088/services/collector", options.url.String())
+
+	cfg.TracesEndpoint = "http+unix://"
+	_, err = cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `invalid "traces_endpoint": missing unix socket path`)
+}
+
+func TestConfig_tokenFile(t *testing.T) {
+	cfg := &Config{
+		Token:     "1234",
+		TokenFile: "/var/run/secrets/hec-token",
+		Endpoint:  "https://example.com:8088",
+	}
+	_, err := cfg.getOptionsFromConfig()
+	assert.EqualError(t, err, `cannot have both "token" and "token_file"`)
+
+	cfg.Token = ""
+	_, err = cfg.getOptionsFromConfig()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,161 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/cumulative"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	// counterSeriesTTL is the duration after which a series that received no data point is forgotten.
+	counterSeriesTTL = 10 * time.Minute
+	// resetReasonValueDecreased is the reason of a reset detected by a lower value.
+	resetReasonValueDecreased = "value_decreased"
+	// resetReasonStartTimeChanged is the reason of a reset detected by a later start time.
+	resetReasonStartTimeChanged = "start_time_changed"
+)
+
+// counterResetDetector remembers the last data point of each series of cumulative monotonic sums, to emit an
+// annotation event when a counter restarts, e.g. after the monitored process restarted.
+type counterResetDetector struct {
+	sourceType string
+	series     *cumulative.Tracker
+}
+
+func newCounterResetDetector(settings CounterResetsSettings) *counterResetDetector {
+	if !settings.Enabled {
+		return nil
+	}
+	return &counterResetDetector{
+		sourceType: settings.SourceType,
+		series:     cumulative.NewTracker(counterSeriesTTL, nil),
+	}
+}
+
+// detect returns the annotation events of the data points of the metric resetting their counter.
+func (d *counterResetDetector) detect(meta resourceMetadata, tm pdata.Metric) []*splunk.Event {
+	if d == nil {
+		return nil
+	}
+	var events []*splunk.Event
+	switch tm.DataType() {
+	case pdata.MetricDataTypeIntSum:
+		sum := tm.IntSum()
+		if !sum.IsMonotonic() || sum.AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return nil
+		}
+		pts := sum.DataPoints()
+		for i := 0; i < pts.Len(); i++ {
+			pt := pts.At(i)
+			if ev := d.observe(meta, meta.namer.metricName(tm.Name()), pt.LabelsMap(), cumulative.Point{
+				StartTime: uint64(pt.StartTime()), Timestamp: uint64(pt.Timestamp()), IntValue: pt.Value()}); ev != nil {
+				events = append(events, ev)
+			}
+		}
+	case pdata.MetricDataTypeDoubleSum:
+		sum := tm.DoubleSum()
+		if !sum.IsMonotonic() || sum.AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return nil
+		}
+		pts := sum.DataPoints()
+		for i := 0; i < pts.Len(); i++ {
+			pt := pts.At(i)
+			if ev := d.observe(meta, meta.namer.metricName(tm.Name()), pt.LabelsMap(), cumulative.Point{
+				StartTime: uint64(pt.StartTime()), Timestamp: uint64(pt.Timestamp()), DoubleValue: pt.Value()}); ev != nil {
+				events = append(events, ev)
+			}
+		}
+	}
+	return events
+}
+
+// observe records the data point of the series and returns an annotation event if it reset the counter.
+func (d *counterResetDetector) observe(
+	meta resourceMetadata,
+	name string,
+	labels pdata.StringMap,
+	point cumulative.Point,
+) *splunk.Event {
+	fields := cloneMap(meta.fields)
+	populateLabels(fields, labels, meta.filter)
+	previous, ok := d.series.Record(seriesKey(name, fields), point)
+	if !ok {
+		return nil
+	}
+
+	var reason string
+	switch cumulative.DetectReset(previous, point) {
+	case cumulative.StartTimeChanged:
+		reason = resetReasonStartTimeChanged
+	case cumulative.ValueDecreased:
+		reason = resetReasonValueDecreased
+	default:
+		return nil
+	}
+
+	sourceType := d.sourceType
+	if sourceType == "" {
+		sourceType = meta.sourceType
+	}
+	meta.dimensions.apply(fields)
+	return &splunk.Event{
+		Time:       timestampToEpochSeconds(pdata.Timestamp(point.Timestamp), meta.timestampPrecision),
+		TimeNanos:  timestampNanos(pdata.Timestamp(point.Timestamp), meta.timestampPrecision),
+		Host:       meta.host,
+		Source:     meta.source,
+		SourceType: sourceType,
+		Index:      meta.index,
+		Event: map[string]interface{}{
+			"message":        "counter reset",
+			"metric_name":    name,
+			"reason":         reason,
+			"previous_value": pointValue(previous),
+			"value":          pointValue(point),
+		},
+		Fields: fields,
+	}
+}
+
+// pointValue returns the value of an integer or floating point counter as a float64.
+func pointValue(point cumulative.Point) float64 {
+	return float64(point.IntValue) + point.DoubleValue
+}
+
+// seriesKey identifies a series by its metric name and the fields of its events.
+func seriesKey(name string, fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		if v, ok := fields[k].(string); ok {
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
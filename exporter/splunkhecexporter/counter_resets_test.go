@@ -0,0 +1,88 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/cumulative"
+)
+
+func newCounter(startTime pdata.Timestamp, timestamp pdata.Timestamp, value int64) pdata.Metric {
+	metric := pdata.NewMetric()
+	metric.SetName("requests")
+	metric.SetDataType(pdata.MetricDataTypeIntSum)
+	sum := metric.IntSum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+	sum.DataPoints().Resize(1)
+	pt := sum.DataPoints().At(0)
+	pt.LabelsMap().Insert("path", "/")
+	pt.SetStartTime(startTime)
+	pt.SetTimestamp(timestamp)
+	pt.SetValue(value)
+	return metric
+}
+
+func TestCounterResetDetector(t *testing.T) {
+	assert.Nil(t, newCounterResetDetector(CounterResetsSettings{}).detect(resourceMetadata{}, newCounter(1, 2, 1)))
+
+	d := newCounterResetDetector(CounterResetsSettings{Enabled: true, SourceType: "otel:reset"})
+	config := createDefaultConfig().(*Config)
+	meta := newResourceMetadata(pdata.NewResource(), config, "", zap.NewNop())
+
+	assert.Empty(t, d.detect(meta, newCounter(10e9, 11e9, 5)))
+	assert.Empty(t, d.detect(meta, newCounter(10e9, 12e9, 8)))
+	// Out of order data points are not resets.
+	assert.Empty(t, d.detect(meta, newCounter(10e9, 11e9, 5)))
+
+	events := d.detect(meta, newCounter(10e9, 13e9, 2))
+	require.Len(t, events, 1)
+	assert.Equal(t, "otel:reset", events[0].SourceType)
+	assert.Equal(t, map[string]interface{}{"path": "/"}, events[0].Fields)
+	assert.Equal(t, map[string]interface{}{
+		"message":        "counter reset",
+		"metric_name":    "requests",
+		"reason":         resetReasonValueDecreased,
+		"previous_value": 8.0,
+		"value":          2.0,
+	}, events[0].Event)
+
+	events = d.detect(meta, newCounter(20e9, 21e9, 3))
+	require.Len(t, events, 1)
+	assert.Equal(t, resetReasonStartTimeChanged, events[0].Event.(map[string]interface{})["reason"])
+
+	gauge := pdata.NewMetric()
+	gauge.SetDataType(pdata.MetricDataTypeIntGauge)
+	assert.Empty(t, d.detect(meta, gauge))
+}
+
+func TestCounterResetDetectorForgetsIdleSeries(t *testing.T) {
+	d := newCounterResetDetector(CounterResetsSettings{Enabled: true})
+	now := time.Now()
+	d.series = cumulative.NewTracker(counterSeriesTTL, func() time.Time { return now })
+	meta := newResourceMetadata(pdata.NewResource(), createDefaultConfig().(*Config), "", zap.NewNop())
+
+	assert.Empty(t, d.detect(meta, newCounter(10e9, 11e9, 5)))
+	now = now.Add(2 * counterSeriesTTL)
+	assert.Empty(t, d.detect(meta, newCounter(10e9, 12e9, 2)))
+	assert.Equal(t, 1, d.series.Len())
+}
@@ -0,0 +1,102 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"go.opentelemetry.io/collector/consumer/pdata"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/cumulative"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// deltaConverter remembers the last data point of each series of cumulative monotonic sums, to send the difference
+// with the previous data point of the series instead of its cumulative value. Unlike the cumulativetodelta processor,
+// which shares its tracking of series, it identifies series by the name of their metric, their resource fields and the
+// labels kept by attributes_filter. Data points sent again by a retry get the same delta, while out of order data
+// points are dropped.
+type deltaConverter struct {
+	series *cumulative.Tracker
+}
+
+func newDeltaConverter(enabled bool) *deltaConverter {
+	if !enabled {
+		return nil
+	}
+	return &deltaConverter{series: cumulative.NewTracker(counterSeriesTTL, nil)}
+}
+
+// convert replaces the cumulative values of the events of a cumulative monotonic sum, holding one event per data
+// point, with deltas. The events of the first data point of each series are dropped, as there is nothing to subtract
+// from their value. Events of other metrics are returned unchanged.
+func (d *deltaConverter) convert(meta resourceMetadata, tm pdata.Metric, events []*splunk.Event) []*splunk.Event {
+	if d == nil {
+		return events
+	}
+	metricFieldName := splunkMetricValue + ":" + meta.namer.metricName(tm.Name())
+	converted := events[:0]
+	switch tm.DataType() {
+	case pdata.MetricDataTypeIntSum:
+		sum := tm.IntSum()
+		if !sum.IsMonotonic() || sum.AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return events
+		}
+		pts := sum.DataPoints()
+		for i := 0; i < pts.Len(); i++ {
+			pt := pts.At(i)
+			point := cumulative.Point{StartTime: uint64(pt.StartTime()), Timestamp: uint64(pt.Timestamp()), IntValue: pt.Value()}
+			delta, ok := d.delta(meta, tm.Name(), pt.LabelsMap(), point)
+			if !ok {
+				continue
+			}
+			events[i].Fields[metricFieldName] = delta.IntValue
+			converted = append(converted, events[i])
+		}
+	case pdata.MetricDataTypeDoubleSum:
+		sum := tm.DoubleSum()
+		if !sum.IsMonotonic() || sum.AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return events
+		}
+		pts := sum.DataPoints()
+		for i := 0; i < pts.Len(); i++ {
+			pt := pts.At(i)
+			point := cumulative.Point{StartTime: uint64(pt.StartTime()), Timestamp: uint64(pt.Timestamp()), DoubleValue: pt.Value()}
+			delta, ok := d.delta(meta, tm.Name(), pt.LabelsMap(), point)
+			if !ok {
+				continue
+			}
+			events[i].Fields[metricFieldName] = delta.DoubleValue
+			converted = append(converted, events[i])
+		}
+	default:
+		return events
+	}
+	return converted
+}
+
+// delta records the data point of the series and returns the difference with its previous value, or false if the
+// series was not seen before. When the counter restarted, the value itself is the delta.
+func (d *deltaConverter) delta(meta resourceMetadata, name string, labels pdata.StringMap, point cumulative.Point) (cumulative.Point, bool) {
+	fields := cloneMap(meta.fields)
+	populateLabels(fields, labels, meta.filter)
+	previous, ok := d.series.Record(seriesKey(name, fields), point)
+	switch {
+	case !ok:
+		return cumulative.Point{}, false
+	case cumulative.DetectReset(previous, point) != cumulative.NoReset:
+		return point, true
+	default:
+		return cumulative.Point{IntValue: point.IntValue - previous.IntValue, DoubleValue: point.DoubleValue - previous.DoubleValue}, true
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func TestDeltaConverter(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	meta := newResourceMetadata(pdata.NewResource(), config, "", zap.NewNop())
+	convert := func(d *deltaConverter, metric pdata.Metric) []interface{} {
+		events, supported := mapMetricToSplunkEvent(meta, metric, zap.NewNop())
+		require.True(t, supported)
+		var values []interface{}
+		for _, event := range d.convert(meta, metric, events) {
+			values = append(values, event.Fields["metric_name:requests"])
+		}
+		return values
+	}
+
+	assert.Equal(t, []interface{}{int64(5)}, convert(newDeltaConverter(false), newCounter(10e9, 11e9, 5)))
+
+	d := newDeltaConverter(true)
+	assert.Empty(t, convert(d, newCounter(10e9, 11e9, 5)))
+	assert.Equal(t, []interface{}{int64(3)}, convert(d, newCounter(10e9, 12e9, 8)))
+	// A retried data point gets the same delta.
+	assert.Equal(t, []interface{}{int64(3)}, convert(d, newCounter(10e9, 12e9, 8)))
+	// Out of order data points are dropped.
+	assert.Empty(t, convert(d, newCounter(10e9, 11e9, 5)))
+	assert.Equal(t, []interface{}{int64(0)}, convert(d, newCounter(10e9, 13e9, 8)))
+	// The counter restarted with a lower value.
+	assert.Equal(t, []interface{}{int64(2)}, convert(d, newCounter(10e9, 14e9, 2)))
+	// The counter restarted with a later start time.
+	assert.Equal(t, []interface{}{int64(4)}, convert(d, newCounter(20e9, 21e9, 4)))
+
+	// Gauges and delta sums are sent as is.
+	gauge := newCounter(10e9, 11e9, 7)
+	gauge.SetDataType(pdata.MetricDataTypeIntGauge)
+	gauge.IntGauge().DataPoints().Resize(1)
+	gauge.IntGauge().DataPoints().At(0).SetValue(7)
+	assert.Equal(t, []interface{}{int64(7)}, convert(d, gauge))
+	deltaSum := newCounter(10e9, 11e9, 7)
+	deltaSum.IntSum().SetAggregationTemporality(pdata.AggregationTemporalityDelta)
+	assert.Equal(t, []interface{}{int64(7)}, convert(d, deltaSum))
+}
+
+func TestDeltaConverterDoubleSum(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	meta := newResourceMetadata(pdata.NewResource(), config, "", zap.NewNop())
+	newDoubleCounter := func(value float64) pdata.Metric {
+		metric := pdata.NewMetric()
+		metric.SetName("bytes")
+		metric.SetDataType(pdata.MetricDataTypeDoubleSum)
+		sum := metric.DoubleSum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		sum.DataPoints().Resize(2)
+		for i := 0; i < 2; i++ {
+			pt := sum.DataPoints().At(i)
+			pt.LabelsMap().Insert("index", string(rune('a'+i)))
+			pt.SetStartTime(10e9)
+			pt.SetValue(value * float64(i+1))
+		}
+		return metric
+	}
+
+	d := newDeltaConverter(true)
+	events, _ := mapMetricToSplunkEvent(meta, newDoubleCounter(1.5), zap.NewNop())
+	assert.Empty(t, d.convert(meta, newDoubleCounter(1.5), events))
+	events, _ = mapMetricToSplunkEvent(meta, newDoubleCounter(2), zap.NewNop())
+	events = d.convert(meta, newDoubleCounter(2), events)
+	require.Len(t, events, 2)
+	assert.Equal(t, 0.5, events[0].Fields["metric_name:bytes"])
+	assert.Equal(t, "a", events[0].Fields["index"])
+	assert.Equal(t, 1.0, events[1].Fields["metric_name:bytes"])
+	assert.Equal(t, "b", events[1].Fields["index"])
+}
@@ -0,0 +1,128 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+const (
+	// dropReasonUnsupportedMetricType is the reason of metrics whose type cannot be translated.
+	dropReasonUnsupportedMetricType = "unsupported_metric_type"
+	// dropReasonSerializationFailed is the reason of records whose events cannot be serialized to JSON.
+	dropReasonSerializationFailed = "serialization_failed"
+	// dropReasonTooLarge is the reason of records whose events are larger than max_content_length.
+	dropReasonTooLarge = "too_large"
+	// dropReasonHTTPError is the reason of records of requests answered with an error status code, which are not
+	// retried.
+	dropReasonHTTPError = "http_error"
+	// dropReasonTransportError is the reason of records of requests that failed to be sent, which are not retried.
+	dropReasonTransportError = "transport_error"
+	// dropReasonNonFiniteValue is the reason of NaN and infinite values, with the "drop_and_count" action.
+	dropReasonNonFiniteValue = "non_finite_value"
+)
+
+// droppedRecords counts the records dropped for a reason, along with an example of them.
+type droppedRecords struct {
+	count   int
+	example string
+}
+
+// diagnostics sends log records describing the records dropped by the exporter to the logs exporter named by
+// diagnostics_exporter, so that translation problems are searchable like any other log.
+type diagnostics struct {
+	exporterName string
+	name         string
+	logger       *zap.Logger
+	exporter     component.LogsExporter
+}
+
+func newDiagnostics(config *Config, logger *zap.Logger) *diagnostics {
+	if config.DiagnosticsExporter == "" {
+		return nil
+	}
+	return &diagnostics{
+		exporterName: config.DiagnosticsExporter,
+		name:         config.Name(),
+		logger:       logger,
+	}
+}
+
+// start finds the diagnostics exporter among the exporters of the logs pipelines.
+func (d *diagnostics) start(host component.Host) error {
+	var available []string
+	for entity, exp := range host.GetExporters()[configmodels.LogsDataType] {
+		available = append(available, entity.Name())
+		if entity.Name() != d.exporterName {
+			continue
+		}
+		logsExp, ok := exp.(component.LogsExporter)
+		if !ok {
+			return fmt.Errorf("the exporter %q isn't a logs exporter", entity.Name())
+		}
+		d.exporter = logsExp
+		return nil
+	}
+	sort.Strings(available)
+	return fmt.Errorf("failed to find logs exporter %q; please configure diagnostics_exporter from one of: %v",
+		d.exporterName, available)
+}
+
+// report sends one log record per reason records of the signal were dropped for. Failures are only logged, so that
+// diagnostics never cause data to be retried.
+func (d *diagnostics) report(ctx context.Context, signal string, drops map[string]*droppedRecords) {
+	if d == nil || d.exporter == nil || len(drops) == 0 {
+		return
+	}
+	reasons := make([]string, 0, len(drops))
+	for reason := range drops {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	ld := pdata.NewLogs()
+	ld.ResourceLogs().Resize(1)
+	rl := ld.ResourceLogs().At(0)
+	rl.InstrumentationLibraryLogs().Resize(1)
+	logs := rl.InstrumentationLibraryLogs().At(0).Logs()
+	logs.Resize(len(reasons))
+	now := pdata.TimestampFromTime(time.Now())
+	for i, reason := range reasons {
+		lr := logs.At(i)
+		lr.SetTimestamp(now)
+		lr.SetSeverityNumber(pdata.SeverityNumberWARN)
+		lr.SetSeverityText("WARN")
+		lr.Body().SetStringVal(fmt.Sprintf("Splunk HEC exporter dropped %d %s records: %s", drops[reason].count, signal, reason))
+		attrs := lr.Attributes()
+		attrs.InsertString("exporter", d.name)
+		attrs.InsertString("signal", signal)
+		attrs.InsertString("reason", reason)
+		attrs.InsertInt("dropped_records", int64(drops[reason].count))
+		if drops[reason].example != "" {
+			attrs.InsertString("example", drops[reason].example)
+		}
+	}
+	if err := d.exporter.ConsumeLogs(ctx, ld); err != nil {
+		d.logger.Debug("Failed to send diagnostics", zap.Error(err))
+	}
+}
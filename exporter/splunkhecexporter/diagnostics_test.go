@@ -0,0 +1,114 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+type logsSinkExporter struct {
+	*consumertest.LogsSink
+}
+
+func (e *logsSinkExporter) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (e *logsSinkExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+type hostWithExporters struct {
+	component.Host
+	exporters map[configmodels.DataType]map[configmodels.NamedEntity]component.Exporter
+}
+
+func (h *hostWithExporters) GetExporters() map[configmodels.DataType]map[configmodels.NamedEntity]component.Exporter {
+	return h.exporters
+}
+
+func TestDiagnostics(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	host := &hostWithExporters{
+		Host: componenttest.NewNopHost(),
+		exporters: map[configmodels.DataType]map[configmodels.NamedEntity]component.Exporter{
+			configmodels.LogsDataType: {
+				&configmodels.ExporterSettings{TypeVal: "logging", NameVal: "logging"}: &logsSinkExporter{sink},
+			},
+		},
+	}
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "http://localhost:0/services/collector"
+	config.PayloadCapture = PayloadCaptureSettings{Enabled: true, DryRun: true}
+	config.DiagnosticsExporter = "unknown"
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	assert.EqualError(t, c.start(context.Background(), host),
+		`failed to find logs exporter "unknown"; please configure diagnostics_exporter from one of: [logging]`)
+
+	config.DiagnosticsExporter = "logging"
+	c = buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), host))
+
+	md := createMetricsData(1)
+	metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	metrics.Resize(metrics.Len() + 2)
+	metrics.At(metrics.Len() - 2).SetName("none_1")
+	metrics.At(metrics.Len() - 1).SetName("none_2")
+	require.NoError(t, c.pushMetricsData(context.Background(), md))
+	require.NoError(t, c.stop(context.Background()))
+
+	require.Len(t, sink.AllLogs(), 1)
+	logs := sink.AllLogs()[0].ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	require.Equal(t, 1, logs.Len())
+	lr := logs.At(0)
+	assert.Equal(t, pdata.SeverityNumberWARN, lr.SeverityNumber())
+	assert.Equal(t, "Splunk HEC exporter dropped 2 metrics records: unsupported_metric_type", lr.Body().StringVal())
+	assert.Equal(t, pdata.NewAttributeMap().InitFromMap(map[string]pdata.AttributeValue{
+		"exporter":        pdata.NewAttributeValueString("splunk_hec"),
+		"signal":          pdata.NewAttributeValueString("metrics"),
+		"reason":          pdata.NewAttributeValueString(dropReasonUnsupportedMetricType),
+		"dropped_records": pdata.NewAttributeValueInt(2),
+		"example":         pdata.NewAttributeValueString("none_1"),
+	}).Sort(), lr.Attributes().Sort())
+
+	// Nothing is reported when no record is dropped.
+	c = buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), host))
+	require.NoError(t, c.pushMetricsData(context.Background(), createMetricsData(1)))
+	require.NoError(t, c.stop(context.Background()))
+	assert.Len(t, sink.AllLogs(), 1)
+}
+
+func TestConfig_diagnosticsExporterItself(t *testing.T) {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "https://example.com:8088"
+	config.DiagnosticsExporter = "splunk_hec"
+	assert.EqualError(t, config.validateConfig(), `"diagnostics_exporter" cannot be the exporter itself`)
+}
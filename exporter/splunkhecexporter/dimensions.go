@@ -0,0 +1,85 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// dimensionMapper drops, renames and adds the dimensions of metric events. A nil mapper keeps the dimensions
+// unchanged.
+type dimensionMapper struct {
+	rename map[string]string
+	drop   map[string]bool
+	add    map[string]string
+}
+
+func newDimensionMapper(settings DimensionsSettings) (*dimensionMapper, error) {
+	if len(settings.Rename) == 0 && len(settings.Drop) == 0 && len(settings.Add) == 0 {
+		return nil, nil
+	}
+	m := &dimensionMapper{
+		rename: settings.Rename,
+		drop:   make(map[string]bool, len(settings.Drop)),
+		add:    settings.Add,
+	}
+	for name, newName := range settings.Rename {
+		if name == "" || newName == "" {
+			return nil, fmt.Errorf("cannot rename %q to %q: dimension names cannot be empty", name, newName)
+		}
+	}
+	for _, name := range settings.Drop {
+		if name == "" {
+			return nil, errors.New("cannot drop an empty dimension name")
+		}
+		m.drop[name] = true
+	}
+	for name := range settings.Add {
+		if name == "" {
+			return nil, errors.New("cannot add an empty dimension name")
+		}
+	}
+	return m, nil
+}
+
+// apply maps the dimensions of the fields of an event. Renames are applied at once, so that dimensions can be
+// swapped.
+func (m *dimensionMapper) apply(fields map[string]interface{}) {
+	if m == nil {
+		return
+	}
+	for name := range m.drop {
+		delete(fields, name)
+	}
+	var renamed map[string]interface{}
+	for name, newName := range m.rename {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if renamed == nil {
+			renamed = map[string]interface{}{}
+		}
+		renamed[newName] = value
+		delete(fields, name)
+	}
+	for name, value := range renamed {
+		fields[name] = value
+	}
+	for name, value := range m.add {
+		fields[name] = value
+	}
+}
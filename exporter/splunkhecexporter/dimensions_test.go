@@ -0,0 +1,95 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func TestDimensionMapper(t *testing.T) {
+	m, err := newDimensionMapper(DimensionsSettings{})
+	require.NoError(t, err)
+	assert.Nil(t, m)
+	fields := map[string]interface{}{"k0": "v0"}
+	m.apply(fields)
+	assert.Equal(t, map[string]interface{}{"k0": "v0"}, fields)
+
+	_, err = newDimensionMapper(DimensionsSettings{Rename: map[string]string{"k0": ""}})
+	assert.EqualError(t, err, `cannot rename "k0" to "": dimension names cannot be empty`)
+	_, err = newDimensionMapper(DimensionsSettings{Drop: []string{""}})
+	assert.EqualError(t, err, "cannot drop an empty dimension name")
+	_, err = newDimensionMapper(DimensionsSettings{Add: map[string]string{"": "v"}})
+	assert.EqualError(t, err, "cannot add an empty dimension name")
+
+	m, err = newDimensionMapper(DimensionsSettings{
+		Rename: map[string]string{"k8s.pod.name": "kubernetes_pod_name", "a": "b", "b": "a"},
+		Drop:   []string{"host.id"},
+		Add:    map[string]string{"env": "prod"},
+	})
+	require.NoError(t, err)
+	fields = map[string]interface{}{
+		"k8s.pod.name":         "pod0",
+		"a":                    "va",
+		"b":                    "vb",
+		"host.id":              "id0",
+		"env":                  "dev",
+		"metric_name:cpu.time": 1.0,
+	}
+	m.apply(fields)
+	assert.Equal(t, map[string]interface{}{
+		"kubernetes_pod_name":  "pod0",
+		"a":                    "vb",
+		"b":                    "va",
+		"env":                  "prod",
+		"metric_name:cpu.time": 1.0,
+	}, fields)
+}
+
+func TestMetricDataToSplunk_dimensions(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	mapper, err := newDimensionMapper(DimensionsSettings{
+		Rename: map[string]string{"k8s.node.name": "kubernetes_node"},
+		Drop:   []string{"k1"},
+		Add:    map[string]string{"env": "prod"},
+	})
+	require.NoError(t, err)
+	config.dimensionMapper = mapper
+
+	resource := pdata.NewResource()
+	resource.Attributes().InsertString("k8s.node.name", "node0")
+	tm := pdata.NewMetric()
+	tm.SetName("gauge")
+	tm.SetDataType(pdata.MetricDataTypeDoubleGauge)
+	tm.DoubleGauge().DataPoints().Resize(1)
+	dp := tm.DoubleGauge().DataPoints().At(0)
+	dp.LabelsMap().Insert("k0", "v0")
+	dp.LabelsMap().Insert("k1", "v1")
+	dp.SetValue(1)
+
+	events, ok := mapMetricToSplunkEvent(newResourceMetadata(resource, config, "", zap.NewNop()), tm, zap.NewNop())
+	require.True(t, ok)
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]interface{}{
+		"kubernetes_node":   "node0",
+		"k0":                "v0",
+		"env":               "prod",
+		"metric_name:gauge": 1.0,
+	}, events[0].Fields)
+}
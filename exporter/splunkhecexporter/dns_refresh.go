@@ -0,0 +1,173 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rotatingTransport sends requests with a transport that can be replaced by a new one, so that the connections of the
+// former one stop being reused.
+type rotatingTransport struct {
+	newTransport func() *http.Transport
+
+	mu      sync.RWMutex
+	current *http.Transport
+}
+
+func newRotatingTransport(newTransport func() *http.Transport) *rotatingTransport {
+	return &rotatingTransport{newTransport: newTransport, current: newTransport()}
+}
+
+func (t *rotatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport().RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the current transport, as called by http.Client.
+func (t *rotatingTransport) CloseIdleConnections() {
+	t.transport().CloseIdleConnections()
+}
+
+func (t *rotatingTransport) transport() *http.Transport {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.current
+}
+
+// rotate replaces the transport, and closes the idle connections of the former one. Its connections still in use are
+// closed once idle for idleConnTimeout, as no request is sent with it anymore.
+func (t *rotatingTransport) rotate() {
+	t.mu.Lock()
+	former := t.current
+	t.current = t.newTransport()
+	t.mu.Unlock()
+	former.CloseIdleConnections()
+}
+
+// dnsRefresher re-resolves the host of the endpoint at an interval, and rotates the connections to it once its
+// addresses changed, so that requests are balanced over the new addresses, e.g. of a load balancer, rather than kept
+// on connections to former ones. A nil dnsRefresher refreshes nothing.
+type dnsRefresher struct {
+	host      string
+	interval  time.Duration
+	transport *rotatingTransport
+	logger    *zap.Logger
+	lookup    func(ctx context.Context, host string) ([]string, error)
+
+	// addrs are the sorted addresses the host last resolved to.
+	addrs  []string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newDNSRefresher returns the refresher of the host of the endpoint, nil if refreshing is disabled or requests are
+// sent to a unix domain socket.
+func newDNSRefresher(config *Config, options *exporterOptions, transport *rotatingTransport, logger *zap.Logger) *dnsRefresher {
+	if config.DNSRefreshInterval <= 0 || options.socketPath != "" {
+		return nil
+	}
+	return &dnsRefresher{
+		host:      options.url.Hostname(),
+		interval:  config.DNSRefreshInterval,
+		transport: transport,
+		logger:    logger,
+		lookup:    net.DefaultResolver.LookupHost,
+	}
+}
+
+// start resolves and refreshes the host in the background. Failures are only logged, as requests resolve it on
+// their own.
+func (r *dnsRefresher) start() {
+	if r == nil {
+		return
+	}
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.done = make(chan struct{})
+	go r.run(ctx)
+}
+
+func (r *dnsRefresher) stop() {
+	if r == nil || r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+	r.cancel = nil
+}
+
+func (r *dnsRefresher) run(ctx context.Context) {
+	defer close(r.done)
+	r.addrs, _ = r.resolve(ctx)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+// refresh resolves the host, and rotates the connections if its addresses changed. Failures keep the connections, as
+// the addresses are likely still valid.
+func (r *dnsRefresher) refresh(ctx context.Context) {
+	addrs, err := r.resolve(ctx)
+	if err != nil || equalAddrs(addrs, r.addrs) {
+		return
+	}
+	if r.addrs == nil {
+		// The host could not be resolved before, so there are no connections to former addresses.
+		r.addrs = addrs
+		return
+	}
+	r.logger.Debug("The addresses of the Splunk HEC endpoint changed, rotating connections",
+		zap.String("host", r.host), zap.Strings("addresses", addrs), zap.Strings("previous_addresses", r.addrs))
+	r.addrs = addrs
+	r.transport.rotate()
+}
+
+func (r *dnsRefresher) resolve(ctx context.Context) ([]string, error) {
+	addrs, err := r.lookup(ctx, r.host)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Warn("Failed to resolve the Splunk HEC endpoint", zap.String("host", r.host), zap.Error(err))
+		}
+		return nil, err
+	}
+	sort.Strings(addrs)
+	return addrs, nil
+}
+
+func equalAddrs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+)
+
+// fakeResolver resolves hosts to the addresses it is set to, or fails if there are none.
+type fakeResolver struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (f *fakeResolver) set(addrs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addrs = addrs
+}
+
+func (f *fakeResolver) lookup(context.Context, string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.addrs) == 0 {
+		return nil, errors.New("no such host")
+	}
+	return append([]string(nil), f.addrs...), nil
+}
+
+func TestDNSRefresh(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DNSRefreshInterval = time.Hour
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	require.NotNil(t, c.dns)
+	resolver := &fakeResolver{}
+	c.dns.lookup = resolver.lookup
+	ctx := context.Background()
+
+	send := func() {
+		require.NoError(t, c.pushLogData(ctx, createLogData(1)))
+	}
+	send()
+	send()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections), "the connection is kept alive")
+
+	resolver.set("10.0.0.1", "10.0.0.2")
+	c.dns.refresh(ctx)
+	send()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections), "the first addresses are only recorded")
+
+	resolver.set("10.0.0.2", "10.0.0.1")
+	c.dns.refresh(ctx)
+	send()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections), "the order of the addresses does not matter")
+
+	resolver.set()
+	c.dns.refresh(ctx)
+	send()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections), "resolution failures keep the connections")
+
+	resolver.set("10.0.0.3")
+	c.dns.refresh(ctx)
+	send()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&connections), "changed addresses rotate the connections")
+	send()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&connections))
+}
+
+func TestDNSRefreshStartStop(t *testing.T) {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "https://splunk.example.com:8088"
+	config.DNSRefreshInterval = 10 * time.Millisecond
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	resolver := &fakeResolver{}
+	resolver.set("10.0.0.1")
+	c.dns.lookup = resolver.lookup
+	transport := c.client.Transport.(*rotatingTransport)
+	initial := transport.transport()
+
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, func() bool {
+		resolver.set("10.0.0.2")
+		return transport.transport() != initial
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, c.stop(context.Background()))
+}
+
+func TestNewDNSRefresherDisabled(t *testing.T) {
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = "https://splunk.example.com:8088"
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	assert.Nil(t, c.dns)
+	assert.IsType(t, &http.Transport{}, c.client.Transport)
+	c.dns.start()
+	c.dns.stop()
+
+	config.DNSRefreshInterval = time.Minute
+	options.socketPath = "/var/run/splunk.sock"
+	assert.Nil(t, newDNSRefresher(config, options, nil, zap.NewNop()))
+}
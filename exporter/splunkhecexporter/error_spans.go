@@ -0,0 +1,61 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// spanFilter decides which spans of a batch are sent to Splunk. A nil filter keeps all spans.
+type spanFilter struct {
+	// errorTraces holds the traces of the batch with an error span, when their root spans are kept.
+	errorTraces map[[16]byte]struct{}
+}
+
+func newSpanFilter(td pdata.Traces, settings ErrorSpansOnlySettings) *spanFilter {
+	if !settings.Enabled {
+		return nil
+	}
+	f := &spanFilter{}
+	if settings.IncludeRootSpans {
+		f.errorTraces = map[[16]byte]struct{}{}
+		rss := td.ResourceSpans()
+		for i := 0; i < rss.Len(); i++ {
+			ilss := rss.At(i).InstrumentationLibrarySpans()
+			for j := 0; j < ilss.Len(); j++ {
+				spans := ilss.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					if span := spans.At(k); span.Status().Code() == pdata.StatusCodeError {
+						f.errorTraces[span.TraceID().Bytes()] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	return f
+}
+
+// keep returns whether the span is sent: spans with an error status, and the root spans of their traces when
+// included.
+func (f *spanFilter) keep(span pdata.Span) bool {
+	if f == nil || span.Status().Code() == pdata.StatusCodeError {
+		return true
+	}
+	if f.errorTraces == nil || !span.ParentSpanID().IsEmpty() {
+		return false
+	}
+	_, ok := f.errorTraces[span.TraceID().Bytes()]
+	return ok
+}
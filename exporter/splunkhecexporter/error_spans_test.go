@@ -0,0 +1,79 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func TestErrorSpansOnly(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	addSpan := func(name string, trace byte, parent byte, code pdata.StatusCode) {
+		span := pdata.NewSpan()
+		span.SetName(name)
+		span.SetTraceID(pdata.NewTraceID([16]byte{trace}))
+		span.SetSpanID(pdata.NewSpanID([8]byte{byte(spans.Len() + 1)}))
+		if parent != 0 {
+			span.SetParentSpanID(pdata.NewSpanID([8]byte{parent}))
+		}
+		span.Status().SetCode(code)
+		spans.Append(span)
+	}
+	addSpan("failed root", 1, 0, pdata.StatusCodeUnset)
+	addSpan("failed child", 1, 1, pdata.StatusCodeError)
+	addSpan("healthy root", 2, 0, pdata.StatusCodeOk)
+	addSpan("healthy child", 2, 3, pdata.StatusCodeUnset)
+
+	tests := []struct {
+		name     string
+		settings ErrorSpansOnlySettings
+		want     []string
+	}{
+		{
+			name: "disabled",
+			want: []string{"failed root", "failed child", "healthy root", "healthy child"},
+		},
+		{
+			name:     "error spans",
+			settings: ErrorSpansOnlySettings{Enabled: true},
+			want:     []string{"failed child"},
+		},
+		{
+			name:     "error spans and their root spans",
+			settings: ErrorSpansOnlySettings{Enabled: true, IncludeRootSpans: true},
+			want:     []string{"failed root", "failed child"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createDefaultConfig().(*Config)
+			config.ErrorSpansOnly = tt.settings
+			events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+			var names []string
+			for _, event := range events {
+				names = append(names, event.Event.(HecSpan).Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// The events are serialized without reflection, as encoding/json dominates the CPU usage of the exporter at high
+// throughput. The serialization is identical to the one of encoding/json, with HTML characters escaped and map keys
+// sorted, which deterministic_output relies on. Values of types other than those of converted attributes, e.g. the
+// spans of trace events, are serialized with encoding/json.
+
+// encodingBuffers holds the buffers events are serialized to before being written to requests.
+var encodingBuffers = sync.Pool{New: func() interface{} { return new([]byte) }}
+
+// keysPool holds the slices map keys are sorted in.
+var keysPool = sync.Pool{New: func() interface{} { return new([]string) }}
+
+// appendEvent appends the JSON serialization of the event to b.
+func appendEvent(b []byte, e *splunk.Event) ([]byte, error) {
+	if e == nil {
+		return append(b, "null"...), nil
+	}
+	var err error
+	b = append(b, '{')
+	switch {
+	case e.TimeNanos != 0:
+		b = append(b, `"time":`...)
+		b = appendEpochNanos(b, e.TimeNanos)
+		b = append(b, ',')
+	case e.Time != nil:
+		b = append(b, `"time":`...)
+		if b, err = appendFloat(b, *e.Time, 64); err != nil {
+			return b, err
+		}
+		b = append(b, ',')
+	}
+	b = append(b, `"host":`...)
+	b = appendString(b, e.Host)
+	if e.Source != "" {
+		b = append(b, `,"source":`...)
+		b = appendString(b, e.Source)
+	}
+	if e.SourceType != "" {
+		b = append(b, `,"sourcetype":`...)
+		b = appendString(b, e.SourceType)
+	}
+	if e.Index != "" {
+		b = append(b, `,"index":`...)
+		b = appendString(b, e.Index)
+	}
+	b = append(b, `,"event":`...)
+	if b, err = appendValue(b, e.Event); err != nil {
+		return b, err
+	}
+	if len(e.Fields) > 0 {
+		b = append(b, `,"fields":`...)
+		if b, err = appendMap(b, e.Fields); err != nil {
+			return b, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// appendEpochNanos appends the nanoseconds since epoch to b as a JSON number of seconds with 9 decimals.
+func appendEpochNanos(b []byte, nanos uint64) []byte {
+	b = strconv.AppendUint(b, nanos/1e9, 10)
+	b = append(b, '.')
+	fraction := nanos % 1e9
+	for divisor := uint64(1e8); divisor > fraction && divisor > 1; divisor /= 10 {
+		b = append(b, '0')
+	}
+	return strconv.AppendUint(b, fraction, 10)
+}
+
+// appendValue appends the JSON serialization of the value to b.
+func appendValue(b []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case string:
+		return appendString(b, v), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case float64:
+		return appendFloat(b, v, 64)
+	case float32:
+		return appendFloat(b, float64(v), 32)
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(b, v, 10), nil
+	case map[string]interface{}:
+		if v == nil {
+			return append(b, "null"...), nil
+		}
+		return appendMap(b, v)
+	case []interface{}:
+		if v == nil {
+			return append(b, "null"...), nil
+		}
+		var err error
+		b = append(b, '[')
+		for i, item := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = appendValue(b, item); err != nil {
+				return b, err
+			}
+		}
+		return append(b, ']'), nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return b, err
+	}
+	return append(b, encoded...), nil
+}
+
+// appendMap appends the JSON serialization of the map, with sorted keys, to b.
+func appendMap(b []byte, m map[string]interface{}) ([]byte, error) {
+	keysPtr := keysPool.Get().(*[]string)
+	defer keysPool.Put(keysPtr)
+	keys := (*keysPtr)[:0]
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	*keysPtr = keys
+
+	var err error
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, k)
+		b = append(b, ':')
+		if b, err = appendValue(b, m[k]); err != nil {
+			return b, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// appendFloat appends the number like encoding/json does: without exponent unless it is very small or large, and
+// failing for NaN and infinite values.
+func appendFloat(b []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return b, &json.UnsupportedValueError{Str: strconv.FormatFloat(f, 'g', -1, bits)}
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends the quoted string like encoding/json does, escaping HTML characters and replacing invalid
+// UTF-8 with the replacement character.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '\\', '"':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript, and are escaped by encoding/json.
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
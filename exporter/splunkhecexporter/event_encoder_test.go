@@ -0,0 +1,171 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestAppendEventMatchesEncodingJSON(t *testing.T) {
+	ts := 1433188255.5
+	tiny := 1e-7
+	tests := []struct {
+		name  string
+		event *splunk.Event
+	}{
+		{name: "nil"},
+		{name: "empty", event: &splunk.Event{}},
+		{
+			name: "metric",
+			event: &splunk.Event{
+				Time:       &ts,
+				Host:       "myhost",
+				Source:     "mysource",
+				SourceType: "mysourcetype",
+				Index:      "myindex",
+				Event:      splunk.HecEventMetricType,
+				Fields: map[string]interface{}{
+					"metric_name:cpu": 0.25,
+					"metric_name:big": 1e21,
+					"metric_name:neg": -3.0,
+					"metric_name:int": int64(-42),
+					"metric_name:f32": float32(0.1),
+					"k8s.pod.name":    "pod-1",
+				},
+			},
+		},
+		{
+			name: "log",
+			event: &splunk.Event{
+				Time: &tiny,
+				Host: "host <&> \"quoted\"",
+				Event: map[string]interface{}{
+					"message": "line1\nline2\t\r\\ \x01    é 日本 \xff",
+					"nested":  map[string]interface{}{"z": true, "a": nil, "m": []interface{}{int64(1), "two", 3.5, nil}},
+					"empty":   map[string]interface{}{},
+					"none":    []interface{}(nil),
+					"uint":    uint64(math.MaxUint64),
+					"int":     7,
+					"int32":   int32(-7),
+				},
+				Fields: map[string]interface{}{"</script>": "&amp;", "b": false},
+			},
+		},
+		{
+			name: "span",
+			event: &splunk.Event{
+				Event:  HecSpan{TraceID: "0102", SpanID: "03", Name: "span <1>", Attributes: map[string]interface{}{"k": "v"}},
+				Fields: map[string]interface{}{},
+			},
+		},
+		{
+			name:  "typed_map",
+			event: &splunk.Event{Event: map[string]string{"b": "2", "a": "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := json.Marshal(tt.event)
+			require.NoError(t, err)
+			actual, err := appendEvent(nil, tt.event)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestAppendFloatMatchesEncodingJSON(t *testing.T) {
+	for _, f := range []float64{0, -0.0, 1, -1, 0.1, 1e-6, 9.99e-7, 1e-9, 123456789.123, 1e20, 1e21, 1.5e300, -2.5e-300, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+		expected, err := json.Marshal(f)
+		require.NoError(t, err)
+		actual, err := appendFloat(nil, f, 64)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), strconv.FormatFloat(f, 'g', -1, 64))
+
+		f32 := float32(f)
+		if math.IsInf(float64(f32), 0) {
+			continue
+		}
+		expected, err = json.Marshal(f32)
+		require.NoError(t, err)
+		actual, err = appendFloat(nil, float64(f32), 32)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), strconv.FormatFloat(float64(f32), 'g', -1, 32))
+	}
+}
+
+func TestAppendEventUnsupportedValues(t *testing.T) {
+	for _, value := range []interface{}{math.NaN(), math.Inf(1), float32(math.Inf(-1)), map[string]interface{}{"k": math.NaN()}, []interface{}{math.Inf(1)}} {
+		_, err := appendEvent(nil, &splunk.Event{Fields: map[string]interface{}{"v": value}})
+		assert.Error(t, err)
+	}
+	ts := math.NaN()
+	_, err := appendEvent(nil, &splunk.Event{Time: &ts})
+	assert.Error(t, err)
+}
+
+func BenchmarkEncodeEvents(b *testing.B) {
+	ts := 1433188255.5
+	events := make([]*splunk.Event, 100)
+	for i := range events {
+		events[i] = &splunk.Event{
+			Time:       &ts,
+			Host:       "myhost",
+			Source:     "mysource",
+			SourceType: "mysourcetype",
+			Index:      "myindex",
+			Event:      "a log line of a reasonable length, with a \"quoted\" word",
+			Fields: map[string]interface{}{
+				"k8s.pod.name":       "pod-" + strconv.Itoa(i),
+				"k8s.namespace.name": "default",
+				"service.name":       "myapp",
+				"http.status_code":   int64(200),
+				"duration":           0.125,
+			},
+		}
+	}
+	b.Run("encoding_json", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := new(bytes.Buffer)
+		for i := 0; i < b.N; i++ {
+			buf.Reset()
+			encoder := json.NewEncoder(buf)
+			for _, e := range events {
+				if err := encoder.Encode(e); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("encodeEventsTo", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := new(bytes.Buffer)
+		for i := 0; i < b.N; i++ {
+			buf.Reset()
+			if err := encodeEventsTo(buf, events, "\n\r\n\r\n"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
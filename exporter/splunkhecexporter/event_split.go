@@ -0,0 +1,122 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	// correlationIDField is the field linking an event split because of max_event_fields to its extension events.
+	correlationIDField = "event_correlation_id"
+	// extensionEventBody is the body of the events holding the excess fields of another event.
+	extensionEventBody = "fields extension"
+)
+
+// splitEvents returns the events, replacing those with more than maxFields fields with the event itself, holding the
+// metric values and as many other fields as fit, and extension events holding the remaining fields. maxFields lower
+// than 2 disables splitting. Deterministic splits link the events with an id derived from the event rather than a random
+// one.
+func splitEvents(events []*splunk.Event, maxFields int, deterministic bool) []*splunk.Event {
+	if maxFields < 2 {
+		return events
+	}
+	var split []*splunk.Event
+	for i, event := range events {
+		if len(event.Fields) <= maxFields {
+			if split != nil {
+				split = append(split, event)
+			}
+			continue
+		}
+		if split == nil {
+			split = append(make([]*splunk.Event, 0, len(events)+1), events[:i]...)
+		}
+		split = append(split, splitEvent(event, maxFields, deterministic)...)
+	}
+	if split == nil {
+		return events
+	}
+	return split
+}
+
+// splitEvent splits an event with more than maxFields fields, distributing its fields in name order.
+func splitEvent(event *splunk.Event, maxFields int, deterministic bool) []*splunk.Event {
+	var id string
+	if deterministic {
+		id = eventCorrelationID(event)
+	} else {
+		id = newCorrelationID()
+	}
+	var metricNames, names []string
+	for name := range event.Fields {
+		if strings.HasPrefix(name, splunkMetricValue+":") {
+			metricNames = append(metricNames, name)
+		} else {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(metricNames)
+	sort.Strings(names)
+	// The metric values always stay in the event, even if they alone exceed the limit.
+	names = append(metricNames, names...)
+
+	first := *event
+	first.Fields = map[string]interface{}{correlationIDField: id}
+	split := []*splunk.Event{&first}
+	current := &first
+	for _, name := range names {
+		if len(current.Fields) == maxFields && !strings.HasPrefix(name, splunkMetricValue+":") {
+			current = &splunk.Event{
+				Time:       event.Time,
+				TimeNanos:  event.TimeNanos,
+				Host:       event.Host,
+				Source:     event.Source,
+				SourceType: event.SourceType,
+				Index:      event.Index,
+				Event:      extensionEventBody,
+				Fields:     map[string]interface{}{correlationIDField: id},
+			}
+			split = append(split, current)
+		}
+		current.Fields[name] = event.Fields[name]
+	}
+	return split
+}
+
+// newCorrelationID returns a random id, linking split events or identifying requests.
+func newCorrelationID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// eventCorrelationID returns an id linking split events derived from the content of the event, so that the same event
+// is always split the same way. It falls back to a random id if the event cannot be serialized.
+func eventCorrelationID(event *splunk.Event) string {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return newCorrelationID()
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,93 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestSplitEvents(t *testing.T) {
+	ts := 1433188255.5
+	small := &splunk.Event{Time: &ts, Event: "small", Fields: map[string]interface{}{"k0": "v0"}}
+	large := &splunk.Event{
+		Time:       &ts,
+		Host:       "myhost",
+		Source:     "mysource",
+		SourceType: "mysourcetype",
+		Index:      "myindex",
+		Event:      splunk.HecEventMetricType,
+		Fields: map[string]interface{}{
+			"k0":              "v0",
+			"k1":              "v1",
+			"k2":              "v2",
+			"k3":              "v3",
+			"metric_name:cpu": 1.0,
+		},
+	}
+	events := []*splunk.Event{small, large}
+
+	assert.Equal(t, events, splitEvents(events, 0, false))
+	assert.Equal(t, events, splitEvents(events, 5, false))
+
+	split := splitEvents(events, 3, false)
+	require.Len(t, split, 4)
+	assert.Same(t, small, split[0])
+
+	id := split[1].Fields[correlationIDField]
+	assert.Len(t, id, 32)
+	assert.Equal(t, splunk.HecEventMetricType, split[1].Event)
+	assert.Equal(t, map[string]interface{}{correlationIDField: id, "metric_name:cpu": 1.0, "k0": "v0"}, split[1].Fields)
+	for _, extension := range split[2:] {
+		assert.Equal(t, extensionEventBody, extension.Event)
+		assert.Equal(t, &ts, extension.Time)
+		assert.Equal(t, "myhost", extension.Host)
+		assert.Equal(t, "mysource", extension.Source)
+		assert.Equal(t, "mysourcetype", extension.SourceType)
+		assert.Equal(t, "myindex", extension.Index)
+	}
+	assert.Equal(t, map[string]interface{}{correlationIDField: id, "k1": "v1", "k2": "v2"}, split[2].Fields)
+	assert.Equal(t, map[string]interface{}{correlationIDField: id, "k3": "v3"}, split[3].Fields)
+
+	// The original event is left unchanged.
+	assert.Len(t, large.Fields, 5)
+}
+
+func TestSplitEventsDeterministic(t *testing.T) {
+	newEvent := func(value string) *splunk.Event {
+		return &splunk.Event{
+			Event:  "log",
+			Fields: map[string]interface{}{"k0": value, "k1": "v1", "k2": "v2", "k3": "v3"},
+		}
+	}
+
+	first := splitEvents([]*splunk.Event{newEvent("v0")}, 3, true)
+	second := splitEvents([]*splunk.Event{newEvent("v0")}, 3, true)
+	require.Len(t, first, 2)
+	assert.Equal(t, first, second, "the same event is split the same way")
+	id := first[0].Fields[correlationIDField]
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, first[1].Fields[correlationIDField])
+
+	other := splitEvents([]*splunk.Event{newEvent("other")}, 3, true)
+	assert.NotEqual(t, id, other[0].Fields[correlationIDField], "other events get other ids")
+
+	random := splitEvents([]*splunk.Event{newEvent("v0")}, 3, false)
+	assert.NotEqual(t, id, random[0].Fields[correlationIDField])
+}
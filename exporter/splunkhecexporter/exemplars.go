@@ -0,0 +1,88 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"sort"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+const (
+	// exemplarTraceIDLabel is the filtered label holding the trace ID of an exemplar.
+	exemplarTraceIDLabel = "trace_id"
+	// exemplarSpanIDLabel is the filtered label holding the span ID of an exemplar.
+	exemplarSpanIDLabel = "span_id"
+)
+
+// exemplar is an int or double exemplar of a histogram data point.
+type exemplar struct {
+	timestamp pdata.Timestamp
+	value     float64
+	labels    pdata.StringMap
+}
+
+func intExemplars(exemplars pdata.IntExemplarSlice) []exemplar {
+	out := make([]exemplar, exemplars.Len())
+	for i := 0; i < exemplars.Len(); i++ {
+		e := exemplars.At(i)
+		out[i] = exemplar{timestamp: e.Timestamp(), value: float64(e.Value()), labels: e.FilteredLabels()}
+	}
+	return out
+}
+
+func doubleExemplars(exemplars pdata.DoubleExemplarSlice) []exemplar {
+	out := make([]exemplar, exemplars.Len())
+	for i := 0; i < exemplars.Len(); i++ {
+		e := exemplars.At(i)
+		out[i] = exemplar{timestamp: e.Timestamp(), value: e.Value(), labels: e.FilteredLabels()}
+	}
+	return out
+}
+
+// bucketExemplars returns the latest exemplar of each bucket of a histogram, the last one being the +Inf bucket,
+// or nil if exemplars are not sent.
+func (m resourceMetadata) bucketExemplars(bounds []float64, exemplars []exemplar) []*exemplar {
+	if !m.exemplars.Enabled || len(exemplars) == 0 {
+		return nil
+	}
+	buckets := make([]*exemplar, len(bounds)+1)
+	for i := range exemplars {
+		e := &exemplars[i]
+		// Buckets hold the values lower than or equal to their upper bound.
+		bucket := sort.SearchFloat64s(bounds, e.value)
+		if latest := buckets[bucket]; latest == nil || e.timestamp > latest.timestamp {
+			buckets[bucket] = e
+		}
+	}
+	return buckets
+}
+
+// addExemplar adds the fields of the exemplar of a bucket, if any.
+func (m resourceMetadata) addExemplar(fields map[string]interface{}, exemplars []*exemplar, bucket int) {
+	if bucket >= len(exemplars) || exemplars[bucket] == nil {
+		return
+	}
+	e := exemplars[bucket]
+	if m.exemplars.ValueField != "" {
+		fields[m.exemplars.ValueField] = e.value
+	}
+	if traceID, ok := e.labels.Get(exemplarTraceIDLabel); ok && m.exemplars.TraceIDField != "" {
+		fields[m.exemplars.TraceIDField] = traceID
+	}
+	if spanID, ok := e.labels.Get(exemplarSpanIDLabel); ok && m.exemplars.SpanIDField != "" {
+		fields[m.exemplars.SpanIDField] = spanID
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func newHistogramWithExemplars() pdata.Metric {
+	metric := pdata.NewMetric()
+	metric.SetName("latency")
+	metric.SetDataType(pdata.MetricDataTypeDoubleHistogram)
+	metric.DoubleHistogram().DataPoints().Resize(1)
+	pt := metric.DoubleHistogram().DataPoints().At(0)
+	pt.SetExplicitBounds([]float64{1, 2})
+	pt.SetBucketCounts([]uint64{1, 2, 1})
+	pt.SetCount(4)
+	pt.SetSum(7)
+	pt.Exemplars().Resize(3)
+	for i, e := range []struct {
+		timestamp pdata.Timestamp
+		value     float64
+		traceID   string
+	}{
+		{1, 1.5, "older"},
+		{2, 2, "latest"},
+		{1, 3, "overflow"},
+	} {
+		exemplar := pt.Exemplars().At(i)
+		exemplar.SetTimestamp(e.timestamp)
+		exemplar.SetValue(e.value)
+		exemplar.FilteredLabels().Insert("trace_id", e.traceID)
+		exemplar.FilteredLabels().Insert("span_id", e.traceID+"-span")
+	}
+	return metric
+}
+
+func TestHistogramExemplars(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	events, supported := mapMetricToSplunkEvent(newResourceMetadata(pdata.NewResource(), config, "", zap.NewNop()), newHistogramWithExemplars(), zap.NewNop())
+	require.True(t, supported)
+	for _, event := range events {
+		assert.NotContains(t, event.Fields, "trace_id")
+	}
+
+	config.Exemplars.Enabled = true
+	config.Exemplars.SpanIDField = ""
+	events, supported = mapMetricToSplunkEvent(newResourceMetadata(pdata.NewResource(), config, "", zap.NewNop()), newHistogramWithExemplars(), zap.NewNop())
+	require.True(t, supported)
+	// sum, count and 3 buckets.
+	require.Len(t, events, 5)
+	assert.NotContains(t, events[0].Fields, "trace_id")
+	assert.NotContains(t, events[1].Fields, "trace_id")
+	assert.Equal(t, map[string]interface{}{"le": "1", "metric_name:latency_bucket": uint64(1)}, events[2].Fields)
+	assert.Equal(t, map[string]interface{}{
+		"le":                         "2",
+		"metric_name:latency_bucket": uint64(3),
+		"trace_id":                   "latest",
+		"exemplar_value":             2.0,
+	}, events[3].Fields)
+	assert.Equal(t, map[string]interface{}{
+		"le":                         "+Inf",
+		"metric_name:latency_bucket": uint64(4),
+		"trace_id":                   "overflow",
+		"exemplar_value":             3.0,
+	}, events[4].Fields)
+}
@@ -15,9 +15,7 @@
 package splunkhecexporter
 
 import (
-	"compress/gzip"
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -38,31 +36,81 @@ const (
 	tlsHandshakeTimeout = 10 * time.Second
 	dialerTimeout       = 30 * time.Second
 	dialerKeepAlive     = 30 * time.Second
+	defaultUserAgent    = "OpenTelemetry-Collector Splunk Exporter/v0.0.1"
 )
 
+// Flusher is implemented by the exporters created by this factory, so that embedders such as serverless wrappers
+// can send buffered data before the runtime is frozen:
+//
+//	if f, ok := exp.(splunkhecexporter.Flusher); ok {
+//	    err = f.Flush(ctx)
+//	}
+//
+// Flush does not drain the sending queue, which should be disabled by such embedders.
+type Flusher interface {
+	// Flush immediately sends the data buffered by the exporter.
+	Flush(ctx context.Context) error
+}
+
 type splunkExporter struct {
 	pushMetricsData func(ctx context.Context, md pdata.Metrics) error
 	pushTraceData   func(ctx context.Context, td pdata.Traces) error
 	pushLogData     func(ctx context.Context, td pdata.Logs) error
 	stop            func(ctx context.Context) (err error)
 	start           func(ctx context.Context, host component.Host) (err error)
+	flush           func(ctx context.Context) error
+}
+
+// flushableTracesExporter adds the Flusher interface to the exporter returned by exporterhelper.
+type flushableTracesExporter struct {
+	component.TracesExporter
+	flush func(ctx context.Context) error
+}
+
+func (e *flushableTracesExporter) Flush(ctx context.Context) error {
+	return e.flush(ctx)
+}
+
+// flushableMetricsExporter adds the Flusher interface to the exporter returned by exporterhelper.
+type flushableMetricsExporter struct {
+	component.MetricsExporter
+	flush func(ctx context.Context) error
+}
+
+func (e *flushableMetricsExporter) Flush(ctx context.Context) error {
+	return e.flush(ctx)
+}
+
+// flushableLogsExporter adds the Flusher interface to the exporter returned by exporterhelper.
+type flushableLogsExporter struct {
+	component.LogsExporter
+	flush func(ctx context.Context) error
+}
+
+func (e *flushableLogsExporter) Flush(ctx context.Context) error {
+	return e.flush(ctx)
 }
 
 type exporterOptions struct {
 	url   *url.URL
 	token string
+	// socketPath is the unix domain socket to connect to instead of the url host, if set.
+	socketPath string
 }
 
-// createExporter returns a new Splunk exporter.
+// createExporter returns a new Splunk exporter sending to the given signal endpoint, or to endpoint if empty. Each
+// exporter has its own connection pool, warm-up and adaptive request size limit, so that the health of the endpoint of
+// a signal never affects the others.
 func createExporter(
 	config *Config,
+	signalEndpoint string,
 	logger *zap.Logger,
 ) (*splunkExporter, error) {
 	if config == nil {
 		return nil, errors.New("nil config")
 	}
 
-	options, err := config.getOptionsFromConfig()
+	options, err := config.getSignalOptions(signalEndpoint)
 	if err != nil {
 		return nil,
 			fmt.Errorf("failed to process %q config: %v", config.Name(), err)
@@ -76,39 +124,85 @@ func createExporter(
 		pushLogData:     client.pushLogData,
 		stop:            client.stop,
 		start:           client.start,
+		flush:           client.flush,
 	}, nil
 }
 
 func buildClient(options *exporterOptions, config *Config, logger *zap.Logger) *client {
-	return &client{
+	redactor := splunk.NewTokenRedactor(config.Token)
+	logger = redactor.Logger(logger)
+	newTransport := func() *http.Transport {
+		return &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         buildDialContext(options),
+			MaxIdleConns:        int(config.MaxConnections),
+			MaxIdleConnsPerHost: int(config.MaxConnections),
+			IdleConnTimeout:     idleConnTimeout,
+			TLSHandshakeTimeout: tlsHandshakeTimeout,
+			TLSClientConfig:     newTLSConfig(config),
+		}
+	}
+	var transport http.RoundTripper = newTransport()
+	var rotating *rotatingTransport
+	if config.DNSRefreshInterval > 0 && options.socketPath == "" {
+		rotating = newRotatingTransport(newTransport)
+		transport = rotating
+	}
+	c := &client{
 		url: options.url,
 		client: &http.Client{
-			Timeout: config.Timeout,
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
-				DialContext: (&net.Dialer{
-					Timeout:   dialerTimeout,
-					KeepAlive: dialerKeepAlive,
-				}).DialContext,
-				MaxIdleConns:        int(config.MaxConnections),
-				MaxIdleConnsPerHost: int(config.MaxConnections),
-				IdleConnTimeout:     idleConnTimeout,
-				TLSHandshakeTimeout: tlsHandshakeTimeout,
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: config.InsecureSkipVerify,
-				},
-			},
-		},
-		logger: logger,
-		zippers: sync.Pool{New: func() interface{} {
-			return gzip.NewWriter(nil)
-		}},
-		headers: map[string]string{
-			"Connection":    "keep-alive",
-			"Content-Type":  "application/json",
-			"User-Agent":    "OpenTelemetry-Collector Splunk Exporter/v0.0.1",
-			"Authorization": splunk.HECTokenHeader + " " + config.Token,
+			Timeout:   config.Timeout,
+			Transport: transport,
 		},
-		config: config,
+		logger:     logger,
+		socketPath: options.socketPath,
+		zippers:    sync.Pool{New: newCompressor(config.Compression)},
+		headers:    buildHeaders(config),
+		config:     config,
+		capturer:   newPayloadCapturer(config.PayloadCapture, logger),
+		redactor:   redactor,
+	}
+	c.logBuffer = newLogBuffer(c, config.LogsBuffer)
+	c.limit = newAdaptiveLimit(config, logger)
+	c.resets = newCounterResetDetector(config.CounterResets)
+	c.deltas = newDeltaConverter(config.CumulativeToDelta)
+	c.throttle = newThrottleTracker(config, logger)
+	c.diagnostics = newDiagnostics(config, logger)
+	c.tokenFile = newTokenFile(config, logger, c.redactor)
+	c.auth = newAuthenticator(config, c.tokenFile, c.redactor)
+	c.dns = newDNSRefresher(config, options, rotating, logger)
+	c.retries = newRetryTracker(config)
+	return c
+}
+
+func buildDialContext(options *exporterOptions) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   dialerTimeout,
+		KeepAlive: dialerKeepAlive,
+	}
+	if options.socketPath == "" {
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", options.socketPath)
+	}
+}
+
+func buildHeaders(config *Config) map[string]string {
+	userAgent := defaultUserAgent
+	if config.UserAgent != "" {
+		userAgent = config.UserAgent
+	}
+	headers := map[string]string{
+		"Connection":   "keep-alive",
+		"Content-Type": "application/json",
+		"User-Agent":   userAgent,
+	}
+	// The credentials are set on each request by the authenticator, as they can change over time.
+	// Static headers from the config take precedence over the defaults above. Keys are canonicalized
+	// since the config loader lowercases them.
+	for k, v := range config.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
 	}
+	return headers
 }
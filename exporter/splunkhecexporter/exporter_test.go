@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -44,7 +46,7 @@ import (
 )
 
 func TestNew(t *testing.T) {
-	got, err := createExporter(nil, zap.NewNop())
+	got, err := createExporter(nil, "", zap.NewNop())
 	assert.EqualError(t, err, "nil config")
 	assert.Nil(t, got)
 
@@ -53,7 +55,7 @@ func TestNew(t *testing.T) {
 		Endpoint:        "https://example.com:8088",
 		TimeoutSettings: exporterhelper.TimeoutSettings{Timeout: 1 * time.Second},
 	}
-	got, err = createExporter(config, zap.NewNop())
+	got, err = createExporter(config, "", zap.NewNop())
 	assert.NoError(t, err)
 	require.NotNil(t, got)
 }
@@ -140,12 +142,11 @@ func TestConsumeMetricsData(t *testing.T) {
 				url:   serverURL,
 				token: "1234",
 			}
-			config := &Config{
-				Source:     "test",
-				SourceType: "test_type",
-				Token:      "1234",
-				Index:      "test_index",
-			}
+			config := createDefaultConfig().(*Config)
+			config.Source = "test"
+			config.SourceType = "test_type"
+			config.Token = "1234"
+			config.Index = "test_index"
 			sender := buildClient(options, config, zap.NewNop())
 
 			md := internaldata.OCToMetrics(tt.md)
@@ -284,12 +285,11 @@ func TestConsumeLogsData(t *testing.T) {
 				url:   serverURL,
 				token: "1234",
 			}
-			config := &Config{
-				Source:     "test",
-				SourceType: "test_type",
-				Token:      "1234",
-				Index:      "test_index",
-			}
+			config := createDefaultConfig().(*Config)
+			config.Source = "test"
+			config.SourceType = "test_type"
+			config.Token = "1234"
+			config.Index = "test_index"
 			sender := buildClient(options, config, zap.NewNop())
 
 			err = sender.pushLogData(context.Background(), tt.ld)
@@ -308,7 +308,114 @@ func TestExporterStartAlwaysReturnsNil(t *testing.T) {
 		Endpoint: "https://example.com:8088",
 		Token:    "abc",
 	}
-	e, err := createExporter(config, zap.NewNop())
+	e, err := createExporter(config, "", zap.NewNop())
 	assert.NoError(t, err)
 	assert.NoError(t, e.start(context.Background(), componenttest.NewNopHost()))
 }
+
+func TestBuildHeaders(t *testing.T) {
+	config := &Config{
+		Token:     "1234",
+		UserAgent: "my-collector/1.0",
+		Headers: map[string]string{
+			"x-tenant":     "tenant-1",
+			"content-type": "application/x-ndjson",
+		},
+	}
+	assert.Equal(t, map[string]string{
+		"Connection":   "keep-alive",
+		"Content-Type": "application/x-ndjson",
+		"User-Agent":   "my-collector/1.0",
+		"X-Tenant":     "tenant-1",
+	}, buildHeaders(config))
+
+	assert.Equal(t, defaultUserAgent, buildHeaders(&Config{})["User-Agent"])
+}
+
+func TestSignalIndexes(t *testing.T) {
+	logs := pdata.NewLogs()
+	logs.ResourceLogs().Resize(1)
+	logs.ResourceLogs().At(0).InstrumentationLibraryLogs().Resize(1)
+	logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().Resize(1)
+
+	metrics := pdata.NewMetrics()
+	metrics.ResourceMetrics().Resize(1)
+	metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Resize(1)
+	metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().Resize(1)
+	metric := metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
+	metric.SetName("gauge")
+	metric.SetDataType(pdata.MetricDataTypeIntGauge)
+	metric.IntGauge().DataPoints().Resize(1)
+
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	traces.ResourceSpans().At(0).InstrumentationLibrarySpans().Resize(1)
+	traces.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().Resize(1)
+
+	config := createDefaultConfig().(*Config)
+	config.Index = "main"
+	config.LogsIndex = "logs"
+	config.TracesIndex = "traces"
+
+	logEvents := logDataToSplunk(zap.NewNop(), logs, config)
+	require.Len(t, logEvents, 1)
+	assert.Equal(t, "logs", logEvents[0].Index)
+
+	// The metrics index is not set.
+	metricEvents, _ := metricDataToSplunk(zap.NewNop(), metrics, config)
+	require.Len(t, metricEvents, 1)
+	assert.Equal(t, "main", metricEvents[0].Index)
+
+	spanEvents, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, spanEvents, 1)
+	assert.Equal(t, "traces", spanEvents[0].Index)
+}
+
+func TestSignalEndpoints(t *testing.T) {
+	var paths []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := createDefaultConfig().(*Config)
+	config.Token = "1234"
+	config.Endpoint = server.URL + "/events"
+	config.MetricsEndpoint = server.URL + "/metrics"
+	config.QueueSettings.Enabled = false
+	config.RetrySettings.Enabled = false
+	params := component.ExporterCreateParams{Logger: zap.NewNop()}
+
+	metricsExporter, err := createMetricsExporter(context.Background(), params, config)
+	require.NoError(t, err)
+	require.NoError(t, metricsExporter.Start(context.Background(), componenttest.NewNopHost()))
+	defer metricsExporter.Shutdown(context.Background())
+	logsExporter, err := createLogsExporter(context.Background(), params, config)
+	require.NoError(t, err)
+	require.NoError(t, logsExporter.Start(context.Background(), componenttest.NewNopHost()))
+	defer logsExporter.Shutdown(context.Background())
+
+	metrics := pdata.NewMetrics()
+	metrics.ResourceMetrics().Resize(1)
+	metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().Resize(1)
+	metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().Resize(1)
+	metric := metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
+	metric.SetName("gauge")
+	metric.SetDataType(pdata.MetricDataTypeIntGauge)
+	metric.IntGauge().DataPoints().Resize(1)
+	require.NoError(t, metricsExporter.ConsumeMetrics(context.Background(), metrics))
+
+	logs := pdata.NewLogs()
+	logs.ResourceLogs().Resize(1)
+	logs.ResourceLogs().At(0).InstrumentationLibraryLogs().Resize(1)
+	logs.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().Resize(1)
+	require.NoError(t, logsExporter.ConsumeLogs(context.Background(), logs))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"/metrics", "/events"}, paths)
+}
@@ -19,9 +19,13 @@ import (
 	"errors"
 	"time"
 
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/translator/conventions"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
 
 const (
@@ -29,10 +33,26 @@ const (
 	typeStr            = "splunk_hec"
 	defaultMaxIdleCons = 100
 	defaultHTTPTimeout = 10 * time.Second
+	// defaultMaxContentLength is the default request body size limit of Splunk HEC.
+	defaultMaxContentLength = 2 * 1024 * 1024
+	defaultFlushInterval    = time.Second
+	defaultDrainTimeout     = 10 * time.Second
+	defaultThrottlingWindow = 30 * time.Second
+	// defaultCounterResetSourceType is the default sourcetype of counter reset annotation events.
+	defaultCounterResetSourceType = "otel:counter_reset"
+	// defaultExemplarTraceIDField, defaultExemplarSpanIDField and defaultExemplarValueField are the default names
+	// of the fields of exemplars.
+	defaultExemplarTraceIDField = "trace_id"
+	defaultExemplarSpanIDField  = "span_id"
+	defaultExemplarValueField   = "exemplar_value"
+	// defaultFastRetryBackoff is the default wait before retrying a request whose connection failed.
+	defaultFastRetryBackoff = 10 * time.Millisecond
 )
 
 // NewFactory creates a factory for Splunk HEC exporter.
 func NewFactory() component.ExporterFactory {
+	view.Register(MetricViews()...)
+
 	return exporterhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
@@ -50,10 +70,37 @@ func createDefaultConfig() configmodels.Exporter {
 		TimeoutSettings: exporterhelper.TimeoutSettings{
 			Timeout: defaultHTTPTimeout,
 		},
-		RetrySettings:      exporterhelper.DefaultRetrySettings(),
-		QueueSettings:      exporterhelper.DefaultQueueSettings(),
-		DisableCompression: false,
-		MaxConnections:     defaultMaxIdleCons,
+		RetrySettings:             exporterhelper.DefaultRetrySettings(),
+		QueueSettings:             exporterhelper.DefaultQueueSettings(),
+		DisableCompression:        false,
+		MaxConnections:            defaultMaxIdleCons,
+		MaxContentLength:          defaultMaxContentLength,
+		IncludeResourceAttributes: true,
+		LogsBuffer: LogsBufferSettings{
+			FlushInterval: defaultFlushInterval,
+		},
+		DrainTimeout: defaultDrainTimeout,
+		FastRetry: FastRetrySettings{
+			MaxRetries: 1,
+			Backoff:    defaultFastRetryBackoff,
+		},
+		Throttling: ThrottlingSettings{
+			Window: defaultThrottlingWindow,
+		},
+		CounterResets: CounterResetsSettings{
+			SourceType: defaultCounterResetSourceType,
+		},
+		Exemplars: ExemplarsSettings{
+			TraceIDField: defaultExemplarTraceIDField,
+			SpanIDField:  defaultExemplarSpanIDField,
+			ValueField:   defaultExemplarValueField,
+		},
+		HecToOtelAttrs: splunk.HecToOtelAttrs{
+			Source:     conventions.AttributeServiceName,
+			SourceType: splunk.SourcetypeLabel,
+			Index:      splunk.IndexLabel,
+			Host:       conventions.AttributeHostName,
+		},
 	}
 }
 
@@ -67,12 +114,12 @@ func createTraceExporter(
 	}
 	expCfg := config.(*Config)
 
-	exp, err := createExporter(expCfg, params.Logger)
+	exp, err := createExporter(expCfg, expCfg.TracesEndpoint, params.Logger)
 	if err != nil {
 		return nil, err
 	}
 
-	return exporterhelper.NewTraceExporter(
+	exporter, err := exporterhelper.NewTraceExporter(
 		expCfg,
 		params.Logger,
 		exp.pushTraceData,
@@ -82,6 +129,10 @@ func createTraceExporter(
 		exporterhelper.WithQueue(expCfg.QueueSettings),
 		exporterhelper.WithStart(exp.start),
 		exporterhelper.WithShutdown(exp.stop))
+	if err != nil {
+		return nil, err
+	}
+	return &flushableTracesExporter{exporter, exp.flush}, nil
 }
 
 func createMetricsExporter(
@@ -94,13 +145,13 @@ func createMetricsExporter(
 	}
 	expCfg := config.(*Config)
 
-	exp, err := createExporter(expCfg, params.Logger)
+	exp, err := createExporter(expCfg, expCfg.MetricsEndpoint, params.Logger)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return exporterhelper.NewMetricsExporter(
+	exporter, err := exporterhelper.NewMetricsExporter(
 		expCfg,
 		params.Logger,
 		exp.pushMetricsData,
@@ -110,6 +161,10 @@ func createMetricsExporter(
 		exporterhelper.WithQueue(expCfg.QueueSettings),
 		exporterhelper.WithStart(exp.start),
 		exporterhelper.WithShutdown(exp.stop))
+	if err != nil {
+		return nil, err
+	}
+	return &flushableMetricsExporter{exporter, exp.flush}, nil
 }
 
 func createLogsExporter(
@@ -122,13 +177,13 @@ func createLogsExporter(
 	}
 	expCfg := config.(*Config)
 
-	exp, err := createExporter(expCfg, params.Logger)
+	exp, err := createExporter(expCfg, expCfg.LogsEndpoint, params.Logger)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return exporterhelper.NewLogsExporter(
+	exporter, err = exporterhelper.NewLogsExporter(
 		expCfg,
 		params.Logger,
 		exp.pushLogData,
@@ -138,4 +193,8 @@ func createLogsExporter(
 		exporterhelper.WithQueue(expCfg.QueueSettings),
 		exporterhelper.WithStart(exp.start),
 		exporterhelper.WithShutdown(exp.stop))
+	if err != nil {
+		return nil, err
+	}
+	return &flushableLogsExporter{exporter, exp.flush}, nil
 }
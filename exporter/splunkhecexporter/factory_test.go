@@ -16,11 +16,14 @@ package splunkhecexporter
 
 import (
 	"context"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configcheck"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.uber.org/zap"
@@ -82,6 +85,34 @@ func TestCreateLogsExporter(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateLogsExporterFlush(t *testing.T) {
+	receivedRequest := make(chan string, 1)
+	server := httptest.NewServer(&CapturingData{testing: t, receivedRequest: receivedRequest, statusCode: 200})
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.Token = "1234-1234"
+	cfg.DisableCompression = true
+	cfg.QueueSettings.Enabled = false
+	cfg.LogsBuffer = LogsBufferSettings{Enabled: true, FlushInterval: time.Hour}
+
+	params := component.ExporterCreateParams{Logger: zap.NewNop()}
+	exp, err := createLogsExporter(context.Background(), params, cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, exp.Start(ctx, componenttest.NewNopHost()))
+	defer exp.Shutdown(ctx)
+
+	require.NoError(t, exp.ConsumeLogs(ctx, createLogData(1)))
+	assert.Empty(t, receivedRequest)
+
+	flusher, ok := exp.(Flusher)
+	require.True(t, ok)
+	require.NoError(t, flusher.Flush(ctx))
+	assert.Contains(t, <-receivedRequest, `"event":"mylog"`)
+}
+
 func TestCreateLogsExporterNoConfig(t *testing.T) {
 	params := component.ExporterCreateParams{Logger: zap.NewNop()}
 	_, err := createLogsExporter(context.Background(), params, nil)
@@ -163,7 +194,7 @@ func TestFactory_CreateMetricsExporterFails(t *testing.T) {
 				},
 				Endpoint: "https://example.com:8000",
 			},
-			errorMessage: "failed to process \"splunk_hec\" config: requires a non-empty \"token\"",
+			errorMessage: "failed to process \"splunk_hec\" config: requires a non-empty \"token\" or \"token_file\"",
 		},
 	}
 	for _, tt := range tests {
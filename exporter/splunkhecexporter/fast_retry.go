@@ -0,0 +1,96 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+)
+
+// send posts a request body to the HEC endpoint, identified by the request id in the X-Request-Id header and the
+// debug logs so that failures can be matched with the logs of splunkd. A request whose connection failed is retried up
+// to fast_retry.max_retries times after fast_retry.backoff, with the same request id, before the error is returned to
+// the queued retry.
+func (c *client) send(ctx context.Context, body *requestBody, compressed bool, requestID string) (*http.Response, error) {
+	for attempt := uint(0); ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.url.String(), nil)
+		if err != nil {
+			return nil, consumererror.Permanent(err)
+		}
+		// The transport closes the readers of the body once done with them, including those of its own retries and
+		// redirects.
+		req.Body = body.reader()
+		req.GetBody = func() (io.ReadCloser, error) { return body.reader(), nil }
+		// The length is always sent rather than a chunked body, which some reverse proxies in front of HEC buffer
+		// poorly or reject.
+		req.ContentLength = int64(len(body.data))
+
+		req.Header.Set(requestIDHeader, requestID)
+		if err = c.setHeaders(req); err != nil {
+			_ = req.Body.Close()
+			return nil, err
+		}
+
+		if compressed {
+			req.Header.Set("Content-Encoding", c.config.contentEncoding())
+		}
+
+		start := time.Now()
+		resp, err := c.client.Do(req)
+		if err != nil {
+			c.logger.Debug("Request to Splunk HEC failed", zap.String("request_id", requestID),
+				zap.Uint("attempt", attempt+1), zap.Int("bytes", len(body.data)), zap.Duration("duration", time.Since(start)),
+				zap.Error(err))
+		} else {
+			c.logger.Debug("Request sent to Splunk HEC", zap.String("request_id", requestID),
+				zap.Uint("attempt", attempt+1), zap.Int("bytes", len(body.data)), zap.Duration("duration", time.Since(start)),
+				zap.Int("status_code", resp.StatusCode))
+		}
+		if err == nil || attempt >= c.config.FastRetry.MaxRetries || !isConnectionError(err) {
+			return resp, err
+		}
+		c.logger.Debug("Retrying a request whose connection failed", zap.Error(err))
+
+		timer := time.NewTimer(c.config.FastRetry.Backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+// isConnectionError tells whether a request failed because its connection was refused, reset or closed, which is
+// likely transient. Timeouts are not, as retrying them right away would only double the latency.
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
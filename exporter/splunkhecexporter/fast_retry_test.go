@@ -0,0 +1,185 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFastRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   uint
+		wantErr      bool
+		wantRequests int32
+	}{
+		{
+			name:         "retried",
+			maxRetries:   1,
+			wantRequests: 2,
+		},
+		{
+			name:         "disabled",
+			maxRetries:   0,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					// Close the connection without answering, like a load balancer dropping an idle connection.
+					conn, _, err := w.(http.Hijacker).Hijack()
+					require.NoError(t, err)
+					conn.Close()
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			config := NewFactory().CreateDefaultConfig().(*Config)
+			config.Token = "1234-1234"
+			config.Endpoint = server.URL
+			config.FastRetry.MaxRetries = tt.maxRetries
+			options, err := config.getOptionsFromConfig()
+			require.NoError(t, err)
+			c := buildClient(options, config, zap.NewNop())
+
+			err = c.pushLogData(context.Background(), createLogData(1))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestFastRetryNotOnTimeout(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.Timeout = 10 * time.Millisecond
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	assert.Error(t, c.pushLogData(context.Background(), createLogData(1)))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRequestID(t *testing.T) {
+	var mu sync.Mutex
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requestIDs = append(requestIDs, r.Header.Get(requestIDHeader))
+		first := len(requestIDs) == 1
+		mu.Unlock()
+		if first {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	core, logs := observer.New(zap.DebugLevel)
+	c := buildClient(options, config, zap.New(core))
+
+	err = c.pushLogData(context.Background(), createLogData(1))
+	require.Error(t, err)
+
+	require.Len(t, requestIDs, 2)
+	requestID := requestIDs[0]
+	assert.Len(t, requestID, 32)
+	assert.Equal(t, requestID, requestIDs[1], "fast retries keep the request id")
+	assert.Contains(t, err.Error(), "request id "+requestID)
+
+	failed := logs.FilterMessage("Request to Splunk HEC failed").All()
+	require.Len(t, failed, 1)
+	assert.Equal(t, requestID, failed[0].ContextMap()["request_id"])
+	assert.Equal(t, uint64(1), failed[0].ContextMap()["attempt"])
+	sent := logs.FilterMessage("Request sent to Splunk HEC").All()
+	require.Len(t, sent, 1)
+	assert.Equal(t, requestID, sent[0].ContextMap()["request_id"])
+	assert.Equal(t, uint64(2), sent[0].ContextMap()["attempt"])
+	assert.Equal(t, int64(http.StatusServiceUnavailable), sent[0].ContextMap()["status_code"])
+
+	require.Error(t, c.pushLogData(context.Background(), createLogData(1)))
+	require.Len(t, requestIDs, 3)
+	assert.NotEqual(t, requestID, requestIDs[2], "each request has its own id")
+}
+
+func TestContentLength(t *testing.T) {
+	for _, disableCompression := range []bool{false, true} {
+		var transferEncoding []string
+		var contentLength int64
+		var received int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			transferEncoding = r.TransferEncoding
+			contentLength = r.ContentLength
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			received = len(body)
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		config := NewFactory().CreateDefaultConfig().(*Config)
+		config.Token = "1234-1234"
+		config.Endpoint = server.URL
+		config.DisableCompression = disableCompression
+		options, err := config.getOptionsFromConfig()
+		require.NoError(t, err)
+		c := buildClient(options, config, zap.NewNop())
+		require.NoError(t, c.pushLogData(context.Background(), createLogData(100)))
+		server.Close()
+
+		assert.Empty(t, transferEncoding, "compression disabled: %v", disableCompression)
+		assert.Greater(t, received, 0)
+		assert.Equal(t, int64(received), contentLength)
+	}
+}
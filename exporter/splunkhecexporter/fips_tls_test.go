@@ -0,0 +1,101 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFIPSTLS(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverTLS *tls.Config
+		wantErr   bool
+	}{
+		{
+			name:      "default",
+			serverTLS: &tls.Config{},
+		},
+		{
+			name:      "tls13_only",
+			serverTLS: &tls.Config{MinVersion: tls.VersionTLS13},
+			wantErr:   true,
+		},
+		{
+			name: "non_approved_cipher_suite",
+			serverTLS: &tls.Config{
+				MaxVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var state *tls.ConnectionState
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				state = r.TLS
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.TLS = tt.serverTLS
+			server.StartTLS()
+			defer server.Close()
+
+			config := NewFactory().CreateDefaultConfig().(*Config)
+			config.Token = "1234-1234"
+			config.Endpoint = server.URL
+			config.FIPSTLS = true
+			config.FastRetry.MaxRetries = 0
+			options, err := config.getOptionsFromConfig()
+			require.NoError(t, err)
+			c := buildClient(options, config, zap.NewNop())
+			roots := x509.NewCertPool()
+			roots.AddCert(server.Certificate())
+			c.client.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+
+			err = c.pushLogData(context.Background(), createLogData(1))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, state)
+			assert.Equal(t, uint16(tls.VersionTLS12), state.Version)
+			assert.Contains(t, fipsCipherSuites, state.CipherSuite)
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig := newTLSConfig(&Config{InsecureSkipVerify: true})
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Zero(t, tlsConfig.MinVersion)
+	assert.Nil(t, tlsConfig.CipherSuites)
+
+	tlsConfig = newTLSConfig(&Config{FIPSTLS: true})
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MaxVersion)
+	assert.Equal(t, fipsCipherSuites, tlsConfig.CipherSuites)
+	assert.Equal(t, fipsCurves, tlsConfig.CurvePreferences)
+}
@@ -3,13 +3,17 @@ module github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunk
 go 1.14
 
 require (
+	github.com/andybalholm/brotli v1.0.6
 	github.com/census-instrumentation/opencensus-proto v0.3.0
+	github.com/open-telemetry/opentelemetry-collector-contrib/internal/common v0.0.0-00010101000000-000000000000
 	github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk v0.0.0-00010101000000-000000000000
 	github.com/stretchr/testify v1.7.0
+	go.opencensus.io v0.23.0
 	go.opentelemetry.io/collector v0.22.1-0.20210323150444-0c6757ec71a5
 	go.uber.org/zap v1.16.0
 	google.golang.org/protobuf v1.26.0
-
 )
 
 replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk => ../../internal/splunk
+
+replace github.com/open-telemetry/opentelemetry-collector-contrib/internal/common => ../../internal/common
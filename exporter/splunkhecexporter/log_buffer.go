@@ -0,0 +1,185 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// logBuffer accumulates log events across batches and sends them once max_content_length is reached, once no
+// batch was received for idle_timeout or, at the latest, flush_interval after the oldest buffered event was added.
+// Batches are reported as sent once they are buffered. Buffered events whose send failed are kept and sent again
+// with the next flush, while the records of the batch that could not be buffered are reported as failed to be retried.
+type logBuffer struct {
+	flushInterval time.Duration
+	idleTimeout   time.Duration
+	logger        *zap.Logger
+
+	mu        sync.Mutex
+	sender    *chunkSender
+	timer     *time.Timer
+	idleTimer *time.Timer
+}
+
+func newLogBuffer(c *client, settings LogsBufferSettings) *logBuffer {
+	if !settings.Enabled {
+		return nil
+	}
+	return &logBuffer{
+		flushInterval: settings.FlushInterval,
+		idleTimeout:   settings.IdleTimeout,
+		logger:        c.logger,
+		sender:        newChunkSender(c, "logs"),
+	}
+}
+
+func (b *logBuffer) push(ctx context.Context, ld pdata.Logs) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sender.permanentErrs = nil
+	b.sender.drops = nil
+	var errs []error
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				events := []*splunk.Event{mapLogRecordToSplunkEvent(rl.Resource(), logs.At(k), b.sender.client.config, b.logger)}
+				index := eventIndex{resource: i, library: j, record: k}
+				if err := b.sender.add(ctx, index, events); err != nil {
+					if b.retryable(err) {
+						// The buffered events are kept to be sent again, and the records from this one onwards are
+						// retried with the batch.
+						b.armTimers()
+						b.sender.client.reportDrops(ctx, "logs", b.sender.drops)
+						return partialLogsError(err, ld, index)
+					}
+					// The buffered events were counted as dropped, buffer the record again without them.
+					b.drop(err)
+					errs = append(errs, err)
+					_ = b.sender.add(ctx, index, events)
+				}
+			}
+		}
+	}
+	b.armTimers()
+	b.sender.client.reportDrops(ctx, "logs", b.sender.drops)
+	if err := b.sender.err(); err != nil {
+		errs = append(errs, err)
+	}
+	return consumererror.CombineErrors(errs)
+}
+
+// armTimers starts the flush timers once events are buffered.
+func (b *logBuffer) armTimers() {
+	if b.sender.chunk.Len() == 0 {
+		return
+	}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.flushInterval, b.onTimer)
+	}
+	if b.idleTimeout > 0 {
+		// Restart the idle countdown with every batch.
+		if b.idleTimer != nil {
+			b.idleTimer.Stop()
+		}
+		b.idleTimer = time.AfterFunc(b.idleTimeout, b.onTimer)
+	}
+}
+
+// retryable tells whether the buffered events whose send failed with the given error are kept to be sent again.
+func (b *logBuffer) retryable(err error) bool {
+	return b.sender.retried && !consumererror.IsPermanent(err)
+}
+
+func (b *logBuffer) onTimer() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.flushLocked(context.Background()); err != nil && b.sender.chunk.Len() > 0 {
+		b.logger.Warn("Failed to send buffered log events, retrying with the next flush",
+			zap.Int("buffered_events", b.sender.chunk.Events()), zap.Error(err))
+	}
+}
+
+// flush sends the buffered events, returning the error of a failed send. Events that can be retried are kept to be
+// sent with the next flush.
+func (b *logBuffer) flush(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, err := b.flushLocked(ctx)
+	return err
+}
+
+// drain sends the buffered events when shutting down, returning the number of events that could not be sent along
+// with the error of the failed send.
+func (b *logBuffer) drain(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	unsent, err := b.flushLocked(ctx)
+	if err != nil && b.sender.chunk.Len() > 0 {
+		// There is no later flush to retry them with.
+		b.sender.dropRecords(sendFailureReason(err), b.sender.chunk.Len(), err.Error())
+		b.reportDrops(ctx)
+		b.drop(err)
+	}
+	return unsent, err
+}
+
+// flushLocked sends the buffered events. If the send failed, the events that were not sent are kept to be sent with
+// the next flush when they can be retried, and dropped otherwise. It returns the number of events that were not sent.
+func (b *logBuffer) flushLocked(ctx context.Context) (int, error) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.idleTimer != nil {
+		b.idleTimer.Stop()
+		b.idleTimer = nil
+	}
+	if err := b.sender.flush(ctx); err != nil {
+		unsent := b.sender.chunk.Events()
+		if b.retryable(err) {
+			b.armTimers()
+			return unsent, err
+		}
+		b.reportDrops(ctx)
+		b.drop(err)
+		return unsent, err
+	}
+	return 0, nil
+}
+
+// reportDrops reports the records dropped by a flush, which happens outside of batches whose drops are reported
+// when they are pushed.
+func (b *logBuffer) reportDrops(ctx context.Context) {
+	b.sender.client.reportDrops(ctx, "logs", b.sender.drops)
+	b.sender.drops = nil
+}
+
+func (b *logBuffer) drop(err error) {
+	b.logger.Error("Failed to send buffered log events", zap.Int("dropped_events", b.sender.chunk.Len()), zap.Error(err))
+	b.sender.reset()
+}
@@ -0,0 +1,151 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+)
+
+func newBufferedTestClient(t *testing.T, statusCode int, maxContentLength uint, flushInterval time.Duration) (*client, chan string, func()) {
+	receivedRequest := make(chan string, 10)
+	capture := CapturingData{testing: t, receivedRequest: receivedRequest, statusCode: statusCode}
+	server := httptest.NewServer(&capture)
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.DisableCompression = true
+	config.MaxContentLength = maxContentLength
+	config.LogsBuffer = LogsBufferSettings{Enabled: true, FlushInterval: flushInterval}
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	return buildClient(options, config, zap.NewNop()), receivedRequest, server.Close
+}
+
+func TestLogBufferCoalescesBatches(t *testing.T) {
+	c, receivedRequest, closeServer := newBufferedTestClient(t, 200, defaultMaxContentLength, 50*time.Millisecond)
+	defer closeServer()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	}
+	select {
+	case request := <-receivedRequest:
+		assert.Equal(t, 3, strings.Count(request, `"event":"mylog"`))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should have received request")
+	}
+}
+
+func TestLogBufferFlushesOnMaxContentLength(t *testing.T) {
+	c, receivedRequest, closeServer := newBufferedTestClient(t, 200, 300, time.Hour)
+	defer closeServer()
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	select {
+	case <-receivedRequest:
+		t.Fatal("Should not have sent a partial chunk")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	select {
+	case request := <-receivedRequest:
+		assert.Equal(t, 1, strings.Count(request, `"event":"mylog"`))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should have received request")
+	}
+
+	// The remaining event is sent on shutdown.
+	require.NoError(t, c.stop(context.Background()))
+	select {
+	case request := <-receivedRequest:
+		assert.Equal(t, 1, strings.Count(request, `"event":"mylog"`))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should have received request")
+	}
+}
+
+func TestLogBufferRetriesOnFailure(t *testing.T) {
+	c, receivedRequest, closeServer := newBufferedTestClient(t, 500, 300, time.Hour)
+	defer closeServer()
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	// Sending the buffered event fails, it is kept and the new record is reported as failed.
+	err := c.pushLogData(context.Background(), createLogData(2))
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+	partialErr, ok := err.(consumererror.PartialError)
+	require.True(t, ok)
+	assert.Equal(t, 2, partialErr.GetLogs().LogRecordCount())
+	<-receivedRequest
+	assert.Equal(t, 1, c.logBuffer.sender.chunk.Len())
+
+	// Flushing keeps failing, and the event is still kept.
+	require.Error(t, c.flush(context.Background()))
+	<-receivedRequest
+	assert.Equal(t, 1, c.logBuffer.sender.chunk.Len())
+}
+
+func TestLogBufferDropsOnFailureWithoutRetry(t *testing.T) {
+	c, receivedRequest, closeServer := newBufferedTestClient(t, 500, 300, time.Hour)
+	defer closeServer()
+	c.config.RetrySettings.Enabled = false
+	c.logBuffer.sender.retried = false
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	// Sending the buffered event fails, it is dropped and the new record is buffered.
+	require.Error(t, c.pushLogData(context.Background(), createLogData(1)))
+	<-receivedRequest
+	assert.Equal(t, 1, c.logBuffer.sender.chunk.Len())
+}
+
+func TestLogBufferFlushesWhenIdle(t *testing.T) {
+	c, receivedRequest, closeServer := newBufferedTestClient(t, 200, defaultMaxContentLength, time.Hour)
+	defer closeServer()
+	c.logBuffer.idleTimeout = 50 * time.Millisecond
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	}
+	select {
+	case request := <-receivedRequest:
+		assert.Equal(t, 2, strings.Count(request, `"event":"mylog"`))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should have received request")
+	}
+}
+
+func TestLogBufferDrainReportsUnsentEvents(t *testing.T) {
+	c, receivedRequest, closeServer := newBufferedTestClient(t, 500, defaultMaxContentLength, time.Hour)
+	defer closeServer()
+	c.config.FastRetry.MaxRetries = 0
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(3)))
+	err := c.stop(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 buffered events could not be sent")
+	<-receivedRequest
+	assert.Equal(t, 0, c.logBuffer.sender.chunk.Len())
+}
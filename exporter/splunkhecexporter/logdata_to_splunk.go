@@ -15,10 +15,12 @@
 package splunkhecexporter
 
 import (
-	"time"
+	"encoding/json"
+	"strings"
+	"text/template"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
-	"go.opentelemetry.io/collector/translator/conventions"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
@@ -28,11 +30,12 @@ func logDataToSplunk(logger *zap.Logger, ld pdata.Logs, config *Config) []*splun
 	var splunkEvents []*splunk.Event
 	rls := ld.ResourceLogs()
 	for i := 0; i < rls.Len(); i++ {
-		ills := rls.At(i).InstrumentationLibraryLogs()
+		rl := rls.At(i)
+		ills := rl.InstrumentationLibraryLogs()
 		for j := 0; j < ills.Len(); j++ {
 			logs := ills.At(j).Logs()
 			for k := 0; k < logs.Len(); k++ {
-				splunkEvents = append(splunkEvents, mapLogRecordToSplunkEvent(logs.At(k), config, logger))
+				splunkEvents = append(splunkEvents, mapLogRecordToSplunkEvent(rl.Resource(), logs.At(k), config, logger))
 			}
 		}
 	}
@@ -40,41 +43,160 @@ func logDataToSplunk(logger *zap.Logger, ld pdata.Logs, config *Config) []*splun
 	return splunkEvents
 }
 
-func mapLogRecordToSplunkEvent(lr pdata.LogRecord, config *Config, logger *zap.Logger) *splunk.Event {
-	host := unknownHostName
-	source := config.Source
-	sourcetype := config.SourceType
-	index := config.Index
+func mapLogRecordToSplunkEvent(res pdata.Resource, lr pdata.LogRecord, config *Config, logger *zap.Logger) *splunk.Event {
+	meta := newHecMetadata(config, config.signalIndex(config.LogsIndex))
+	meta.render(config, logger, res.Attributes(), lr.Attributes())
+	if sourceType, ok := severitySourceType(config.LogSeverity.SourceTypes, lr); ok {
+		meta.sourceType = sourceType
+	}
+	mapping := config.metadataMapping()
+	meta.update(mapping, res.Attributes())
+	meta.update(mapping, lr.Attributes())
+	meta.update(splunkOverrides, res.Attributes())
+	meta.update(splunkOverrides, lr.Attributes())
 	fields := map[string]interface{}{}
+	if config.LogSeverity.TextField != "" && lr.SeverityText() != "" {
+		fields[config.LogSeverity.TextField] = lr.SeverityText()
+	}
+	if config.LogSeverity.NumberField != "" && lr.SeverityNumber() != pdata.SeverityNumberUNDEFINED {
+		fields[config.LogSeverity.NumberField] = int32(lr.SeverityNumber())
+	}
+	attributes := map[string]interface{}{}
 	lr.Attributes().ForEach(func(k string, v pdata.AttributeValue) {
-		switch k {
-		case conventions.AttributeHostName:
-			host = v.StringVal()
-			fields[k] = v.StringVal()
-		case conventions.AttributeServiceName:
-			source = v.StringVal()
-			fields[k] = v.StringVal()
-		case splunk.SourcetypeLabel:
-			sourcetype = v.StringVal()
-		case splunk.IndexLabel:
-			index = v.StringVal()
-		default:
-			fields[k] = convertAttributeValue(v, logger)
+		// HEC metadata labels and passed through access tokens are not sent as attributes.
+		if isReservedLabel(k) || !config.attributeFilter.keepAttribute(k, v) {
+			return
 		}
+		attributes[k] = convertAttributeValue(v, logger)
 	})
+	placement := config.logAttributesPlacement()
+	if placement == placementFields || placement == placementBoth {
+		for k, v := range attributes {
+			fields[k] = v
+		}
+	}
 
-	eventValue := convertAttributeValue(lr.Body(), logger)
+	var eventValue interface{}
+	if config.RawLogBody {
+		// Maps and arrays are serialized to JSON, other values are formatted as is.
+		eventValue = tracetranslator.AttributeValueToString(lr.Body(), false)
+	} else {
+		eventValue = convertAttributeValue(lr.Body(), logger)
+		if config.StringifyStructuredLogBodies {
+			eventValue = stringifyStructuredBody(lr.Body(), eventValue, logger)
+		}
+		if config.logTemplate != nil {
+			eventValue = formatLogEvent(config.logTemplate, lr, eventValue, config.attributeFilter, logger)
+		}
+		if (placement == placementEvent || placement == placementBoth) && len(attributes) > 0 {
+			eventValue = embedAttributes(eventValue, attributes)
+		}
+	}
 	return &splunk.Event{
-		Time:       nanoTimestampToEpochMilliseconds(lr.Timestamp()),
-		Host:       host,
-		Source:     source,
-		SourceType: sourcetype,
-		Index:      index,
+		Time:       timestampToEpochSeconds(lr.Timestamp(), config.timestampPrecision()),
+		TimeNanos:  timestampNanos(lr.Timestamp(), config.timestampPrecision()),
+		Host:       meta.host,
+		Source:     meta.source,
+		SourceType: meta.sourceType,
+		Index:      meta.index,
 		Event:      eventValue,
 		Fields:     fields,
 	}
 }
 
+// embedAttributes returns an event payload holding the attributes along with the body. The attributes are merged
+// into map bodies, whose keys take precedence, and other bodies are held in the "body" key.
+func embedAttributes(body interface{}, attributes map[string]interface{}) map[string]interface{} {
+	event := make(map[string]interface{}, len(attributes)+1)
+	for k, v := range attributes {
+		event[k] = v
+	}
+	if values, ok := body.(map[string]interface{}); ok {
+		for k, v := range values {
+			event[k] = v
+		}
+	} else {
+		event["body"] = body
+	}
+	return event
+}
+
+// stringifyStructuredBody serializes map and array bodies to a JSON string, other bodies are returned unchanged.
+func stringifyStructuredBody(body pdata.AttributeValue, value interface{}, logger *zap.Logger) interface{} {
+	if body.Type() != pdata.AttributeValueMAP && body.Type() != pdata.AttributeValueARRAY {
+		return value
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		logger.Debug("Failed to serialize log body", zap.Error(err))
+		return value
+	}
+	return string(b)
+}
+
+// severitySourceType returns the sourcetype mapped to the severity text of the record, or else to the level of its
+// severity number.
+func severitySourceType(sourceTypes map[string]string, lr pdata.LogRecord) (string, bool) {
+	if len(sourceTypes) == 0 {
+		return "", false
+	}
+	if text := lr.SeverityText(); text != "" {
+		if sourceType, ok := sourceTypes[strings.ToLower(text)]; ok {
+			return sourceType, true
+		}
+	}
+	if level := severityLevel(lr.SeverityNumber()); level != "" {
+		sourceType, ok := sourceTypes[level]
+		return sourceType, ok
+	}
+	return "", false
+}
+
+// severityLevel returns the level of a severity number, as defined by the OpenTelemetry log data model.
+func severityLevel(number pdata.SeverityNumber) string {
+	switch {
+	case number >= pdata.SeverityNumberFATAL:
+		return "fatal"
+	case number >= pdata.SeverityNumberERROR:
+		return "error"
+	case number >= pdata.SeverityNumberWARN:
+		return "warn"
+	case number >= pdata.SeverityNumberINFO:
+		return "info"
+	case number >= pdata.SeverityNumberDEBUG:
+		return "debug"
+	case number >= pdata.SeverityNumberTRACE:
+		return "trace"
+	default:
+		return ""
+	}
+}
+
+// formatLogEvent renders the log template over the record, falling back to the body if rendering fails.
+func formatLogEvent(tmpl *template.Template, lr pdata.LogRecord, body interface{}, filter *attributeFilter, logger *zap.Logger) interface{} {
+	attributes := map[string]interface{}{}
+	lr.Attributes().ForEach(func(k string, v pdata.AttributeValue) {
+		if filter.keepAttribute(k, v) {
+			attributes[k] = convertAttributeValue(v, logger)
+		}
+	})
+	data := map[string]interface{}{
+		"body":            body,
+		"severity":        lr.SeverityText(),
+		"severity_number": int32(lr.SeverityNumber()),
+		"name":            lr.Name(),
+		"trace_id":        lr.TraceID().HexString(),
+		"span_id":         lr.SpanID().HexString(),
+		"attributes":      attributes,
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		logger.Debug("Failed to render log template", zap.Error(err))
+		return body
+	}
+	return buf.String()
+}
+
 func convertAttributeValue(value pdata.AttributeValue, logger *zap.Logger) interface{} {
 	switch value.Type() {
 	case pdata.AttributeValueINT:
@@ -105,18 +227,3 @@ func convertAttributeValue(value pdata.AttributeValue, logger *zap.Logger) inter
 		return value
 	}
 }
-
-// nanoTimestampToEpochMilliseconds transforms nanoseconds into <sec>.<ms>. For example, 1433188255.500 indicates 1433188255 seconds and 500 milliseconds after epoch.
-func nanoTimestampToEpochMilliseconds(ts pdata.Timestamp) *float64 {
-	duration := time.Duration(ts)
-	if duration == 0 {
-		// some telemetry sources send data with timestamps set to 0 by design, as their original target destinations
-		// (i.e. before Open Telemetry) are setup with the know-how on how to consume them. In this case,
-		// we want to omit the time field when sending data to the Splunk HEC so that the HEC adds a timestamp
-		// at indexing time, which will be much more useful than a 0-epoch-time value.
-		return nil
-	}
-
-	val := duration.Round(time.Millisecond).Seconds()
-	return &val
-}
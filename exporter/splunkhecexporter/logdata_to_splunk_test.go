@@ -36,6 +36,51 @@ func Test_logDataToSplunk(t *testing.T) {
 		configDataFn     func() *Config
 		wantSplunkEvents []*splunk.Event
 	}{
+		{
+			name: "preserved_hec_metadata",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString(splunk.HostLabel, "myhost")
+				logRecord.Attributes().InsertString(splunk.SourceLabel, "mysource")
+				logRecord.Attributes().InsertString(splunk.SourcetypeLabel, "mysourcetype")
+				logRecord.Attributes().InsertString(splunk.IndexLabel, "myindex")
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				return createDefaultConfig().(*Config)
+			},
+			wantSplunkEvents: []*splunk.Event{
+				func() *splunk.Event {
+					event := commonLogSplunkEvent("mylog", ts, map[string]interface{}{"custom": "custom"},
+						"myhost", "mysource", "mysourcetype")
+					event.Index = "myindex"
+					return event
+				}(),
+			},
+		},
+		{
+			name: "passed_through_access_token",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString(splunk.SFxAccessTokenLabel, "secret")
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"custom": "custom"}, "unknown", "source", "sourcetype"),
+			},
+		},
 		{
 			name: "valid",
 			logDataFn: func() pdata.Logs {
@@ -49,10 +94,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"custom": "custom", "service.name": "myapp", "host.name": "myhost"},
@@ -72,10 +117,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"foo": float64(123), "service.name": "myapp", "host.name": "myhost"}, "myhost", "myapp", "myapp-type"),
@@ -91,26 +136,224 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"custom": "custom"}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "with attribute mapping",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString("k8s.namespace.name", "kube-system")
+				logRecord.SetTimestamp(ts)
+				logs := makeLog(logRecord)
+				resource := logs.ResourceLogs().At(0).Resource()
+				resource.Attributes().InsertString("k8s.namespace.name", "default")
+				resource.Attributes().InsertString("k8s.node.name", "node-1")
+				resource.Attributes().InsertString("k8s.container.name", "app")
+				return logs
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.HecToOtelAttrs = splunk.HecToOtelAttrs{
+					Index:      "k8s.namespace.name",
+					Host:       "k8s.node.name",
+					SourceType: "k8s.container.name",
+				}
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				{
+					Time:       timestampToEpochSeconds(ts, timestampPrecisionMillisecond),
+					Host:       "node-1",
 					Source:     "source",
+					SourceType: "app",
+					Index:      "kube-system",
+					Event:      "mylog",
+					Fields:     map[string]interface{}{"k8s.namespace.name": "kube-system"},
+				},
+			},
+		},
+		{
+			name: "with com.splunk overrides",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString("k8s.namespace.name", "kube-system")
+				logRecord.Attributes().InsertString(splunk.SourceLabel, "record-source")
+				logRecord.SetTimestamp(ts)
+				logs := makeLog(logRecord)
+				resource := logs.ResourceLogs().At(0).Resource()
+				resource.Attributes().InsertString(splunk.IndexLabel, "routed")
+				resource.Attributes().InsertString(splunk.SourceLabel, "resource-source")
+				return logs
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.HecToOtelAttrs.Index = "k8s.namespace.name"
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				{
+					Time:       timestampToEpochSeconds(ts, timestampPrecisionMillisecond),
+					Host:       "unknown",
+					Source:     "record-source",
 					SourceType: "sourcetype",
+					Index:      "routed",
+					Event:      "mylog",
+					Fields:     map[string]interface{}{"k8s.namespace.name": "kube-system"},
+				},
+			},
+		},
+		{
+			name: "with source and sourcetype templates",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString("k8s.container.name", "sidecar")
+				logRecord.SetTimestamp(ts)
+				logs := makeLog(logRecord)
+				resource := logs.ResourceLogs().At(0).Resource()
+				resource.Attributes().InsertString("service.name", "checkout")
+				resource.Attributes().InsertString("k8s.container.name", "app")
+				return logs
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Token = "token"
+				config.Endpoint = "https://example.com:8088"
+				config.Source = "otel/{{.service_name}}/{{.k8s_container_name}}"
+				config.SourceType = "{{.missing}}"
+				require.NoError(t, config.validateConfig())
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				{
+					Time:       timestampToEpochSeconds(ts, timestampPrecisionMillisecond),
+					Host:       "unknown",
+					Source:     "otel/checkout/sidecar",
+					SourceType: "",
+					Event:      "mylog",
+					Fields:     map[string]interface{}{"k8s.container.name": "sidecar"},
+				},
+			},
+		},
+		{
+			name: "with severity mapping",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.SetSeverityText("Warning")
+				logRecord.SetSeverityNumber(pdata.SeverityNumberWARN)
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.LogSeverity = LogSeveritySettings{
+					TextField:   "severity",
+					NumberField: "severity_number",
+					SourceTypes: map[string]string{"warning": "app:warning", "warn": "app:warn"},
 				}
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
-				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"custom": "custom"}, "unknown", "source", "sourcetype"),
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"severity": "Warning", "severity_number": int32(13)},
+					"unknown", "source", "app:warning"),
 			},
 		},
 		{
-			name: "log_is_empty",
+			name: "with severity number mapping",
 			logDataFn: func() pdata.Logs {
 				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.SetSeverityNumber(pdata.SeverityNumberERROR3)
+				logRecord.SetTimestamp(ts)
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.LogSeverity = LogSeveritySettings{
+					TextField:   "severity",
+					SourceTypes: map[string]string{"error": "app:error"},
 				}
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{}, "unknown", "source", "app:error"),
+			},
+		},
+		{
+			name: "with log template",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.SetSeverityText("WARN")
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Token = "1234"
+				config.Endpoint = "https://example.com:8088"
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.LogTemplate = `[{{.severity}}] {{.body}} ({{.attributes.custom}})`
+				require.NoError(t, config.validateConfig())
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("[WARN] mylog (custom)", ts, map[string]interface{}{"custom": "custom"}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "with failing log template",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Token = "1234"
+				config.Endpoint = "https://example.com:8088"
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.LogTemplate = `{{index .body 10}}`
+				require.NoError(t, config.validateConfig())
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "log_is_empty",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent(nil, 0, map[string]interface{}{}, "unknown", "source", "sourcetype"),
@@ -129,10 +372,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent(float64(42), ts, map[string]interface{}{"custom": "custom", "service.name": "myapp", "host.name": "myhost"}, "myhost", "myapp", "myapp-type"),
@@ -151,10 +394,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent(int64(42), ts, map[string]interface{}{"custom": "custom", "service.name": "myapp", "host.name": "myhost"}, "myhost", "myapp", "myapp-type"),
@@ -173,10 +416,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent(true, ts, map[string]interface{}{"custom": "custom", "service.name": "myapp", "host.name": "myhost"}, "myhost", "myapp", "myapp-type"),
@@ -199,10 +442,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent(map[string]interface{}{"23": float64(45), "foo": "bar"}, ts,
@@ -210,6 +453,148 @@ func Test_logDataToSplunk(t *testing.T) {
 					"myhost", "myapp", "myapp-type"),
 			},
 		},
+		{
+			name: "with stringified map body",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				attVal := pdata.NewAttributeValueMap()
+				attMap := attVal.MapVal()
+				attMap.InsertDouble("23", 45)
+				attMap.InsertString("foo", "bar")
+				attVal.CopyTo(logRecord.Body())
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.StringifyStructuredLogBodies = true
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent(`{"23":45,"foo":"bar"}`, ts, map[string]interface{}{}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "with stringified string body",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.StringifyStructuredLogBodies = true
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "with raw int body",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetIntVal(42)
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.RawLogBody = true
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("42", ts, map[string]interface{}{"custom": "custom"}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "with attributes in event",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.Attributes().InsertString(splunk.IndexLabel, "myindex")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.AttributesPlacement.Logs = "event"
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				{
+					Time:       timestampToEpochSeconds(ts, timestampPrecisionMillisecond),
+					Host:       "unknown",
+					Source:     "source",
+					SourceType: "sourcetype",
+					Index:      "myindex",
+					Event:      map[string]interface{}{"body": "mylog", "custom": "custom"},
+					Fields:     map[string]interface{}{},
+				},
+			},
+		},
+		{
+			name: "with attributes in fields and map body",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				attVal := pdata.NewAttributeValueMap()
+				attVal.MapVal().InsertString("foo", "bar")
+				attVal.MapVal().InsertString("custom", "body")
+				attVal.CopyTo(logRecord.Body())
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.AttributesPlacement.Logs = "both"
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent(map[string]interface{}{"foo": "bar", "custom": "body"}, ts,
+					map[string]interface{}{"custom": "custom"}, "unknown", "source", "sourcetype"),
+			},
+		},
+		{
+			name: "with attributes filter",
+			logDataFn: func() pdata.Logs {
+				logRecord := pdata.NewLogRecord()
+				logRecord.Body().SetStringVal("mylog")
+				logRecord.Attributes().InsertString(conventions.AttributeHostName, "myhost")
+				logRecord.Attributes().InsertString("user.email", "john@example.com")
+				logRecord.Attributes().InsertString("custom", "custom")
+				logRecord.SetTimestamp(ts)
+				return makeLog(logRecord)
+			},
+			configDataFn: func() *Config {
+				config := createDefaultConfig().(*Config)
+				config.Token = "1234"
+				config.Endpoint = "https://example.com:8088"
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				config.AttributesFilter = AttributesFilterSettings{
+					Exclude: []string{"user.email", conventions.AttributeHostName},
+				}
+				require.NoError(t, config.validateConfig())
+				return config
+			},
+			wantSplunkEvents: []*splunk.Event{
+				commonLogSplunkEvent("mylog", ts, map[string]interface{}{"custom": "custom"}, "myhost", "source", "sourcetype"),
+			},
+		},
 		{
 			name: "with nil body",
 			logDataFn: func() pdata.Logs {
@@ -222,10 +607,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent(nil, ts, map[string]interface{}{"custom": "custom", "service.name": "myapp", "host.name": "myhost"},
@@ -248,10 +633,10 @@ func Test_logDataToSplunk(t *testing.T) {
 				return makeLog(logRecord)
 			},
 			configDataFn: func() *Config {
-				return &Config{
-					Source:     "source",
-					SourceType: "sourcetype",
-				}
+				config := createDefaultConfig().(*Config)
+				config.Source = "source"
+				config.SourceType = "sourcetype"
+				return config
 			},
 			wantSplunkEvents: []*splunk.Event{
 				commonLogSplunkEvent([]interface{}{"foo"}, ts, map[string]interface{}{"custom": "custom", "service.name": "myapp", "host.name": "myhost"},
@@ -290,7 +675,7 @@ func commonLogSplunkEvent(
 	sourcetype string,
 ) *splunk.Event {
 	return &splunk.Event{
-		Time:       nanoTimestampToEpochMilliseconds(ts),
+		Time:       timestampToEpochSeconds(ts, timestampPrecisionMillisecond),
 		Host:       host,
 		Event:      event,
 		Source:     source,
@@ -319,12 +704,3 @@ func Test_nilInstrumentationLogs(t *testing.T) {
 	events := logDataToSplunk(zap.NewNop(), logs, &Config{})
 	assert.Equal(t, 0, len(events))
 }
-
-func Test_nanoTimestampToEpochMilliseconds(t *testing.T) {
-	splunkTs := nanoTimestampToEpochMilliseconds(1001000000)
-	assert.Equal(t, 1.001, *splunkTs)
-	splunkTs = nanoTimestampToEpochMilliseconds(1001990000)
-	assert.Equal(t, 1.002, *splunkTs)
-	splunkTs = nanoTimestampToEpochMilliseconds(0)
-	assert.True(t, nil == splunkTs)
-}
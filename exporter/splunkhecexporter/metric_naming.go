@@ -0,0 +1,86 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// metricNamer renames metrics and prefixes their names. A nil namer keeps the names unchanged.
+type metricNamer struct {
+	prefix string
+	rules  []metricRenameRule
+}
+
+// metricRenameRule renames the metrics named exactly name, or matching pattern.
+type metricRenameRule struct {
+	name    string
+	pattern *regexp.Regexp
+	newName string
+}
+
+func newMetricNamer(prefix string, renames []MetricRenameSettings) (*metricNamer, error) {
+	if prefix == "" && len(renames) == 0 {
+		return nil, nil
+	}
+	n := &metricNamer{prefix: prefix}
+	for i, rename := range renames {
+		if rename.Name == "" {
+			return nil, fmt.Errorf("rule %d: name cannot be empty", i)
+		}
+		if rename.NewName == "" {
+			return nil, fmt.Errorf("rule %d: new_name cannot be empty", i)
+		}
+		rule := metricRenameRule{newName: rename.NewName}
+		switch rename.MatchType {
+		case "", matchTypeStrict:
+			rule.name = rename.Name
+		case matchTypeRegexp:
+			pattern, err := regexp.Compile(rename.Name)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q: %v", i, rename.Name, err)
+			}
+			rule.pattern = pattern
+		default:
+			return nil, fmt.Errorf("rule %d: unsupported match_type %q, must be %q or %q",
+				i, rename.MatchType, matchTypeStrict, matchTypeRegexp)
+		}
+		n.rules = append(n.rules, rule)
+	}
+	return n, nil
+}
+
+// metricName returns the name a metric is sent as: renamed by the first matching rule, if any, then prefixed.
+func (n *metricNamer) metricName(name string) string {
+	if n == nil {
+		return name
+	}
+	for _, rule := range n.rules {
+		if rule.pattern == nil {
+			if rule.name == name {
+				name = rule.newName
+				break
+			}
+			continue
+		}
+		if match := rule.pattern.FindStringSubmatchIndex(name); match != nil && match[0] == 0 && match[1] == len(name) {
+			// Only whole names are renamed, expanding the $1-style references of the new name.
+			name = string(rule.pattern.ExpandString(nil, rule.newName, name, match))
+			break
+		}
+	}
+	return n.prefix + name
+}
@@ -0,0 +1,69 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func TestMetricNamer(t *testing.T) {
+	n, err := newMetricNamer("", nil)
+	require.NoError(t, err)
+	assert.Nil(t, n)
+	assert.Equal(t, "system.cpu.time", n.metricName("system.cpu.time"))
+
+	n, err = newMetricNamer("otel.", []MetricRenameSettings{
+		{Name: "system.cpu.time", NewName: "cpu.seconds"},
+		{MatchType: matchTypeRegexp, Name: `system\.(cpu|memory)\.(.*)`, NewName: "${1}_$2"},
+		{MatchType: matchTypeRegexp, Name: `system\.memory\.usage`, NewName: "unused"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "otel.cpu.seconds", n.metricName("system.cpu.time"))
+	assert.Equal(t, "otel.cpu_utilization", n.metricName("system.cpu.utilization"))
+	assert.Equal(t, "otel.memory_usage", n.metricName("system.memory.usage"))
+	// Patterns match whole names only.
+	assert.Equal(t, "otel.host.system.cpu.time", n.metricName("host.system.cpu.time"))
+	assert.Equal(t, "otel.system.disk.io", n.metricName("system.disk.io"))
+}
+
+func TestMetricNamerInvalid(t *testing.T) {
+	_, err := newMetricNamer("", []MetricRenameSettings{{NewName: "b"}})
+	assert.EqualError(t, err, "rule 0: name cannot be empty")
+	_, err = newMetricNamer("", []MetricRenameSettings{{Name: "a"}})
+	assert.EqualError(t, err, "rule 0: new_name cannot be empty")
+	_, err = newMetricNamer("", []MetricRenameSettings{{MatchType: matchTypeRegexp, Name: "(", NewName: "b"}})
+	assert.EqualError(t, err, "rule 0: invalid pattern \"(\": error parsing regexp: missing closing ): `(`")
+	_, err = newMetricNamer("", []MetricRenameSettings{{MatchType: "glob", Name: "a", NewName: "b"}})
+	assert.EqualError(t, err, `rule 0: unsupported match_type "glob", must be "strict" or "regexp"`)
+}
+
+func TestRenamedMetricEvents(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.Token = "token"
+	config.Endpoint = "https://example.com:8088"
+	config.MetricPrefix = "otel."
+	config.MetricRenames = []MetricRenameSettings{{Name: "requests", NewName: "http.requests"}}
+	require.NoError(t, config.validateConfig())
+
+	events, supported := mapMetricToSplunkEvent(newResourceMetadata(pdata.NewResource(), config, "", zap.NewNop()), newCounter(10e9, 11e9, 5), zap.NewNop())
+	require.True(t, supported)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(5), events[0].Fields["metric_name:otel.http.requests"])
+}
@@ -17,9 +17,10 @@ package splunkhecexporter
 import (
 	"math"
 	"strconv"
+	"strings"
+	"text/template"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
-	"go.opentelemetry.io/collector/translator/conventions"
 	tracetranslator "go.opentelemetry.io/collector/translator/trace"
 	"go.uber.org/zap"
 
@@ -37,6 +38,8 @@ const (
 	sumSuffix = "_sum"
 	// bucketSuffix is the bucket metric value suffix.
 	bucketSuffix = "_bucket"
+	// quantileLabel is the label holding the quantile of summary metric values.
+	quantileLabel = "quantile"
 )
 
 func metricDataToSplunk(logger *zap.Logger, data pdata.Metrics, config *Config) ([]*splunk.Event, int) {
@@ -46,208 +49,388 @@ func metricDataToSplunk(logger *zap.Logger, data pdata.Metrics, config *Config)
 	rms := data.ResourceMetrics()
 	for i := 0; i < rms.Len(); i++ {
 		rm := rms.At(i)
-		host := unknownHostName
-		source := config.Source
-		sourceType := config.SourceType
-		index := config.Index
-		commonFields := map[string]interface{}{}
-		resource := rm.Resource()
-		attributes := resource.Attributes()
-		if conventionHost, isSet := attributes.Get(conventions.AttributeHostName); isSet {
-			host = conventionHost.StringVal()
-		}
-		if sourceSet, isSet := attributes.Get(conventions.AttributeServiceName); isSet {
-			source = sourceSet.StringVal()
+		meta := newMetricResourceMetadata(rm.Resource(), config, logger)
+		ilms := rm.InstrumentationLibraryMetrics()
+		for ilmi := 0; ilmi < ilms.Len(); ilmi++ {
+			metrics := ilms.At(ilmi).Metrics()
+			for tmi := 0; tmi < metrics.Len(); tmi++ {
+				events, supported := mapMetricToSplunkEvent(meta, metrics.At(tmi), logger)
+				if !supported {
+					numDroppedTimeSeries++
+					continue
+				}
+				splunkMetrics = append(splunkMetrics, events...)
+			}
 		}
-		if sourcetypeSet, isSet := attributes.Get(splunk.SourcetypeLabel); isSet {
-			sourceType = sourcetypeSet.StringVal()
+	}
+
+	return splunkMetrics, numDroppedTimeSeries
+}
+
+// splunkOverrides are the well-known attributes overriding the HEC metadata of events, e.g. set by upstream
+// processors making routing decisions. They take precedence over the hec_metadata_to_otel_attrs mapping.
+var splunkOverrides = splunk.HecToOtelAttrs{
+	Source:     splunk.SourceLabel,
+	SourceType: splunk.SourcetypeLabel,
+	Index:      splunk.IndexLabel,
+	Host:       splunk.HostLabel,
+}
+
+// isReservedLabel tells whether the attribute is one of the splunkOverrides, sent as HEC metadata rather than as a
+// field, or the access token passed through by SignalFx receivers, which must never be sent.
+func isReservedLabel(key string) bool {
+	switch key {
+	case splunk.SourceLabel, splunk.SourcetypeLabel, splunk.IndexLabel, splunk.HostLabel, splunk.SFxAccessTokenLabel:
+		return true
+	}
+	return false
+}
+
+// hecMetadata is the HEC metadata of an event.
+type hecMetadata struct {
+	host       string
+	source     string
+	sourceType string
+	index      string
+}
+
+// newHecMetadata returns the HEC metadata of events of the given index whose attributes are not mapped to any.
+func newHecMetadata(config *Config, index string) hecMetadata {
+	return hecMetadata{
+		host:       unknownHostName,
+		source:     config.Source,
+		sourceType: config.SourceType,
+		index:      index,
+	}
+}
+
+// update overrides the metadata mapped to the given attributes by hec_metadata_to_otel_attrs.
+func (m *hecMetadata) update(mapping splunk.HecToOtelAttrs, attributes pdata.AttributeMap) {
+	lookup := func(key string, field *string) {
+		if key == "" {
+			return
 		}
-		if indexSet, isSet := attributes.Get(splunk.IndexLabel); isSet {
-			index = indexSet.StringVal()
+		if v, ok := attributes.Get(key); ok {
+			*field = tracetranslator.AttributeValueToString(v, false)
 		}
-		attributes.ForEach(func(k string, v pdata.AttributeValue) {
-			commonFields[k] = tracetranslator.AttributeValueToString(v, false)
-		})
+	}
+	lookup(mapping.Host, &m.host)
+	lookup(mapping.Source, &m.source)
+	lookup(mapping.SourceType, &m.sourceType)
+	lookup(mapping.Index, &m.index)
+}
 
-		rm.Resource().Attributes().ForEach(func(k string, v pdata.AttributeValue) {
-			commonFields[k] = tracetranslator.AttributeValueToString(v, false)
+// render sets the source and sourcetype rendered from their templates, if any, with the given attributes. Later
+// attributes take precedence. A value whose template fails to render is left empty, and the error is logged.
+func (m *hecMetadata) render(config *Config, logger *zap.Logger, attributes ...pdata.AttributeMap) {
+	if config.sourceTemplate == nil && config.sourceTypeTemplate == nil {
+		return
+	}
+	data := map[string]string{}
+	for _, attrs := range attributes {
+		attrs.ForEach(func(k string, v pdata.AttributeValue) {
+			data[strings.ReplaceAll(k, ".", "_")] = tracetranslator.AttributeValueToString(v, false)
 		})
-		ilms := rm.InstrumentationLibraryMetrics()
-		for ilmi := 0; ilmi < ilms.Len(); ilmi++ {
-			ilm := ilms.At(ilmi)
-			metrics := ilm.Metrics()
-			for tmi := 0; tmi < metrics.Len(); tmi++ {
-				tm := metrics.At(tmi)
-				metricFieldName := splunkMetricValue + ":" + tm.Name()
-				switch tm.DataType() {
-				case pdata.MetricDataTypeIntGauge:
-					pts := tm.IntGauge().DataPoints()
-					for gi := 0; gi < pts.Len(); gi++ {
-						dataPt := pts.At(gi)
-						fields := cloneMap(commonFields)
-						populateLabels(fields, dataPt.LabelsMap())
-						fields[metricFieldName] = dataPt.Value()
+	}
+	if config.sourceTemplate != nil {
+		m.source = renderMetadataTemplate(config.sourceTemplate, data, logger)
+	}
+	if config.sourceTypeTemplate != nil {
+		m.sourceType = renderMetadataTemplate(config.sourceTypeTemplate, data, logger)
+	}
+}
+
+func renderMetadataTemplate(tmpl *template.Template, data map[string]string, logger *zap.Logger) string {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		logger.Debug("Failed to render HEC metadata template", zap.String("template", tmpl.Name()), zap.Error(err))
+		return ""
+	}
+	return b.String()
+}
 
-						sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-						splunkMetrics = append(splunkMetrics, sm)
-					}
-				case pdata.MetricDataTypeDoubleGauge:
-					pts := tm.DoubleGauge().DataPoints()
-					for gi := 0; gi < pts.Len(); gi++ {
-						dataPt := pts.At(gi)
-						fields := cloneMap(commonFields)
-						populateLabels(fields, dataPt.LabelsMap())
-						fields[metricFieldName] = dataPt.Value()
-						sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-						splunkMetrics = append(splunkMetrics, sm)
-					}
-				case pdata.MetricDataTypeDoubleHistogram:
-					pts := tm.DoubleHistogram().DataPoints()
-					for gi := 0; gi < pts.Len(); gi++ {
-						dataPt := pts.At(gi)
-						bounds := dataPt.ExplicitBounds()
-						counts := dataPt.BucketCounts()
-						// first, add one event for sum, and one for count
-						{
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields[metricFieldName+sumSuffix] = dataPt.Sum()
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-						{
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields[metricFieldName+countSuffix] = dataPt.Count()
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-						// Spec says counts is optional but if present it must have one more
-						// element than the bounds array.
-						if len(counts) == 0 || len(counts) != len(bounds)+1 {
-							continue
-						}
-						value := uint64(0)
-						// now create buckets for each bound.
-						for bi := 0; bi < len(bounds); bi++ {
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields["le"] = float64ToDimValue(bounds[bi])
-							value += counts[bi]
-							fields[metricFieldName+bucketSuffix] = value
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-						// add an upper bound for +Inf
-						{
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields["le"] = float64ToDimValue(math.Inf(1))
-							fields[metricFieldName+bucketSuffix] = value + counts[len(counts)-1]
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-					}
-				case pdata.MetricDataTypeIntHistogram:
-					pts := tm.IntHistogram().DataPoints()
-					for gi := 0; gi < pts.Len(); gi++ {
-						dataPt := pts.At(gi)
-						bounds := dataPt.ExplicitBounds()
-						counts := dataPt.BucketCounts()
-						// first, add one event for sum, and one for count
-						{
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields[metricFieldName+sumSuffix] = dataPt.Sum()
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-						{
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields[metricFieldName+countSuffix] = dataPt.Count()
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-						// Spec says counts is optional but if present it must have one more
-						// element than the bounds array.
-						if len(counts) == 0 || len(counts) != len(bounds)+1 {
-							continue
-						}
-						value := uint64(0)
-						// now create buckets for each bound.
-						for bi := 0; bi < len(bounds); bi++ {
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields["le"] = float64ToDimValue(bounds[bi])
-							value += counts[bi]
-							fields[metricFieldName+bucketSuffix] = value
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-						// add an upper bound for +Inf
-						{
-							fields := cloneMap(commonFields)
-							populateLabels(fields, dataPt.LabelsMap())
-							fields["le"] = float64ToDimValue(math.Inf(1))
-							fields[metricFieldName+bucketSuffix] = value + counts[len(counts)-1]
-							sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-							splunkMetrics = append(splunkMetrics, sm)
-						}
-					}
-				case pdata.MetricDataTypeDoubleSum:
-					pts := tm.DoubleSum().DataPoints()
-					for gi := 0; gi < pts.Len(); gi++ {
-						dataPt := pts.At(gi)
-						fields := cloneMap(commonFields)
-						populateLabels(fields, dataPt.LabelsMap())
-						fields[metricFieldName] = dataPt.Value()
+// resourceMetadata holds the event metadata and fields derived from a resource, shared by all its events.
+type resourceMetadata struct {
+	hecMetadata
+	fields map[string]interface{}
+	// filter decides which labels and attributes of the events are sent.
+	filter *attributeFilter
+	// timestampPrecision is the precision of the time of the events.
+	timestampPrecision string
+	// exemplars defines the fields exemplars of histogram buckets are sent as.
+	exemplars ExemplarsSettings
+	// namer renames metrics.
+	namer *metricNamer
+	// dimensions maps the dimensions of metric events.
+	dimensions *dimensionMapper
+	// unitField is the field holding the unit of metrics, if any.
+	unitField string
+	// spanIndexRoute is the value of the span_index_routing attribute on the resource, if any.
+	spanIndexRoute string
+}
 
-						sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-						splunkMetrics = append(splunkMetrics, sm)
-					}
-				case pdata.MetricDataTypeIntSum:
-					pts := tm.IntSum().DataPoints()
-					for gi := 0; gi < pts.Len(); gi++ {
-						dataPt := pts.At(gi)
-						fields := cloneMap(commonFields)
-						populateLabels(fields, dataPt.LabelsMap())
-						fields[metricFieldName] = dataPt.Value()
+func newResourceMetadata(resource pdata.Resource, config *Config, index string, logger *zap.Logger) resourceMetadata {
+	meta := resourceMetadata{
+		hecMetadata: newHecMetadata(config, index),
+		fields:      map[string]interface{}{},
+		filter:      config.attributeFilter,
 
-						sm := createEvent(dataPt.Timestamp(), host, source, sourceType, index, fields)
-						splunkMetrics = append(splunkMetrics, sm)
-					}
-				case pdata.MetricDataTypeNone:
-					fallthrough
-				default:
-					logger.Warn(
-						"Point with unsupported type",
-						zap.Any("metric", rm))
-					numDroppedTimeSeries++
-				}
+		timestampPrecision: config.timestampPrecision(),
+		exemplars:          config.Exemplars,
+		namer:              config.metricNamer,
+		dimensions:         config.dimensionMapper,
+		unitField:          config.MetricUnitField,
+	}
+	attributes := resource.Attributes()
+	meta.render(config, logger, attributes)
+	meta.update(config.metadataMapping(), attributes)
+	meta.update(splunkOverrides, attributes)
+	attributes.ForEach(func(k string, v pdata.AttributeValue) {
+		if !isReservedLabel(k) && meta.filter.keepAttribute(k, v) {
+			meta.fields[k] = tracetranslator.AttributeValueToString(v, false)
+		}
+	})
+	if key := config.SpanIndexRouting.Attribute; key != "" {
+		if v, ok := attributes.Get(key); ok {
+			meta.spanIndexRoute = tracetranslator.AttributeValueToString(v, false)
+		}
+	}
+	return meta
+}
+
+// newMetricResourceMetadata returns the metadata of the metric events of a resource, whose fields only hold the resource
+// attributes sent as dimensions.
+func newMetricResourceMetadata(resource pdata.Resource, config *Config, logger *zap.Logger) resourceMetadata {
+	meta := newResourceMetadata(resource, config, config.signalIndex(config.MetricsIndex), logger)
+	switch {
+	case !config.IncludeResourceAttributes:
+		meta.fields = map[string]interface{}{}
+	case len(config.ResourceAttributesAllowList) > 0:
+		fields := make(map[string]interface{}, len(config.ResourceAttributesAllowList))
+		for _, k := range config.ResourceAttributesAllowList {
+			if v, ok := meta.fields[k]; ok {
+				fields[k] = v
 			}
 		}
+		meta.fields = fields
 	}
+	return meta
+}
 
-	return splunkMetrics, numDroppedTimeSeries
+// mapMetricToSplunkEvent returns the events of all data points of the metric, and false if its type is not supported.
+func mapMetricToSplunkEvent(meta resourceMetadata, tm pdata.Metric, logger *zap.Logger) ([]*splunk.Event, bool) {
+	var splunkMetrics []*splunk.Event
+	metricFieldName := splunkMetricValue + ":" + meta.namer.metricName(tm.Name())
+	if meta.unitField != "" && tm.Unit() != "" {
+		meta.fields = cloneMap(meta.fields)
+		meta.fields[meta.unitField] = tm.Unit()
+	}
+	switch tm.DataType() {
+	case pdata.MetricDataTypeIntGauge:
+		pts := tm.IntGauge().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			fields := cloneMap(meta.fields)
+			populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+			fields[metricFieldName] = dataPt.Value()
+
+			sm := createEvent(dataPt.Timestamp(), meta, fields)
+			splunkMetrics = append(splunkMetrics, sm)
+		}
+	case pdata.MetricDataTypeDoubleGauge:
+		pts := tm.DoubleGauge().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			fields := cloneMap(meta.fields)
+			populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+			fields[metricFieldName] = dataPt.Value()
+			sm := createEvent(dataPt.Timestamp(), meta, fields)
+			splunkMetrics = append(splunkMetrics, sm)
+		}
+	case pdata.MetricDataTypeDoubleHistogram:
+		pts := tm.DoubleHistogram().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			bounds := dataPt.ExplicitBounds()
+			counts := dataPt.BucketCounts()
+			exemplars := meta.bucketExemplars(bounds, doubleExemplars(dataPt.Exemplars()))
+			// first, add one event for sum, and one for count
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[metricFieldName+sumSuffix] = dataPt.Sum()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[metricFieldName+countSuffix] = dataPt.Count()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			// Spec says counts is optional but if present it must have one more
+			// element than the bounds array.
+			if len(counts) == 0 || len(counts) != len(bounds)+1 {
+				continue
+			}
+			value := uint64(0)
+			// now create buckets for each bound.
+			for bi := 0; bi < len(bounds); bi++ {
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields["le"] = float64ToDimValue(bounds[bi])
+				meta.addExemplar(fields, exemplars, bi)
+				value += counts[bi]
+				fields[metricFieldName+bucketSuffix] = value
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			// add an upper bound for +Inf
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields["le"] = float64ToDimValue(math.Inf(1))
+				meta.addExemplar(fields, exemplars, len(bounds))
+				fields[metricFieldName+bucketSuffix] = value + counts[len(counts)-1]
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+		}
+	case pdata.MetricDataTypeIntHistogram:
+		pts := tm.IntHistogram().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			bounds := dataPt.ExplicitBounds()
+			counts := dataPt.BucketCounts()
+			exemplars := meta.bucketExemplars(bounds, intExemplars(dataPt.Exemplars()))
+			// first, add one event for sum, and one for count
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[metricFieldName+sumSuffix] = dataPt.Sum()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[metricFieldName+countSuffix] = dataPt.Count()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			// Spec says counts is optional but if present it must have one more
+			// element than the bounds array.
+			if len(counts) == 0 || len(counts) != len(bounds)+1 {
+				continue
+			}
+			value := uint64(0)
+			// now create buckets for each bound.
+			for bi := 0; bi < len(bounds); bi++ {
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields["le"] = float64ToDimValue(bounds[bi])
+				meta.addExemplar(fields, exemplars, bi)
+				value += counts[bi]
+				fields[metricFieldName+bucketSuffix] = value
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			// add an upper bound for +Inf
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields["le"] = float64ToDimValue(math.Inf(1))
+				meta.addExemplar(fields, exemplars, len(bounds))
+				fields[metricFieldName+bucketSuffix] = value + counts[len(counts)-1]
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+		}
+	case pdata.MetricDataTypeDoubleSummary:
+		pts := tm.DoubleSummary().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			// first, add one event for sum, and one for count
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[metricFieldName+sumSuffix] = dataPt.Sum()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			{
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[metricFieldName+countSuffix] = dataPt.Count()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+			// now create an event for each quantile.
+			quantiles := dataPt.QuantileValues()
+			for qi := 0; qi < quantiles.Len(); qi++ {
+				quantile := quantiles.At(qi)
+				fields := cloneMap(meta.fields)
+				populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+				fields[quantileLabel] = float64ToDimValue(quantile.Quantile())
+				fields[metricFieldName] = quantile.Value()
+				sm := createEvent(dataPt.Timestamp(), meta, fields)
+				splunkMetrics = append(splunkMetrics, sm)
+			}
+		}
+	case pdata.MetricDataTypeDoubleSum:
+		pts := tm.DoubleSum().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			fields := cloneMap(meta.fields)
+			populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+			fields[metricFieldName] = dataPt.Value()
+
+			sm := createEvent(dataPt.Timestamp(), meta, fields)
+			splunkMetrics = append(splunkMetrics, sm)
+		}
+	case pdata.MetricDataTypeIntSum:
+		pts := tm.IntSum().DataPoints()
+		for gi := 0; gi < pts.Len(); gi++ {
+			dataPt := pts.At(gi)
+			fields := cloneMap(meta.fields)
+			populateLabels(fields, dataPt.LabelsMap(), meta.filter)
+			fields[metricFieldName] = dataPt.Value()
+
+			sm := createEvent(dataPt.Timestamp(), meta, fields)
+			splunkMetrics = append(splunkMetrics, sm)
+		}
+	case pdata.MetricDataTypeNone:
+		fallthrough
+	default:
+		logger.Warn(
+			"Point with unsupported type",
+			zap.Any("metric", tm))
+		return nil, false
+	}
+	return splunkMetrics, true
 }
 
-func createEvent(timestamp pdata.Timestamp, host string, source string, sourceType string, index string, fields map[string]interface{}) *splunk.Event {
+func createEvent(timestamp pdata.Timestamp, meta resourceMetadata, fields map[string]interface{}) *splunk.Event {
+	meta.dimensions.apply(fields)
 	return &splunk.Event{
-		Time:       timestampToSecondsWithMillisecondPrecision(timestamp),
-		Host:       host,
-		Source:     source,
-		SourceType: sourceType,
-		Index:      index,
+		Time:       timestampToEpochSeconds(timestamp, meta.timestampPrecision),
+		TimeNanos:  timestampNanos(timestamp, meta.timestampPrecision),
+		Host:       meta.host,
+		Source:     meta.source,
+		SourceType: meta.sourceType,
+		Index:      meta.index,
 		Event:      splunk.HecEventMetricType,
 		Fields:     fields,
 	}
 
 }
 
-func populateLabels(fields map[string]interface{}, labelsMap pdata.StringMap) {
+func populateLabels(fields map[string]interface{}, labelsMap pdata.StringMap, filter *attributeFilter) {
 	labelsMap.ForEach(func(k string, v string) {
-		fields[k] = v
+		if filter.keepLabel(k, v) {
+			fields[k] = v
+		}
 	})
 }
 
@@ -259,7 +442,12 @@ func cloneMap(fields map[string]interface{}) map[string]interface{} {
 	return newFields
 }
 
-func timestampToSecondsWithMillisecondPrecision(ts pdata.Timestamp) *float64 {
+// timestampToEpochSeconds transforms nanoseconds into seconds since epoch with the given precision. For example,
+// 1433188255.500 indicates 1433188255 seconds and 500 milliseconds after epoch. Whole seconds are truncated, while
+// milliseconds and microseconds are rounded. The spacing of float64 values being below a microsecond until 2242,
+// microseconds are serialized exactly. Nanoseconds are approximated, their exact value being sent from
+// timestampNanos.
+func timestampToEpochSeconds(ts pdata.Timestamp, precision string) *float64 {
 	if ts == 0 {
 		// some telemetry sources send data with timestamps set to 0 by design, as their original target destinations
 		// (i.e. before Open Telemetry) are setup with the know-how on how to consume them. In this case,
@@ -268,11 +456,27 @@ func timestampToSecondsWithMillisecondPrecision(ts pdata.Timestamp) *float64 {
 		return nil
 	}
 
-	val := math.Round(float64(ts)/1e6) / 1e3
-
+	var val float64
+	switch precision {
+	case timestampPrecisionSecond:
+		val = float64(ts / 1e9)
+	case timestampPrecisionMicrosecond:
+		// Rounding the integer nanoseconds, which exceed the precision of a float64.
+		val = float64((ts+500)/1e3) / 1e6
+	default:
+		val = math.Round(float64(ts)/1e6) / 1e3
+	}
 	return &val
 }
 
+// timestampNanos returns the exact time of events sent with nanosecond precision, or 0 for other precisions.
+func timestampNanos(ts pdata.Timestamp, precision string) uint64 {
+	if precision != timestampPrecisionNanosecond {
+		return 0
+	}
+	return uint64(ts)
+}
+
 func float64ToDimValue(f float64) string {
 	return strconv.FormatFloat(f, 'g', -1, 64)
 }
@@ -20,8 +20,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
@@ -33,7 +35,7 @@ func Test_metricDataToSplunk(t *testing.T) {
 	unixNSecs := int64(11 * time.Millisecond)
 	tsUnix := time.Unix(unixSecs, unixNSecs)
 	ts := pdata.TimestampFromTime(tsUnix)
-	tsMSecs := timestampToSecondsWithMillisecondPrecision(ts)
+	tsMSecs := timestampToEpochSeconds(ts, timestampPrecisionMillisecond)
 
 	doubleVal := 1234.5678
 	int64Val := int64(123)
@@ -258,8 +260,8 @@ func Test_metricDataToSplunk(t *testing.T) {
 				return metrics
 			},
 			wantSplunkMetrics: []*splunk.Event{
-				commonSplunkMetric("gauge_double_with_dims", tsMSecs, []string{"com.splunk.index", "com.splunk.sourcetype", "host.name", "service.name", "k0", "k1"}, []interface{}{"myindex", "mysourcetype", "myhost", "mysource", "v0", "v1"}, doubleVal, "mysource", "mysourcetype", "myindex", "myhost"),
-				commonSplunkMetric("gauge_int_with_dims", tsMSecs, []string{"com.splunk.index", "com.splunk.sourcetype", "host.name", "service.name", "k0", "k1"}, []interface{}{"myindex", "mysourcetype", "myhost", "mysource", "v0", "v1"}, int64Val, "mysource", "mysourcetype", "myindex", "myhost"),
+				commonSplunkMetric("gauge_double_with_dims", tsMSecs, []string{"host.name", "service.name", "k0", "k1"}, []interface{}{"myhost", "mysource", "v0", "v1"}, doubleVal, "mysource", "mysourcetype", "myindex", "myhost"),
+				commonSplunkMetric("gauge_int_with_dims", tsMSecs, []string{"host.name", "service.name", "k0", "k1"}, []interface{}{"myhost", "mysource", "v0", "v1"}, int64Val, "mysource", "mysourcetype", "myindex", "myhost"),
 			},
 		},
 
@@ -496,6 +498,54 @@ func Test_metricDataToSplunk(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "double_summary",
+			metricsDataFn: func() pdata.Metrics {
+				metrics := newMetricsWithResources()
+				ilm := metrics.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0)
+				ilm.Metrics().Resize(1)
+				summary := ilm.Metrics().At(0)
+				summary.SetName("summary_with_dims")
+				summary.SetDataType(pdata.MetricDataTypeDoubleSummary)
+				summary.DoubleSummary().DataPoints().Resize(1)
+				summaryPt := summary.DoubleSummary().DataPoints().At(0)
+				summaryPt.SetCount(7)
+				summaryPt.SetSum(23)
+				summaryPt.QuantileValues().Resize(2)
+				summaryPt.QuantileValues().At(0).SetQuantile(0.5)
+				summaryPt.QuantileValues().At(0).SetValue(2)
+				summaryPt.QuantileValues().At(1).SetQuantile(0.99)
+				summaryPt.QuantileValues().At(1).SetValue(6.5)
+				summaryPt.SetTimestamp(pdata.TimestampFromTime(tsUnix))
+				return metrics
+			},
+			wantSplunkMetrics: []*splunk.Event{
+				{
+					Host:   "unknown",
+					Event:  "metric",
+					Time:   tsMSecs,
+					Fields: map[string]interface{}{"k0": "v0", "k1": "v1", "metric_name:summary_with_dims_sum": float64(23)},
+				},
+				{
+					Host:   "unknown",
+					Event:  "metric",
+					Time:   tsMSecs,
+					Fields: map[string]interface{}{"k0": "v0", "k1": "v1", "metric_name:summary_with_dims_count": uint64(7)},
+				},
+				{
+					Host:   "unknown",
+					Event:  "metric",
+					Time:   tsMSecs,
+					Fields: map[string]interface{}{"k0": "v0", "k1": "v1", "quantile": "0.5", "metric_name:summary_with_dims": float64(2)},
+				},
+				{
+					Host:   "unknown",
+					Event:  "metric",
+					Time:   tsMSecs,
+					Fields: map[string]interface{}{"k0": "v0", "k1": "v1", "quantile": "0.99", "metric_name:summary_with_dims": 6.5},
+				},
+			},
+		},
 		{
 			name: "int_sum",
 			metricsDataFn: func() pdata.Metrics {
@@ -573,7 +623,7 @@ func Test_metricDataToSplunk(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			md := tt.metricsDataFn()
-			gotMetrics, gotNumDroppedTimeSeries := metricDataToSplunk(logger, md, &Config{})
+			gotMetrics, gotNumDroppedTimeSeries := metricDataToSplunk(logger, md, createDefaultConfig().(*Config))
 			assert.Equal(t, tt.wantNumDroppedTimeseries, gotNumDroppedTimeSeries)
 			for i, want := range tt.wantSplunkMetrics {
 				assert.Equal(t, want, gotMetrics[i])
@@ -610,24 +660,133 @@ func commonSplunkMetric(
 	}
 }
 
+func TestNewResourceMetadataTemplates(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.Token = "token"
+	config.Endpoint = "https://example.com:8088"
+	config.SourceType = "otel:{{.k8s_namespace_name}}"
+	require.NoError(t, config.validateConfig())
+
+	resource := pdata.NewResource()
+	resource.Attributes().InsertString("k8s.namespace.name", "default")
+	meta := newResourceMetadata(resource, config, "", zap.NewNop())
+	assert.Equal(t, "otel:default", meta.sourceType)
+
+	// The template takes precedence over the attribute mapped to the sourcetype.
+	config.HecToOtelAttrs.SourceType = "com.splunk.sourcetype.mapped"
+	resource.Attributes().InsertString("com.splunk.sourcetype.mapped", "mapped")
+	meta = newResourceMetadata(resource, config, "", zap.NewNop())
+	assert.Equal(t, "otel:default", meta.sourceType)
+
+	// Templates failing to render are logged.
+	config.Source = "{{index .k8s_namespace_name 10}}"
+	require.NoError(t, config.validateConfig())
+	core, logs := observer.New(zap.DebugLevel)
+	meta = newResourceMetadata(resource, config, "", zap.New(core))
+	assert.Equal(t, "", meta.source)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "Failed to render HEC metadata template", logs.All()[0].Message)
+}
+
+func TestMetricUnitField(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MetricUnitField = "metric_unit"
+	resource := pdata.NewResource()
+	resource.Attributes().InsertString("k0", "v0")
+	meta := newResourceMetadata(resource, config, "", zap.NewNop())
+
+	tm := pdata.NewMetric()
+	tm.SetName("system.cpu.time")
+	tm.SetUnit("s")
+	tm.SetDataType(pdata.MetricDataTypeIntGauge)
+	tm.IntGauge().DataPoints().Resize(1)
+	tm.IntGauge().DataPoints().At(0).SetValue(1)
+	events, ok := mapMetricToSplunkEvent(meta, tm, zap.NewNop())
+	require.True(t, ok)
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]interface{}{"k0": "v0", "metric_unit": "s", "metric_name:system.cpu.time": int64(1)}, events[0].Fields)
+	// The fields shared by the events of the resource are left unchanged.
+	assert.Equal(t, map[string]interface{}{"k0": "v0"}, meta.fields)
+
+	tm.SetUnit("")
+	events, ok = mapMetricToSplunkEvent(meta, tm, zap.NewNop())
+	require.True(t, ok)
+	assert.NotContains(t, events[0].Fields, "metric_unit")
+}
+
+func TestNewMetricResourceMetadata(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	resource := pdata.NewResource()
+	resource.Attributes().InsertString("k8s.pod.name", "pod0")
+	resource.Attributes().InsertString("k8s.pod.uid", "uid0")
+	resource.Attributes().InsertString("host.name", "host0")
+
+	assert.Equal(t, map[string]interface{}{"k8s.pod.name": "pod0", "k8s.pod.uid": "uid0", "host.name": "host0"},
+		newMetricResourceMetadata(resource, config, zap.NewNop()).fields)
+
+	config.ResourceAttributesAllowList = []string{"k8s.pod.name", "k8s.namespace.name"}
+	assert.Equal(t, map[string]interface{}{"k8s.pod.name": "pod0"}, newMetricResourceMetadata(resource, config, zap.NewNop()).fields)
+
+	config.IncludeResourceAttributes = false
+	meta := newMetricResourceMetadata(resource, config, zap.NewNop())
+	assert.Empty(t, meta.fields)
+	// Resource attributes still set the HEC metadata.
+	assert.Equal(t, "host0", meta.host)
+}
+
 func TestTimestampFormat(t *testing.T) {
 	ts := pdata.Timestamp(32001000345)
-	assert.Equal(t, 32.001, *timestampToSecondsWithMillisecondPrecision(ts))
+	assert.Equal(t, 32.001, *timestampToEpochSeconds(ts, timestampPrecisionMillisecond))
 }
 
 func TestTimestampFormatRounding(t *testing.T) {
 	ts := pdata.Timestamp(32001999345)
-	assert.Equal(t, 32.002, *timestampToSecondsWithMillisecondPrecision(ts))
+	assert.Equal(t, 32.002, *timestampToEpochSeconds(ts, timestampPrecisionMillisecond))
 }
 
 func TestTimestampFormatRoundingWithNanos(t *testing.T) {
 	ts := pdata.Timestamp(9999999999991500001)
-	assert.Equal(t, 9999999999.992, *timestampToSecondsWithMillisecondPrecision(ts))
+	assert.Equal(t, 9999999999.992, *timestampToEpochSeconds(ts, timestampPrecisionMillisecond))
+}
+
+func TestTimestampFormatPrecision(t *testing.T) {
+	ts := pdata.Timestamp(1433188255999123456)
+	assert.Equal(t, 1433188255.0, *timestampToEpochSeconds(ts, timestampPrecisionSecond))
+	assert.Equal(t, 1433188255.999, *timestampToEpochSeconds(ts, timestampPrecisionMillisecond))
+	assert.Equal(t, 1433188255.999123, *timestampToEpochSeconds(ts, timestampPrecisionMicrosecond))
+
+	// Microseconds are serialized exactly.
+	b, err := appendEvent(nil, &splunk.Event{Time: timestampToEpochSeconds(ts, timestampPrecisionMicrosecond)})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"time":1433188255.999123,`)
+	b, err = appendEvent(nil, &splunk.Event{Time: timestampToEpochSeconds(pdata.Timestamp(4102444799999999500), timestampPrecisionMicrosecond)})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"time":4102444800,`)
+	b, err = appendEvent(nil, &splunk.Event{Time: timestampToEpochSeconds(pdata.Timestamp(4102444799000001000), timestampPrecisionMicrosecond)})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"time":4102444799.000001,`)
+
+	// Nanoseconds are serialized exactly from the integer timestamp.
+	assert.Equal(t, uint64(0), timestampNanos(ts, timestampPrecisionMicrosecond))
+	for nanos, want := range map[uint64]string{
+		1433188255999123456: "1433188255.999123456",
+		1433188255000000050: "1433188255.000000050",
+		1433188255000000000: "1433188255.000000000",
+		999:                 "0.000000999",
+	} {
+		b, err = appendEvent(nil, &splunk.Event{
+			Time:      timestampToEpochSeconds(pdata.Timestamp(nanos), timestampPrecisionNanosecond),
+			TimeNanos: timestampNanos(pdata.Timestamp(nanos), timestampPrecisionNanosecond),
+		})
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"time":`+want+`,`)
+	}
 }
 
 func TestNilTimeWhenTimestampIsZero(t *testing.T) {
 	ts := pdata.Timestamp(0)
-	assert.Nil(t, timestampToSecondsWithMillisecondPrecision(ts))
+	assert.Nil(t, timestampToEpochSeconds(ts, timestampPrecisionMillisecond))
+	assert.Nil(t, timestampToEpochSeconds(ts, timestampPrecisionSecond))
 }
 
 func newMetricsWithResources() pdata.Metrics {
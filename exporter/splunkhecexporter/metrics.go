@@ -0,0 +1,145 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+var (
+	tagKeyExporter = tag.MustNewKey(obsreport.ExporterKey)
+	tagKeySignal   = tag.MustNewKey("signal")
+	tagKeyReason   = tag.MustNewKey("reason")
+	tagKeyAttempt  = tag.MustNewKey("attempt")
+
+	mDroppedRecords = stats.Int64(
+		"exporter/splunkhec/dropped_records",
+		"Number of records dropped by the exporter, by signal and reason",
+		stats.UnitDimensionless)
+	mSentEvents = stats.Int64(
+		"exporter/splunkhec/sent_events",
+		"Number of events accepted by Splunk HEC, by signal and attempt, first or retry",
+		stats.UnitDimensionless)
+	mEventLatency = stats.Float64(
+		"exporter/splunkhec/event_latency",
+		"Time between the timestamp of records and their successful sending, by signal",
+		stats.UnitMilliseconds)
+
+	// eventLatencyDistribution has exponential bucket bounds, from 10 milliseconds to about a day. It is created once,
+	// as views are only registered again, e.g. by each factory, if their aggregation is the same.
+	eventLatencyDistribution = view.Distribution(exponentialBounds(10, 2, 24)...)
+)
+
+// MetricViews returns the metrics views of the exporter.
+func MetricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mDroppedRecords.Name(),
+			Measure:     mDroppedRecords,
+			Description: mDroppedRecords.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyExporter, tagKeySignal, tagKeyReason},
+		},
+		{
+			Name:        mSentEvents.Name(),
+			Measure:     mSentEvents,
+			Description: mSentEvents.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyExporter, tagKeySignal, tagKeyAttempt},
+		},
+		{
+			Name:        mEventLatency.Name(),
+			Measure:     mEventLatency,
+			Description: mEventLatency.Description(),
+			Aggregation: eventLatencyDistribution,
+			TagKeys:     []tag.Key{tagKeyExporter, tagKeySignal},
+		},
+	}
+}
+
+// exponentialBounds returns count bucket bounds, starting at start and growing by factor.
+func exponentialBounds(start float64, factor float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+func recordDroppedRecords(ctx context.Context, exporterName string, signal string, reason string, count int) {
+	if count == 0 {
+		return
+	}
+	_ = stats.RecordWithTags(
+		ctx,
+		[]tag.Mutator{
+			tag.Upsert(tagKeyExporter, exporterName),
+			tag.Upsert(tagKeySignal, signal),
+			tag.Upsert(tagKeyReason, reason),
+		},
+		mDroppedRecords.M(int64(count)))
+}
+
+func recordSentEvents(ctx context.Context, exporterName string, signal string, attempt string, count int) {
+	if count == 0 {
+		return
+	}
+	_ = stats.RecordWithTags(
+		ctx,
+		[]tag.Mutator{
+			tag.Upsert(tagKeyExporter, exporterName),
+			tag.Upsert(tagKeySignal, signal),
+			tag.Upsert(tagKeyAttempt, attempt),
+		},
+		mSentEvents.M(int64(count)))
+}
+
+// recordEventLatencies records the time elapsed between the given times, in seconds since epoch, and now. Times in
+// the future, e.g. because of clock skew, count as no latency.
+func recordEventLatencies(ctx context.Context, exporterName string, signal string, now time.Time, times []float64) {
+	if len(times) == 0 {
+		return
+	}
+	nowSeconds := float64(now.UnixNano()) / 1e9
+	measurements := make([]stats.Measurement, 0, len(times))
+	for _, t := range times {
+		if math.IsNaN(t) {
+			continue
+		}
+		latency := (nowSeconds - t) * 1e3
+		if latency < 0 {
+			latency = 0
+		}
+		measurements = append(measurements, mEventLatency.M(latency))
+	}
+	if len(measurements) == 0 {
+		return
+	}
+	_ = stats.RecordWithTags(
+		ctx,
+		[]tag.Mutator{
+			tag.Upsert(tagKeyExporter, exporterName),
+			tag.Upsert(tagKeySignal, signal),
+		},
+		measurements...)
+}
@@ -0,0 +1,221 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+func TestDroppedRecordsMetric(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.NameVal = "splunk_hec/drops"
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	md := createMetricsData(1)
+	metrics := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	metrics.Resize(metrics.Len() + 1)
+	metrics.At(metrics.Len() - 1).SetName("none")
+
+	// Records failing to be sent are retried.
+	require.Error(t, c.pushMetricsData(context.Background(), md))
+	// Unless retries are disabled.
+	config.RetrySettings.Enabled = false
+	require.Error(t, c.pushMetricsData(context.Background(), md))
+
+	rows, err := view.RetrieveData(mDroppedRecords.Name())
+	require.NoError(t, err)
+	got := map[string]float64{}
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tg := range row.Tags {
+			tags[tg.Key.Name()] = tg.Value
+		}
+		if tags[tagKeyExporter.Name()] == "splunk_hec/drops" {
+			got[tags[tagKeySignal.Name()]+"/"+tags[tagKeyReason.Name()]] = row.Data.(*view.SumData).Value
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"metrics/" + dropReasonUnsupportedMetricType: 2,
+		"metrics/" + dropReasonHTTPError:             float64(md.MetricCount() - 1),
+	}, got)
+}
+
+func TestEventLatencyMetric(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.NameVal = "splunk_hec/latency"
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	ld := createLogData(2)
+	logs := ld.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs()
+	logs.At(0).SetTimestamp(pdata.TimestampFromTime(time.Now().Add(-time.Minute)))
+	// Records from the future count as no latency.
+	logs.At(1).SetTimestamp(pdata.TimestampFromTime(time.Now().Add(time.Hour)))
+	require.NoError(t, c.pushLogData(context.Background(), ld))
+
+	rows, err := view.RetrieveData(mEventLatency.Name())
+	require.NoError(t, err)
+	var data *view.DistributionData
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tg := range row.Tags {
+			tags[tg.Key.Name()] = tg.Value
+		}
+		if tags[tagKeyExporter.Name()] == "splunk_hec/latency" {
+			assert.Equal(t, "logs", tags[tagKeySignal.Name()])
+			data = row.Data.(*view.DistributionData)
+		}
+	}
+	require.NotNil(t, data)
+	assert.EqualValues(t, 2, data.Count)
+	assert.Equal(t, 0.0, data.Min)
+	assert.InDelta(t, 60_000, data.Max, 5_000)
+}
+
+func TestSentEventsMetric(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.NameVal = "splunk_hec/sent"
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	// The records of the failed request are counted as retried once the batch of the error is pushed again, as by the
+	// exporter helper.
+	ld := createLogData(3)
+	err = c.pushLogData(context.Background(), ld)
+	require.Error(t, err)
+	if partial, ok := err.(consumererror.PartialError); ok {
+		ld = partial.GetLogs()
+	}
+	require.NoError(t, c.pushLogData(context.Background(), ld))
+	// They are only counted as retried once, and the same records pushed in another batch are sent for the first time.
+	require.NoError(t, c.pushLogData(context.Background(), ld))
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(3)))
+	require.NoError(t, c.pushTraceData(context.Background(), createTraceData(2)))
+
+	assert.Equal(t, map[string]float64{
+		"logs/" + attemptRetry:   3,
+		"logs/" + attemptFirst:   6,
+		"traces/" + attemptFirst: 2,
+	}, sentEvents(t, "splunk_hec/sent"))
+}
+
+func TestSentEventsMetricPartialFailure(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.NameVal = "splunk_hec/partial"
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.MaxEventCount = 1
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	// The first record is sent, the second one fails and the third one is not sent.
+	err = c.pushLogData(context.Background(), createLogData(3))
+	var partial consumererror.PartialError
+	require.True(t, errors.As(err, &partial))
+	require.Equal(t, 2, partial.GetLogs().LogRecordCount())
+	require.NoError(t, c.pushLogData(context.Background(), partial.GetLogs()))
+
+	assert.Equal(t, map[string]float64{
+		"logs/" + attemptRetry: 1,
+		"logs/" + attemptFirst: 2,
+	}, sentEvents(t, "splunk_hec/partial"))
+}
+
+// sentEvents returns the events counted as sent by the given exporter, by signal and attempt.
+func sentEvents(t *testing.T, exporterName string) map[string]float64 {
+	rows, err := view.RetrieveData(mSentEvents.Name())
+	require.NoError(t, err)
+	got := map[string]float64{}
+	for _, row := range rows {
+		tags := map[string]string{}
+		for _, tg := range row.Tags {
+			tags[tg.Key.Name()] = tg.Value
+		}
+		if tags[tagKeyExporter.Name()] == exporterName {
+			got[tags[tagKeySignal.Name()]+"/"+tags[tagKeyAttempt.Name()]] = row.Data.(*view.SumData).Value
+		}
+	}
+	return got
+}
@@ -0,0 +1,106 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// metricEvent is a metric event along with the index of the metric it was created from.
+type metricEvent struct {
+	index eventIndex
+	event *splunk.Event
+}
+
+// mergeMetricEvents merges the metric events sharing their time, HEC metadata and dimensions into multi-metric
+// events, as supported by Splunk 8.0 and later. Values of the same metric are never merged, and events that are not
+// metrics are returned unchanged. The merged events keep the order of the first event they hold, along with its index.
+func mergeMetricEvents(events []metricEvent) []metricEvent {
+	merged := make([]metricEvent, 0, len(events))
+	// groups holds the positions in merged of the multi-metric events by key.
+	groups := map[string][]int{}
+	for _, e := range events {
+		if e.event.Event != splunk.HecEventMetricType {
+			merged = append(merged, e)
+			continue
+		}
+		key, ok := metricEventKey(e.event)
+		if !ok {
+			merged = append(merged, e)
+			continue
+		}
+		target := -1
+		for _, pos := range groups[key] {
+			if !hasMetricNameConflict(merged[pos].event, e.event) {
+				target = pos
+				break
+			}
+		}
+		if target < 0 {
+			groups[key] = append(groups[key], len(merged))
+			// The event is copied so that merging other events into it does not alter the original one.
+			event := *e.event
+			event.Fields = cloneMap(e.event.Fields)
+			merged = append(merged, metricEvent{index: e.index, event: &event})
+			continue
+		}
+		for k, v := range e.event.Fields {
+			if strings.HasPrefix(k, splunkMetricValue+":") {
+				merged[target].event.Fields[k] = v
+			}
+		}
+	}
+	return merged
+}
+
+// metricEventKey returns the key of the time, HEC metadata and dimensions of a metric event.
+func metricEventKey(event *splunk.Event) (string, bool) {
+	dimensions := make(map[string]interface{}, len(event.Fields))
+	for k, v := range event.Fields {
+		if !strings.HasPrefix(k, splunkMetricValue+":") {
+			dimensions[k] = v
+		}
+	}
+	// Maps are serialized with sorted keys.
+	b, err := json.Marshal(struct {
+		Time       *float64
+		TimeNanos  uint64
+		Host       string
+		Source     string
+		SourceType string
+		Index      string
+		Dimensions map[string]interface{}
+	}{event.Time, event.TimeNanos, event.Host, event.Source, event.SourceType, event.Index, dimensions})
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// hasMetricNameConflict returns whether both events hold a value of the same metric.
+func hasMetricNameConflict(a *splunk.Event, b *splunk.Event) bool {
+	for k := range b.Fields {
+		if !strings.HasPrefix(k, splunkMetricValue+":") {
+			continue
+		}
+		if _, ok := a.Fields[k]; ok {
+			return true
+		}
+	}
+	return false
+}
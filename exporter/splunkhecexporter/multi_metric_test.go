@@ -0,0 +1,63 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestMergeMetricEvents(t *testing.T) {
+	ts := 1433188255.5
+	otherTs := 1433188256.5
+	metric := func(index int, time *float64, fields map[string]interface{}) metricEvent {
+		return metricEvent{
+			index: eventIndex{record: index},
+			event: &splunk.Event{Time: time, Host: "myhost", Event: splunk.HecEventMetricType, Fields: fields},
+		}
+	}
+	events := []metricEvent{
+		metric(0, &ts, map[string]interface{}{"k0": "v0", "metric_name:cpu": 1}),
+		metric(1, &ts, map[string]interface{}{"k0": "v0", "metric_name:mem": 2}),
+		// Same metric as the first event, which cannot be merged with it.
+		metric(1, &ts, map[string]interface{}{"k0": "v0", "metric_name:cpu": 3}),
+		metric(2, &ts, map[string]interface{}{"k0": "v1", "metric_name:disk": 4}),
+		metric(2, &otherTs, map[string]interface{}{"k0": "v0", "metric_name:disk": 5}),
+		metric(3, &ts, map[string]interface{}{"k0": "v0", "metric_name:disk": 6}),
+		{index: eventIndex{record: 3}, event: &splunk.Event{Time: &ts, Event: "reset", Fields: map[string]interface{}{"k0": "v0"}}},
+	}
+
+	merged := mergeMetricEvents(events)
+	require.Len(t, merged, 5)
+
+	assert.Equal(t, eventIndex{record: 0}, merged[0].index)
+	assert.Equal(t, map[string]interface{}{"k0": "v0", "metric_name:cpu": 1, "metric_name:mem": 2, "metric_name:disk": 6}, merged[0].event.Fields)
+	assert.Equal(t, "myhost", merged[0].event.Host)
+	assert.Equal(t, &ts, merged[0].event.Time)
+
+	assert.Equal(t, eventIndex{record: 1}, merged[1].index)
+	assert.Equal(t, map[string]interface{}{"k0": "v0", "metric_name:cpu": 3}, merged[1].event.Fields)
+
+	assert.Equal(t, map[string]interface{}{"k0": "v1", "metric_name:disk": 4}, merged[2].event.Fields)
+	assert.Equal(t, map[string]interface{}{"k0": "v0", "metric_name:disk": 5}, merged[3].event.Fields)
+	assert.Equal(t, "reset", merged[4].event.Event)
+
+	// The original events are left unchanged.
+	assert.Equal(t, map[string]interface{}{"k0": "v0", "metric_name:cpu": 1}, events[0].event.Fields)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// handleNonFiniteValues drops or converts the NaN and infinite values of the fields of the events, which would
+// otherwise fail the JSON encoding of the whole record. Events left without a metric value are dropped.
+func (s *chunkSender) handleNonFiniteValues(events []*splunk.Event) []*splunk.Event {
+	settings := s.client.config.NonFiniteValues
+	kept := events[:0]
+	for _, event := range events {
+		names := nonFiniteFields(event.Fields)
+		if len(names) == 0 {
+			kept = append(kept, event)
+			continue
+		}
+		if settings.Action == nonFiniteConvert {
+			flagField := s.client.config.nonFiniteFlagField()
+			for _, name := range names {
+				value := event.Fields[name].(float64)
+				event.Fields[name] = settings.SentinelValue
+				if _, ok := event.Fields[flagField]; !ok {
+					event.Fields[flagField] = strconv.FormatFloat(value, 'g', -1, 64)
+				}
+			}
+			kept = append(kept, event)
+			continue
+		}
+
+		if settings.Action == nonFiniteDropAndCount {
+			s.drop(dropReasonNonFiniteValue, names[0])
+		}
+		for _, name := range names {
+			delete(event.Fields, name)
+		}
+		if event.Event != splunk.HecEventMetricType || hasMetricValue(event.Fields) {
+			kept = append(kept, event)
+		}
+	}
+	return kept
+}
+
+// nonFiniteFields returns the sorted names of the fields holding NaN or infinite values.
+func nonFiniteFields(fields map[string]interface{}) []string {
+	var names []string
+	for name, value := range fields {
+		if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hasMetricValue returns whether the fields of a metric event hold any metric value.
+func hasMetricValue(fields map[string]interface{}) bool {
+	for name := range fields {
+		if strings.HasPrefix(name, splunkMetricValue+":") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestHandleNonFiniteValues(t *testing.T) {
+	newEvents := func() []*splunk.Event {
+		return []*splunk.Event{
+			{Event: splunk.HecEventMetricType, Fields: map[string]interface{}{"k0": "v0", "metric_name:cpu": 1.0}},
+			{Event: splunk.HecEventMetricType, Fields: map[string]interface{}{"k0": "v0", "metric_name:cpu": math.NaN()}},
+			{Event: splunk.HecEventMetricType, Fields: map[string]interface{}{
+				"metric_name:cpu": math.Inf(1),
+				"metric_name:mem": 2.0,
+			}},
+			{Event: "span", Fields: map[string]interface{}{"exemplar_value": math.Inf(-1)}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		settings NonFiniteValuesSettings
+		want     []map[string]interface{}
+		drops    map[string]*droppedRecords
+	}{
+		{
+			name: "drop",
+			want: []map[string]interface{}{
+				{"k0": "v0", "metric_name:cpu": 1.0},
+				{"metric_name:mem": 2.0},
+				{},
+			},
+		},
+		{
+			name:     "drop_and_count",
+			settings: NonFiniteValuesSettings{Action: "drop_and_count"},
+			want: []map[string]interface{}{
+				{"k0": "v0", "metric_name:cpu": 1.0},
+				{"metric_name:mem": 2.0},
+				{},
+			},
+			drops: map[string]*droppedRecords{
+				dropReasonNonFiniteValue: {count: 3, example: "metric_name:cpu"},
+			},
+		},
+		{
+			name:     "convert",
+			settings: NonFiniteValuesSettings{Action: "convert", SentinelValue: -1, FlagField: "flag"},
+			want: []map[string]interface{}{
+				{"k0": "v0", "metric_name:cpu": 1.0},
+				{"k0": "v0", "metric_name:cpu": -1.0, "flag": "NaN"},
+				{"metric_name:cpu": -1.0, "metric_name:mem": 2.0, "flag": "+Inf"},
+				{"exemplar_value": -1.0, "flag": "-Inf"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newChunkSender(&client{config: &Config{NonFiniteValues: tt.settings}}, "metrics")
+			events := s.handleNonFiniteValues(newEvents())
+			require.Len(t, events, len(tt.want))
+			for i, want := range tt.want {
+				assert.Equal(t, want, events[i].Fields)
+			}
+			assert.Equal(t, tt.drops, s.drops)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// payloadCapturer records serialized HEC payloads, either to a file or to the debug logger.
+type payloadCapturer struct {
+	path   string
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newPayloadCapturer(settings PayloadCaptureSettings, logger *zap.Logger) *payloadCapturer {
+	if !settings.Enabled {
+		return nil
+	}
+	return &payloadCapturer{
+		path:   settings.Path,
+		logger: logger,
+	}
+}
+
+func (p *payloadCapturer) open() error {
+	if p.path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.file = f
+	p.mu.Unlock()
+	return nil
+}
+
+// capture records the uncompressed payload. Failures are logged and never prevent the payload from being sent.
+func (p *payloadCapturer) capture(payload []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.file == nil {
+		// The payload is copied, as its buffer is reused once the request was sent.
+		p.logger.Debug("Captured HEC payload", zap.String("payload", string(payload)))
+		return
+	}
+	if _, err := p.file.Write(payload); err != nil {
+		p.logger.Warn("Failed to write captured HEC payload", zap.String("path", p.path), zap.Error(err))
+	}
+}
+
+func (p *payloadCapturer) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.file == nil {
+		return nil
+	}
+	err := p.file.Close()
+	p.file = nil
+	return err
+}
@@ -0,0 +1,169 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	// defaultRedactionReplacement replaces the redacted values of rules without a replacement.
+	defaultRedactionReplacement = "****"
+	// eventBodyField is the field name rules match the body of events against when it is not a map.
+	eventBodyField = "event"
+)
+
+// redactor replaces the parts of field values matching redaction rules just before events are serialized. A nil
+// redactor leaves events unchanged.
+type redactor struct {
+	rules []redactionRule
+}
+
+type redactionRule struct {
+	// field matches the field names the rule applies to, all of them if nil.
+	field       *regexp.Regexp
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func newRedactor(rules []RedactionRule) (*redactor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	r := &redactor{rules: make([]redactionRule, 0, len(rules))}
+	for i, rule := range rules {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf(`requires a non-empty "pattern" in "redaction[%d]"`, i)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid "pattern" in "redaction[%d]": %v`, i, err)
+		}
+		parsed := redactionRule{pattern: pattern, replacement: rule.Replacement}
+		if parsed.replacement == "" {
+			parsed.replacement = defaultRedactionReplacement
+		}
+		if rule.Field != "" {
+			if parsed.field, err = regexp.Compile(rule.Field); err != nil {
+				return nil, fmt.Errorf(`invalid "field" in "redaction[%d]": %v`, i, err)
+			}
+		}
+		r.rules = append(r.rules, parsed)
+	}
+	return r, nil
+}
+
+// redact replaces the redacted values of the fields and payload of the events. Maps and slices holding redacted
+// values are copied rather than altered, as they may be shared with other events.
+func (r *redactor) redact(events []*splunk.Event) {
+	if r == nil {
+		return
+	}
+	for _, e := range events {
+		if fields, ok := r.redactMap(e.Fields); ok {
+			e.Fields = fields
+		}
+		switch event := e.Event.(type) {
+		case HecSpan:
+			e.Event = r.redactSpan(event)
+		default:
+			if value, ok := r.redactValue(eventBodyField, e.Event); ok {
+				e.Event = value
+			}
+		}
+	}
+}
+
+func (r *redactor) redactSpan(span HecSpan) HecSpan {
+	if attributes, ok := r.redactMap(span.Attributes); ok {
+		span.Attributes = attributes
+	}
+	if len(span.Events) > 0 {
+		events := make([]HecEvent, len(span.Events))
+		copy(events, span.Events)
+		for i := range events {
+			if attributes, ok := r.redactMap(events[i].Attributes); ok {
+				events[i].Attributes = attributes
+			}
+		}
+		span.Events = events
+	}
+	if len(span.Links) > 0 {
+		links := make([]HecLink, len(span.Links))
+		copy(links, span.Links)
+		for i := range links {
+			if attributes, ok := r.redactMap(links[i].Attributes); ok {
+				links[i].Attributes = attributes
+			}
+		}
+		span.Links = links
+	}
+	return span
+}
+
+// redactMap returns a copy of the map with its redacted values replaced, and false if no value was redacted.
+func (r *redactor) redactMap(m map[string]interface{}) (map[string]interface{}, bool) {
+	var redacted map[string]interface{}
+	for k, v := range m {
+		value, ok := r.redactValue(k, v)
+		if !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(map[string]interface{}, len(m))
+			for k, v := range m {
+				redacted[k] = v
+			}
+		}
+		redacted[k] = value
+	}
+	return redacted, redacted != nil
+}
+
+// redactValue returns the value of the named field with its redacted parts replaced, and false if nothing was
+// redacted. The values of nested maps are matched by their own key, and the elements of slices by the field name.
+func (r *redactor) redactValue(field string, v interface{}) (interface{}, bool) {
+	switch value := v.(type) {
+	case string:
+		redacted := value
+		for _, rule := range r.rules {
+			if rule.field == nil || rule.field.MatchString(field) {
+				redacted = rule.pattern.ReplaceAllString(redacted, rule.replacement)
+			}
+		}
+		return redacted, redacted != value
+	case map[string]interface{}:
+		return r.redactMap(value)
+	case []interface{}:
+		var redacted []interface{}
+		for i, elem := range value {
+			elemValue, ok := r.redactValue(field, elem)
+			if !ok {
+				continue
+			}
+			if redacted == nil {
+				redacted = make([]interface{}, len(value))
+				copy(redacted, value)
+			}
+			redacted[i] = elemValue
+		}
+		return redacted, redacted != nil
+	default:
+		return v, false
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestRedactor(t *testing.T) {
+	r, err := newRedactor([]RedactionRule{
+		{Field: "^http.url$", Pattern: `(password=)[^&]*`, Replacement: "${1}xxx"},
+		{Pattern: `\b\d{4}-\d{4}-\d{4}-\d{4}\b`},
+	})
+	require.NoError(t, err)
+
+	shared := map[string]interface{}{"http.url": "https://example.com/?user=me&password=secret", "port": 443}
+	events := []*splunk.Event{
+		{
+			Fields: shared,
+			Event: HecSpan{
+				Name:       "card 1234-5678-9012-3456",
+				Attributes: map[string]interface{}{"card": "1234-5678-9012-3456", "ok": "1234"},
+				Events:     []HecEvent{{Attributes: map[string]interface{}{"cards": []interface{}{"1111-2222-3333-4444", "none"}}}},
+			},
+		},
+		{
+			Fields: shared,
+			Event:  "paid with 1234-5678-9012-3456, password=secret",
+		},
+		{
+			Fields: map[string]interface{}{"other": "password=secret"},
+			Event:  map[string]interface{}{"body": map[string]interface{}{"http.url": "/?password=secret"}},
+		},
+	}
+	r.redact(events)
+
+	assert.Equal(t, "https://example.com/?user=me&password=secret", shared["http.url"], "shared fields must not be altered")
+	assert.Equal(t, map[string]interface{}{"http.url": "https://example.com/?user=me&password=xxx", "port": 443}, events[0].Fields)
+	assert.Equal(t, events[0].Fields, events[1].Fields)
+	span := events[0].Event.(HecSpan)
+	assert.Equal(t, "card 1234-5678-9012-3456", span.Name, "only fields are redacted")
+	assert.Equal(t, map[string]interface{}{"card": "****", "ok": "1234"}, span.Attributes)
+	assert.Equal(t, []interface{}{"****", "none"}, span.Events[0].Attributes["cards"])
+	assert.Equal(t, "paid with ****, password=secret", events[1].Event)
+	assert.Equal(t, map[string]interface{}{"other": "password=secret"}, events[2].Fields)
+	assert.Equal(t, map[string]interface{}{"body": map[string]interface{}{"http.url": "/?password=xxx"}}, events[2].Event)
+
+	var nilRedactor *redactor
+	nilRedactor.redact(events)
+}
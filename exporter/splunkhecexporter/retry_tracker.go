@@ -0,0 +1,119 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	attemptFirst = "first"
+	attemptRetry = "retry"
+
+	// maxTrackedRetries is the maximum number of records awaiting a retry that are tracked. The records of batches
+	// failing while the tracker is full count as sent for the first time once retried.
+	maxTrackedRetries = 100000
+	// defaultRetryTrackingTTL is how long records awaiting a retry are tracked when retries have no maximum elapsed
+	// time.
+	defaultRetryTrackingTTL = time.Hour
+)
+
+// retryMarker holds the records of a batch pushed again by the exporter helper whose send failed before.
+type retryMarker struct {
+	records map[eventIndex]struct{}
+	expires time.Time
+}
+
+// retryTracker soft-deletes the records of failed requests that the exporter helper retries: rather than forgetting
+// them, it marks the batch pushed again, i.e. the data of the partial error or else the failed batch itself, with the
+// indexes of the records that were sent, so that their events are counted as retried rather than sent for the first
+// time, and the first attempt count remains a measure of unique data. The records of the batch that were not sent
+// before it failed count as sent for the first time. A nil retryTracker tracks nothing.
+type retryTracker struct {
+	ttl time.Duration
+
+	mu sync.Mutex
+	// pending holds the markers by batch, i.e. pdata.Logs, pdata.Metrics or pdata.Traces, which are compared by
+	// identity.
+	pending map[interface{}]*retryMarker
+	// records is the number of records of the pending markers.
+	records int
+}
+
+// newRetryTracker returns the tracker of the retried records, nil if retries are disabled.
+func newRetryTracker(config *Config) *retryTracker {
+	if !config.RetrySettings.Enabled {
+		return nil
+	}
+	ttl := config.RetrySettings.MaxElapsedTime
+	if ttl <= 0 {
+		ttl = defaultRetryTrackingTTL
+	}
+	return &retryTracker{ttl: ttl, pending: map[interface{}]*retryMarker{}}
+}
+
+// failed marks the records of the given indexes in the batch pushed again as awaiting a retry.
+func (t *retryTracker) failed(batch interface{}, records []eventIndex, now time.Time) {
+	if t == nil || len(records) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.records+len(records) > maxTrackedRetries {
+		t.expire(now)
+		if t.records+len(records) > maxTrackedRetries {
+			return
+		}
+	}
+	marker := &retryMarker{records: make(map[eventIndex]struct{}, len(records)), expires: now.Add(t.ttl)}
+	for _, index := range records {
+		marker.records[index] = struct{}{}
+	}
+	if previous, ok := t.pending[batch]; ok {
+		t.records -= len(previous.records)
+	}
+	t.pending[batch] = marker
+	t.records += len(marker.records)
+}
+
+// retried returns the records of the batch that were awaiting a retry, if any, and stops tracking them.
+func (t *retryTracker) retried(batch interface{}, now time.Time) map[eventIndex]struct{} {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	marker, ok := t.pending[batch]
+	if !ok {
+		return nil
+	}
+	delete(t.pending, batch)
+	t.records -= len(marker.records)
+	if !now.Before(marker.expires) {
+		return nil
+	}
+	return marker.records
+}
+
+// expire stops tracking the batches whose retries are exhausted.
+func (t *retryTracker) expire(now time.Time) {
+	for batch, marker := range t.pending {
+		if !now.Before(marker.expires) {
+			delete(t.pending, batch)
+			t.records -= len(marker.records)
+		}
+	}
+}
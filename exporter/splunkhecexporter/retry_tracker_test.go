@@ -0,0 +1,82 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+func TestNewRetryTracker(t *testing.T) {
+	assert.Nil(t, newRetryTracker(&Config{}))
+	var tracker *retryTracker
+	ld := pdata.NewLogs()
+	tracker.failed(ld, []eventIndex{{}}, time.Now())
+	assert.Nil(t, tracker.retried(ld, time.Now()))
+
+	tracker = newRetryTracker(&Config{RetrySettings: exporterhelper.RetrySettings{Enabled: true}})
+	require.NotNil(t, tracker)
+	assert.Equal(t, defaultRetryTrackingTTL, tracker.ttl)
+	tracker = newRetryTracker(&Config{RetrySettings: exporterhelper.RetrySettings{Enabled: true, MaxElapsedTime: time.Minute}})
+	assert.Equal(t, time.Minute, tracker.ttl)
+}
+
+func TestRetryTracker(t *testing.T) {
+	tracker := newRetryTracker(&Config{RetrySettings: exporterhelper.RetrySettings{Enabled: true, MaxElapsedTime: time.Minute}})
+	now := time.Now()
+	a, b := createLogData(2), createLogData(2)
+
+	tracker.failed(a, []eventIndex{{record: 0}, {record: 1}}, now)
+	tracker.failed(b, []eventIndex{{record: 1}}, now)
+	assert.Nil(t, tracker.retried(createLogData(2), now), "batches with the same records are tracked separately")
+	assert.Equal(t, map[eventIndex]struct{}{{record: 0}: {}, {record: 1}: {}}, tracker.retried(a, now))
+	assert.Nil(t, tracker.retried(a, now), "records are only retried once")
+	assert.Nil(t, tracker.retried(b, now.Add(time.Minute)), "retries are exhausted")
+	assert.Empty(t, tracker.pending)
+	assert.Zero(t, tracker.records)
+}
+
+func TestRetryTrackerFull(t *testing.T) {
+	tracker := newRetryTracker(&Config{RetrySettings: exporterhelper.RetrySettings{Enabled: true, MaxElapsedTime: time.Minute}})
+	now := time.Now()
+	records := make([]eventIndex, maxTrackedRetries)
+	for i := range records {
+		records[i] = eventIndex{record: i}
+	}
+	full, more := pdata.NewLogs(), pdata.NewLogs()
+	tracker.failed(full, records, now)
+	tracker.failed(more, []eventIndex{{}}, now)
+	assert.Equal(t, maxTrackedRetries, tracker.records)
+	assert.Nil(t, tracker.retried(more, now))
+
+	// The expired batches make room for new ones.
+	tracker.failed(more, []eventIndex{{}}, now.Add(time.Minute))
+	assert.Equal(t, 1, tracker.records)
+	assert.Len(t, tracker.retried(more, now.Add(time.Minute)), 1)
+}
+
+func TestEventIndexRelativeTo(t *testing.T) {
+	from := eventIndex{resource: 1, library: 2, record: 3}
+	assert.Equal(t, eventIndex{}, from.relativeTo(from))
+	assert.Equal(t, eventIndex{record: 2}, eventIndex{resource: 1, library: 2, record: 5}.relativeTo(from))
+	assert.Equal(t, eventIndex{library: 1, record: 1}, eventIndex{resource: 1, library: 3, record: 1}.relativeTo(from))
+	assert.Equal(t, eventIndex{resource: 1, library: 1}, eventIndex{resource: 2, library: 1}.relativeTo(from))
+	assert.Equal(t, eventIndex{resource: 1}, eventIndex{resource: 1}.relativeTo(eventIndex{}))
+}
@@ -0,0 +1,118 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"go.opentelemetry.io/collector/translator/conventions"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// semanticConventionFields maps the OpenTelemetry semantic convention attributes to the field names conventionally
+// used in Splunk, e.g. by the Splunk Connect for Kubernetes and the Splunk Add-on for Unix and Linux.
+var semanticConventionFields = map[string]string{
+	conventions.AttributeOSType:                "os",
+	conventions.AttributeHostID:                "host_id",
+	conventions.AttributeCloudRegion:           "region",
+	conventions.AttributeDeploymentEnvironment: "environment",
+	conventions.AttributeServiceVersion:        "version",
+	conventions.AttributeK8sCluster:            "cluster_name",
+	conventions.AttributeK8sNamespace:          "namespace",
+	conventions.AttributeK8sPod:                "pod",
+	conventions.AttributeK8sNodeName:           "node",
+	conventions.AttributeContainerName:         "container_name",
+	conventions.AttributeContainerID:           "container_id",
+}
+
+// semanticConventionMapper renames the fields of events named after the OpenTelemetry semantic conventions to their
+// Splunk names, and drops the fields of the attributes already sent as the host and source of events, e.g. host.name
+// and service.name. A nil semanticConventionMapper leaves events unchanged.
+type semanticConventionMapper struct {
+	// metadataAttrs holds the attributes mapped to the host and source of events.
+	metadataAttrs []string
+}
+
+// newSemanticConventionMapper returns the mapper of the events, nil if disabled. Only the attributes that metadataMapping
+// maps to the host and source are dropped, so that they are kept when a template renders the source instead, or when
+// the mapping is disabled.
+func newSemanticConventionMapper(config *Config) *semanticConventionMapper {
+	if !config.MapSemanticConventions {
+		return nil
+	}
+	m := &semanticConventionMapper{}
+	mapping := config.metadataMapping()
+	for _, attr := range []string{mapping.Host, mapping.Source} {
+		if attr != "" {
+			m.metadataAttrs = append(m.metadataAttrs, attr)
+		}
+	}
+	return m
+}
+
+// apply maps the fields of the events. Fields already holding the Splunk name of an attribute are kept over the
+// attribute. Fields maps are copied rather than altered, as they may be shared with other events.
+func (m *semanticConventionMapper) apply(events []*splunk.Event) {
+	if m == nil {
+		return
+	}
+	for _, e := range events {
+		if m.mapped(e.Fields) {
+			e.Fields = m.mapFields(e.Fields)
+		}
+	}
+}
+
+// mapped tells whether any of the fields is mapped.
+func (m *semanticConventionMapper) mapped(fields map[string]interface{}) bool {
+	for k := range fields {
+		if _, ok := semanticConventionFields[k]; ok {
+			return true
+		}
+	}
+	for _, attr := range m.metadataAttrs {
+		if _, ok := fields[attr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *semanticConventionMapper) mapFields(fields map[string]interface{}) map[string]interface{} {
+	mapped := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if _, ok := semanticConventionFields[k]; !ok && !m.isMetadataAttr(k) {
+			mapped[k] = v
+		}
+	}
+	for k, v := range fields {
+		name, ok := semanticConventionFields[k]
+		if !ok || m.isMetadataAttr(k) {
+			continue
+		}
+		if _, exists := mapped[name]; !exists {
+			mapped[name] = v
+		}
+	}
+	return mapped
+}
+
+func (m *semanticConventionMapper) isMetadataAttr(key string) bool {
+	for _, attr := range m.metadataAttrs {
+		if key == attr {
+			return true
+		}
+	}
+	return false
+}
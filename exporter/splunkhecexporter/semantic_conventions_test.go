@@ -0,0 +1,131 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestSemanticConventionMapperDisabled(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	m := newSemanticConventionMapper(config)
+	assert.Nil(t, m)
+
+	events := []*splunk.Event{{Fields: map[string]interface{}{"os.type": "linux"}}}
+	m.apply(events)
+	assert.Equal(t, map[string]interface{}{"os.type": "linux"}, events[0].Fields)
+}
+
+func TestSemanticConventionMapper(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MapSemanticConventions = true
+	m := newSemanticConventionMapper(config)
+	require.NotNil(t, m)
+
+	shared := map[string]interface{}{
+		"os.type":            "linux",
+		"k8s.pod.name":       "pod-1",
+		"k8s.namespace.name": "default",
+		"host.name":          "host1",
+		"service.name":       "myapp",
+		"metric_name:cpu":    1.0,
+	}
+	events := []*splunk.Event{
+		{Host: "host1", Source: "myapp", Fields: shared},
+		{Host: "host1", Source: "myapp", Fields: shared},
+		{Fields: map[string]interface{}{"container.name": "app", "container_name": "explicit"}},
+		{Fields: map[string]interface{}{"custom": "value"}},
+	}
+	m.apply(events)
+
+	want := map[string]interface{}{
+		"os":              "linux",
+		"pod":             "pod-1",
+		"namespace":       "default",
+		"metric_name:cpu": 1.0,
+	}
+	assert.Equal(t, want, events[0].Fields)
+	assert.Equal(t, want, events[1].Fields)
+	assert.Equal(t, "linux", shared["os.type"], "shared fields must not be altered")
+	assert.Equal(t, map[string]interface{}{"container_name": "explicit"}, events[2].Fields)
+	assert.Equal(t, map[string]interface{}{"custom": "value"}, events[3].Fields)
+}
+
+func TestSemanticConventionMapperMetadataAttrs(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.MapSemanticConventions = true
+	config.HecToOtelAttrs.Host = "k8s.node.name"
+	config.HecToOtelAttrs.Source = ""
+	m := newSemanticConventionMapper(config)
+
+	events := []*splunk.Event{{Fields: map[string]interface{}{
+		"k8s.node.name": "node-1",
+		"host.name":     "host1",
+		"service.name":  "myapp",
+	}}}
+	m.apply(events)
+	assert.Equal(t, map[string]interface{}{"host.name": "host1", "service.name": "myapp"}, events[0].Fields)
+}
+
+func TestSemanticConventionMapperFollowsMetadataMapping(t *testing.T) {
+	fields := func() map[string]interface{} {
+		return map[string]interface{}{"host.name": "host1", "service.name": "myapp"}
+	}
+	tests := []struct {
+		name      string
+		configure func(config *Config)
+		want      map[string]interface{}
+	}{
+		{
+			name:      "default mapping",
+			configure: func(config *Config) {},
+			want:      map[string]interface{}{},
+		},
+		{
+			// The source is rendered from the template rather than taken from service.name, which is kept.
+			name: "templated source",
+			configure: func(config *Config) {
+				config.Source = "{{.k8s_namespace_name}}"
+			},
+			want: map[string]interface{}{"service.name": "myapp"},
+		},
+		{
+			name: "disabled mapping",
+			configure: func(config *Config) {
+				config.HecToOtelAttrs = splunk.HecToOtelAttrs{}
+			},
+			want: fields(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createDefaultConfig().(*Config)
+			config.Endpoint = "http://localhost:8088/services/collector"
+			config.Token = "1234-1234"
+			config.MapSemanticConventions = true
+			tt.configure(config)
+			require.NoError(t, config.validateConfig())
+
+			events := []*splunk.Event{{Fields: fields()}}
+			newSemanticConventionMapper(config).apply(events)
+			assert.Equal(t, tt.want, events[0].Fields)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ThrottledError is the error of requests failing while HEC is saturated, i.e. answering 429 Too Many Requests or
+// 503 Service Unavailable for at least throttling.window. It is retryable, and lets pipeline components tell
+// backpressure apart from other failures with errors.As, e.g. to slow down ingestion rather than retrying right away.
+type ThrottledError struct {
+	// Err is the error of the request.
+	Err error
+	// Duration is how long HEC has been throttling requests.
+	Duration time.Duration
+	// RetryAfter is the delay requested by the Retry-After header of the response, 0 if it had none.
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("HEC throttled requests for %v, retry after %v: %v", e.Duration, e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("HEC throttled requests for %v: %v", e.Duration, e.Err)
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// isThrottled tells whether the error is a ThrottledError.
+func isThrottled(err error) bool {
+	var throttledErr *ThrottledError
+	return errors.As(err, &throttledErr)
+}
+
+// throttleTracker tracks how long HEC has been answering requests with throttling status codes.
+type throttleTracker struct {
+	window time.Duration
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu sync.Mutex
+	// since is the time of the first throttling response of the current throttled period, zero outside of one.
+	since time.Time
+	// reported is whether the current throttled period lasted long enough to be reported.
+	reported bool
+}
+
+func newThrottleTracker(config *Config, logger *zap.Logger) *throttleTracker {
+	return &throttleTracker{
+		window: config.Throttling.Window,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// check accounts for the response of a request, and returns its error, wrapped into a ThrottledError if HEC has been
+// throttling requests for at least the window. Responses with other status codes end the throttled period.
+func (t *throttleTracker) check(resp *http.Response, err error) error {
+	if t == nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		if t.reported {
+			t.logger.Info("HEC stopped throttling requests", zap.Duration("duration", t.now().Sub(t.since)))
+		}
+		t.since = time.Time{}
+		t.reported = false
+		return err
+	}
+
+	now := t.now()
+	if t.since.IsZero() {
+		t.since = now
+	}
+	duration := now.Sub(t.since)
+	if duration < t.window {
+		return err
+	}
+	if !t.reported {
+		t.reported = true
+		t.logger.Warn("HEC is throttling requests", zap.Duration("duration", duration), zap.Int("status_code", resp.StatusCode))
+	}
+	return &ThrottledError{Err: err, Duration: duration, RetryAfter: retryAfter(resp)}
+}
+
+// retryAfter returns the delay of the Retry-After header of the response, in seconds or as an HTTP date, 0 if none.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+)
+
+func newTestThrottleTracker(window time.Duration) (*throttleTracker, *time.Time) {
+	now := time.Unix(1000, 0)
+	t := newThrottleTracker(&Config{Throttling: ThrottlingSettings{Window: window}}, zap.NewNop())
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+func testResponse(statusCode int, headers ...string) *http.Response {
+	resp := &http.Response{StatusCode: statusCode, Header: http.Header{}}
+	for i := 0; i+1 < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+func TestThrottleTrackerWindow(t *testing.T) {
+	tracker, now := newTestThrottleTracker(10 * time.Second)
+	statusErr := &httpStatusError{statusCode: http.StatusTooManyRequests}
+
+	assert.Equal(t, statusErr, tracker.check(testResponse(http.StatusTooManyRequests), statusErr))
+	*now = now.Add(5 * time.Second)
+	assert.Equal(t, statusErr, tracker.check(testResponse(http.StatusServiceUnavailable), statusErr))
+
+	*now = now.Add(5 * time.Second)
+	err := tracker.check(testResponse(http.StatusTooManyRequests), statusErr)
+	var throttledErr *ThrottledError
+	require.True(t, errors.As(err, &throttledErr))
+	assert.Equal(t, 10*time.Second, throttledErr.Duration)
+	assert.Equal(t, dropReasonHTTPError, sendFailureReason(err))
+	assert.False(t, consumererror.IsPermanent(err))
+
+	// Another status code ends the throttled period.
+	assert.NoError(t, tracker.check(testResponse(http.StatusOK), nil))
+	*now = now.Add(time.Minute)
+	assert.Equal(t, statusErr, tracker.check(testResponse(http.StatusTooManyRequests), statusErr))
+}
+
+func TestThrottleTrackerIgnoresOtherErrors(t *testing.T) {
+	tracker, _ := newTestThrottleTracker(0)
+	statusErr := &httpStatusError{statusCode: http.StatusBadRequest}
+	assert.Equal(t, statusErr, tracker.check(testResponse(http.StatusBadRequest), statusErr))
+	assert.True(t, isThrottled(tracker.check(testResponse(http.StatusServiceUnavailable), statusErr)))
+}
+
+func TestThrottledErrorRetryAfter(t *testing.T) {
+	tracker, _ := newTestThrottleTracker(0)
+	err := tracker.check(testResponse(http.StatusTooManyRequests, "Retry-After", "120"), &httpStatusError{statusCode: http.StatusTooManyRequests})
+	var throttledErr *ThrottledError
+	require.True(t, errors.As(err, &throttledErr))
+	assert.Equal(t, 2*time.Minute, throttledErr.RetryAfter)
+	assert.Equal(t, `HEC throttled requests for 0s, retry after 2m0s: HTTP 429 "Too Many Requests"`, err.Error())
+
+	assert.Equal(t, time.Duration(0), retryAfter(testResponse(http.StatusTooManyRequests, "Retry-After", "soon")))
+	assert.Equal(t, time.Duration(0), retryAfter(testResponse(http.StatusTooManyRequests)))
+}
+
+func TestThrottledErrorReturnedByClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.Token = "1234-1234"
+	config.Endpoint = server.URL
+	config.Throttling.Window = 0
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+
+	// Nothing was sent, so the error is not hidden by a partial error.
+	err = c.pushLogData(context.Background(), createLogData(2))
+	var throttledErr *ThrottledError
+	require.True(t, errors.As(err, &throttledErr))
+	_, partial := err.(consumererror.PartialError)
+	assert.False(t, partial)
+}
@@ -0,0 +1,135 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// tokenFileCheckInterval is the interval token files are re-read at.
+const tokenFileCheckInterval = 10 * time.Second
+
+// tokenFile holds the HEC token read from token_file, and re-reads it whenever it changes or the collector receives
+// SIGHUP, so that tokens rotated by secret managers are picked up without a restart. A nil tokenFile holds no token.
+type tokenFile struct {
+	path     string
+	interval time.Duration
+	logger   *zap.Logger
+	// redactor redacts the tokens read from the file from logs and errors.
+	redactor *splunk.TokenRedactor
+
+	mu    sync.RWMutex
+	token string
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newTokenFile(config *Config, logger *zap.Logger, redactor *splunk.TokenRedactor) *tokenFile {
+	if config.TokenFile == "" {
+		return nil
+	}
+	return &tokenFile{
+		path:     config.TokenFile,
+		interval: tokenFileCheckInterval,
+		logger:   logger,
+		redactor: redactor,
+	}
+}
+
+// get returns the current token.
+func (t *tokenFile) get() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.token
+}
+
+// start reads the token, failing if it cannot be read, and watches the file for changes until stop is called.
+func (t *tokenFile) start() error {
+	if t == nil {
+		return nil
+	}
+	if _, err := t.load(); err != nil {
+		return err
+	}
+	t.done = make(chan struct{})
+	t.stopped = make(chan struct{})
+	// SIGHUP is handled from now on, rather than once the watcher runs, as it would otherwise terminate the process.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go t.watch(hup)
+	return nil
+}
+
+func (t *tokenFile) stop() {
+	if t == nil || t.done == nil {
+		return
+	}
+	close(t.done)
+	<-t.stopped
+	t.done = nil
+}
+
+func (t *tokenFile) watch(hup chan os.Signal) {
+	defer close(t.stopped)
+	defer signal.Stop(hup)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-hup:
+		case <-t.done:
+			return
+		}
+		// The previous token is kept when the file cannot be read, e.g. while a secret manager replaces it.
+		changed, err := t.load()
+		if err != nil {
+			t.logger.Warn("Could not re-read the HEC token file, keeping the previous token", zap.Error(err))
+		} else if changed {
+			t.logger.Info("Reloaded the HEC token file", zap.String("path", t.path))
+		}
+	}
+}
+
+// load reads the token from the file, and tells whether it changed.
+func (t *tokenFile) load() (bool, error) {
+	content, err := ioutil.ReadFile(t.path)
+	if err != nil {
+		return false, fmt.Errorf("could not read the token file: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return false, errors.New("the token file is empty")
+	}
+	t.redactor.Set(redactionSourceTokenFile, token)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := token != t.token
+	t.token = token
+	return changed, nil
+}
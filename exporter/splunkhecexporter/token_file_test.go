@@ -0,0 +1,123 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeTokenFile(t *testing.T, path string, token string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(token), 0600))
+}
+
+func TestTokenFileDisabled(t *testing.T) {
+	tf := newTokenFile(&Config{}, zap.NewNop(), nil)
+	assert.Nil(t, tf)
+	assert.NoError(t, tf.start())
+	tf.stop()
+}
+
+func TestTokenFileStartFails(t *testing.T) {
+	dir := t.TempDir()
+	tf := newTokenFile(&Config{TokenFile: filepath.Join(dir, "missing")}, zap.NewNop(), nil)
+	assert.Error(t, tf.start())
+
+	path := filepath.Join(dir, "empty")
+	writeTokenFile(t, path, " \n")
+	tf = newTokenFile(&Config{TokenFile: path}, zap.NewNop(), nil)
+	assert.EqualError(t, tf.start(), "the token file is empty")
+}
+
+func TestTokenFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	writeTokenFile(t, path, "token-1\n")
+	tf := newTokenFile(&Config{TokenFile: path}, zap.NewNop(), nil)
+	tf.interval = 10 * time.Millisecond
+	require.NoError(t, tf.start())
+	defer tf.stop()
+	assert.Equal(t, "token-1", tf.get())
+
+	writeTokenFile(t, path, "token-2")
+	assert.Eventually(t, func() bool { return tf.get() == "token-2" }, 5*time.Second, 10*time.Millisecond)
+
+	// The previous token is kept while the file cannot be read.
+	require.NoError(t, os.Remove(path))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "token-2", tf.get())
+}
+
+func TestTokenFileReloadOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not supported on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "token")
+	writeTokenFile(t, path, "token-1")
+	tf := newTokenFile(&Config{TokenFile: path}, zap.NewNop(), nil)
+	tf.interval = time.Hour
+	require.NoError(t, tf.start())
+	defer tf.stop()
+
+	writeTokenFile(t, path, "token-2")
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+	assert.Eventually(t, func() bool { return tf.get() == "token-2" }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestClientTokenFile(t *testing.T) {
+	var mu sync.Mutex
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	writeTokenFile(t, path, "token-1")
+	config := NewFactory().CreateDefaultConfig().(*Config)
+	config.TokenFile = path
+	config.Endpoint = server.URL
+	options, err := config.getOptionsFromConfig()
+	require.NoError(t, err)
+	c := buildClient(options, config, zap.NewNop())
+	require.NoError(t, c.start(context.Background(), nil))
+	defer func() { assert.NoError(t, c.stop(context.Background())) }()
+
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+	writeTokenFile(t, path, "token-2")
+	_, err = c.tokenFile.load()
+	require.NoError(t, err)
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Splunk token-1", "Splunk token-2"}, authorizations)
+}
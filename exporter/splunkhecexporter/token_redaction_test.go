@@ -0,0 +1,61 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTokenRedaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	writeTokenFile(t, path, "file-secret")
+	for name, tt := range map[string]struct {
+		setToken func(*Config)
+		token    string
+	}{
+		"token":      {setToken: func(config *Config) { config.Token = "config-secret" }, token: "config-secret"},
+		"token_file": {setToken: func(config *Config) { config.TokenFile = path }, token: "file-secret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			config := NewFactory().CreateDefaultConfig().(*Config)
+			tt.setToken(config)
+			// Some proxies authenticate with the token in the query string, which errors then hold.
+			config.Endpoint = "http://127.0.0.1:1/services/collector?token=" + tt.token
+			config.FastRetry.MaxRetries = 1
+			config.RetrySettings.Enabled = false
+			exp, err := createExporter(config, "", zap.New(core))
+			require.NoError(t, err)
+			require.NoError(t, exp.start(context.Background(), nil))
+			defer func() { assert.NoError(t, exp.stop(context.Background())) }()
+
+			err = exp.pushLogData(context.Background(), createLogData(1))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "token=<redacted token>")
+			assert.NotContains(t, err.Error(), "secret")
+			require.NotZero(t, logs.Len(), "the retried request is logged")
+			assert.NotContains(t, fmt.Sprint(logs.All()), "secret")
+		})
+	}
+}
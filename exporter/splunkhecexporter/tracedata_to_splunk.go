@@ -15,6 +15,8 @@
 package splunkhecexporter
 
 import (
+	"strings"
+
 	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/translator/conventions"
 	tracetranslator "go.opentelemetry.io/collector/translator/trace"
@@ -23,6 +25,16 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
 
+const (
+	// traceStateField is the field holding the W3C tracestate of a span, so that searches and correlations can use it
+	// without parsing the event.
+	traceStateField = "trace_state"
+	// spanEventType is the type of the HEC events holding span events sent separately from their span.
+	spanEventType = "span_event"
+	// spanLinkType is the type of the HEC events holding span links sent separately from their span.
+	spanLinkType = "span_link"
+)
+
 // HecEvent is a data structure holding a span event to export explicitly to Splunk HEC.
 type HecEvent struct {
 	Attributes map[string]interface{} `json:"attributes,omitempty"`
@@ -48,6 +60,7 @@ type HecSpanStatus struct {
 type HecSpan struct {
 	TraceID    string                 `json:"trace_id"`
 	SpanID     string                 `json:"span_id"`
+	TraceState pdata.TraceState       `json:"trace_state,omitempty"`
 	ParentSpan string                 `json:"parent_span_id"`
 	Name       string                 `json:"name"`
 	Attributes map[string]interface{} `json:"attributes,omitempty"`
@@ -62,57 +75,20 @@ type HecSpan struct {
 func traceDataToSplunk(logger *zap.Logger, data pdata.Traces, config *Config) ([]*splunk.Event, int) {
 	numDroppedSpans := 0
 	splunkEvents := make([]*splunk.Event, 0, data.SpanCount())
+	filter := newSpanFilter(data, config.ErrorSpansOnly)
 	rss := data.ResourceSpans()
 	for i := 0; i < rss.Len(); i++ {
 		rs := rss.At(i)
-		host := unknownHostName
-		source := config.Source
-		sourceType := config.SourceType
-		index := config.Index
-		commonFields := map[string]interface{}{}
-		resource := rs.Resource()
-		attributes := resource.Attributes()
-		if conventionHost, isSet := attributes.Get(conventions.AttributeHostName); isSet {
-			host = conventionHost.StringVal()
-		}
-		if sourceSet, isSet := attributes.Get(conventions.AttributeServiceName); isSet {
-			source = sourceSet.StringVal()
-		}
-		if sourcetypeSet, isSet := attributes.Get(splunk.SourcetypeLabel); isSet {
-			sourceType = sourcetypeSet.StringVal()
-		}
-		if indexSet, isSet := attributes.Get(splunk.IndexLabel); isSet {
-			index = indexSet.StringVal()
-		}
-		attributes.ForEach(func(k string, v pdata.AttributeValue) {
-			commonFields[k] = tracetranslator.AttributeValueToString(v, false)
-		})
-
-		if sourceSet, isSet := rs.Resource().Attributes().Get(conventions.AttributeServiceName); isSet {
-			source = sourceSet.StringVal()
-		}
-		if sourcetypeSet, isSet := rs.Resource().Attributes().Get(splunk.SourcetypeLabel); isSet {
-			sourceType = sourcetypeSet.StringVal()
-		}
-		rs.Resource().Attributes().ForEach(func(k string, v pdata.AttributeValue) {
-			commonFields[k] = tracetranslator.AttributeValueToString(v, false)
-		})
+		meta := newSpanResourceMetadata(rs.Resource(), config, logger)
 		ilss := rs.InstrumentationLibrarySpans()
 		for sils := 0; sils < ilss.Len(); sils++ {
-			ils := ilss.At(sils)
-			spans := ils.Spans()
+			libraryMeta := withInstrumentationLibrary(meta, ilss.At(sils).InstrumentationLibrary())
+			spans := ilss.At(sils).Spans()
 			for si := 0; si < spans.Len(); si++ {
-				span := spans.At(si)
-				se := &splunk.Event{
-					Time:       timestampToSecondsWithMillisecondPrecision(span.StartTime()),
-					Host:       host,
-					Source:     source,
-					SourceType: sourceType,
-					Index:      index,
-					Event:      toHecSpan(logger, span),
-					Fields:     commonFields,
+				if !filter.keep(spans.At(si)) {
+					continue
 				}
-				splunkEvents = append(splunkEvents, se)
+				splunkEvents = append(splunkEvents, mapSpanToSplunkEvents(libraryMeta, spans.At(si), config, logger)...)
 			}
 		}
 	}
@@ -120,10 +96,233 @@ func traceDataToSplunk(logger *zap.Logger, data pdata.Traces, config *Config) ([
 	return splunkEvents, numDroppedSpans
 }
 
-func toHecSpan(logger *zap.Logger, span pdata.Span) HecSpan {
+// spanServiceMapping maps the service and host of resources to the source and host of span events.
+var spanServiceMapping = splunk.HecToOtelAttrs{
+	Source: conventions.AttributeServiceName,
+	Host:   conventions.AttributeHostName,
+}
+
+// newSpanResourceMetadata returns the metadata of the span events of a resource.
+func newSpanResourceMetadata(resource pdata.Resource, config *Config, logger *zap.Logger) resourceMetadata {
+	meta := newResourceMetadata(resource, config, config.signalIndex(config.TracesIndex), logger)
+	if config.SpanSourceFromServiceName {
+		meta.update(spanServiceMapping, resource.Attributes())
+		// The well-known attributes still take precedence.
+		meta.update(splunkOverrides, resource.Attributes())
+	}
+	return meta
+}
+
+// withInstrumentationLibrary returns the metadata of the spans of an instrumentation library, whose name and version
+// are added to the fields so that traces can be filtered by instrumentation source.
+func withInstrumentationLibrary(meta resourceMetadata, library pdata.InstrumentationLibrary) resourceMetadata {
+	fields := map[string]interface{}{}
+	if library.Name() != "" && meta.filter.keep(conventions.InstrumentationLibraryName) {
+		fields[conventions.InstrumentationLibraryName] = library.Name()
+	}
+	if library.Version() != "" && meta.filter.keep(conventions.InstrumentationLibraryVersion) {
+		fields[conventions.InstrumentationLibraryVersion] = library.Version()
+	}
+	meta.fields = mergeFields(meta.fields, fields)
+	return meta
+}
+
+// mapSpanToSplunkEvents returns the event of the span, followed by the events of its span events and links when
+// they are sent separately.
+func mapSpanToSplunkEvents(meta resourceMetadata, span pdata.Span, config *Config, logger *zap.Logger) []*splunk.Event {
+	if index, ok := routeSpanIndex(config.SpanIndexRouting, meta.spanIndexRoute, span); ok {
+		meta.index = index
+	}
+	hecSpan := toHecSpan(logger, span, meta.filter)
+	var separate []*splunk.Event
+	if config.SeparateSpanEventsAndLinks {
+		separate = separateEventsAndLinks(meta, span, hecSpan)
+		hecSpan.Events = nil
+		hecSpan.Links = nil
+	}
+	fields := meta.fields
+	switch config.spanAttributesPlacement() {
+	case placementFields:
+		fields = mergeFields(fields, hecSpan.Attributes)
+		hecSpan.Attributes = nil
+	case placementBoth:
+		fields = mergeFields(fields, hecSpan.Attributes)
+	}
+	if hecSpan.TraceState != "" {
+		fields = mergeFields(fields, map[string]interface{}{traceStateField: string(hecSpan.TraceState)})
+	}
+	fields = mergeFields(fields, spanStatusFields(config.SpanStatusMapping, span.Status()))
+	var event interface{} = hecSpan
+	switch config.SpanFormat {
+	case spanFormatAPM:
+		event = toAPMSpan(meta, span, hecSpan)
+	case spanFormatCompact:
+		event = toCompactSpan(span, hecSpan)
+	}
+	return append([]*splunk.Event{newSpanEvent(meta, span.StartTime(), event, fields)}, separate...)
+}
+
+// spanStatusCodeNames are the names of span status codes in span_status_mapping.
+var spanStatusCodeNames = map[pdata.StatusCode]string{
+	pdata.StatusCodeUnset: "unset",
+	pdata.StatusCodeOk:    "ok",
+	pdata.StatusCodeError: "error",
+}
+
+// spanStatusFields returns the fields the span status is mapped to, if any.
+func spanStatusFields(settings SpanStatusMappingSettings, status pdata.SpanStatus) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if settings.ErrorField != "" {
+		fields[settings.ErrorField] = status.Code() == pdata.StatusCodeError
+	}
+	if settings.CodeField != "" {
+		code, ok := settings.Codes[spanStatusCodeNames[status.Code()]]
+		if !ok {
+			code = status.Code().String()
+		}
+		fields[settings.CodeField] = code
+	}
+	if settings.MessageField != "" && status.Message() != "" {
+		fields[settings.MessageField] = status.Message()
+	}
+	return fields
+}
+
+// routeSpanIndex returns the index picked by the routing attribute of the span, or else by the value of the
+// attribute on its resource, and false if neither has the attribute or its value is not mapped to any index.
+func routeSpanIndex(settings SpanIndexRoutingSettings, resourceValue string, span pdata.Span) (string, bool) {
+	if settings.Attribute == "" {
+		return "", false
+	}
+	value := resourceValue
+	if v, ok := span.Attributes().Get(settings.Attribute); ok {
+		value = tracetranslator.AttributeValueToString(v, false)
+	}
+	if value == "" {
+		return "", false
+	}
+	if len(settings.Indexes) == 0 {
+		return value, true
+	}
+	index, ok := settings.Indexes[value]
+	return index, ok
+}
+
+// separateEventsAndLinks returns one event per span event and link of the span, holding the trace_id and span_id of
+// the span so that they can be correlated with it.
+func separateEventsAndLinks(meta resourceMetadata, span pdata.Span, hecSpan HecSpan) []*splunk.Event {
+	events := make([]*splunk.Event, 0, len(hecSpan.Events)+len(hecSpan.Links))
+	for _, e := range hecSpan.Events {
+		event := map[string]interface{}{
+			"type":     spanEventType,
+			"trace_id": hecSpan.TraceID,
+			"span_id":  hecSpan.SpanID,
+			"name":     e.Name,
+		}
+		if len(e.Attributes) > 0 {
+			event["attributes"] = e.Attributes
+		}
+		ts := e.Timestamp
+		if ts == 0 {
+			ts = span.StartTime()
+		}
+		events = append(events, newSpanEvent(meta, ts, event, meta.fields))
+	}
+	for _, l := range hecSpan.Links {
+		event := map[string]interface{}{
+			"type":            spanLinkType,
+			"trace_id":        hecSpan.TraceID,
+			"span_id":         hecSpan.SpanID,
+			"linked_trace_id": l.TraceID,
+			"linked_span_id":  l.SpanID,
+		}
+		if l.TraceState != "" {
+			event["linked_trace_state"] = l.TraceState
+		}
+		if len(l.Attributes) > 0 {
+			event["attributes"] = l.Attributes
+		}
+		events = append(events, newSpanEvent(meta, span.StartTime(), event, meta.fields))
+	}
+	return events
+}
+
+func newSpanEvent(meta resourceMetadata, ts pdata.Timestamp, event interface{}, fields map[string]interface{}) *splunk.Event {
+	return &splunk.Event{
+		Time:       timestampToEpochSeconds(ts, meta.timestampPrecision),
+		TimeNanos:  timestampNanos(ts, meta.timestampPrecision),
+		Host:       meta.host,
+		Source:     meta.source,
+		SourceType: meta.sourceType,
+		Index:      meta.index,
+		Event:      event,
+		Fields:     fields,
+	}
+}
+
+// toAPMSpan returns the event of a span in the flat layout expected by Splunk APM and IT Service Intelligence: the
+// identifiers, timing and status of the span and the service attributes of its resource are top level keys, and the
+// span attributes are tags.
+func toAPMSpan(meta resourceMetadata, span pdata.Span, hecSpan HecSpan) map[string]interface{} {
+	var duration float64
+	if span.EndTime() > span.StartTime() {
+		duration = float64(span.EndTime()-span.StartTime()) / 1e6
+	}
+	event := map[string]interface{}{
+		"trace_id":    hecSpan.TraceID,
+		"span_id":     hecSpan.SpanID,
+		"name":        hecSpan.Name,
+		"kind":        hecSpan.Kind,
+		"start_time":  float64(span.StartTime()) / 1e6,
+		"duration_ms": duration,
+		"error":       span.Status().Code() == pdata.StatusCodeError,
+		"status_code": hecSpan.Status.Code,
+	}
+	if hecSpan.ParentSpan != "" {
+		event["parent_id"] = hecSpan.ParentSpan
+	}
+	if hecSpan.TraceState != "" {
+		event["trace_state"] = hecSpan.TraceState
+	}
+	if hecSpan.Status.Message != "" {
+		event["status_message"] = hecSpan.Status.Message
+	}
+	for k, v := range meta.fields {
+		if strings.HasPrefix(k, "service.") || k == conventions.AttributeDeploymentEnvironment {
+			event[k] = v
+		}
+	}
+	if len(hecSpan.Attributes) > 0 {
+		event["tags"] = hecSpan.Attributes
+	}
+	if len(hecSpan.Events) > 0 {
+		event["events"] = hecSpan.Events
+	}
+	if len(hecSpan.Links) > 0 {
+		event["links"] = hecSpan.Links
+	}
+	return event
+}
+
+// mergeFields returns a copy of the resource fields with the given attributes added, without altering the
+// resource fields shared by all events of the resource.
+func mergeFields(fields map[string]interface{}, attributes map[string]interface{}) map[string]interface{} {
+	if len(attributes) == 0 {
+		return fields
+	}
+	merged := cloneMap(fields)
+	for k, v := range attributes {
+		merged[k] = v
+	}
+	return merged
+}
+
+func toHecSpan(logger *zap.Logger, span pdata.Span, filter *attributeFilter) HecSpan {
 	attributes := map[string]interface{}{}
 	span.Attributes().ForEach(func(k string, v pdata.AttributeValue) {
-		attributes[k] = convertAttributeValue(v, logger)
+		if filter.keepAttribute(k, v) {
+			attributes[k] = convertAttributeValue(v, logger)
+		}
 	})
 
 	links := make([]HecLink, span.Links().Len())
@@ -160,6 +359,7 @@ func toHecSpan(logger *zap.Logger, span pdata.Span) HecSpan {
 	return HecSpan{
 		TraceID:    span.TraceID().HexString(),
 		SpanID:     span.SpanID().HexString(),
+		TraceState: span.TraceState(),
 		ParentSpan: span.ParentSpanID().HexString(),
 		Name:       span.Name(),
 		Attributes: attributes,
@@ -86,7 +86,7 @@ func Test_traceDataToSplunk(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			traces := tt.traceDataFn()
 
-			gotEvents, gotNumDroppedSpans := traceDataToSplunk(logger, traces, &Config{})
+			gotEvents, gotNumDroppedSpans := traceDataToSplunk(logger, traces, createDefaultConfig().(*Config))
 			assert.Equal(t, tt.wantNumDroppedSpans, gotNumDroppedSpans)
 			require.Equal(t, len(tt.wantSplunkEvents), len(gotEvents))
 			for i, want := range tt.wantSplunkEvents {
@@ -97,6 +97,323 @@ func Test_traceDataToSplunk(t *testing.T) {
 	}
 }
 
+func Test_traceDataToSplunk_attributesPlacement(t *testing.T) {
+	ts := pdata.Timestamp(123)
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("service.name", "myservice")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	rs.InstrumentationLibrarySpans().At(0).Spans().Append(makeSpan("myspan", &ts))
+
+	tests := []struct {
+		placement      string
+		wantFields     map[string]interface{}
+		wantAttributes map[string]interface{}
+	}{
+		{
+			placement:      "event",
+			wantFields:     map[string]interface{}{"service.name": "myservice"},
+			wantAttributes: map[string]interface{}{"foo": "bar"},
+		},
+		{
+			placement:  "fields",
+			wantFields: map[string]interface{}{"service.name": "myservice", "foo": "bar"},
+		},
+		{
+			placement:      "both",
+			wantFields:     map[string]interface{}{"service.name": "myservice", "foo": "bar"},
+			wantAttributes: map[string]interface{}{"foo": "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.placement, func(t *testing.T) {
+			config := createDefaultConfig().(*Config)
+			config.AttributesPlacement.Spans = tt.placement
+			events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.wantFields, events[0].Fields)
+			assert.Equal(t, tt.wantAttributes, events[0].Event.(HecSpan).Attributes)
+		})
+	}
+}
+
+func Test_traceDataToSplunk_apmSpanFormat(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("service.name", "myservice")
+	rs.Resource().Attributes().InsertString("service.version", "1.2.3")
+	rs.Resource().Attributes().InsertString("deployment.environment", "prod")
+	rs.Resource().Attributes().InsertString("host.name", "myhost")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	spans.Resize(2)
+	root := spans.At(0)
+	root.SetName("root")
+	root.SetTraceID(pdata.NewTraceID([16]byte{1}))
+	root.SetSpanID(pdata.NewSpanID([8]byte{2}))
+	root.SetKind(pdata.SpanKindSERVER)
+	root.SetStartTime(pdata.Timestamp(1_000_000_000))
+	root.SetEndTime(pdata.Timestamp(1_012_500_000))
+	root.Attributes().InsertString("http.method", "GET")
+	child := spans.At(1)
+	child.SetName("child")
+	child.SetTraceID(pdata.NewTraceID([16]byte{1}))
+	child.SetSpanID(pdata.NewSpanID([8]byte{3}))
+	child.SetParentSpanID(pdata.NewSpanID([8]byte{2}))
+	child.SetStartTime(pdata.Timestamp(1_001_000_000))
+	child.SetEndTime(pdata.Timestamp(1_002_000_000))
+	child.Status().SetCode(pdata.StatusCodeError)
+	child.Status().SetMessage("boom")
+
+	config := createDefaultConfig().(*Config)
+	config.SpanFormat = "apm"
+	events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 2)
+	assert.Equal(t, map[string]interface{}{
+		"trace_id":               "01000000000000000000000000000000",
+		"span_id":                "0200000000000000",
+		"name":                   "root",
+		"kind":                   "SPAN_KIND_SERVER",
+		"start_time":             1000.0,
+		"duration_ms":            12.5,
+		"error":                  false,
+		"status_code":            "STATUS_CODE_UNSET",
+		"service.name":           "myservice",
+		"service.version":        "1.2.3",
+		"deployment.environment": "prod",
+		"tags":                   map[string]interface{}{"http.method": "GET"},
+	}, events[0].Event)
+	assert.Equal(t, map[string]interface{}{
+		"trace_id":               "01000000000000000000000000000000",
+		"span_id":                "0300000000000000",
+		"parent_id":              "0200000000000000",
+		"name":                   "child",
+		"kind":                   "SPAN_KIND_UNSPECIFIED",
+		"start_time":             1001.0,
+		"duration_ms":            1.0,
+		"error":                  true,
+		"status_code":            "STATUS_CODE_ERROR",
+		"status_message":         "boom",
+		"service.name":           "myservice",
+		"service.version":        "1.2.3",
+		"deployment.environment": "prod",
+	}, events[1].Event)
+	assert.Equal(t, "myhost", events[0].Fields["host.name"])
+}
+
+func Test_traceDataToSplunk_separateSpanEventsAndLinks(t *testing.T) {
+	ts := pdata.Timestamp(2_000_000_000)
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("service.name", "myservice")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	span := makeSpan("myspan", &ts)
+	span.SetTraceID(pdata.NewTraceID([16]byte{1}))
+	span.SetSpanID(pdata.NewSpanID([8]byte{2}))
+	rs.InstrumentationLibrarySpans().At(0).Spans().Append(span)
+
+	config := createDefaultConfig().(*Config)
+	config.SeparateSpanEventsAndLinks = true
+	events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 3)
+
+	hecSpan := events[0].Event.(HecSpan)
+	assert.Equal(t, "myspan", hecSpan.Name)
+	assert.Nil(t, hecSpan.Events)
+	assert.Nil(t, hecSpan.Links)
+
+	assert.Equal(t, map[string]interface{}{
+		"type":       "span_event",
+		"trace_id":   "01000000000000000000000000000000",
+		"span_id":    "0200000000000000",
+		"name":       "myEvent",
+		"attributes": map[string]interface{}{"foo": "bar"},
+	}, events[1].Event)
+	assert.Equal(t, 2.0, *events[1].Time)
+	assert.Equal(t, map[string]interface{}{"service.name": "myservice"}, events[1].Fields)
+
+	assert.Equal(t, map[string]interface{}{
+		"type":               "span_link",
+		"trace_id":           "01000000000000000000000000000000",
+		"span_id":            "0200000000000000",
+		"linked_trace_id":    "12345678000000000000000000000000",
+		"linked_span_id":     "1234000000000000",
+		"linked_trace_state": pdata.TraceState("OK"),
+		"attributes": map[string]interface{}{
+			"foo":    int64(1),
+			"bar":    false,
+			"foobar": []interface{}{"a", "b"},
+		},
+	}, events[2].Event)
+	assert.Equal(t, 2.0, *events[2].Time)
+}
+
+func Test_traceDataToSplunk_spanIndexRouting(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("deployment.environment", "staging")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	for _, env := range []string{"production", "", "development"} {
+		span := pdata.NewSpan()
+		if env != "" {
+			span.Attributes().InsertString("deployment.environment", env)
+		}
+		spans.Append(span)
+	}
+
+	tests := []struct {
+		name     string
+		settings SpanIndexRoutingSettings
+		want     []string
+	}{
+		{
+			name: "disabled",
+			want: []string{"main", "main", "main"},
+		},
+		{
+			name: "mapped values",
+			settings: SpanIndexRoutingSettings{
+				Attribute: "deployment.environment",
+				Indexes:   map[string]string{"production": "traces_prod", "staging": "traces_staging"},
+			},
+			want: []string{"traces_prod", "traces_staging", "main"},
+		},
+		{
+			name:     "values as indexes",
+			settings: SpanIndexRoutingSettings{Attribute: "deployment.environment"},
+			want:     []string{"production", "staging", "development"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createDefaultConfig().(*Config)
+			config.Index = "main"
+			config.SpanIndexRouting = tt.settings
+			events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+			var indexes []string
+			for _, event := range events {
+				indexes = append(indexes, event.Index)
+			}
+			assert.Equal(t, tt.want, indexes)
+		})
+	}
+}
+
+func Test_traceDataToSplunk_spanSourceFromServiceName(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(2)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("service.name", "myservice")
+	rs.Resource().Attributes().InsertString("host.name", "myhost")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	rs.InstrumentationLibrarySpans().At(0).Spans().Append(pdata.NewSpan())
+	rs = traces.ResourceSpans().At(1)
+	rs.Resource().Attributes().InsertString("service.name", "myservice")
+	rs.Resource().Attributes().InsertString("com.splunk.source", "override")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	rs.InstrumentationLibrarySpans().At(0).Spans().Append(pdata.NewSpan())
+
+	config := createDefaultConfig().(*Config)
+	config.Source = "otel"
+	config.HecToOtelAttrs = splunk.HecToOtelAttrs{}
+	events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 2)
+	assert.Equal(t, "otel", events[0].Source)
+	assert.Equal(t, "unknown", events[0].Host)
+
+	config.SpanSourceFromServiceName = true
+	events, _ = traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 2)
+	assert.Equal(t, "myservice", events[0].Source)
+	assert.Equal(t, "myhost", events[0].Host)
+	assert.Equal(t, "override", events[1].Source)
+}
+
+func Test_traceDataToSplunk_traceState(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	spans.Resize(2)
+	spans.At(0).SetTraceState("congo=t61rcWkgMzE,rojo=00f067aa0ba902b7")
+
+	config := createDefaultConfig().(*Config)
+	events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 2)
+	assert.Equal(t, pdata.TraceState("congo=t61rcWkgMzE,rojo=00f067aa0ba902b7"), events[0].Event.(HecSpan).TraceState)
+	assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", events[0].Fields[traceStateField])
+	assert.NotContains(t, events[1].Fields, traceStateField)
+
+	config.SpanFormat = spanFormatAPM
+	events, _ = traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 2)
+	assert.Equal(t, pdata.TraceState("congo=t61rcWkgMzE,rojo=00f067aa0ba902b7"), events[0].Event.(map[string]interface{})["trace_state"])
+	assert.NotContains(t, events[1].Event.(map[string]interface{}), "trace_state")
+}
+
+func Test_traceDataToSplunk_spanStatusMapping(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("resource", "R1")
+	rs.InstrumentationLibrarySpans().Resize(1)
+	spans := rs.InstrumentationLibrarySpans().At(0).Spans()
+	spans.Resize(3)
+	spans.At(0).Status().SetCode(pdata.StatusCodeError)
+	spans.At(0).Status().SetMessage("connection refused")
+	spans.At(1).Status().SetCode(pdata.StatusCodeOk)
+
+	config := createDefaultConfig().(*Config)
+	events, _ := traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 3)
+	assert.Equal(t, map[string]interface{}{"resource": "R1"}, events[0].Fields)
+
+	config.SpanStatusMapping = SpanStatusMappingSettings{
+		ErrorField:   "error",
+		CodeField:    "status",
+		Codes:        map[string]string{"ok": "success", "error": "failure"},
+		MessageField: "otel.status_description",
+	}
+	events, _ = traceDataToSplunk(zap.NewNop(), traces, config)
+	require.Len(t, events, 3)
+	assert.Equal(t, map[string]interface{}{
+		"resource":                "R1",
+		"error":                   true,
+		"status":                  "failure",
+		"otel.status_description": "connection refused",
+	}, events[0].Fields)
+	assert.Equal(t, map[string]interface{}{"resource": "R1", "error": false, "status": "success"}, events[1].Fields)
+	assert.Equal(t, map[string]interface{}{"resource": "R1", "error": false, "status": "STATUS_CODE_UNSET"}, events[2].Fields)
+}
+
+func Test_traceDataToSplunk_instrumentationLibrary(t *testing.T) {
+	traces := pdata.NewTraces()
+	traces.ResourceSpans().Resize(1)
+	rs := traces.ResourceSpans().At(0)
+	rs.Resource().Attributes().InsertString("resource", "R1")
+	rs.InstrumentationLibrarySpans().Resize(2)
+	ils := rs.InstrumentationLibrarySpans().At(0)
+	ils.InstrumentationLibrary().SetName("io.opentelemetry.jdbc")
+	ils.InstrumentationLibrary().SetVersion("1.0.0")
+	ils.Spans().Resize(1)
+	rs.InstrumentationLibrarySpans().At(1).Spans().Resize(1)
+
+	events, _ := traceDataToSplunk(zap.NewNop(), traces, createDefaultConfig().(*Config))
+	require.Len(t, events, 2)
+	assert.Equal(t, map[string]interface{}{
+		"resource":             "R1",
+		"otel.library.name":    "io.opentelemetry.jdbc",
+		"otel.library.version": "1.0.0",
+	}, events[0].Fields)
+	assert.Equal(t, map[string]interface{}{"resource": "R1"}, events[1].Fields)
+}
+
 func makeSpan(name string, ts *pdata.Timestamp) pdata.Span {
 	span := pdata.NewSpan()
 	span.Attributes().InsertString("foo", "bar")
@@ -137,7 +454,7 @@ func commonSplunkEvent(
 	ts pdata.Timestamp,
 ) *splunk.Event {
 	return &splunk.Event{
-		Time:       timestampToSecondsWithMillisecondPrecision(ts),
+		Time:       timestampToEpochSeconds(ts, timestampPrecisionMillisecond),
 		Host:       "myhost",
 		Source:     "myservice",
 		SourceType: "mysourcetype",
@@ -169,7 +486,7 @@ func commonSplunkEvent(
 			},
 		},
 		Fields: map[string]interface{}{
-			"com.splunk.sourcetype": "mysourcetype", "com.splunk.index": "myindex", "host.name": "myhost", "service.name": "myservice",
+			"host.name": "myhost", "service.name": "myservice",
 		},
 	}
 }
@@ -0,0 +1,78 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecexporter
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// healthPath is the path of the HEC health endpoint, requested to open connections on start.
+const healthPath = "/services/collector/health"
+
+// warmUp resolves the host of the endpoint and opens connections to it, which are then kept idle for the
+// following requests. Failures are only logged, as requests will resolve and dial the endpoint again.
+func (c *client) warmUp(ctx context.Context) {
+	if c.socketPath == "" {
+		if _, err := net.DefaultResolver.LookupHost(ctx, c.url.Hostname()); err != nil {
+			c.logger.Warn("Failed to resolve the Splunk HEC endpoint", zap.String("host", c.url.Hostname()), zap.Error(err))
+			return
+		}
+	}
+
+	connections := c.config.Warmup.Connections
+	if c.config.MaxConnections > 0 && connections > c.config.MaxConnections {
+		// Connections above the idle connection limit would be closed right away.
+		connections = c.config.MaxConnections
+	}
+	// The requests are sent concurrently, so that each one opens its own connection.
+	var wg sync.WaitGroup
+	for i := uint(0); i < connections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.openConnection(ctx); err != nil {
+				c.logger.Debug("Failed to open a connection to the Splunk HEC endpoint", zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// openConnection requests the health endpoint, leaving the connection idle once the response is read. Any response
+// will do, e.g. when a proxy fronting Splunk does not serve the health endpoint.
+func (c *client) openConnection(ctx context.Context) error {
+	healthURL := *c.url
+	healthURL.Path = healthPath
+	healthURL.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if err = c.setHeaders(req); err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return splunk.DrainAndClose(resp.Body)
+}
@@ -28,4 +28,27 @@ type Config struct {
 
 	// Egress holds config settings to use for forwarded requests.
 	Egress confighttp.HTTPClientSettings `mapstructure:"egress"`
+
+	// Routes forward requests whose path starts with a given prefix to another target than the egress endpoint,
+	// e.g. to send the datapoint and event ingest APIs of the Smart Agent to different upstreams.
+	Routes []RouteConfig `mapstructure:"routes"`
+
+	// AccessToken is added to forwarded requests not carrying a token, in the TokenHeader header.
+	AccessToken string `mapstructure:"access_token"`
+
+	// TokenHeader is the header the access token is sent in. Defaults to X-SF-Token.
+	TokenHeader string `mapstructure:"token_header"`
+}
+
+// RouteConfig defines the target of requests whose path starts with a given prefix.
+type RouteConfig struct {
+	// PathPrefix is the prefix of the paths of the requests forwarded by this route, e.g. /v2/datapoint.
+	// The route with the longest matching prefix is used.
+	PathPrefix string `mapstructure:"path_prefix"`
+
+	// Endpoint is the target the requests are forwarded to.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// AccessToken overrides the access token added to the requests forwarded by this route.
+	AccessToken string `mapstructure:"access_token"`
 }
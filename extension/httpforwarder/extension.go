@@ -21,6 +21,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"go.opentelemetry.io/collector/component"
 	"go.uber.org/zap"
@@ -28,6 +30,8 @@ import (
 
 type httpForwarder struct {
 	forwardTo  *url.URL
+	routes     []route
+	token      string
 	httpClient *http.Client
 	server     *http.Server
 	logger     *zap.Logger
@@ -36,6 +40,13 @@ type httpForwarder struct {
 
 var _ component.Extension = (*httpForwarder)(nil)
 
+// route is a parsed RouteConfig.
+type route struct {
+	pathPrefix string
+	forwardTo  *url.URL
+	token      string
+}
+
 func (h *httpForwarder) Start(_ context.Context, host component.Host) error {
 	listener, err := h.config.Ingress.ToListener()
 	if err != nil {
@@ -60,10 +71,11 @@ func (h *httpForwarder) Shutdown(_ context.Context) error {
 }
 
 func (h *httpForwarder) forwardRequest(writer http.ResponseWriter, request *http.Request) {
+	forwardTo, token := h.target(request.URL.Path)
 	forwarderRequest := request.Clone(request.Context())
-	forwarderRequest.URL.Host = h.forwardTo.Host
-	forwarderRequest.URL.Scheme = h.forwardTo.Scheme
-	forwarderRequest.Host = h.forwardTo.Host
+	forwarderRequest.URL.Host = forwardTo.Host
+	forwarderRequest.URL.Scheme = forwardTo.Scheme
+	forwarderRequest.Host = forwardTo.Host
 	// Clear RequestURI to avoid getting "http: Request.RequestURI can't be set in client requests" error.
 	forwarderRequest.RequestURI = ""
 
@@ -72,6 +84,11 @@ func (h *httpForwarder) forwardRequest(writer http.ResponseWriter, request *http
 		forwarderRequest.Header.Add(k, v)
 	}
 
+	// Add the access token unless the client sent one.
+	if token != "" && forwarderRequest.Header.Get(h.tokenHeader()) == "" {
+		forwarderRequest.Header.Set(h.tokenHeader(), token)
+	}
+
 	// Add "Via" header for tracking purposes on both the outgoing requests and responses.
 	// See https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Via.
 	addViaHeader(forwarderRequest.Header, request.Proto, request.Host)
@@ -103,6 +120,27 @@ func (h *httpForwarder) forwardRequest(writer http.ResponseWriter, request *http
 	}
 }
 
+// target returns the URL and access token of the route with the longest prefix matching the path, or the egress
+// endpoint and default access token if none matches.
+func (h *httpForwarder) target(path string) (*url.URL, string) {
+	for _, r := range h.routes {
+		if strings.HasPrefix(path, r.pathPrefix) {
+			if r.token != "" {
+				return r.forwardTo, r.token
+			}
+			return r.forwardTo, h.token
+		}
+	}
+	return h.forwardTo, h.token
+}
+
+func (h *httpForwarder) tokenHeader() string {
+	if h.config.TokenHeader == "" {
+		return defaultTokenHeader
+	}
+	return h.config.TokenHeader
+}
+
 func addViaHeader(header http.Header, protocol string, host string) {
 	header.Add("Via", fmt.Sprintf("%s %s", protocol, host))
 }
@@ -117,6 +155,25 @@ func newHTTPForwarder(config *Config, logger *zap.Logger) (component.Extension,
 		return nil, fmt.Errorf("enter a valid URL for 'egress.endpoint': %w", err)
 	}
 
+	routes := make([]route, 0, len(config.Routes))
+	for i, r := range config.Routes {
+		if !strings.HasPrefix(r.PathPrefix, "/") {
+			return nil, fmt.Errorf("'routes[%d].path_prefix' config option must start with '/'", i)
+		}
+		if r.Endpoint == "" {
+			return nil, fmt.Errorf("'routes[%d].endpoint' config option cannot be empty", i)
+		}
+		routeURL, err := url.Parse(r.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("enter a valid URL for 'routes[%d].endpoint': %w", i, err)
+		}
+		routes = append(routes, route{pathPrefix: r.PathPrefix, forwardTo: routeURL, token: r.AccessToken})
+	}
+	// Longest prefixes first, so that the most specific route matches.
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].pathPrefix) > len(routes[j].pathPrefix)
+	})
+
 	httpClient, err := config.Egress.ToClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP Client: %w", err)
@@ -125,6 +182,8 @@ func newHTTPForwarder(config *Config, logger *zap.Logger) (component.Extension,
 	h := &httpForwarder{
 		config:     config,
 		forwardTo:  url,
+		routes:     routes,
+		token:      config.AccessToken,
 		httpClient: httpClient,
 		logger:     logger,
 	}
@@ -235,6 +235,62 @@ func TestExtension(t *testing.T) {
 	}
 }
 
+func TestExtensionRoutes(t *testing.T) {
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Backend", name)
+			w.Header().Set("Token", r.Header.Get("X-SF-Token"))
+		}))
+	}
+	defaultBackend := newBackend("default")
+	defer defaultBackend.Close()
+	eventBackend := newBackend("event")
+	defer eventBackend.Close()
+	datapointBackend := newBackend("datapoint")
+	defer datapointBackend.Close()
+
+	listenAt := testutil.GetAvailableLocalAddress(t)
+	config := &Config{
+		Ingress: confighttp.HTTPServerSettings{Endpoint: listenAt},
+		Egress:  confighttp.HTTPClientSettings{Endpoint: defaultBackend.URL},
+		Routes: []RouteConfig{
+			{PathPrefix: "/v2", Endpoint: datapointBackend.URL},
+			{PathPrefix: "/v2/event", Endpoint: eventBackend.URL, AccessToken: "event-token"},
+		},
+		AccessToken: "token",
+	}
+	hf, err := newHTTPForwarder(config, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, hf.Start(ctx, componenttest.NewNopHost()))
+	defer hf.Shutdown(ctx)
+
+	tests := []struct {
+		path        string
+		headers     map[string]string
+		wantBackend string
+		wantToken   string
+	}{
+		{path: "/v1/metric", wantBackend: "default", wantToken: "token"},
+		{path: "/v2/datapoint", wantBackend: "datapoint", wantToken: "token"},
+		{path: "/v2/event", wantBackend: "event", wantToken: "event-token"},
+		{path: "/v2/event", headers: map[string]string{"X-SF-Token": "client-token"}, wantBackend: "event", wantToken: "client-token"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			response, err := http.DefaultClient.Do(httpRequest(t, clientRequestArgs{
+				method:  "POST",
+				url:     fmt.Sprintf("http://%s%s", listenAt, test.path),
+				headers: test.headers,
+			}))
+			require.NoError(t, err)
+			defer response.Body.Close()
+			assert.Equal(t, test.wantBackend, response.Header.Get("Backend"))
+			assert.Equal(t, test.wantToken, response.Header.Get("Token"))
+		})
+	}
+}
+
 func httpRequest(t *testing.T, args clientRequestArgs) *http.Request {
 	r, err := http.NewRequest(args.method, args.url, ioutil.NopCloser(strings.NewReader(args.body)))
 	require.NoError(t, err)
@@ -30,6 +30,9 @@ const (
 
 	// Default endpoints to bind to.
 	defaultEndpoint = ":6060"
+
+	// Default header of the access token added to forwarded requests.
+	defaultTokenHeader = "X-SF-Token"
 )
 
 // NewFactory creates a factory for HostObserver extension.
@@ -52,6 +55,7 @@ func createDefaultConfig() configmodels.Extension {
 		Egress: confighttp.HTTPClientSettings{
 			Timeout: 10 * time.Second,
 		},
+		TokenHeader: defaultTokenHeader,
 	}
 }
 
@@ -39,6 +39,7 @@ func TestFactory(t *testing.T) {
 	require.Equal(t, configmodels.Type(expectType), cfg.Type())
 	require.Equal(t, ":6060", cfg.Ingress.Endpoint)
 	require.Equal(t, 10*time.Second, cfg.Egress.Timeout)
+	require.Equal(t, "X-SF-Token", cfg.TokenHeader)
 
 	tests := []struct {
 		name           string
@@ -73,6 +74,24 @@ func TestFactory(t *testing.T) {
 			wantErr:        true,
 			wantErrMessage: "failed to create HTTP Client: ",
 		},
+		{
+			name: "Invalid config - route without leading slash",
+			config: &Config{
+				Egress: confighttp.HTTPClientSettings{Endpoint: "localhost:9090"},
+				Routes: []RouteConfig{{PathPrefix: "v2/event", Endpoint: "localhost:9091"}},
+			},
+			wantErr:        true,
+			wantErrMessage: "'routes[0].path_prefix' config option must start with '/'",
+		},
+		{
+			name: "Invalid config - route without endpoint",
+			config: &Config{
+				Egress: confighttp.HTTPClientSettings{Endpoint: "localhost:9090"},
+				Routes: []RouteConfig{{PathPrefix: "/v2/event"}},
+			},
+			wantErr:        true,
+			wantErrMessage: "'routes[0].endpoint' config option cannot be empty",
+		},
 		{
 			name:   "Valid config",
 			config: &Config{Egress: confighttp.HTTPClientSettings{Endpoint: "localhost:9090"}},
@@ -0,0 +1,142 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cumulative remembers the last data point of the series of cumulative monotonic counters, to convert their
+// values to deltas or detect their resets.
+package cumulative
+
+import (
+	"sync"
+	"time"
+)
+
+// Point is a data point of a cumulative counter.
+type Point struct {
+	// StartTime is when the counter started, in nanoseconds since epoch, or 0 if unknown.
+	StartTime uint64
+	// Timestamp is the time of the data point, in nanoseconds since epoch.
+	Timestamp uint64
+	// IntValue is the value of integer counters.
+	IntValue int64
+	// DoubleValue is the value of floating point counters.
+	DoubleValue float64
+}
+
+// Reset is the reason a counter restarted since its previous data point.
+type Reset int
+
+const (
+	// NoReset means that the counter did not restart.
+	NoReset Reset = iota
+	// StartTimeChanged is a restart detected by a later start time.
+	StartTimeChanged
+	// ValueDecreased is a restart detected by a lower value, which is then the delta since the restart.
+	ValueDecreased
+)
+
+// DetectReset returns whether and why the counter restarted between its previous and current data points. A current
+// data point that is not later than the previous one is never a restart.
+func DetectReset(previous Point, current Point) Reset {
+	switch {
+	case current.Timestamp != 0 && current.Timestamp <= previous.Timestamp:
+		return NoReset
+	case current.StartTime != 0 && previous.StartTime != 0 && current.StartTime > previous.StartTime:
+		return StartTimeChanged
+	case current.IntValue < previous.IntValue || current.DoubleValue < previous.DoubleValue:
+		return ValueDecreased
+	default:
+		return NoReset
+	}
+}
+
+type entry struct {
+	point Point
+	// previous is the data point preceding point, if hasPrevious.
+	previous    Point
+	hasPrevious bool
+	// seen is when the last data point of the series was recorded.
+	seen time.Time
+}
+
+// Tracker remembers the last data point of each series, identified by a key of the caller. Series that received no
+// data point for maxStaleness are forgotten. It is safe for concurrent use.
+type Tracker struct {
+	maxStaleness time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	series    map[string]entry
+	lastPrune time.Time
+}
+
+// NewTracker creates a Tracker forgetting the series after maxStaleness, or never if 0. The now function, time.Now if
+// nil, returns the current time.
+func NewTracker(maxStaleness time.Duration, now func() time.Time) *Tracker {
+	if now == nil {
+		now = time.Now
+	}
+	return &Tracker{
+		maxStaleness: maxStaleness,
+		now:          now,
+		series:       map[string]entry{},
+		lastPrune:    now(),
+	}
+}
+
+// Record records the data point of the series and returns the data point preceding it, or false if there is none.
+// Data points older than the last recorded one are out of order: they are ignored and return false. A data point with
+// the same timestamp as the last recorded one, such as one sent again by a retry, leaves the series unchanged and
+// returns the same preceding data point, so that its delta is the same. Data points without timestamp are always
+// recorded.
+func (t *Tracker) Record(key string, point Point) (Point, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.prune(now)
+	last, ok := t.series[key]
+	switch {
+	case !ok:
+		t.series[key] = entry{point: point, seen: now}
+		return Point{}, false
+	case point.Timestamp != 0 && point.Timestamp < last.point.Timestamp:
+		return Point{}, false
+	case point.Timestamp != 0 && point.Timestamp == last.point.Timestamp:
+		last.seen = now
+		t.series[key] = last
+		return last.previous, last.hasPrevious
+	default:
+		t.series[key] = entry{point: point, previous: last.point, hasPrevious: true, seen: now}
+		return last.point, true
+	}
+}
+
+// Len returns the number of remembered series.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.series)
+}
+
+// prune forgets the series that received no data point for maxStaleness.
+func (t *Tracker) prune(now time.Time) {
+	if t.maxStaleness == 0 || now.Sub(t.lastPrune) < t.maxStaleness {
+		return
+	}
+	t.lastPrune = now
+	for key, e := range t.series {
+		if now.Sub(e.seen) >= t.maxStaleness {
+			delete(t.series, key)
+		}
+	}
+}
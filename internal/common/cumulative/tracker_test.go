@@ -0,0 +1,89 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cumulative
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectReset(t *testing.T) {
+	assert.Equal(t, NoReset, DetectReset(Point{StartTime: 1, IntValue: 5}, Point{StartTime: 1, IntValue: 5}))
+	assert.Equal(t, NoReset, DetectReset(Point{DoubleValue: 1.5}, Point{DoubleValue: 2}))
+	assert.Equal(t, StartTimeChanged, DetectReset(Point{StartTime: 1, IntValue: 5}, Point{StartTime: 2, IntValue: 8}))
+	assert.Equal(t, ValueDecreased, DetectReset(Point{StartTime: 1, IntValue: 5}, Point{StartTime: 1, IntValue: 2}))
+	assert.Equal(t, ValueDecreased, DetectReset(Point{DoubleValue: 1.5}, Point{DoubleValue: 1}))
+	// Unknown start times do not signal restarts.
+	assert.Equal(t, NoReset, DetectReset(Point{IntValue: 5}, Point{StartTime: 2, IntValue: 5}))
+	// Out of order data points do not signal restarts.
+	assert.Equal(t, NoReset, DetectReset(Point{Timestamp: 2, IntValue: 5}, Point{Timestamp: 1, IntValue: 2}))
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tracker := NewTracker(time.Minute, func() time.Time { return now })
+
+	_, ok := tracker.Record("a", Point{IntValue: 1})
+	assert.False(t, ok)
+	previous, ok := tracker.Record("a", Point{IntValue: 3})
+	assert.True(t, ok)
+	assert.Equal(t, Point{IntValue: 1}, previous)
+
+	now = now.Add(30 * time.Second)
+	tracker.Record("b", Point{IntValue: 1})
+	assert.Equal(t, 2, tracker.Len())
+
+	// Series a is forgotten once stale.
+	now = now.Add(45 * time.Second)
+	_, ok = tracker.Record("b", Point{IntValue: 2})
+	assert.True(t, ok)
+	assert.Equal(t, 1, tracker.Len())
+	_, ok = tracker.Record("a", Point{IntValue: 4})
+	assert.False(t, ok)
+}
+
+func TestTrackerWithoutMaxStaleness(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tracker := NewTracker(0, func() time.Time { return now })
+	tracker.Record("a", Point{IntValue: 1})
+	now = now.Add(24 * time.Hour)
+	_, ok := tracker.Record("a", Point{IntValue: 2})
+	assert.True(t, ok)
+}
+
+func TestTrackerOrdering(t *testing.T) {
+	tracker := NewTracker(0, nil)
+	_, ok := tracker.Record("a", Point{Timestamp: 10, IntValue: 1})
+	assert.False(t, ok)
+	_, ok = tracker.Record("a", Point{Timestamp: 10, IntValue: 1})
+	assert.False(t, ok)
+	previous, ok := tracker.Record("a", Point{Timestamp: 20, IntValue: 3})
+	assert.True(t, ok)
+	assert.Equal(t, Point{Timestamp: 10, IntValue: 1}, previous)
+
+	// A data point sent again is compared with the same preceding data point.
+	previous, ok = tracker.Record("a", Point{Timestamp: 20, IntValue: 3})
+	assert.True(t, ok)
+	assert.Equal(t, Point{Timestamp: 10, IntValue: 1}, previous)
+
+	// Out of order data points are ignored.
+	_, ok = tracker.Record("a", Point{Timestamp: 15, IntValue: 2})
+	assert.False(t, ok)
+	previous, ok = tracker.Record("a", Point{Timestamp: 30, IntValue: 6})
+	assert.True(t, ok)
+	assert.Equal(t, Point{Timestamp: 20, IntValue: 3}, previous)
+}
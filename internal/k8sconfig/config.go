@@ -56,6 +56,10 @@ type APIConfig struct {
 	// token provided to the agent pod), or `kubeConfig` to use credentials
 	// from `~/.kube/config`.
 	AuthType AuthType `mapstructure:"auth_type"`
+
+	// Context is the kubeconfig context to use with the `kubeConfig` auth type, e.g. to connect to one of several
+	// clusters. The current context is used when empty.
+	Context string `mapstructure:"context"`
 }
 
 // Validate validates the K8s API config
@@ -64,6 +68,10 @@ func (c APIConfig) Validate() error {
 		return fmt.Errorf("invalid authType for kubernetes: %v", c.AuthType)
 	}
 
+	if c.Context != "" && c.AuthType != AuthTypeKubeConfig {
+		return fmt.Errorf("context can only be set with authType %v", AuthTypeKubeConfig)
+	}
+
 	return nil
 }
 
@@ -86,7 +94,7 @@ func createRestConfig(apiConf APIConfig) (*rest.Config, error) {
 	switch authType {
 	case AuthTypeKubeConfig:
 		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-		configOverrides := &clientcmd.ConfigOverrides{}
+		configOverrides := &clientcmd.ConfigOverrides{CurrentContext: apiConf.Context}
 		authConf, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 			loadingRules, configOverrides).ClientConfig()
 
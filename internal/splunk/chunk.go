@@ -0,0 +1,180 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunk
+
+import (
+	"bytes"
+)
+
+// ChunkBudget bounds the chunks of serialized records sent in a single request. A zero limit means unbounded.
+type ChunkBudget struct {
+	// MaxContentLength is the maximum size in bytes of a chunk.
+	MaxContentLength int
+	// MaxEventCount is the maximum number of events of a chunk.
+	MaxEventCount int
+	// SplitByMetadata keeps records of different HEC metadata in separate chunks, so that a request never mixes
+	// indexes, sourcetypes or hosts, e.g. of different tenants.
+	SplitByMetadata bool
+}
+
+// ChunkMetadata is the HEC metadata chunks are split by, that of the first event of each record.
+type ChunkMetadata struct {
+	Index      string
+	SourceType string
+	Host       string
+}
+
+// EventChunkMetadata returns the chunk metadata of an event.
+func EventChunkMetadata(e *Event) ChunkMetadata {
+	return ChunkMetadata{Index: e.Index, SourceType: e.SourceType, Host: e.Host}
+}
+
+// Admits tells whether a record of the given size in bytes and number of events fits in a chunk on its own. Records
+// that are not admitted can never be sent.
+func (b ChunkBudget) Admits(size int, events int) bool {
+	return (b.MaxContentLength <= 0 || size <= b.MaxContentLength) && (b.MaxEventCount <= 0 || events <= b.MaxEventCount)
+}
+
+// ChunkCursor is the position of a record within the pending chunk of a Chunker, 0 for the first one. The cursor
+// after the last record is the number of pending records.
+type ChunkCursor int
+
+// Chunker accumulates serialized records, each holding one or more events, into a pending chunk. Records are never
+// split across chunks. Callers check whether a record fits with Fits, flush the pending chunk when it does not, and
+// Add it along with its metadata. Once a range of records is sent, Continue keeps the records that were not sent as
+// the continuation of the pending chunk.
+//
+// A Chunker is not safe for concurrent use. The zero value is an empty Chunker ready to use.
+type Chunker struct {
+	buf bytes.Buffer
+	// ends holds the end offset in buf of each pending record, and eventEnds the number of events up to and including
+	// each pending record.
+	ends      []int
+	eventEnds []int
+	// metadata holds the metadata of each pending record.
+	metadata []ChunkMetadata
+}
+
+// Fits tells whether a record of the given size in bytes, number of events and metadata can be added to the pending
+// chunk without exceeding the budget, nor mixing metadata if the budget splits by metadata. A record always fits in an
+// empty chunk.
+func (c *Chunker) Fits(budget ChunkBudget, size int, events int, metadata ChunkMetadata) bool {
+	if c.Len() == 0 {
+		return true
+	}
+	if budget.SplitByMetadata && metadata != c.metadata[len(c.metadata)-1] {
+		return false
+	}
+	return budget.Admits(c.Size()+size, c.Events()+events)
+}
+
+// Add appends a serialized record holding the given number of events of the given metadata to the pending chunk, and
+// returns its cursor.
+func (c *Chunker) Add(record []byte, events int, metadata ChunkMetadata) ChunkCursor {
+	c.buf.Write(record)
+	c.ends = append(c.ends, c.buf.Len())
+	c.eventEnds = append(c.eventEnds, c.Events()+events)
+	c.metadata = append(c.metadata, metadata)
+	return ChunkCursor(len(c.ends) - 1)
+}
+
+// Metadata returns the metadata of the record at the cursor.
+func (c *Chunker) Metadata(cursor ChunkCursor) ChunkMetadata {
+	return c.metadata[cursor]
+}
+
+// Len returns the number of pending records.
+func (c *Chunker) Len() int {
+	return len(c.ends)
+}
+
+// Size returns the size in bytes of the pending records.
+func (c *Chunker) Size() int {
+	return c.buf.Len()
+}
+
+// Events returns the number of events of the pending records.
+func (c *Chunker) Events() int {
+	if len(c.eventEnds) == 0 {
+		return 0
+	}
+	return c.eventEnds[len(c.eventEnds)-1]
+}
+
+// Payload returns the serialized records from one cursor up to the other, excluded. The payload is only valid until
+// the next call to Add, Continue or Reset.
+func (c *Chunker) Payload(from ChunkCursor, to ChunkCursor) []byte {
+	if from >= to {
+		return nil
+	}
+	return c.buf.Bytes()[c.start(from):c.ends[to-1]]
+}
+
+// EventCount returns the number of events of the records from one cursor up to the other, excluded.
+func (c *Chunker) EventCount(from ChunkCursor, to ChunkCursor) int {
+	if from >= to {
+		return 0
+	}
+	start := 0
+	if from > 0 {
+		start = c.eventEnds[from-1]
+	}
+	return c.eventEnds[to-1] - start
+}
+
+// Continue discards the records before the cursor, typically the ones that were sent, and keeps the records from the
+// cursor onwards as the pending chunk, their cursors shifted accordingly.
+func (c *Chunker) Continue(from ChunkCursor) {
+	if from <= 0 {
+		return
+	}
+	if int(from) >= c.Len() {
+		c.Reset()
+		return
+	}
+	end := c.ends[from-1]
+	remaining := c.buf.Bytes()[end:]
+	// The remaining bytes are copied, rather than moved within the buffer, as payloads handed out earlier may still be
+	// read, e.g. by a request being retried.
+	var buf bytes.Buffer
+	buf.Write(remaining)
+	c.buf = buf
+	c.ends = c.ends[:copy(c.ends, c.ends[from:])]
+	for i := range c.ends {
+		c.ends[i] -= end
+	}
+	eventEnd := c.eventEnds[from-1]
+	c.eventEnds = c.eventEnds[:copy(c.eventEnds, c.eventEnds[from:])]
+	for i := range c.eventEnds {
+		c.eventEnds[i] -= eventEnd
+	}
+	c.metadata = c.metadata[:copy(c.metadata, c.metadata[from:])]
+}
+
+// Reset discards all pending records.
+func (c *Chunker) Reset() {
+	c.buf.Reset()
+	c.ends = c.ends[:0]
+	c.eventEnds = c.eventEnds[:0]
+	c.metadata = c.metadata[:0]
+}
+
+// start returns the start offset of the record at the cursor.
+func (c *Chunker) start(cursor ChunkCursor) int {
+	if cursor == 0 {
+		return 0
+	}
+	return c.ends[cursor-1]
+}
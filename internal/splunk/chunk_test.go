@@ -0,0 +1,234 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkBudgetAdmits(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget ChunkBudget
+		size   int
+		events int
+		want   bool
+	}{
+		{name: "unbounded", budget: ChunkBudget{}, size: 1 << 30, events: 1 << 20, want: true},
+		{name: "below_content_length", budget: ChunkBudget{MaxContentLength: 10}, size: 9, events: 1, want: true},
+		{name: "at_content_length", budget: ChunkBudget{MaxContentLength: 10}, size: 10, events: 1, want: true},
+		{name: "above_content_length", budget: ChunkBudget{MaxContentLength: 10}, size: 11, events: 1, want: false},
+		{name: "at_event_count", budget: ChunkBudget{MaxEventCount: 2}, size: 100, events: 2, want: true},
+		{name: "above_event_count", budget: ChunkBudget{MaxEventCount: 2}, size: 100, events: 3, want: false},
+		{name: "both_limits", budget: ChunkBudget{MaxContentLength: 10, MaxEventCount: 2}, size: 10, events: 2, want: true},
+		{name: "both_limits_size_exceeded", budget: ChunkBudget{MaxContentLength: 10, MaxEventCount: 2}, size: 11, events: 2, want: false},
+		{name: "both_limits_count_exceeded", budget: ChunkBudget{MaxContentLength: 10, MaxEventCount: 2}, size: 10, events: 3, want: false},
+		{name: "negative_limits", budget: ChunkBudget{MaxContentLength: -1, MaxEventCount: -1}, size: 10, events: 3, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.budget.Admits(tt.size, tt.events))
+		})
+	}
+}
+
+func TestChunkerEmpty(t *testing.T) {
+	var c Chunker
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, 0, c.Size())
+	assert.Equal(t, 0, c.Events())
+	assert.Nil(t, c.Payload(0, 0))
+	assert.Equal(t, 0, c.EventCount(0, 0))
+	// A record always fits in an empty chunk, even one that is not admitted on its own.
+	assert.True(t, c.Fits(ChunkBudget{MaxContentLength: 1, MaxEventCount: 1}, 10, 10, ChunkMetadata{}))
+	c.Continue(1)
+	c.Reset()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestChunkerAdd(t *testing.T) {
+	var c Chunker
+	assert.Equal(t, ChunkCursor(0), c.Add([]byte("aa"), 1, ChunkMetadata{}))
+	assert.Equal(t, ChunkCursor(1), c.Add([]byte("bbb"), 2, ChunkMetadata{}))
+	assert.Equal(t, ChunkCursor(2), c.Add([]byte("c"), 3, ChunkMetadata{}))
+
+	assert.Equal(t, 3, c.Len())
+	assert.Equal(t, 6, c.Size())
+	assert.Equal(t, 6, c.Events())
+	assert.Equal(t, "aabbbc", string(c.Payload(0, 3)))
+	assert.Equal(t, "aa", string(c.Payload(0, 1)))
+	assert.Equal(t, "bbb", string(c.Payload(1, 2)))
+	assert.Equal(t, "bbbc", string(c.Payload(1, 3)))
+	assert.Nil(t, c.Payload(2, 2))
+	assert.Nil(t, c.Payload(2, 1))
+	assert.Equal(t, 6, c.EventCount(0, 3))
+	assert.Equal(t, 2, c.EventCount(1, 2))
+	assert.Equal(t, 5, c.EventCount(1, 3))
+	assert.Equal(t, 0, c.EventCount(3, 3))
+}
+
+func TestChunkerFits(t *testing.T) {
+	var c Chunker
+	c.Add([]byte("aaaa"), 2, ChunkMetadata{})
+
+	tests := []struct {
+		name   string
+		budget ChunkBudget
+		size   int
+		events int
+		want   bool
+	}{
+		{name: "unbounded", budget: ChunkBudget{}, size: 100, events: 100, want: true},
+		{name: "fills_content_length", budget: ChunkBudget{MaxContentLength: 10}, size: 6, events: 1, want: true},
+		{name: "exceeds_content_length", budget: ChunkBudget{MaxContentLength: 10}, size: 7, events: 1, want: false},
+		{name: "fills_event_count", budget: ChunkBudget{MaxEventCount: 5}, size: 100, events: 3, want: true},
+		{name: "exceeds_event_count", budget: ChunkBudget{MaxEventCount: 5}, size: 100, events: 4, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Fits(tt.budget, tt.size, tt.events, ChunkMetadata{}))
+		})
+	}
+}
+
+func TestChunkerContinue(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        ChunkCursor
+		wantLen     int
+		wantPayload string
+		wantEvents  int
+	}{
+		{name: "nothing_sent", from: 0, wantLen: 3, wantPayload: "aabbbc", wantEvents: 6},
+		{name: "first_sent", from: 1, wantLen: 2, wantPayload: "bbbc", wantEvents: 5},
+		{name: "two_sent", from: 2, wantLen: 1, wantPayload: "c", wantEvents: 3},
+		{name: "all_sent", from: 3, wantLen: 0, wantPayload: "", wantEvents: 0},
+		{name: "beyond_end", from: 4, wantLen: 0, wantPayload: "", wantEvents: 0},
+		{name: "negative", from: -1, wantLen: 3, wantPayload: "aabbbc", wantEvents: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Chunker
+			c.Add([]byte("aa"), 1, ChunkMetadata{})
+			c.Add([]byte("bbb"), 2, ChunkMetadata{})
+			c.Add([]byte("c"), 3, ChunkMetadata{})
+
+			c.Continue(tt.from)
+			assert.Equal(t, tt.wantLen, c.Len())
+			assert.Equal(t, len(tt.wantPayload), c.Size())
+			assert.Equal(t, tt.wantPayload, string(c.Payload(0, ChunkCursor(c.Len()))))
+			assert.Equal(t, tt.wantEvents, c.Events())
+			assert.Equal(t, tt.wantEvents, c.EventCount(0, ChunkCursor(c.Len())))
+		})
+	}
+}
+
+func TestChunkerContinueShiftsCursors(t *testing.T) {
+	var c Chunker
+	c.Add([]byte("aa"), 1, ChunkMetadata{})
+	c.Add([]byte("bbb"), 2, ChunkMetadata{})
+	c.Add([]byte("c"), 3, ChunkMetadata{})
+	c.Continue(1)
+
+	assert.Equal(t, "bbb", string(c.Payload(0, 1)))
+	assert.Equal(t, "c", string(c.Payload(1, 2)))
+	assert.Equal(t, 2, c.EventCount(0, 1))
+	assert.Equal(t, 3, c.EventCount(1, 2))
+
+	// Records added afterwards follow the continuation.
+	assert.Equal(t, ChunkCursor(2), c.Add([]byte("dd"), 4, ChunkMetadata{}))
+	assert.Equal(t, "bbbcdd", string(c.Payload(0, 3)))
+	assert.Equal(t, 9, c.Events())
+}
+
+func TestChunkerContinueKeepsPayloads(t *testing.T) {
+	var c Chunker
+	c.Add([]byte("aa"), 1, ChunkMetadata{})
+	c.Add([]byte("bbb"), 1, ChunkMetadata{})
+	payload := c.Payload(0, 2)
+
+	c.Continue(1)
+	c.Add([]byte("zzzzz"), 1, ChunkMetadata{})
+	assert.Equal(t, "aabbb", string(payload))
+	assert.Equal(t, "bbbzzzzz", string(c.Payload(0, 2)))
+}
+
+func TestChunkerReset(t *testing.T) {
+	var c Chunker
+	c.Add([]byte("aa"), 1, ChunkMetadata{})
+	c.Add([]byte("bbb"), 2, ChunkMetadata{})
+	c.Reset()
+
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, 0, c.Size())
+	assert.Equal(t, 0, c.Events())
+	assert.Equal(t, ChunkCursor(0), c.Add([]byte("c"), 3, ChunkMetadata{}))
+	assert.Equal(t, "c", string(c.Payload(0, 1)))
+	assert.Equal(t, 3, c.Events())
+}
+
+func TestChunkerBatches(t *testing.T) {
+	// Chunks records the way senders do: flushing the pending chunk whenever the next record does not fit.
+	budget := ChunkBudget{MaxContentLength: 6, MaxEventCount: 3}
+	records := []struct {
+		data   string
+		events int
+	}{
+		{"aa", 1}, {"bb", 1}, {"cc", 1}, {"dddd", 1}, {"e", 2}, {"f", 2}, {"gggggg", 1},
+	}
+	var c Chunker
+	var chunks []string
+	flush := func() {
+		chunks = append(chunks, string(c.Payload(0, ChunkCursor(c.Len()))))
+		c.Reset()
+	}
+	for _, r := range records {
+		assert.True(t, budget.Admits(len(r.data), r.events))
+		if !c.Fits(budget, len(r.data), r.events, ChunkMetadata{}) {
+			flush()
+		}
+		c.Add([]byte(r.data), r.events, ChunkMetadata{})
+	}
+	flush()
+	assert.Equal(t, []string{"aabbcc", "dddde", "f", "gggggg"}, chunks)
+}
+
+func TestChunkerSplitByMetadata(t *testing.T) {
+	main := ChunkMetadata{Index: "main", SourceType: "otel", Host: "host1"}
+	other := ChunkMetadata{Index: "other", SourceType: "otel", Host: "host1"}
+	otherHost := ChunkMetadata{Index: "main", SourceType: "otel", Host: "host2"}
+
+	var c Chunker
+	c.Add([]byte("aa"), 1, main)
+	assert.True(t, c.Fits(ChunkBudget{}, 2, 1, other), "metadata is ignored unless splitting by it")
+	budget := ChunkBudget{SplitByMetadata: true}
+	assert.True(t, c.Fits(budget, 2, 1, main))
+	assert.False(t, c.Fits(budget, 2, 1, other))
+	assert.False(t, c.Fits(budget, 2, 1, otherHost))
+	assert.False(t, c.Fits(ChunkBudget{SplitByMetadata: true, MaxContentLength: 3}, 2, 1, main), "the size still bounds chunks")
+
+	c.Add([]byte("bb"), 1, main)
+	c.Continue(1)
+	assert.Equal(t, main, c.Metadata(0))
+	c.Reset()
+	assert.True(t, c.Fits(budget, 2, 1, other), "a record always fits in an empty chunk")
+}
+
+func TestEventChunkMetadata(t *testing.T) {
+	e := &Event{Index: "main", SourceType: "otel", Host: "host1", Source: "ignored"}
+	assert.Equal(t, ChunkMetadata{Index: "main", SourceType: "otel", Host: "host1"}, EventChunkMetadata(e))
+}
@@ -25,10 +25,14 @@ const (
 	SFxAccessTokenLabel   = "com.splunk.signalfx.access_token" // #nosec
 	SFxEventCategoryKey   = "com.splunk.signalfx.event_category"
 	SFxEventPropertiesKey = "com.splunk.signalfx.event_properties"
+	SourceLabel           = "com.splunk.source"
 	SourcetypeLabel       = "com.splunk.sourcetype"
 	IndexLabel            = "com.splunk.index"
+	HostLabel             = "com.splunk.host"
 	HECTokenHeader        = "Splunk"
 	HecTokenLabel         = "com.splunk.hec.access_token" // #nosec
+	// HecPrechunkedLabel marks the resources of log batches already sized for a single HEC request.
+	HecPrechunkedLabel = "com.splunk.hec.prechunked"
 	// HecEventMetricType is the type of HEC event. Set to metric, as per https://docs.splunk.com/Documentation/Splunk/8.0.3/Metrics/GetMetricsInOther.
 	HecEventMetricType = "metric"
 )
@@ -39,6 +43,18 @@ type AccessTokenPassthroughConfig struct {
 	AccessTokenPassthrough bool `mapstructure:"access_token_passthrough"`
 }
 
+// HecToOtelAttrs defines the mapping of Splunk HEC metadata to attributes.
+type HecToOtelAttrs struct {
+	// Source indicates the mapping of the source field to a specific unified model attribute.
+	Source string `mapstructure:"source"`
+	// SourceType indicates the mapping of the sourcetype field to a specific unified model attribute.
+	SourceType string `mapstructure:"sourcetype"`
+	// Index indicates the mapping of the index field to a specific unified model attribute.
+	Index string `mapstructure:"index"`
+	// Host indicates the mapping of the host field to a specific unified model attribute.
+	Host string `mapstructure:"host"`
+}
+
 // Event represents a metric in Splunk HEC format
 type Event struct {
 	Time       *float64               `json:"time,omitempty"`       // optional epoch time - set to nil if the event timestamp is missing or unknown
@@ -48,6 +64,9 @@ type Event struct {
 	Index      string                 `json:"index,omitempty"`      // optional name of the Splunk index to store the event in; not required if the token has a default index set in Splunk
 	Event      interface{}            `json:"event"`                // type of event: set to "metric" or nil if the event represents a metric, or is the payload of the event.
 	Fields     map[string]interface{} `json:"fields,omitempty"`     // dimensions and metric data
+	// TimeNanos is the exact time of the event in nanoseconds since epoch, if set, which float64 Time cannot hold. It is
+	// serialized in place of Time by the Splunk HEC exporter when sending nanosecond timestamps.
+	TimeNanos uint64 `json:"-"`
 }
 
 // IsMetric returns true if the Splunk event is a metric.
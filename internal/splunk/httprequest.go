@@ -16,6 +16,8 @@ package splunk
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"time"
@@ -26,6 +28,9 @@ import (
 
 const HeaderRetryAfter = "Retry-After"
 
+// MaxDrainedBodySize is the maximum number of bytes read from the rest of response bodies by DrainAndClose.
+const MaxDrainedBodySize = 4 * 1024
+
 // HandleHTTPCode handles an http response and returns the right type of error in case of a failure.
 func HandleHTTPCode(resp *http.Response) error {
 	// SignalFx accepts all 2XX codes.
@@ -58,3 +63,18 @@ func HandleHTTPCode(resp *http.Response) error {
 
 	return err
 }
+
+// DrainAndClose reads the rest of a response body, up to MaxDrainedBodySize bytes, so that its connection is reused
+// for the next requests, and closes it. The connection of larger bodies is closed instead, rather than letting a
+// misbehaving endpoint stream unbounded data into the exporter. It returns the error of reading or closing the body.
+func DrainAndClose(body io.ReadCloser) error {
+	// The extra byte reads the end of bodies of exactly MaxDrainedBodySize bytes.
+	_, err := io.CopyN(ioutil.Discard, body, MaxDrainedBodySize+1)
+	if err == io.EOF {
+		err = nil
+	}
+	if closeErr := body.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -15,13 +15,17 @@
 package splunk
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 )
@@ -95,3 +99,41 @@ func TestConsumeMetrics(t *testing.T) {
 		})
 	}
 }
+
+// endlessBody is a response body that never ends, counting the bytes read from it.
+type endlessBody struct {
+	read   int
+	closed bool
+}
+
+func (b *endlessBody) Read(p []byte) (int, error) {
+	b.read += len(p)
+	return len(p), nil
+}
+
+func (b *endlessBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// failingBody is a response body whose reads fail.
+type failingBody struct {
+	endlessBody
+}
+
+func (b *failingBody) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestDrainAndClose(t *testing.T) {
+	body := &endlessBody{}
+	require.NoError(t, DrainAndClose(body))
+	assert.True(t, body.closed)
+	assert.LessOrEqual(t, body.read, MaxDrainedBodySize+1)
+
+	assert.NoError(t, DrainAndClose(ioutil.NopCloser(strings.NewReader("short"))))
+
+	failing := &failingBody{}
+	assert.EqualError(t, DrainAndClose(failing), "connection reset")
+	assert.True(t, failing.closed)
+}
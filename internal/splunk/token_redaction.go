@@ -0,0 +1,245 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunk
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedToken replaces the access tokens redacted by a TokenRedactor.
+const RedactedToken = "<redacted token>"
+
+const (
+	// minRedactedTokenLength is the length under which secrets are not redacted, as they would redact common
+	// substrings of the logs rather than secrets.
+	minRedactedTokenLength = 8
+	// maxPassthroughTokens is the number of most recently seen passthrough tokens that are redacted.
+	maxPassthroughTokens = 256
+)
+
+// TokenRedactor scrubs access tokens from log messages and error strings, so that they never appear in the logs of
+// the collector. Tokens are known upfront, set per source as they are refreshed or rotated, or passed through from
+// receivers, of which only the most recently seen are redacted. A nil TokenRedactor redacts nothing.
+type TokenRedactor struct {
+	mu sync.RWMutex
+	// static holds the tokens known upfront.
+	static []string
+	// sources holds the current token of each source.
+	sources map[string]string
+	// passthrough holds the most recently seen passthrough tokens, the most recent first, indexed by passthroughIndex.
+	passthrough      *list.List
+	passthroughIndex map[string]*list.Element
+	replacer         *strings.Replacer
+}
+
+// NewTokenRedactor returns a TokenRedactor of the given tokens. Empty and very short tokens are ignored.
+func NewTokenRedactor(tokens ...string) *TokenRedactor {
+	r := &TokenRedactor{
+		sources:          map[string]string{},
+		passthrough:      list.New(),
+		passthroughIndex: map[string]*list.Element{},
+	}
+	for _, token := range tokens {
+		if redactable(token) {
+			r.static = append(r.static, token)
+		}
+	}
+	r.rebuild()
+	return r
+}
+
+// Set sets the token of the given source, e.g. a refreshed access token, which replaces the previous token of the
+// source. An empty token removes the token of the source. Very short tokens are ignored.
+func (r *TokenRedactor) Set(source string, token string) {
+	if r == nil {
+		return
+	}
+	if !redactable(token) {
+		token = ""
+	}
+	r.mu.RLock()
+	current, ok := r.sources[source]
+	r.mu.RUnlock()
+	if (ok && current == token) || (!ok && token == "") {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token == "" {
+		delete(r.sources, source)
+	} else {
+		r.sources[source] = token
+	}
+	r.rebuild()
+}
+
+// AddPassthrough adds a token passed through from receivers. Only the maxPassthroughTokens most recently seen ones
+// are redacted. Empty and very short tokens are ignored.
+func (r *TokenRedactor) AddPassthrough(token string) {
+	if r == nil || !redactable(token) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.passthroughIndex[token]; ok {
+		r.passthrough.MoveToFront(e)
+		return
+	}
+	r.passthroughIndex[token] = r.passthrough.PushFront(token)
+	if r.passthrough.Len() > maxPassthroughTokens {
+		oldest := r.passthrough.Back()
+		r.passthrough.Remove(oldest)
+		delete(r.passthroughIndex, oldest.Value.(string))
+	}
+	r.rebuild()
+}
+
+// rebuild builds the replacer of the current tokens, called with the lock held.
+func (r *TokenRedactor) rebuild() {
+	pairs := make([]string, 0, 2*(len(r.static)+len(r.sources)+r.passthrough.Len()))
+	for _, token := range r.static {
+		pairs = append(pairs, token, RedactedToken)
+	}
+	for _, token := range r.sources {
+		pairs = append(pairs, token, RedactedToken)
+	}
+	for e := r.passthrough.Front(); e != nil; e = e.Next() {
+		pairs = append(pairs, e.Value.(string), RedactedToken)
+	}
+	if len(pairs) == 0 {
+		r.replacer = nil
+		return
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+// redactable tells whether the token is long enough to be redacted.
+func redactable(token string) bool {
+	return len(token) >= minRedactedTokenLength
+}
+
+// Redact returns the string with the tokens replaced by RedactedToken.
+func (r *TokenRedactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	r.mu.RLock()
+	replacer := r.replacer
+	r.mu.RUnlock()
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// RedactError returns the error with the tokens redacted from its message. Errors without tokens are returned as is,
+// and redacted errors wrap the original one, so that errors.Is, errors.As and consumererror.IsPermanent still apply.
+// Partial errors are returned as is since the data they hold cannot be rewrapped, their errors are never expected to
+// hold tokens.
+func (r *TokenRedactor) RedactError(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	if _, ok := err.(consumererror.PartialError); ok {
+		return err
+	}
+	msg := err.Error()
+	if redacted := r.Redact(msg); redacted != msg {
+		return &redactedError{msg: redacted, err: err}
+	}
+	return err
+}
+
+// Logger returns a logger redacting the tokens from the messages and the string, byte string, stringer and error
+// fields of the entries logged by the given logger.
+func (r *TokenRedactor) Logger(logger *zap.Logger) *zap.Logger {
+	if r == nil {
+		return logger
+	}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core, redactor: r}
+	}))
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactingCore redacts the tokens from the entries written to the wrapped core.
+type redactingCore struct {
+	zapcore.Core
+	redactor *TokenRedactor
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.Redact(entry.Message)
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = c.redactor.Redact(f.String)
+		case zapcore.ByteStringType:
+			f = zap.String(f.Key, c.redactor.Redact(string(f.Interface.([]byte))))
+		case zapcore.StringerType:
+			f = zap.String(f.Key, c.redactor.Redact(stringerString(f.Interface.(fmt.Stringer))))
+		case zapcore.ErrorType:
+			f = zap.NamedError(f.Key, c.redactor.RedactError(f.Interface.(error)))
+		}
+		redacted[i] = f
+	}
+	return redacted
+}
+
+// stringerString returns the string of a stringer, or of the panic of a stringer of a nil pointer, as zap does.
+func stringerString(s fmt.Stringer) (str string) {
+	defer func() {
+		if err := recover(); err != nil {
+			str = fmt.Sprintf("PANIC=%v", err)
+		}
+	}()
+	return s.String()
+}
@@ -0,0 +1,127 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunk
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTokenRedactorRedact(t *testing.T) {
+	r := NewTokenRedactor("secret-1", "", "short")
+	assert.Equal(t, "token <redacted token> rejected", r.Redact("token secret-1 rejected"))
+	assert.Equal(t, "token secret-2 rejected", r.Redact("token secret-2 rejected"))
+	assert.Equal(t, "short", r.Redact("short"), "short tokens are ignored")
+
+	r.Set("oauth2", "secret-2")
+	assert.Equal(t, "<redacted token>, <redacted token>", r.Redact("secret-1, secret-2"))
+	assert.Equal(t, "", r.Redact(""), "empty tokens are ignored")
+
+	// A refreshed token replaces the previous one of its source.
+	r.Set("oauth2", "secret-3")
+	assert.Equal(t, "secret-2, <redacted token>", r.Redact("secret-2, secret-3"))
+	r.Set("oauth2", "")
+	assert.Equal(t, "secret-3", r.Redact("secret-3"))
+	assert.Equal(t, "<redacted token>", r.Redact("secret-1"), "tokens known upfront are kept")
+
+	var nilRedactor *TokenRedactor
+	nilRedactor.Set("oauth2", "secret-1")
+	nilRedactor.AddPassthrough("secret-1")
+	assert.Equal(t, "secret-1", nilRedactor.Redact("secret-1"))
+	assert.Equal(t, "no tokens", NewTokenRedactor().Redact("no tokens"))
+}
+
+func TestTokenRedactorPassthrough(t *testing.T) {
+	r := NewTokenRedactor()
+	for i := 0; i < maxPassthroughTokens; i++ {
+		r.AddPassthrough(fmt.Sprintf("passthrough-%d", i))
+	}
+	// Seeing the oldest token again makes it the most recent one.
+	r.AddPassthrough("passthrough-0")
+	r.AddPassthrough("passthrough-new")
+	assert.Equal(t, "<redacted token>", r.Redact("passthrough-0"))
+	assert.Equal(t, "<redacted token>", r.Redact("passthrough-new"))
+	assert.Equal(t, "passthrough-1", r.Redact("passthrough-1"), "the least recently seen token is evicted")
+	assert.Equal(t, maxPassthroughTokens, r.passthrough.Len())
+	assert.Len(t, r.passthroughIndex, maxPassthroughTokens)
+}
+
+func TestTokenRedactorRedactError(t *testing.T) {
+	r := NewTokenRedactor("secret-token")
+
+	assert.Nil(t, r.RedactError(nil))
+	clean := errors.New("no token")
+	assert.Same(t, clean, r.RedactError(clean))
+
+	urlErr := &url.Error{Op: "Post", URL: "https://ingest.example.com/?token=secret-token", Err: errors.New("timeout")}
+	permanent := consumererror.Permanent(urlErr)
+	err := r.RedactError(permanent)
+	assert.Equal(t, `Permanent error: Post "https://ingest.example.com/?token=<redacted token>": timeout`, err.Error())
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.True(t, errors.Is(err, permanent))
+
+	partial := consumererror.PartialLogsError(errors.New("secret-token"), pdata.NewLogs())
+	_, ok := r.RedactError(partial).(consumererror.PartialError)
+	assert.True(t, ok, "partial errors are kept")
+}
+
+type stringer string
+
+func (s stringer) String() string {
+	return string(s)
+}
+
+func TestTokenRedactorLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	r := NewTokenRedactor("secret-token")
+	logger := r.Logger(zap.New(core)).With(zap.String("with", "secret-token"))
+
+	logger.Debug("request with secret-token",
+		zap.String("string", "token=secret-token"),
+		zap.ByteString("bytes", []byte("secret-token")),
+		zap.Stringer("stringer", stringer("secret-token")),
+		zap.Error(fmt.Errorf("rejected secret-token")),
+		zap.Int("int", 1))
+	r.AddPassthrough("later-token")
+	logger.Info("later-token")
+
+	require.Equal(t, 2, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "request with <redacted token>", entry.Message)
+	assert.Equal(t, map[string]interface{}{
+		"with":     "<redacted token>",
+		"string":   "token=<redacted token>",
+		"bytes":    "<redacted token>",
+		"stringer": "<redacted token>",
+		"error":    "rejected <redacted token>",
+		"int":      int64(1),
+	}, entry.ContextMap())
+	assert.Equal(t, "<redacted token>", logs.All()[1].Message)
+	assert.NotContains(t, fmt.Sprint(logs.All()), "secret")
+
+	var nilRedactor *TokenRedactor
+	plain := zap.New(core)
+	assert.Same(t, plain, nilRedactor.Logger(plain))
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cumulativetodeltaprocessor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	// typeStr is the value of "type" for this processor in the configuration.
+	typeStr configmodels.Type = "cumulativetodelta"
+
+	defaultMaxStaleness = time.Hour
+)
+
+var (
+	errNegativeMaxStaleness = errors.New(`"max_staleness" cannot be negative`)
+	processorCapabilities   = component.ProcessorCapabilities{MutatesConsumedData: true}
+)
+
+// NewFactory returns a new factory for the cumulative to delta processor.
+func NewFactory() component.ProcessorFactory {
+	return processorhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		processorhelper.WithMetrics(createMetricsProcessor))
+}
+
+// createDefaultConfig creates the default configuration for the processor.
+func createDefaultConfig() configmodels.Processor {
+	return &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: string(typeStr),
+		},
+		MaxStaleness: defaultMaxStaleness,
+	}
+}
+
+// createMetricsProcessor creates a metrics processor based on this config.
+func createMetricsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateParams,
+	cfg configmodels.Processor,
+	nextConsumer consumer.Metrics) (component.MetricsProcessor, error) {
+
+	oCfg := cfg.(*Config)
+	if oCfg.MaxStaleness < 0 {
+		return nil, errNegativeMaxStaleness
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		cfg,
+		nextConsumer,
+		newCumulativeToDeltaProcessor(params.Logger, oCfg),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
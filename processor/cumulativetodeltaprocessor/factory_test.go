@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cumulativetodeltaprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func TestDefaultConfiguration(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	assert.Empty(t, c.Metrics)
+	assert.Equal(t, time.Hour, c.MaxStaleness)
+}
+
+func TestCreateMetricsProcessor(t *testing.T) {
+	params := component.ProcessorCreateParams{Logger: zap.NewNop()}
+	mp, err := createMetricsProcessor(context.Background(), params, createDefaultConfig(), consumertest.NewMetricsNop())
+	assert.NoError(t, err)
+	assert.NotNil(t, mp)
+	assert.Equal(t, true, mp.GetCapabilities().MutatesConsumedData)
+
+	mp, err = createMetricsProcessor(context.Background(), params, &Config{MaxStaleness: -time.Second}, consumertest.NewMetricsNop())
+	assert.Equal(t, errNegativeMaxStaleness, err)
+	assert.Nil(t, mp)
+}
@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cumulativetodeltaprocessor
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/consumer/pdata"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/cumulative"
+)
+
+// cumulativeToDeltaProcessor converts cumulative monotonic sums to delta sums, remembering the last data point of
+// each series to send its difference with the previous data point instead of its cumulative value.
+type cumulativeToDeltaProcessor struct {
+	logger *zap.Logger
+	// metrics are the names of the converted metrics, all of them if empty.
+	metrics map[string]struct{}
+	// series remembers the last data point of each series, as the splunkhec exporter does to send deltas.
+	series *cumulative.Tracker
+}
+
+func newCumulativeToDeltaProcessor(logger *zap.Logger, cfg *Config) *cumulativeToDeltaProcessor {
+	metrics := make(map[string]struct{}, len(cfg.Metrics))
+	for _, name := range cfg.Metrics {
+		metrics[name] = struct{}{}
+	}
+	return &cumulativeToDeltaProcessor{
+		logger:  logger,
+		metrics: metrics,
+		series:  cumulative.NewTracker(cfg.MaxStaleness, nil),
+	}
+}
+
+// ProcessMetrics converts the cumulative monotonic sums of md to delta sums. The first data point of each series is
+// dropped, as there is nothing to subtract from its value, along with the metrics left without data points.
+func (p *cumulativeToDeltaProcessor) ProcessMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceKey := attributesKey(rm.Resource().Attributes())
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			ms := ilms.At(j).Metrics()
+			kept := pdata.NewMetricSlice()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				if p.convert(resourceKey, m) {
+					kept.Append(m)
+				}
+			}
+			if kept.Len() < ms.Len() {
+				ms.Resize(0)
+				kept.MoveAndAppendTo(ms)
+			}
+		}
+	}
+	return md, nil
+}
+
+// convert converts the metric to a delta sum if it is a converted cumulative monotonic sum, and returns whether it
+// still has data points.
+func (p *cumulativeToDeltaProcessor) convert(resourceKey string, m pdata.Metric) bool {
+	if len(p.metrics) > 0 {
+		if _, ok := p.metrics[m.Name()]; !ok {
+			return true
+		}
+	}
+	switch m.DataType() {
+	case pdata.MetricDataTypeIntSum:
+		sum := m.IntSum()
+		if !sum.IsMonotonic() || sum.AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return true
+		}
+		sum.SetAggregationTemporality(pdata.AggregationTemporalityDelta)
+		pts := sum.DataPoints()
+		kept := pdata.NewIntDataPointSlice()
+		for i := 0; i < pts.Len(); i++ {
+			pt := pts.At(i)
+			if p.convertIntDataPoint(seriesKey(m.Name(), resourceKey, pt.LabelsMap()), pt) {
+				kept.Append(pt)
+			}
+		}
+		if kept.Len() < pts.Len() {
+			pts.Resize(0)
+			kept.MoveAndAppendTo(pts)
+		}
+		return pts.Len() > 0
+	case pdata.MetricDataTypeDoubleSum:
+		sum := m.DoubleSum()
+		if !sum.IsMonotonic() || sum.AggregationTemporality() != pdata.AggregationTemporalityCumulative {
+			return true
+		}
+		sum.SetAggregationTemporality(pdata.AggregationTemporalityDelta)
+		pts := sum.DataPoints()
+		kept := pdata.NewDoubleDataPointSlice()
+		for i := 0; i < pts.Len(); i++ {
+			pt := pts.At(i)
+			if p.convertDoubleDataPoint(seriesKey(m.Name(), resourceKey, pt.LabelsMap()), pt) {
+				kept.Append(pt)
+			}
+		}
+		if kept.Len() < pts.Len() {
+			pts.Resize(0)
+			kept.MoveAndAppendTo(pts)
+		}
+		return pts.Len() > 0
+	default:
+		return true
+	}
+}
+
+// convertIntDataPoint records the data point of the series and replaces its value with the difference with the
+// previous data point, returning false if the series was not seen before. The value of a restarted counter is its
+// delta since the restart.
+func (p *cumulativeToDeltaProcessor) convertIntDataPoint(key string, pt pdata.IntDataPoint) bool {
+	point := cumulative.Point{StartTime: uint64(pt.StartTime()), Timestamp: uint64(pt.Timestamp()), IntValue: pt.Value()}
+	previous, ok := p.series.Record(key, point)
+	if !ok {
+		return false
+	}
+	if cumulative.DetectReset(previous, point) == cumulative.NoReset {
+		pt.SetStartTime(pdata.Timestamp(previous.Timestamp))
+		pt.SetValue(pt.Value() - previous.IntValue)
+	}
+	return true
+}
+
+// convertDoubleDataPoint records the data point of the series and replaces its value with the difference with the
+// previous data point, returning false if the series was not seen before. The value of a restarted counter is its
+// delta since the restart.
+func (p *cumulativeToDeltaProcessor) convertDoubleDataPoint(key string, pt pdata.DoubleDataPoint) bool {
+	point := cumulative.Point{StartTime: uint64(pt.StartTime()), Timestamp: uint64(pt.Timestamp()), DoubleValue: pt.Value()}
+	previous, ok := p.series.Record(key, point)
+	if !ok {
+		return false
+	}
+	if cumulative.DetectReset(previous, point) == cumulative.NoReset {
+		pt.SetStartTime(pdata.Timestamp(previous.Timestamp))
+		pt.SetValue(pt.Value() - previous.DoubleValue)
+	}
+	return true
+}
+
+// seriesKey identifies the series of a data point by the name of its metric, its resource and its labels.
+func seriesKey(name string, resourceKey string, labels pdata.StringMap) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte(0)
+	b.WriteString(resourceKey)
+	keys := make([]string, 0, labels.Len())
+	labels.ForEach(func(k string, _ string) {
+		keys = append(keys, k)
+	})
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, _ := labels.Get(k)
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// attributesKey returns a string identifying the attributes of a resource, whatever their order.
+func attributesKey(attrs pdata.AttributeMap) string {
+	keys := make([]string, 0, attrs.Len())
+	attrs.ForEach(func(k string, _ pdata.AttributeValue) {
+		keys = append(keys, k)
+	})
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		v, _ := attrs.Get(k)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tracetranslator.AttributeValueToString(v, false))
+		b.WriteByte(0)
+	}
+	return b.String()
+}
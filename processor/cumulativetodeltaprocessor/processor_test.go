@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cumulativetodeltaprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/cumulative"
+)
+
+type testPoint struct {
+	start int64
+	ts    int64
+	value float64
+}
+
+func newTestMetrics(name string, dataType pdata.MetricDataType, monotonic bool, points ...testPoint) pdata.Metrics {
+	md := pdata.NewMetrics()
+	md.ResourceMetrics().Resize(1)
+	rm := md.ResourceMetrics().At(0)
+	rm.Resource().Attributes().InsertString("host.name", "host1")
+	rm.InstrumentationLibraryMetrics().Resize(1)
+	ms := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+	ms.Resize(1)
+	m := ms.At(0)
+	m.SetName(name)
+	m.SetDataType(dataType)
+	switch dataType {
+	case pdata.MetricDataTypeIntSum:
+		m.IntSum().SetIsMonotonic(monotonic)
+		m.IntSum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		pts := m.IntSum().DataPoints()
+		pts.Resize(len(points))
+		for i, p := range points {
+			pts.At(i).SetStartTime(pdata.Timestamp(p.start))
+			pts.At(i).SetTimestamp(pdata.Timestamp(p.ts))
+			pts.At(i).SetValue(int64(p.value))
+			pts.At(i).LabelsMap().Insert("point", string(rune('a'+i)))
+		}
+	case pdata.MetricDataTypeDoubleSum:
+		m.DoubleSum().SetIsMonotonic(monotonic)
+		m.DoubleSum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+		pts := m.DoubleSum().DataPoints()
+		pts.Resize(len(points))
+		for i, p := range points {
+			pts.At(i).SetStartTime(pdata.Timestamp(p.start))
+			pts.At(i).SetTimestamp(pdata.Timestamp(p.ts))
+			pts.At(i).SetValue(p.value)
+			pts.At(i).LabelsMap().Insert("point", string(rune('a'+i)))
+		}
+	}
+	return md
+}
+
+// testPoints returns the data points of the first metric of md, or nil if md has no metric.
+func testPoints(md pdata.Metrics) []testPoint {
+	ms := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	if ms.Len() == 0 {
+		return nil
+	}
+	var points []testPoint
+	m := ms.At(0)
+	switch m.DataType() {
+	case pdata.MetricDataTypeIntSum:
+		for i := 0; i < m.IntSum().DataPoints().Len(); i++ {
+			pt := m.IntSum().DataPoints().At(i)
+			points = append(points, testPoint{int64(pt.StartTime()), int64(pt.Timestamp()), float64(pt.Value())})
+		}
+	case pdata.MetricDataTypeDoubleSum:
+		for i := 0; i < m.DoubleSum().DataPoints().Len(); i++ {
+			pt := m.DoubleSum().DataPoints().At(i)
+			points = append(points, testPoint{int64(pt.StartTime()), int64(pt.Timestamp()), pt.Value()})
+		}
+	}
+	return points
+}
+
+func TestProcessMetrics(t *testing.T) {
+	for _, dataType := range []pdata.MetricDataType{pdata.MetricDataTypeIntSum, pdata.MetricDataTypeDoubleSum} {
+		t.Run(dataType.String(), func(t *testing.T) {
+			p := newCumulativeToDeltaProcessor(zap.NewNop(), createDefaultConfig().(*Config))
+
+			md, err := p.ProcessMetrics(context.Background(), newTestMetrics("requests", dataType, true,
+				testPoint{start: 1, ts: 10, value: 100}))
+			require.NoError(t, err)
+			assert.Nil(t, testPoints(md), "the first data point of a series has no delta")
+
+			md, err = p.ProcessMetrics(context.Background(), newTestMetrics("requests", dataType, true,
+				testPoint{start: 1, ts: 20, value: 130}, testPoint{start: 1, ts: 20, value: 7}))
+			require.NoError(t, err)
+			assert.Equal(t, []testPoint{{start: 10, ts: 20, value: 30}}, testPoints(md))
+			m := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
+			if dataType == pdata.MetricDataTypeIntSum {
+				assert.Equal(t, pdata.AggregationTemporalityDelta, m.IntSum().AggregationTemporality())
+			} else {
+				assert.Equal(t, pdata.AggregationTemporalityDelta, m.DoubleSum().AggregationTemporality())
+			}
+
+			md, err = p.ProcessMetrics(context.Background(), newTestMetrics("requests", dataType, true,
+				testPoint{start: 1, ts: 30, value: 20}, testPoint{start: 1, ts: 30, value: 9}))
+			require.NoError(t, err)
+			assert.Equal(t, []testPoint{{start: 1, ts: 30, value: 20}, {start: 20, ts: 30, value: 2}}, testPoints(md),
+				"a lower value is a restart")
+
+			md, err = p.ProcessMetrics(context.Background(), newTestMetrics("requests", dataType, true,
+				testPoint{start: 35, ts: 40, value: 50}))
+			require.NoError(t, err)
+			assert.Equal(t, []testPoint{{start: 35, ts: 40, value: 50}}, testPoints(md), "a later start time is a restart")
+		})
+	}
+}
+
+func TestProcessMetrics_unconverted(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Metrics = []string{"requests"}
+	p := newCumulativeToDeltaProcessor(zap.NewNop(), cfg)
+
+	for _, md := range []pdata.Metrics{
+		newTestMetrics("other", pdata.MetricDataTypeIntSum, true, testPoint{start: 1, ts: 10, value: 100}),
+		newTestMetrics("requests", pdata.MetricDataTypeIntSum, false, testPoint{start: 1, ts: 10, value: 100}),
+		newTestMetrics("requests", pdata.MetricDataTypeIntGauge, false),
+	} {
+		want := pdata.NewMetrics()
+		md.Clone().ResourceMetrics().MoveAndAppendTo(want.ResourceMetrics())
+		got, err := p.ProcessMetrics(context.Background(), md)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	assert.Equal(t, 0, p.series.Len())
+}
+
+func TestProcessMetrics_maxStaleness(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxStaleness = time.Minute
+	p := newCumulativeToDeltaProcessor(zap.NewNop(), cfg)
+	p.series = cumulative.NewTracker(cfg.MaxStaleness, func() time.Time { return now })
+
+	_, err := p.ProcessMetrics(context.Background(), newTestMetrics("requests", pdata.MetricDataTypeIntSum, true,
+		testPoint{start: 1, ts: 10, value: 100}))
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	md, err := p.ProcessMetrics(context.Background(), newTestMetrics("requests", pdata.MetricDataTypeIntSum, true,
+		testPoint{start: 1, ts: 20, value: 110}))
+	require.NoError(t, err)
+	assert.Equal(t, []testPoint{{start: 10, ts: 20, value: 10}}, testPoints(md))
+
+	now = now.Add(2 * time.Minute)
+	md, err = p.ProcessMetrics(context.Background(), newTestMetrics("requests", pdata.MetricDataTypeIntSum, true,
+		testPoint{start: 1, ts: 30, value: 120}))
+	require.NoError(t, err)
+	assert.Nil(t, testPoints(md), "the stale series is forgotten")
+	assert.Equal(t, 1, p.series.Len())
+}
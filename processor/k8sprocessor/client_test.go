@@ -27,6 +27,7 @@ import (
 
 // fakeClient is used as a replacement for WatchClient in test cases.
 type fakeClient struct {
+	APIConfig    k8sconfig.APIConfig
 	Pods         map[kube.PodIdentifier]*kube.Pod
 	Rules        kube.ExtractionRules
 	Filters      kube.Filters
@@ -46,6 +47,7 @@ func newFakeClient(_ *zap.Logger, apiCfg k8sconfig.APIConfig, rules kube.Extract
 
 	ls, fs := selectors()
 	return &fakeClient{
+		APIConfig:    apiCfg,
 		Pods:         map[kube.PodIdentifier]*kube.Pod{},
 		Rules:        rules,
 		Filters:      filters,
@@ -43,6 +43,34 @@ type Config struct {
 	// Association section allows to define rules for tagging spans, metrics,
 	// and logs with Pod metadata.
 	Association []PodAssociationConfig `mapstructure:"pod_association"`
+
+	// Clusters section allows gateway collectors receiving telemetry from
+	// several clusters to look pods up in the cluster each resource comes from.
+	// Each cluster is queried with its own API connection, while resources
+	// from other clusters are looked up with the top-level API connection.
+	Clusters []ClusterConfig `mapstructure:"clusters"`
+
+	// ClusterAttribute is the resource attribute holding the name of the
+	// cluster a resource comes from, matched against the names of Clusters.
+	// Defaults to k8s.cluster.name.
+	ClusterAttribute string `mapstructure:"cluster_attribute"`
+}
+
+// ClusterConfig allows specifying the API connection to a cluster, e.g.
+// with a kubeconfig context:
+//
+//   k8s_tagger:
+//     cluster_attribute: k8s.cluster.name
+//     clusters:
+//       - name: us-west
+//         auth_type: kubeConfig
+//         context: us-west-admin
+type ClusterConfig struct {
+	// Name of the cluster, matched against the value of the cluster
+	// attribute of resources.
+	Name string `mapstructure:"name"`
+
+	k8sconfig.APIConfig `mapstructure:",squash"`
 }
 
 // ExtractConfig section allows specifying extraction rules to extract
@@ -69,6 +97,36 @@ type ExtractConfig struct {
 	// It is a list of FieldExtractConfig type. See FieldExtractConfig
 	// documentation for more details.
 	Labels []FieldExtractConfig `mapstructure:"labels"`
+
+	// Service allows inferring the service.name and service.version
+	// resource attributes from pod labels, for resources whose
+	// instrumentation did not set them.
+	// See ServiceExtractConfig documentation for more details.
+	Service ServiceExtractConfig `mapstructure:"service"`
+}
+
+// ServiceExtractConfig allows inferring the service of pods from their
+// labels, following the app.kubernetes.io recommended labels by default,
+// so that backends group telemetry by service without instrumentation
+// changes. Attributes already set by instrumentation are kept, except
+// the unknown_service default of the OpenTelemetry SDKs.
+type ServiceExtractConfig struct {
+	// Enabled enables the inference of service.name and service.version.
+	Enabled bool `mapstructure:"enabled"`
+
+	// NameLabels are the pod labels service.name is taken from, in order
+	// of precedence. Defaults to app.kubernetes.io/name,
+	// app.kubernetes.io/instance and app.
+	NameLabels []string `mapstructure:"name_labels"`
+
+	// VersionLabels are the pod labels service.version is taken from, in
+	// order of precedence. Defaults to app.kubernetes.io/version and version.
+	VersionLabels []string `mapstructure:"version_labels"`
+
+	// FromWorkload falls back to the name of the workload controlling the
+	// pod, i.e. its deployment, statefulset, daemonset or job, for
+	// service.name when none of the name labels is set.
+	FromWorkload bool `mapstructure:"from_workload"`
 }
 
 // FieldExtractConfig allows specifying an extraction rule to extract a value from exactly one field.
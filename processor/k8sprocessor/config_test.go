@@ -75,6 +75,12 @@ func TestLoadConfig(t *testing.T) {
 					{TagName: "l1", Key: "label1"},
 					{TagName: "l2", Key: "label2", Regex: "field=(?P<value>.+)"},
 				},
+				Service: ServiceExtractConfig{
+					Enabled:       true,
+					NameLabels:    []string{"app.kubernetes.io/name", "app"},
+					VersionLabels: []string{"app.kubernetes.io/version"},
+					FromWorkload:  true,
+				},
 			},
 			Filter: FilterConfig{
 				Namespace:      "ns2",
@@ -111,5 +117,12 @@ func TestLoadConfig(t *testing.T) {
 					Name: "k8s.pod.uid",
 				},
 			},
+			ClusterAttribute: "k8s.cluster.name",
+			Clusters: []ClusterConfig{
+				{
+					Name:      "us-east",
+					APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig, Context: "us-east-admin"},
+				},
+			},
 		})
 }
@@ -40,6 +40,22 @@
 //
 // If Pod association rules are not configured resources are associated with metadata only by connection's IP Address.
 //
+// Service inference
+//
+// When "extract.service.enabled" is set, the processor derives the service.name and service.version resource
+// attributes from the app.kubernetes.io recommended labels of pods, so that backends group telemetry by service even
+// when instrumentation did not set them. The labels are tried in order of precedence, and "from_workload" falls back
+// to the name of the deployment, statefulset, daemonset or job controlling the pod. Attributes set by instrumentation
+// are kept, except the unknown_service default of the OpenTelemetry SDKs.
+//
+//    k8s_tagger:
+//      extract:
+//        service:
+//          enabled: true
+//          name_labels: [app.kubernetes.io/name, app.kubernetes.io/instance, app] # default
+//          version_labels: [app.kubernetes.io/version, version] # default
+//          from_workload: true
+//
 // RBAC
 //
 // TODO: mention the required RBAC rules.
@@ -115,6 +131,26 @@
 // the IP address of spans, logs and metrics sent by the agents as well as directly by other services/pods.
 //
 //
+// As a gateway for several clusters
+//
+// A gateway collector receiving telemetry from several clusters can look pods up in the cluster each resource
+// comes from, since IP addresses are only unique within a cluster. Each cluster listed in "clusters" is queried
+// with its own API connection, e.g. with a kubeconfig context, and is selected by the value of the resource
+// attribute named by "cluster_attribute" (k8s.cluster.name by default). Resources from other clusters are
+// looked up with the top-level API connection.
+//
+//    k8s_tagger:
+//      auth_type: serviceAccount
+//      clusters:
+//        - name: us-east
+//          auth_type: kubeConfig
+//          context: us-east-admin
+//        - name: eu-west
+//          auth_type: kubeConfig
+//          context: eu-west-admin
+//
+// Agents must add the cluster attribute to the resources they forward, e.g. with the resource processor.
+//
 // Caveats
 //
 // There are some edge-cases and scenarios where k8s_tagger will not work properly.
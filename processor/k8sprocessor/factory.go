@@ -182,6 +182,7 @@ func createProcessorOpts(cfg configmodels.Processor) []Option {
 	opts = append(opts, WithExtractMetadata(oCfg.Extract.Metadata...))
 	opts = append(opts, WithExtractLabels(oCfg.Extract.Labels...))
 	opts = append(opts, WithExtractAnnotations(oCfg.Extract.Annotations...))
+	opts = append(opts, WithExtractService(oCfg.Extract.Service))
 
 	// filters
 	opts = append(opts, WithFilterNode(oCfg.Filter.Node, oCfg.Filter.NodeFromEnvVar))
@@ -191,6 +192,7 @@ func createProcessorOpts(cfg configmodels.Processor) []Option {
 	opts = append(opts, WithAPIConfig(oCfg.APIConfig))
 
 	opts = append(opts, WithExtractPodAssociations(oCfg.Association...))
+	opts = append(opts, WithClusters(oCfg.ClusterAttribute, oCfg.Clusters...))
 
 	return opts
 }
@@ -24,6 +24,7 @@ import (
 	"go.opentelemetry.io/collector/translator/conventions"
 	"go.uber.org/zap"
 	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -253,9 +254,50 @@ func (c *WatchClient) extractPodAttributes(pod *api_v1.Pod) map[string]string {
 			tags[r.Name] = c.extractField(v, r)
 		}
 	}
+
+	if name := firstLabel(pod, c.Rules.ServiceName); name != "" {
+		tags[conventions.AttributeServiceName] = name
+	} else if c.Rules.ServiceNameFromWorkload {
+		if name := workloadName(pod); name != "" {
+			tags[conventions.AttributeServiceName] = name
+		}
+	}
+	if version := firstLabel(pod, c.Rules.ServiceVersion); version != "" {
+		tags[conventions.AttributeServiceVersion] = version
+	}
 	return tags
 }
 
+// firstLabel returns the value of the first of the labels set on the pod.
+func firstLabel(pod *api_v1.Pod, keys []string) string {
+	for _, key := range keys {
+		if v := pod.Labels[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// workloadName returns the name of the workload controlling the pod, the
+// deployment of pods controlled by a replicaset, or "" if none.
+func workloadName(pod *api_v1.Pod) string {
+	owner := meta_v1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+	switch owner.Kind {
+	case "ReplicaSet":
+		// format: [deployment-name]-[pod-template-hash]
+		if hash := pod.Labels["pod-template-hash"]; hash != "" {
+			return strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+		return owner.Name
+	case "StatefulSet", "DaemonSet", "Job":
+		return owner.Name
+	}
+	return ""
+}
+
 func (c *WatchClient) extractField(v string, r FieldExtractionRule) string {
 	// Check if a subset of the field should be extracted with a regular expression
 	// instead of the whole field.
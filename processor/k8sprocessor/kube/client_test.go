@@ -445,6 +445,94 @@ func TestExtractionRules(t *testing.T) {
 	}
 }
 
+func TestExtractionRulesService(t *testing.T) {
+	c, _ := newTestClientWithRulesAndFilters(t, ExtractionRules{}, Filters{})
+	controller := true
+
+	testCases := []struct {
+		name       string
+		rules      ExtractionRules
+		labels     map[string]string
+		owner      *meta_v1.OwnerReference
+		attributes map[string]string
+	}{{
+		name:   "disabled",
+		rules:  ExtractionRules{},
+		labels: map[string]string{"app.kubernetes.io/name": "cart", "app.kubernetes.io/version": "1.2.3"},
+	}, {
+		name: "recommended_labels",
+		rules: ExtractionRules{
+			ServiceName:    []string{"app.kubernetes.io/name", "app.kubernetes.io/instance", "app"},
+			ServiceVersion: []string{"app.kubernetes.io/version", "version"},
+		},
+		labels: map[string]string{"app.kubernetes.io/name": "cart", "app": "legacy", "app.kubernetes.io/version": "1.2.3"},
+		attributes: map[string]string{
+			"service.name":    "cart",
+			"service.version": "1.2.3",
+		},
+	}, {
+		name: "precedence",
+		rules: ExtractionRules{
+			ServiceName: []string{"app.kubernetes.io/name", "app.kubernetes.io/instance", "app"},
+		},
+		labels:     map[string]string{"app.kubernetes.io/name": "", "app": "legacy"},
+		attributes: map[string]string{"service.name": "legacy"},
+	}, {
+		name: "label_over_workload",
+		rules: ExtractionRules{
+			ServiceName:             []string{"app"},
+			ServiceNameFromWorkload: true,
+		},
+		labels:     map[string]string{"app": "cart", "pod-template-hash": "abc12"},
+		owner:      &meta_v1.OwnerReference{Kind: "ReplicaSet", Name: "cart-deployment-abc12", Controller: &controller},
+		attributes: map[string]string{"service.name": "cart"},
+	}, {
+		name:       "deployment",
+		rules:      ExtractionRules{ServiceName: []string{"app"}, ServiceNameFromWorkload: true},
+		labels:     map[string]string{"pod-template-hash": "abc12"},
+		owner:      &meta_v1.OwnerReference{Kind: "ReplicaSet", Name: "cart-deployment-abc12", Controller: &controller},
+		attributes: map[string]string{"service.name": "cart-deployment"},
+	}, {
+		name:       "statefulset",
+		rules:      ExtractionRules{ServiceNameFromWorkload: true},
+		owner:      &meta_v1.OwnerReference{Kind: "StatefulSet", Name: "db", Controller: &controller},
+		attributes: map[string]string{"service.name": "db"},
+	}, {
+		name:  "not_a_controller",
+		rules: ExtractionRules{ServiceNameFromWorkload: true},
+		owner: &meta_v1.OwnerReference{Kind: "StatefulSet", Name: "db"},
+	}, {
+		name:  "unknown_workload",
+		rules: ExtractionRules{ServiceNameFromWorkload: true},
+		owner: &meta_v1.OwnerReference{Kind: "Node", Name: "node1", Controller: &controller},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &api_v1.Pod{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:   "cart-deployment-abc12-xyz3",
+					UID:    "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+					Labels: tc.labels,
+				},
+				Status: api_v1.PodStatus{
+					PodIP: "1.1.1.1",
+				},
+			}
+			if tc.owner != nil {
+				pod.OwnerReferences = []meta_v1.OwnerReference{*tc.owner}
+			}
+			c.Rules = tc.rules
+			c.handlePodAdd(pod)
+			p, ok := c.GetPod(PodIdentifier(pod.Status.PodIP))
+			require.True(t, ok)
+			assert.Equal(t, len(tc.attributes), len(p.Attributes))
+			for k, v := range tc.attributes {
+				assert.Equal(t, v, p.Attributes[k])
+			}
+		})
+	}
+}
+
 func TestFilters(t *testing.T) {
 	testCases := []struct {
 		name    string
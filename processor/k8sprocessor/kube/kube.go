@@ -118,6 +118,14 @@ type ExtractionRules struct {
 
 	Annotations []FieldExtractionRule
 	Labels      []FieldExtractionRule
+
+	// ServiceName and ServiceVersion are the pod labels the service.name and
+	// service.version tags are taken from. The first label set wins.
+	ServiceName    []string
+	ServiceVersion []string
+	// ServiceNameFromWorkload falls back to the name of the workload
+	// controlling the pod for the service.name tag.
+	ServiceNameFromWorkload bool
 }
 
 // FieldExtractionRule is used to specify which fields to extract from pod fields
@@ -19,6 +19,7 @@ import (
 	"os"
 	"regexp"
 
+	"go.opentelemetry.io/collector/translator/conventions"
 	"k8s.io/apimachinery/pkg/selection"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -40,6 +41,13 @@ const (
 	metadataNode       = "node"
 )
 
+var (
+	// defaultServiceNameLabels and defaultServiceVersionLabels follow the
+	// recommended labels of https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+	defaultServiceNameLabels    = []string{"app.kubernetes.io/name", "app.kubernetes.io/instance", "app"}
+	defaultServiceVersionLabels = []string{"app.kubernetes.io/version", "version"}
+)
+
 // Option represents a configuration option that can be passes.
 // to the k8s-tagger
 type Option func(*kubernetesprocessor) error
@@ -125,6 +133,25 @@ func WithExtractAnnotations(annotations ...FieldExtractConfig) Option {
 	}
 }
 
+// WithExtractService allows inferring the service of pods from their labels.
+func WithExtractService(cfg ServiceExtractConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		if !cfg.Enabled {
+			return nil
+		}
+		p.rules.ServiceName = cfg.NameLabels
+		if len(p.rules.ServiceName) == 0 {
+			p.rules.ServiceName = defaultServiceNameLabels
+		}
+		p.rules.ServiceVersion = cfg.VersionLabels
+		if len(p.rules.ServiceVersion) == 0 {
+			p.rules.ServiceVersion = defaultServiceVersionLabels
+		}
+		p.rules.ServiceNameFromWorkload = cfg.FromWorkload
+		return nil
+	}
+}
+
 func extractFieldRules(fieldType string, fields ...FieldExtractConfig) ([]kube.FieldExtractionRule, error) {
 	rules := []kube.FieldExtractionRule{}
 	for _, a := range fields {
@@ -249,3 +276,30 @@ func WithExtractPodAssociations(podAssociations ...PodAssociationConfig) Option
 		return nil
 	}
 }
+
+// WithClusters allows looking pods up in the cluster named by the given
+// resource attribute, with one API connection per cluster. The attribute
+// defaults to k8s.cluster.name.
+func WithClusters(attribute string, clusters ...ClusterConfig) Option {
+	return func(p *kubernetesprocessor) error {
+		if attribute == "" {
+			attribute = conventions.AttributeK8sCluster
+		}
+		names := map[string]bool{}
+		for _, cluster := range clusters {
+			if cluster.Name == "" {
+				return fmt.Errorf("cluster name cannot be empty")
+			}
+			if names[cluster.Name] {
+				return fmt.Errorf("duplicate cluster name %q", cluster.Name)
+			}
+			names[cluster.Name] = true
+			if err := cluster.APIConfig.Validate(); err != nil {
+				return fmt.Errorf("cluster %q: %w", cluster.Name, err)
+			}
+		}
+		p.clusterAttribute = attribute
+		p.clusters = clusters
+		return nil
+	}
+}
@@ -221,6 +221,30 @@ func TestWithExtractMetadata(t *testing.T) {
 	assert.False(t, p.rules.Node)
 }
 
+func TestWithExtractService(t *testing.T) {
+	p := &kubernetesprocessor{}
+	assert.NoError(t, WithExtractService(ServiceExtractConfig{NameLabels: []string{"app"}})(p))
+	assert.Nil(t, p.rules.ServiceName)
+	assert.Nil(t, p.rules.ServiceVersion)
+
+	p = &kubernetesprocessor{}
+	assert.NoError(t, WithExtractService(ServiceExtractConfig{Enabled: true})(p))
+	assert.Equal(t, []string{"app.kubernetes.io/name", "app.kubernetes.io/instance", "app"}, p.rules.ServiceName)
+	assert.Equal(t, []string{"app.kubernetes.io/version", "version"}, p.rules.ServiceVersion)
+	assert.False(t, p.rules.ServiceNameFromWorkload)
+
+	p = &kubernetesprocessor{}
+	assert.NoError(t, WithExtractService(ServiceExtractConfig{
+		Enabled:       true,
+		NameLabels:    []string{"component"},
+		VersionLabels: []string{"release"},
+		FromWorkload:  true,
+	})(p))
+	assert.Equal(t, []string{"component"}, p.rules.ServiceName)
+	assert.Equal(t, []string{"release"}, p.rules.ServiceVersion)
+	assert.True(t, p.rules.ServiceNameFromWorkload)
+}
+
 func TestWithFilterLabels(t *testing.T) {
 	tests := []struct {
 		name  string
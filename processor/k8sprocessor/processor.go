@@ -16,9 +16,12 @@ package k8sprocessor
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/translator/conventions"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/k8sconfig"
@@ -28,6 +31,8 @@ import (
 const (
 	k8sIPLabelName    string = "k8s.pod.ip"
 	clientIPLabelName string = "ip"
+
+	unknownServicePrefix = "unknown_service"
 )
 
 type kubernetesprocessor struct {
@@ -38,6 +43,11 @@ type kubernetesprocessor struct {
 	rules           kube.ExtractionRules
 	filters         kube.Filters
 	podAssociations []kube.Association
+	// clusters are looked up with their own client in clusterClients,
+	// by the value of the clusterAttribute of resources.
+	clusters         []ClusterConfig
+	clusterAttribute string
+	clusterClients   map[string]kube.Client
 }
 
 func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kube.ClientProvider) error {
@@ -50,6 +60,15 @@ func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kub
 			return err
 		}
 		kp.kc = kc
+
+		kp.clusterClients = make(map[string]kube.Client, len(kp.clusters))
+		for _, cluster := range kp.clusters {
+			kc, err := kubeClient(logger.With(zap.String("cluster", cluster.Name)), cluster.APIConfig, kp.rules, kp.filters, kp.podAssociations, nil, nil)
+			if err != nil {
+				return fmt.Errorf("cluster %q: %w", cluster.Name, err)
+			}
+			kp.clusterClients[cluster.Name] = kc
+		}
 	}
 	return nil
 }
@@ -57,6 +76,9 @@ func (kp *kubernetesprocessor) initKubeClient(logger *zap.Logger, kubeClient kub
 func (kp *kubernetesprocessor) Start(_ context.Context, _ component.Host) error {
 	if !kp.passthroughMode {
 		go kp.kc.Start()
+		for _, kc := range kp.clusterClients {
+			go kc.Start()
+		}
 	}
 	return nil
 }
@@ -64,6 +86,9 @@ func (kp *kubernetesprocessor) Start(_ context.Context, _ component.Host) error
 func (kp *kubernetesprocessor) Shutdown(context.Context) error {
 	if !kp.passthroughMode {
 		kp.kc.Stop()
+		for _, kc := range kp.clusterClients {
+			kc.Stop()
+		}
 	}
 	return nil
 }
@@ -109,14 +134,40 @@ func (kp *kubernetesprocessor) processResource(ctx context.Context, resource pda
 	if kp.passthroughMode {
 		return
 	}
-	attrsToAdd := kp.getAttributesForPod(podIdentifierValue)
+	attrsToAdd := kp.getAttributesForPod(kp.clientForResource(resource), podIdentifierValue)
 	for key, val := range attrsToAdd {
+		if key == conventions.AttributeServiceName && hasUnknownServiceName(resource) {
+			resource.Attributes().UpdateString(key, val)
+			continue
+		}
 		resource.Attributes().InsertString(key, val)
 	}
 }
 
-func (kp *kubernetesprocessor) getAttributesForPod(identifier kube.PodIdentifier) map[string]string {
-	pod, ok := kp.kc.GetPod(identifier)
+// hasUnknownServiceName tells whether the service.name of the resource is
+// the unknown_service default of the OpenTelemetry SDKs, i.e. was not set
+// by instrumentation.
+func hasUnknownServiceName(resource pdata.Resource) bool {
+	v, ok := resource.Attributes().Get(conventions.AttributeServiceName)
+	return ok && v.Type() == pdata.AttributeValueSTRING && strings.HasPrefix(v.StringVal(), unknownServicePrefix)
+}
+
+// clientForResource returns the client of the cluster named by the cluster attribute of the resource, or else
+// the default client.
+func (kp *kubernetesprocessor) clientForResource(resource pdata.Resource) kube.Client {
+	if len(kp.clusterClients) == 0 {
+		return kp.kc
+	}
+	if v, ok := resource.Attributes().Get(kp.clusterAttribute); ok && v.Type() == pdata.AttributeValueSTRING {
+		if kc, ok := kp.clusterClients[v.StringVal()]; ok {
+			return kc
+		}
+	}
+	return kp.kc
+}
+
+func (kp *kubernetesprocessor) getAttributesForPod(kc kube.Client, identifier kube.PodIdentifier) map[string]string {
+	pod, ok := kc.GetPod(identifier)
 	if !ok {
 		return nil
 	}
@@ -661,6 +661,87 @@ func TestProcessorAddLabels(t *testing.T) {
 	}
 }
 
+func TestProcessorServiceName(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	var kp *kubernetesprocessor
+	next := new(consumertest.TracesSink)
+	p, err := newTraceProcessor(cfg, next, withExtractKubernetesProcessorInto(&kp))
+	require.NoError(t, err)
+	kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{Attributes: map[string]string{
+		conventions.AttributeServiceName:    "cart",
+		conventions.AttributeServiceVersion: "1.2.3",
+	}}
+
+	ctx := client.NewContext(context.Background(), &client.Client{IP: "1.1.1.1"})
+	for set, want := range map[string]string{
+		"":                     "cart",
+		"unknown_service":      "cart",
+		"unknown_service:java": "cart",
+		"checkout":             "checkout",
+	} {
+		next.Reset()
+		traces := generateTraces(func(res pdata.Resource) {
+			if set != "" {
+				res.Attributes().InsertString(conventions.AttributeServiceName, set)
+			}
+		})
+		require.NoError(t, p.ConsumeTraces(ctx, traces))
+		require.Len(t, next.AllTraces(), 1)
+		res := next.AllTraces()[0].ResourceSpans().At(0).Resource()
+		assertResourceHasStringAttribute(t, res, conventions.AttributeServiceName, want)
+		assertResourceHasStringAttribute(t, res, conventions.AttributeServiceVersion, "1.2.3")
+	}
+}
+
+func TestProcessorClusters(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Clusters = []ClusterConfig{
+		{Name: "east", APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig, Context: "east-admin"}},
+	}
+
+	var kp *kubernetesprocessor
+	next := new(consumertest.TracesSink)
+	p, err := newTraceProcessor(cfg, next, withExtractKubernetesProcessorInto(&kp))
+	require.NoError(t, err)
+	require.Len(t, kp.clusterClients, 1)
+	assert.Equal(t, "east-admin", kp.clusterClients["east"].(*fakeClient).APIConfig.Context)
+
+	// The same IP is used by different pods in each cluster.
+	kp.kc.(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{Attributes: map[string]string{"pod": "default-pod"}}
+	kp.clusterClients["east"].(*fakeClient).Pods["1.1.1.1"] = &kube.Pod{Attributes: map[string]string{"pod": "east-pod"}}
+
+	ctx := client.NewContext(context.Background(), &client.Client{IP: "1.1.1.1"})
+	for cluster, pod := range map[string]string{"east": "east-pod", "west": "default-pod", "": "default-pod"} {
+		next.Reset()
+		traces := generateTraces(func(res pdata.Resource) {
+			if cluster != "" {
+				res.Attributes().InsertString(conventions.AttributeK8sCluster, cluster)
+			}
+		})
+		require.NoError(t, p.ConsumeTraces(ctx, traces))
+		require.Len(t, next.AllTraces(), 1)
+		assertResourceHasStringAttribute(t, next.AllTraces()[0].ResourceSpans().At(0).Resource(), "pod", pod)
+	}
+}
+
+func TestProcessorBadClusters(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Clusters = []ClusterConfig{
+		{Name: "east", APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig}},
+		{Name: "east", APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig}},
+	}
+	_, err := newTraceProcessor(cfg, consumertest.NewTracesNop())
+	assert.EqualError(t, err, `duplicate cluster name "east"`)
+
+	cfg.Clusters = []ClusterConfig{{Name: "east", APIConfig: k8sconfig.APIConfig{AuthType: "bad"}}}
+	_, err = newTraceProcessor(cfg, consumertest.NewTracesNop())
+	assert.EqualError(t, err, `cluster "east": invalid authType for kubernetes: bad`)
+
+	cfg.Clusters = []ClusterConfig{{APIConfig: k8sconfig.APIConfig{AuthType: k8sconfig.AuthTypeKubeConfig}}}
+	_, err = newTraceProcessor(cfg, consumertest.NewTracesNop())
+	assert.EqualError(t, err, "cluster name cannot be empty")
+}
+
 func TestProcessorPicksUpPassthoughPodIp(t *testing.T) {
 	m := newMultiTest(
 		t,
@@ -74,17 +74,20 @@ type DataCollector struct {
 	metricsStore           *metricsStore
 	metadataStore          *metadataStore
 	nodeConditionsToReport []string
+	// resourceQuotaNamespaceLabels are the labels of namespaces added to the resource of their resource quotas.
+	resourceQuotaNamespaceLabels []string
 }
 
 // newDataCollector returns a DataCollector.
-func NewDataCollector(logger *zap.Logger, nodeConditionsToReport []string) *DataCollector {
+func NewDataCollector(logger *zap.Logger, nodeConditionsToReport []string, resourceQuotaNamespaceLabels []string) *DataCollector {
 	return &DataCollector{
 		logger: logger,
 		metricsStore: &metricsStore{
 			metricsCache: map[types.UID][]internaldata.MetricsData{},
 		},
-		metadataStore:          &metadataStore{},
-		nodeConditionsToReport: nodeConditionsToReport,
+		metadataStore:                &metadataStore{},
+		nodeConditionsToReport:       nodeConditionsToReport,
+		resourceQuotaNamespaceLabels: resourceQuotaNamespaceLabels,
 	}
 }
 
@@ -131,7 +134,7 @@ func (dc *DataCollector) SyncMetrics(obj interface{}) {
 	case *corev1.ReplicationController:
 		rm = getMetricsForReplicationController(o)
 	case *corev1.ResourceQuota:
-		rm = getMetricsForResourceQuota(o)
+		rm = getMetricsForResourceQuota(o, dc.metadataStore, dc.resourceQuotaNamespaceLabels)
 	case *appsv1.Deployment:
 		rm = getMetricsForDeployment(o)
 	case *appsv1.ReplicaSet:
@@ -45,7 +45,10 @@ var resourceQuotaUsedMetric = &metricspb.MetricDescriptor{
 	}},
 }
 
-func getMetricsForResourceQuota(rq *corev1.ResourceQuota) []*resourceMetrics {
+// k8sKeyNamespaceLabelPrefix prefixes the resource attributes holding labels of namespaces.
+const k8sKeyNamespaceLabelPrefix = "k8s.namespace.labels."
+
+func getMetricsForResourceQuota(rq *corev1.ResourceQuota, ms *metadataStore, namespaceLabels []string) []*resourceMetrics {
 	metrics := make([]*metricspb.Metric, 0)
 
 	for _, t := range []struct {
@@ -81,20 +84,41 @@ func getMetricsForResourceQuota(rq *corev1.ResourceQuota) []*resourceMetrics {
 
 	return []*resourceMetrics{
 		{
-			resource: getResourceForResourceQuota(rq),
+			resource: getResourceForResourceQuota(rq, ms, namespaceLabels),
 			metrics:  metrics,
 		},
 	}
 }
 
-func getResourceForResourceQuota(rq *corev1.ResourceQuota) *resourcepb.Resource {
+func getResourceForResourceQuota(rq *corev1.ResourceQuota, ms *metadataStore, namespaceLabels []string) *resourcepb.Resource {
+	labels := map[string]string{
+		k8sKeyResourceQuotaUID:            string(rq.UID),
+		k8sKeyResourceQuotaName:           rq.Name,
+		conventions.AttributeK8sNamespace: rq.Namespace,
+		conventions.AttributeK8sCluster:   rq.ClusterName,
+	}
+	if ns := getNamespace(ms, rq.Namespace); ns != nil {
+		for _, label := range namespaceLabels {
+			if value, ok := ns.Labels[label]; ok {
+				labels[k8sKeyNamespaceLabelPrefix+label] = value
+			}
+		}
+	}
 	return &resourcepb.Resource{
-		Type: k8sType,
-		Labels: map[string]string{
-			k8sKeyResourceQuotaUID:            string(rq.UID),
-			k8sKeyResourceQuotaName:           rq.Name,
-			conventions.AttributeK8sNamespace: rq.Namespace,
-			conventions.AttributeK8sCluster:   rq.ClusterName,
-		},
+		Type:   k8sType,
+		Labels: labels,
+	}
+}
+
+// getNamespace returns the namespace of the given name from the store, or nil if it is not known (yet).
+func getNamespace(ms *metadataStore, name string) *corev1.Namespace {
+	if ms == nil || ms.namespaces == nil {
+		return nil
+	}
+	obj, exists, err := ms.namespaces.GetByKey(name)
+	if err != nil || !exists {
+		return nil
 	}
+	ns, _ := obj.(*corev1.Namespace)
+	return ns
 }
@@ -30,7 +30,7 @@ import (
 func TestRequestQuotaMetrics(t *testing.T) {
 	rq := newResourceQuota("1")
 
-	actualResourceMetrics := getMetricsForResourceQuota(rq)
+	actualResourceMetrics := getMetricsForResourceQuota(rq, &metadataStore{}, nil)
 
 	require.Equal(t, 1, len(actualResourceMetrics))
 
@@ -51,6 +51,46 @@ func TestRequestQuotaMetrics(t *testing.T) {
 		metricspb.MetricDescriptor_GAUGE_INT64, map[string]string{"resource": "requests.cpu"}, 1000)
 }
 
+func TestRequestQuotaMetricsWithNamespaceLabels(t *testing.T) {
+	rq := newResourceQuota("1")
+	ms := &metadataStore{
+		namespaces: &testutils.MockStore{
+			Cache: map[string]interface{}{
+				"test-namespace": &corev1.Namespace{
+					ObjectMeta: v1.ObjectMeta{
+						Name: "test-namespace",
+						Labels: map[string]string{
+							"team":        "payments",
+							"cost-center": "cc-42",
+							"other":       "ignored",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	actualResourceMetrics := getMetricsForResourceQuota(rq, ms, []string{"team", "cost-center", "missing"})
+
+	require.Equal(t, 1, len(actualResourceMetrics))
+	testutils.AssertResource(t, actualResourceMetrics[0].resource, k8sType,
+		map[string]string{
+			"k8s.resourcequota.uid":            "test-resourcequota-1-uid",
+			"k8s.resourcequota.name":           "test-resourcequota-1",
+			"k8s.namespace.name":               "test-namespace",
+			"k8s.cluster.name":                 "test-cluster",
+			"k8s.namespace.labels.team":        "payments",
+			"k8s.namespace.labels.cost-center": "cc-42",
+		},
+	)
+
+	// The labels are left out while the namespace is not known.
+	rq.Namespace = "unknown-namespace"
+	actualResourceMetrics = getMetricsForResourceQuota(rq, ms, []string{"team"})
+	_, ok := actualResourceMetrics[0].resource.Labels["k8s.namespace.labels.team"]
+	require.False(t, ok)
+}
+
 func newResourceQuota(id string) *corev1.ResourceQuota {
 	return &corev1.ResourceQuota{
 		ObjectMeta: v1.ObjectMeta{
@@ -15,6 +15,7 @@
 package k8sclusterreceiver
 
 import (
+	"errors"
 	"time"
 
 	"go.opentelemetry.io/collector/config/configmodels"
@@ -34,13 +35,43 @@ type Config struct {
 	// Node condition types to report. See all condition types, see
 	// here: https://kubernetes.io/docs/concepts/architecture/nodes/#condition.
 	NodeConditionTypesToReport []string `mapstructure:"node_conditions_to_report"`
+	// Labels of namespaces added to the resource of the resource quota metrics of the namespace, as
+	// k8s.namespace.labels.<label> attributes, e.g. to break quotas down by team or cost center.
+	ResourceQuotaNamespaceLabels []string `mapstructure:"resource_quota_namespace_labels"`
 	// List of exporters to which metadata from this receiver should be forwarded to.
 	MetadataExporters []string `mapstructure:"metadata_exporters"`
 
+	// ResyncInterval is the period at which informers replay all the objects they watch, on top of the
+	// updates received from the API server. 0 disables resyncs.
+	ResyncInterval time.Duration `mapstructure:"resync_interval"`
+	// WatchErrorBackoff configures how long failed watches of the API server wait before being restarted.
+	WatchErrorBackoff WatchErrorBackoffSettings `mapstructure:"watch_error_backoff"`
+
 	// For mocking.
 	makeClient func(apiConf k8sconfig.APIConfig) (k8s.Interface, error)
 }
 
+// WatchErrorBackoffSettings configures the exponential backoff of failed watches of the API server.
+type WatchErrorBackoffSettings struct {
+	// InitialInterval is the delay after the first of consecutive watch errors. 0 disables the backoff.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	// MaxInterval is the upper bound of the delay, doubled after every consecutive watch error.
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+}
+
+func (cfg *Config) validate() error {
+	if cfg.ResyncInterval < 0 {
+		return errors.New("\"resync_interval\" must not be negative")
+	}
+	if cfg.WatchErrorBackoff.InitialInterval < 0 {
+		return errors.New("\"watch_error_backoff.initial_interval\" must not be negative")
+	}
+	if cfg.WatchErrorBackoff.MaxInterval < cfg.WatchErrorBackoff.InitialInterval {
+		return errors.New("\"watch_error_backoff.max_interval\" must not be lower than \"initial_interval\"")
+	}
+	return nil
+}
+
 func (cfg *Config) getK8sClient() (k8s.Interface, error) {
 	if cfg.makeClient == nil {
 		cfg.makeClient = k8sconfig.MakeClient
@@ -54,9 +54,15 @@ func TestLoadConfig(t *testing.T) {
 				TypeVal: configmodels.Type(receiverType),
 				NameVal: "k8s_cluster/all_settings",
 			},
-			CollectionInterval:         30 * time.Second,
-			NodeConditionTypesToReport: []string{"Ready", "MemoryPressure"},
-			MetadataExporters:          []string{"nop"},
+			CollectionInterval:           30 * time.Second,
+			NodeConditionTypesToReport:   []string{"Ready", "MemoryPressure"},
+			ResourceQuotaNamespaceLabels: []string{"team"},
+			MetadataExporters:            []string{"nop"},
+			ResyncInterval:               10 * time.Minute,
+			WatchErrorBackoff: WatchErrorBackoffSettings{
+				InitialInterval: 5 * time.Second,
+				MaxInterval:     5 * time.Minute,
+			},
 			APIConfig: k8sconfig.APIConfig{
 				AuthType: k8sconfig.AuthTypeServiceAccount,
 			},
@@ -71,8 +77,43 @@ func TestLoadConfig(t *testing.T) {
 			},
 			CollectionInterval:         30 * time.Second,
 			NodeConditionTypesToReport: []string{"Ready"},
+			WatchErrorBackoff: WatchErrorBackoffSettings{
+				InitialInterval: time.Second,
+				MaxInterval:     2 * time.Minute,
+			},
 			APIConfig: k8sconfig.APIConfig{
 				AuthType: k8sconfig.AuthTypeServiceAccount,
 			},
 		})
 }
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:    "negative resync interval",
+			modify:  func(cfg *Config) { cfg.ResyncInterval = -time.Second },
+			wantErr: `"resync_interval" must not be negative`,
+		},
+		{
+			name:    "negative initial interval",
+			modify:  func(cfg *Config) { cfg.WatchErrorBackoff.InitialInterval = -time.Second },
+			wantErr: `"watch_error_backoff.initial_interval" must not be negative`,
+		},
+		{
+			name:    "max interval lower than initial interval",
+			modify:  func(cfg *Config) { cfg.WatchErrorBackoff.MaxInterval = time.Millisecond },
+			wantErr: `"watch_error_backoff.max_interval" must not be lower than "initial_interval"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			assert.EqualError(t, cfg.validate(), tt.wantErr)
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configmodels"
 	"go.opentelemetry.io/collector/consumer"
@@ -32,6 +33,8 @@ const (
 
 	// Default config values.
 	defaultCollectionInterval = 10 * time.Second
+	defaultWatchErrorInitial  = time.Second
+	defaultWatchErrorMax      = 2 * time.Minute
 )
 
 var defaultNodeConditionsToReport = []string{"Ready"}
@@ -44,6 +47,10 @@ func createDefaultConfig() configmodels.Receiver {
 		},
 		CollectionInterval:         defaultCollectionInterval,
 		NodeConditionTypesToReport: defaultNodeConditionsToReport,
+		WatchErrorBackoff: WatchErrorBackoffSettings{
+			InitialInterval: defaultWatchErrorInitial,
+			MaxInterval:     defaultWatchErrorMax,
+		},
 		APIConfig: k8sconfig.APIConfig{
 			AuthType: k8sconfig.AuthTypeServiceAccount,
 		},
@@ -54,6 +61,9 @@ func createMetricsReceiver(
 	_ context.Context, params component.ReceiverCreateParams, cfg configmodels.Receiver,
 	consumer consumer.Metrics) (component.MetricsReceiver, error) {
 	rCfg := cfg.(*Config)
+	if err := rCfg.validate(); err != nil {
+		return nil, err
+	}
 
 	k8sClient, err := rCfg.getK8sClient()
 	if err != nil {
@@ -64,6 +74,8 @@ func createMetricsReceiver(
 
 // NewFactory creates a factory for k8s_cluster receiver.
 func NewFactory() component.ReceiverFactory {
+	view.Register(MetricViews()...)
+
 	return receiverhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
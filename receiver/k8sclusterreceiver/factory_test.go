@@ -44,6 +44,10 @@ func TestFactory(t *testing.T) {
 		},
 		CollectionInterval:         10 * time.Second,
 		NodeConditionTypesToReport: defaultNodeConditionsToReport,
+		WatchErrorBackoff: WatchErrorBackoffSettings{
+			InitialInterval: time.Second,
+			MaxInterval:     2 * time.Minute,
+		},
 		APIConfig: k8sconfig.APIConfig{
 			AuthType: k8sconfig.AuthTypeServiceAccount,
 		},
@@ -0,0 +1,68 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sclusterreceiver
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+var (
+	tagKeyReceiver = tag.MustNewKey(obsreport.ReceiverKey)
+	tagKeyResource = tag.MustNewKey("resource")
+
+	mWatchErrors = stats.Int64(
+		"receiver/k8s_cluster/watch_errors",
+		"Number of failed watches of the Kubernetes API server, by resource type",
+		stats.UnitDimensionless)
+	mWatchBackoff = stats.Int64(
+		"receiver/k8s_cluster/watch_backoff",
+		"Delay before restarting the latest failed watch of the Kubernetes API server, by resource type",
+		stats.UnitMilliseconds)
+)
+
+// MetricViews returns the metrics views of the receiver.
+func MetricViews() []*view.View {
+	tagKeys := []tag.Key{tagKeyReceiver, tagKeyResource}
+	return []*view.View{
+		{
+			Name:        mWatchErrors.Name(),
+			Measure:     mWatchErrors,
+			Description: mWatchErrors.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     tagKeys,
+		},
+		{
+			Name:        mWatchBackoff.Name(),
+			Measure:     mWatchBackoff,
+			Description: mWatchBackoff.Description(),
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
+	}
+}
+
+func recordWatchError(receiverName string, resource string, backoff time.Duration) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(tagKeyReceiver, receiverName), tag.Upsert(tagKeyResource, resource)},
+		mWatchErrors.M(1),
+		mWatchBackoff.M(backoff.Milliseconds()))
+}
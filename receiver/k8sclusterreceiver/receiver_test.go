@@ -175,7 +175,7 @@ func setupReceiver(
 		NodeConditionTypesToReport: []string{"Ready"},
 	}
 
-	rw := newResourceWatcher(logger, client, config.NodeConditionTypesToReport, initialSyncTimeout)
+	rw := newResourceWatcher(logger, client, config, initialSyncTimeout)
 	rw.dataCollector.SetupMetadataStore(&corev1.Service{}, &testutils.MockStore{})
 
 	return &kubernetesReceiver{
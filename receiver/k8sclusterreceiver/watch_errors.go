@@ -0,0 +1,87 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sclusterreceiver
+
+import (
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchErrorBackoff computes the delay before restarting a failed watch. It adds to the short backoff of
+// client-go reflectors, capped at 30s, so that large clusters do not hammer the API server after transient failures.
+type watchErrorBackoff struct {
+	settings  WatchErrorBackoffSettings
+	now       func() time.Time
+	delay     time.Duration
+	lastError time.Time
+}
+
+func newWatchErrorBackoff(settings WatchErrorBackoffSettings) *watchErrorBackoff {
+	return &watchErrorBackoff{
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+// next returns the delay before restarting the watch after an error, doubling it on consecutive errors.
+// A watch that ran for longer than the maximum interval since the previous error starts over from the
+// initial interval.
+func (b *watchErrorBackoff) next() time.Duration {
+	now := b.now()
+	if b.delay == 0 || now.Sub(b.lastError) > b.delay+b.settings.MaxInterval {
+		b.delay = b.settings.InitialInterval
+	} else {
+		b.delay *= 2
+		if b.delay > b.settings.MaxInterval {
+			b.delay = b.settings.MaxInterval
+		}
+	}
+	b.lastError = now
+	return b.delay
+}
+
+// watchErrorHandler records failed watches of the given resource type and holds off restarting them. Reflectors
+// call the handler synchronously before retrying, so blocking in it delays the next list and watch.
+func (rw *resourceWatcher) watchErrorHandler(resource string) cache.WatchErrorHandler {
+	backoff := newWatchErrorBackoff(rw.config.WatchErrorBackoff)
+	return func(r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(r, err)
+		// Watches closed by the server or expired resource versions are part of normal operation.
+		if err == io.EOF || apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			return
+		}
+
+		delay := backoff.next()
+		recordWatchError(rw.config.Name(), resource, delay)
+		if delay <= 0 {
+			return
+		}
+		rw.logger.Warn("Failed to watch Kubernetes resources, backing off",
+			zap.String("resource", resource),
+			zap.Duration("backoff", delay),
+			zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-rw.done:
+		}
+	}
+}
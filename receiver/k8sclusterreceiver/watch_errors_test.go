@@ -0,0 +1,81 @@
+// Copyright 2021, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sclusterreceiver
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWatchErrorBackoff(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newWatchErrorBackoff(WatchErrorBackoffSettings{
+		InitialInterval: time.Second,
+		MaxInterval:     5 * time.Second,
+	})
+	b.now = func() time.Time { return now }
+
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		delays = append(delays, b.next())
+		now = now.Add(b.delay)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, delays)
+
+	// The watch recovered for longer than the max interval.
+	now = now.Add(time.Minute)
+	assert.Equal(t, time.Second, b.next())
+}
+
+func TestWatchErrorHandler(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.WatchErrorBackoff.InitialInterval = time.Hour
+	config.WatchErrorBackoff.MaxInterval = time.Hour
+	rw := newResourceWatcher(zap.NewNop(), fake.NewSimpleClientset(), config, time.Minute)
+	done := make(chan struct{})
+	rw.done = done
+
+	r := cache.NewReflector(&cache.ListWatch{}, &corev1.Pod{}, cache.NewStore(cache.MetaNamespaceKeyFunc), 0)
+	handler := rw.watchErrorHandler("Pod")
+
+	// Watches closed normally are not delayed.
+	handler(r, io.EOF)
+
+	returned := make(chan struct{})
+	go func() {
+		handler(r, errors.New("connection refused"))
+		close(returned)
+	}()
+	select {
+	case <-returned:
+		t.Fatal("watch error handler returned before the backoff elapsed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(done)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("watch error handler did not return once the receiver stopped")
+	}
+}
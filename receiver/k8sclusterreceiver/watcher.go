@@ -17,6 +17,7 @@ package k8sclusterreceiver
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -48,6 +49,9 @@ type resourceWatcher struct {
 	timedContextForInitialSync context.Context
 	initialSyncDone            *atomic.Bool
 	initialSyncTimedOut        *atomic.Bool
+	config                     *Config
+	// done is closed once the receiver stops, interrupting watch error backoffs.
+	done <-chan struct{}
 }
 
 type metadataConsumer func(metadata []*metadata.MetadataUpdate) error
@@ -55,14 +59,15 @@ type metadataConsumer func(metadata []*metadata.MetadataUpdate) error
 // newResourceWatcher creates a Kubernetes resource watcher.
 func newResourceWatcher(
 	logger *zap.Logger, client kubernetes.Interface,
-	nodeConditionTypesToReport []string, initialSyncTimeout time.Duration) *resourceWatcher {
+	config *Config, initialSyncTimeout time.Duration) *resourceWatcher {
 	rw := &resourceWatcher{
 		client:              client,
 		logger:              logger,
-		dataCollector:       collection.NewDataCollector(logger, nodeConditionTypesToReport),
+		dataCollector:       collection.NewDataCollector(logger, config.NodeConditionTypesToReport, config.ResourceQuotaNamespaceLabels),
 		initialSyncDone:     atomic.NewBool(false),
 		initialSyncTimedOut: atomic.NewBool(false),
 		initialTimeout:      initialSyncTimeout,
+		config:              config,
 	}
 
 	rw.prepareSharedInformerFactory()
@@ -71,7 +76,7 @@ func newResourceWatcher(
 }
 
 func (rw *resourceWatcher) prepareSharedInformerFactory() {
-	factory := informers.NewSharedInformerFactoryWithOptions(rw.client, 0)
+	factory := informers.NewSharedInformerFactoryWithOptions(rw.client, rw.config.ResyncInterval)
 
 	// Add shared informers for each resource type that has to be watched.
 	rw.setupInformers(&corev1.Pod{}, factory.Core().V1().Pods().Informer())
@@ -99,6 +104,7 @@ func (rw *resourceWatcher) prepareSharedInformerFactory() {
 func (rw *resourceWatcher) startWatchingResources(ctx context.Context) {
 	var cancel context.CancelFunc
 	rw.timedContextForInitialSync, cancel = context.WithTimeout(ctx, rw.initialTimeout)
+	rw.done = ctx.Done()
 
 	// Start off individual informers in the factory.
 	rw.sharedInformerFactory.Start(ctx.Done())
@@ -121,6 +127,11 @@ func (rw *resourceWatcher) setupInformers(o runtime.Object, informer cache.Share
 		DeleteFunc: rw.onDelete,
 	})
 	rw.dataCollector.SetupMetadataStore(o, informer.GetStore())
+
+	resource := reflect.TypeOf(o).Elem().Name()
+	if err := informer.SetWatchErrorHandler(rw.watchErrorHandler(resource)); err != nil {
+		rw.logger.Warn("Failed to set watch error handler", zap.String("resource", resource), zap.Error(err))
+	}
 }
 
 func (rw *resourceWatcher) onAdd(obj interface{}) {
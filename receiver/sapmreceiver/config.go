@@ -27,4 +27,23 @@ type Config struct {
 	confighttp.HTTPServerSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
 
 	splunk.AccessTokenPassthroughConfig `mapstructure:",squash"`
+
+	// ProcessTagsMapping renames the resource attributes translated from Jaeger process tags, e.g. from "ip" to
+	// "host.ip", so that downstream processors see consistent conventions. Attributes are not renamed if the
+	// target attribute is already set.
+	ProcessTagsMapping map[string]string `mapstructure:"process_tags_mapping"`
+
+	// UnixSocket makes the receiver listen on a unix domain socket instead of the TCP endpoint, e.g. for agents on
+	// the same host, removing the TCP overhead and the need for network policies.
+	UnixSocket UnixSocketSettings `mapstructure:"unix_socket"`
+}
+
+// UnixSocketSettings defines the unix domain socket the receiver listens on.
+type UnixSocketSettings struct {
+	// Path of the socket. The receiver listens on the TCP endpoint if empty.
+	Path string `mapstructure:"path"`
+
+	// Permissions of the socket file as an octal number, e.g. "0660" to restrict access to a group of agents.
+	// Defaults to the permissions given by the umask of the collector.
+	Permissions string `mapstructure:"permissions"`
 }
@@ -42,7 +42,7 @@ func TestLoadConfig(t *testing.T) {
 
 	// The receiver `sapm/disabled` doesn't count because disabled receivers
 	// are excluded from the final list.
-	assert.Equal(t, len(cfg.Receivers), 4)
+	assert.Equal(t, len(cfg.Receivers), 6)
 
 	r0 := cfg.Receivers["sapm"]
 	assert.Equal(t, r0, factory.CreateDefaultConfig())
@@ -91,4 +91,10 @@ func TestLoadConfig(t *testing.T) {
 				AccessTokenPassthrough: true,
 			},
 		})
+
+	r4 := cfg.Receivers["sapm/process_tags"].(*Config)
+	assert.Equal(t, map[string]string{"ip": "host.ip"}, r4.ProcessTagsMapping)
+
+	r5 := cfg.Receivers["sapm/unix_socket"].(*Config)
+	assert.Equal(t, UnixSocketSettings{Path: "/var/run/sapm.sock", Permissions: "0660"}, r5.UnixSocket)
 }
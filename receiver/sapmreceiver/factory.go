@@ -18,8 +18,10 @@ package sapmreceiver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"os"
 	"strconv"
 
 	"go.opentelemetry.io/collector/component"
@@ -75,8 +77,15 @@ func extractPortFromEndpoint(endpoint string) (int, error) {
 	return int(port), nil
 }
 
-// verify that the configured port is not 0
+// verify that the configured port is not 0, or that the unix socket is valid
 func (rCfg *Config) validate() error {
+	if rCfg.UnixSocket.Path != "" {
+		if rCfg.TLSSetting != nil {
+			return errors.New(`"unix_socket" cannot be used with "tls_settings"`)
+		}
+		_, err := rCfg.UnixSocket.permissions()
+		return err
+	}
 	_, err := extractPortFromEndpoint(rCfg.Endpoint)
 	if err != nil {
 		return err
@@ -84,6 +93,18 @@ func (rCfg *Config) validate() error {
 	return nil
 }
 
+// permissions returns the permissions of the socket file, 0 if not set.
+func (s UnixSocketSettings) permissions() (os.FileMode, error) {
+	if s.Permissions == "" {
+		return 0, nil
+	}
+	perm, err := strconv.ParseUint(s.Permissions, 8, 32)
+	if err != nil || perm > 0777 {
+		return 0, fmt.Errorf(`invalid "unix_socket.permissions" %q, must be an octal number such as "0660"`, s.Permissions)
+	}
+	return os.FileMode(perm), nil
+}
+
 // CreateTracesReceiver creates a trace receiver based on provided config.
 func createTraceReceiver(
 	ctx context.Context,
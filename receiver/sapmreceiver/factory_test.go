@@ -22,6 +22,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configcheck"
 	"go.opentelemetry.io/collector/config/configerror"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.uber.org/zap"
 )
 
@@ -78,3 +80,25 @@ func TestCreateLargePort(t *testing.T) {
 	_, err := factory.CreateTracesReceiver(context.Background(), params, cfg, nil)
 	assert.Error(t, err, "receiver creation with too large port number must fail")
 }
+
+func TestCreateUnixSocket(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	// The TCP endpoint is not used.
+	rCfg.Endpoint = ""
+	rCfg.UnixSocket.Path = "/var/run/sapm.sock"
+	params := component.ReceiverCreateParams{Logger: zap.NewNop()}
+	_, err := factory.CreateTracesReceiver(context.Background(), params, cfg, consumertest.NewTracesNop())
+	assert.NoError(t, err)
+
+	rCfg.UnixSocket.Permissions = "0999"
+	_, err = factory.CreateTracesReceiver(context.Background(), params, cfg, consumertest.NewTracesNop())
+	assert.EqualError(t, err, `invalid "unix_socket.permissions" "0999", must be an octal number such as "0660"`)
+
+	rCfg.UnixSocket.Permissions = ""
+	rCfg.TLSSetting = &configtls.TLSServerSetting{}
+	_, err = factory.CreateTracesReceiver(context.Background(), params, cfg, consumertest.NewTracesNop())
+	assert.EqualError(t, err, `"unix_socket" cannot be used with "tls_settings"`)
+}
@@ -18,11 +18,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	splunksapm "github.com/signalfx/sapm-proto/gen"
@@ -30,6 +34,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenterror"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/pdata"
 	"go.opentelemetry.io/collector/obsreport"
 	jaegertranslator "go.opentelemetry.io/collector/translator/trace/jaeger"
 	"go.uber.org/zap"
@@ -90,6 +95,10 @@ func (sr *sapmReceiver) handleRequest(ctx context.Context, req *http.Request) er
 		}
 	}
 
+	if len(sr.config.ProcessTagsMapping) > 0 {
+		mapProcessTags(td, sr.config.ProcessTagsMapping)
+	}
+
 	// pass the trace data to the next consumer
 	err = sr.nextConsumer.ConsumeTraces(ctx, td)
 	if err != nil {
@@ -100,6 +109,25 @@ func (sr *sapmReceiver) handleRequest(ctx context.Context, req *http.Request) er
 	return err
 }
 
+// mapProcessTags renames the resource attributes translated from Jaeger process tags according to the mapping.
+func mapProcessTags(td pdata.Traces, mapping map[string]string) {
+	rSpans := td.ResourceSpans()
+	for i := 0; i < rSpans.Len(); i++ {
+		attrs := rSpans.At(i).Resource().Attributes()
+		for from, to := range mapping {
+			value, ok := attrs.Get(from)
+			if !ok {
+				continue
+			}
+			if _, exists := attrs.Get(to); exists {
+				continue
+			}
+			attrs.Insert(to, value)
+			attrs.Delete(from)
+		}
+	}
+}
+
 // HTTPHandlerFunction returns an http.HandlerFunc that handles SAPM requests
 func (sr *sapmReceiver) HTTPHandlerFunc(rw http.ResponseWriter, req *http.Request) {
 	// create context with the receiver name from the request context
@@ -171,9 +199,15 @@ func (sr *sapmReceiver) Start(_ context.Context, host component.Host) error {
 		var ln net.Listener
 
 		// set up the listener
-		ln, err = sr.config.HTTPServerSettings.ToListener()
+		if sr.config.UnixSocket.Path != "" {
+			ln, err = listenUnix(sr.config.UnixSocket)
+		} else {
+			ln, err = sr.config.HTTPServerSettings.ToListener()
+			if err != nil {
+				err = fmt.Errorf("failed to bind to address %s: %w", sr.config.Endpoint, err)
+			}
+		}
 		if err != nil {
-			err = fmt.Errorf("failed to bind to address %s: %w", sr.config.Endpoint, err)
 			return
 		}
 
@@ -194,6 +228,51 @@ func (sr *sapmReceiver) Start(_ context.Context, host component.Host) error {
 	return err
 }
 
+// listenUnix listens on the unix domain socket, replacing the socket file left behind by a previous run, if any.
+// The socket file is removed when the listener is closed.
+func listenUnix(settings UnixSocketSettings) (net.Listener, error) {
+	perm, err := settings.permissions()
+	if err != nil {
+		return nil, err
+	}
+	if err = removeStaleSocket(settings.Path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", settings.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", settings.Path, err)
+	}
+	if perm != 0 {
+		if err = os.Chmod(settings.Path, perm); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to set the permissions of unix socket %s: %w", settings.Path, err)
+		}
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket file at the path if nothing listens on it anymore, as found by dialing it. A
+// socket still in use, e.g. by another collector, is left untouched.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		// Listening fails on anything but a missing file.
+		return nil
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("unix socket %s is in use by another process", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return nil
+	}
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+	return nil
+}
+
 // StopTraceRetention stops the the sapmReceiver's server
 func (sr *sapmReceiver) Shutdown(context.Context) error {
 	sr.mu.Lock()
@@ -23,7 +23,10 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -391,3 +394,102 @@ func TestAccessTokenPassthrough(t *testing.T) {
 		})
 	}
 }
+
+func TestMapProcessTags(t *testing.T) {
+	td := pdata.NewTraces()
+	td.ResourceSpans().Resize(2)
+	first := td.ResourceSpans().At(0).Resource().Attributes()
+	first.InsertString("ip", "10.0.0.1")
+	first.InsertString("client-uuid", "1234")
+	second := td.ResourceSpans().At(1).Resource().Attributes()
+	second.InsertString("ip", "10.0.0.2")
+	second.InsertString("host.ip", "10.0.0.3")
+
+	mapProcessTags(td, map[string]string{"ip": "host.ip", "client-uuid": "service.instance.id"})
+
+	assert.Equal(t, map[string]interface{}{
+		"host.ip":             "10.0.0.1",
+		"service.instance.id": "1234",
+	}, attributesToMap(first))
+	// The target attribute is already set.
+	assert.Equal(t, map[string]interface{}{
+		"ip":      "10.0.0.2",
+		"host.ip": "10.0.0.3",
+	}, attributesToMap(second))
+}
+
+func attributesToMap(attrs pdata.AttributeMap) map[string]interface{} {
+	m := map[string]interface{}{}
+	attrs.ForEach(func(k string, v pdata.AttributeValue) {
+		m[k] = v.StringVal()
+	})
+	return m
+}
+
+func TestUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sapmreceiver")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "sapm.sock")
+
+	// A socket left behind by a previous run is replaced.
+	stale, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	config := &Config{
+		UnixSocket: UnixSocketSettings{
+			Path:        socketPath,
+			Permissions: "0660",
+		},
+	}
+	sink := new(consumertest.TracesSink)
+	sr := setupReceiver(t, config, sink)
+	defer sr.Shutdown(context.Background())
+
+	fi, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), fi.Mode().Perm())
+
+	sapm := &splunksapm.PostSpansRequest{
+		Batches: []*model.Batch{grpcFixture(time.Now().UTC())},
+	}
+	reqBytes, err := sapm.Marshal()
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "http://unix"+sapmprotocol.TraceEndpointV2, bytes.NewReader(reqBytes))
+	require.NoError(t, err)
+	req.Header.Set(sapmprotocol.ContentTypeHeaderName, sapmprotocol.ContentTypeHeaderValue)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, len(sink.AllTraces()))
+}
+
+func TestUnixSocketInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sapmreceiver")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "sapm.sock")
+
+	// A socket another process still listens on is not removed.
+	live, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer live.Close()
+
+	_, err = listenUnix(UnixSocketSettings{Path: socketPath})
+	require.EqualError(t, err, "unix socket "+socketPath+" is in use by another process")
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	conn.Close()
+}
@@ -27,4 +27,9 @@ type Config struct {
 	confighttp.HTTPServerSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
 
 	splunk.AccessTokenPassthroughConfig `mapstructure:",squash"`
+
+	// DecodeDistributions decodes the histograms and summaries sent as sets of datapoints, i.e. "_bucket" datapoints
+	// with an "upper_bound" dimension or datapoints with a "quantile" dimension along with their "_count" and "_sum"
+	// ones, into OTLP histograms and summaries rather than converting each datapoint on its own.
+	DecodeDistributions bool `mapstructure:"decode_distributions"`
 }
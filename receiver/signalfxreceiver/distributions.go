@@ -0,0 +1,378 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxreceiver
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+// SignalFx has no distribution datapoint type: histograms and summaries are sent as sets of datapoints sharing the
+// timestamp and dimensions of the distribution, which are the ones recognized here.
+const (
+	// upperBoundDimensionKey is the dimension holding the upper bound of the buckets of histograms, e.g. "+Inf".
+	upperBoundDimensionKey = "upper_bound"
+	// quantileDimensionKey is the dimension holding the quantile of the values of summaries, e.g. "0.99".
+	quantileDimensionKey = "quantile"
+
+	// bucketSuffix is the suffix of the metric of the buckets of histograms.
+	bucketSuffix = "_bucket"
+	// countSuffix is the suffix of the metric of the count of distributions.
+	countSuffix = "_count"
+	// sumSuffix is the suffix of the metric of the sum of distributions. The sum of histograms may also be sent under
+	// the metric of the distribution itself, as the SignalFx exporter does.
+	sumSuffix = "_sum"
+)
+
+// distributionKey identifies the datapoints of a distribution.
+type distributionKey struct {
+	metric     string
+	timestamp  int64
+	dimensions string
+}
+
+// distributionValue is a bucket of a histogram or a quantile of a summary, along with its datapoint.
+type distributionValue struct {
+	bound float64
+	dp    *sfxpb.DataPoint
+}
+
+// distribution accumulates the datapoints of a histogram, or a summary.
+type distribution struct {
+	key     distributionKey
+	summary bool
+	// dimensions are the dimensions of the distribution, without the upper_bound or quantile one.
+	dimensions []*sfxpb.Dimension
+	values     []distributionValue
+	count      *sfxpb.DataPoint
+	sum        *sfxpb.DataPoint
+	// metricSum is the datapoint of the metric of the distribution itself, used as its sum if there is no _sum one.
+	metricSum *sfxpb.DataPoint
+	// invalid is whether datapoints of the distribution conflict, e.g. with two counts.
+	invalid bool
+}
+
+// decodeDistributions decodes the histograms and summaries held by the datapoints into metrics. It returns the
+// datapoints that are not part of a complete distribution, to be converted on their own, and the ones that were
+// decoded. Datapoints of incomplete or inconsistent distributions are left as is.
+func decodeDistributions(dps []*sfxpb.DataPoint) ([]*sfxpb.DataPoint, []*sfxpb.DataPoint, pdata.MetricSlice) {
+	metrics := pdata.NewMetricSlice()
+	distributions := map[distributionKey]*distribution{}
+	var ordered []*distribution
+	members := make([]bool, len(dps))
+
+	// Buckets and quantiles come first, as they tell which metrics are distributions.
+	for i, dp := range dps {
+		if dp == nil {
+			continue
+		}
+		summary := false
+		metric := dp.Metric
+		boundKey := upperBoundDimensionKey
+		if strings.HasSuffix(metric, bucketSuffix) && hasDimension(dp, upperBoundDimensionKey) {
+			metric = strings.TrimSuffix(metric, bucketSuffix)
+		} else if hasDimension(dp, quantileDimensionKey) {
+			summary = true
+			boundKey = quantileDimensionKey
+		} else {
+			continue
+		}
+		bound, dimensions, ok := splitBound(dp, boundKey)
+		if !ok || (summary && (bound < 0 || bound > 1)) {
+			continue
+		}
+		if _, ok := datumValue(dp); !ok {
+			continue
+		}
+		key := distributionKey{metric: metric, timestamp: dp.Timestamp, dimensions: dimensionsKey(dimensions)}
+		d := distributions[key]
+		if d == nil {
+			d = &distribution{key: key, summary: summary, dimensions: dimensions}
+			distributions[key] = d
+			ordered = append(ordered, d)
+		} else if d.summary != summary {
+			continue
+		}
+		d.values = append(d.values, distributionValue{bound: bound, dp: dp})
+		members[i] = true
+	}
+	if len(ordered) == 0 {
+		return dps, nil, metrics
+	}
+
+	for i, dp := range dps {
+		if dp == nil || members[i] {
+			continue
+		}
+		if _, ok := datumValue(dp); !ok {
+			continue
+		}
+		key := distributionKey{timestamp: dp.Timestamp, dimensions: dimensionsKey(dp.Dimensions)}
+		var field **sfxpb.DataPoint
+		switch {
+		case strings.HasSuffix(dp.Metric, countSuffix):
+			key.metric = strings.TrimSuffix(dp.Metric, countSuffix)
+			if d := distributions[key]; d != nil {
+				field = &d.count
+			}
+		case strings.HasSuffix(dp.Metric, sumSuffix):
+			key.metric = strings.TrimSuffix(dp.Metric, sumSuffix)
+			if d := distributions[key]; d != nil {
+				field = &d.sum
+			}
+		}
+		if field == nil {
+			key.metric = dp.Metric
+			if d := distributions[key]; d != nil && !d.summary {
+				field = &d.metricSum
+			}
+		}
+		if field == nil {
+			continue
+		}
+		if *field != nil {
+			distributions[key].invalid = true
+			continue
+		}
+		*field = dp
+	}
+
+	decoded := make(map[*sfxpb.DataPoint]bool)
+	for _, d := range ordered {
+		if d.sum == nil {
+			d.sum = d.metricSum
+		}
+		if d.invalid || d.count == nil || d.sum == nil {
+			continue
+		}
+		var ok bool
+		if d.summary {
+			ok = d.fillSummary(metrics)
+		} else {
+			ok = d.fillHistogram(metrics)
+		}
+		if !ok {
+			continue
+		}
+		decoded[d.count] = true
+		decoded[d.sum] = true
+		for _, v := range d.values {
+			decoded[v.dp] = true
+		}
+	}
+	if len(decoded) == 0 {
+		return dps, nil, metrics
+	}
+
+	remaining := make([]*sfxpb.DataPoint, 0, len(dps)-len(decoded))
+	decodedDPs := make([]*sfxpb.DataPoint, 0, len(decoded))
+	for _, dp := range dps {
+		if decoded[dp] {
+			decodedDPs = append(decodedDPs, dp)
+		} else {
+			remaining = append(remaining, dp)
+		}
+	}
+	return remaining, decodedDPs, metrics
+}
+
+// fillHistogram appends the histogram to the metrics, and tells whether its datapoints are consistent. Bucket
+// counts are accepted both per bucket, as sent by the SignalFx exporter, and cumulative, as in Prometheus.
+func (d *distribution) fillHistogram(metrics pdata.MetricSlice) bool {
+	count, ok := countValue(d.count)
+	if !ok {
+		return false
+	}
+	sort.Slice(d.values, func(i, j int) bool { return d.values[i].bound < d.values[j].bound })
+	n := len(d.values)
+	if !math.IsInf(d.values[n-1].bound, 1) {
+		return false
+	}
+	bounds := make([]float64, 0, n-1)
+	counts := make([]uint64, n)
+	var total uint64
+	cumulative := true
+	for i, v := range d.values {
+		c, ok := countValue(v.dp)
+		if !ok || (i > 0 && v.bound == d.values[i-1].bound) {
+			return false
+		}
+		if i < n-1 {
+			bounds = append(bounds, v.bound)
+		}
+		if i > 0 && c < counts[i-1] {
+			cumulative = false
+		}
+		counts[i] = c
+		total += c
+	}
+	switch {
+	case total == count:
+	case cumulative && counts[n-1] == count:
+		for i := n - 1; i > 0; i-- {
+			counts[i] -= counts[i-1]
+		}
+	default:
+		return false
+	}
+
+	temporality := pdata.AggregationTemporalityCumulative
+	if d.count.GetMetricType() == sfxpb.MetricType_COUNTER {
+		temporality = pdata.AggregationTemporalityDelta
+	}
+	metrics.Resize(metrics.Len() + 1)
+	m := metrics.At(metrics.Len() - 1)
+	m.SetName(d.key.metric)
+	if d.sum.Value.IntValue != nil {
+		m.SetDataType(pdata.MetricDataTypeIntHistogram)
+		m.IntHistogram().SetAggregationTemporality(temporality)
+		dps := m.IntHistogram().DataPoints()
+		dps.Resize(1)
+		dp := dps.At(0)
+		dp.SetTimestamp(dpTimestamp(d.count))
+		dp.SetCount(count)
+		dp.SetSum(*d.sum.Value.IntValue)
+		dp.SetBucketCounts(counts)
+		dp.SetExplicitBounds(bounds)
+		fillInLabels(d.dimensions, dp.LabelsMap())
+		return true
+	}
+	sum, _ := datumValue(d.sum)
+	m.SetDataType(pdata.MetricDataTypeDoubleHistogram)
+	m.DoubleHistogram().SetAggregationTemporality(temporality)
+	dps := m.DoubleHistogram().DataPoints()
+	dps.Resize(1)
+	dp := dps.At(0)
+	dp.SetTimestamp(dpTimestamp(d.count))
+	dp.SetCount(count)
+	dp.SetSum(sum)
+	dp.SetBucketCounts(counts)
+	dp.SetExplicitBounds(bounds)
+	fillInLabels(d.dimensions, dp.LabelsMap())
+	return true
+}
+
+// fillSummary appends the summary to the metrics, and tells whether its datapoints are consistent.
+func (d *distribution) fillSummary(metrics pdata.MetricSlice) bool {
+	count, ok := countValue(d.count)
+	if !ok {
+		return false
+	}
+	sort.Slice(d.values, func(i, j int) bool { return d.values[i].bound < d.values[j].bound })
+	for i := 1; i < len(d.values); i++ {
+		if d.values[i].bound == d.values[i-1].bound {
+			return false
+		}
+	}
+
+	metrics.Resize(metrics.Len() + 1)
+	m := metrics.At(metrics.Len() - 1)
+	m.SetName(d.key.metric)
+	m.SetDataType(pdata.MetricDataTypeDoubleSummary)
+	dps := m.DoubleSummary().DataPoints()
+	dps.Resize(1)
+	dp := dps.At(0)
+	dp.SetTimestamp(dpTimestamp(d.count))
+	dp.SetCount(count)
+	sum, _ := datumValue(d.sum)
+	dp.SetSum(sum)
+	quantiles := dp.QuantileValues()
+	quantiles.Resize(len(d.values))
+	for i, v := range d.values {
+		value, _ := datumValue(v.dp)
+		quantiles.At(i).SetQuantile(v.bound)
+		quantiles.At(i).SetValue(value)
+	}
+	fillInLabels(d.dimensions, dp.LabelsMap())
+	return true
+}
+
+// hasDimension tells whether the datapoint has the dimension.
+func hasDimension(dp *sfxpb.DataPoint, key string) bool {
+	for _, dim := range dp.Dimensions {
+		if dim != nil && dim.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBound returns the numeric value of the given dimension of the datapoint, and its other dimensions.
+func splitBound(dp *sfxpb.DataPoint, key string) (float64, []*sfxpb.Dimension, bool) {
+	var bound float64
+	found := false
+	dimensions := make([]*sfxpb.Dimension, 0, len(dp.Dimensions)-1)
+	for _, dim := range dp.Dimensions {
+		if dim == nil {
+			continue
+		}
+		if dim.Key != key {
+			dimensions = append(dimensions, dim)
+			continue
+		}
+		value, err := strconv.ParseFloat(dim.Value, 64)
+		if err != nil || found || math.IsNaN(value) {
+			return 0, nil, false
+		}
+		bound, found = value, true
+	}
+	return bound, dimensions, found
+}
+
+// dimensionsKey returns a key identifying the dimensions, regardless of their order.
+func dimensionsKey(dimensions []*sfxpb.Dimension) string {
+	pairs := make([]string, 0, len(dimensions))
+	for _, dim := range dimensions {
+		if dim != nil {
+			pairs = append(pairs, dim.Key+"\x00"+dim.Value)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\x01")
+}
+
+// datumValue returns the numeric value of the datapoint.
+func datumValue(dp *sfxpb.DataPoint) (float64, bool) {
+	switch {
+	case dp.Value.IntValue != nil:
+		return float64(*dp.Value.IntValue), true
+	case dp.Value.DoubleValue != nil:
+		return *dp.Value.DoubleValue, true
+	}
+	return 0, false
+}
+
+// countValue returns the value of the datapoint as a count, which must be a non-negative integer.
+func countValue(dp *sfxpb.DataPoint) (uint64, bool) {
+	if dp.Value.IntValue != nil {
+		if *dp.Value.IntValue < 0 {
+			return 0, false
+		}
+		return uint64(*dp.Value.IntValue), true
+	}
+	if dp.Value.DoubleValue != nil {
+		value := *dp.Value.DoubleValue
+		if value < 0 || value != math.Trunc(value) || math.IsInf(value, 0) {
+			return 0, false
+		}
+		return uint64(value), true
+	}
+	return 0, false
+}
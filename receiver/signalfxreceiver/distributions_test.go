@@ -0,0 +1,229 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxreceiver
+
+import (
+	"testing"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/pdata"
+)
+
+func distributionDP(metric string, metricType sfxpb.MetricType, value interface{}, dims ...string) *sfxpb.DataPoint {
+	dp := &sfxpb.DataPoint{Metric: metric, Timestamp: 1000, MetricType: metricType.Enum()}
+	switch v := value.(type) {
+	case int:
+		dp.Value.IntValue = int64Ptr(int64(v))
+	case float64:
+		dp.Value.DoubleValue = float64Ptr(v)
+	}
+	for i := 0; i+1 < len(dims); i += 2 {
+		dp.Dimensions = append(dp.Dimensions, &sfxpb.Dimension{Key: dims[i], Value: dims[i+1]})
+	}
+	return dp
+}
+
+func TestDecodeDistributionsHistogram(t *testing.T) {
+	cumulative := sfxpb.MetricType_CUMULATIVE_COUNTER
+	tests := []struct {
+		name string
+		dps  []*sfxpb.DataPoint
+	}{
+		{
+			// As sent by the SignalFx exporter: per bucket counts and the sum under the metric itself.
+			name: "per_bucket",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_count", cumulative, 6, "host", "a"),
+				distributionDP("latency", cumulative, 21, "host", "a"),
+				distributionDP("latency_bucket", cumulative, 1, "host", "a", "upper_bound", "1"),
+				distributionDP("latency_bucket", cumulative, 2, "upper_bound", "5", "host", "a"),
+				distributionDP("latency_bucket", cumulative, 3, "host", "a", "upper_bound", "+Inf"),
+			},
+		},
+		{
+			// As sent by Prometheus monitors: cumulative counts, out of order, and a _sum datapoint.
+			name: "cumulative_buckets",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_bucket", cumulative, 6, "host", "a", "upper_bound", "+Inf"),
+				distributionDP("latency_bucket", cumulative, 1, "host", "a", "upper_bound", "1"),
+				distributionDP("latency_bucket", cumulative, 3, "host", "a", "upper_bound", "5"),
+				distributionDP("latency_sum", cumulative, 21, "host", "a"),
+				distributionDP("latency_count", cumulative, 6, "host", "a"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, decoded, metrics := decodeDistributions(tt.dps)
+			assert.Empty(t, remaining)
+			assert.Len(t, decoded, len(tt.dps))
+			require.Equal(t, 1, metrics.Len())
+
+			m := metrics.At(0)
+			assert.Equal(t, "latency", m.Name())
+			require.Equal(t, pdata.MetricDataTypeIntHistogram, m.DataType())
+			assert.Equal(t, pdata.AggregationTemporalityCumulative, m.IntHistogram().AggregationTemporality())
+			require.Equal(t, 1, m.IntHistogram().DataPoints().Len())
+			dp := m.IntHistogram().DataPoints().At(0)
+			assert.Equal(t, pdata.Timestamp(1000*1e6), dp.Timestamp())
+			assert.Equal(t, uint64(6), dp.Count())
+			assert.Equal(t, int64(21), dp.Sum())
+			assert.Equal(t, []float64{1, 5}, dp.ExplicitBounds())
+			assert.Equal(t, []uint64{1, 2, 3}, dp.BucketCounts())
+			assert.Equal(t, map[string]string{"host": "a"}, labelsAsMap(dp.LabelsMap()))
+		})
+	}
+}
+
+func TestDecodeDistributionsDoubleHistogram(t *testing.T) {
+	counter := sfxpb.MetricType_COUNTER
+	dps := []*sfxpb.DataPoint{
+		distributionDP("size_count", counter, 2),
+		distributionDP("size_sum", counter, 1.5),
+		distributionDP("size_bucket", counter, 2.0, "upper_bound", "+Inf"),
+	}
+	remaining, _, metrics := decodeDistributions(dps)
+	assert.Empty(t, remaining)
+	require.Equal(t, 1, metrics.Len())
+	m := metrics.At(0)
+	require.Equal(t, pdata.MetricDataTypeDoubleHistogram, m.DataType())
+	assert.Equal(t, pdata.AggregationTemporalityDelta, m.DoubleHistogram().AggregationTemporality())
+	dp := m.DoubleHistogram().DataPoints().At(0)
+	assert.Equal(t, uint64(2), dp.Count())
+	assert.Equal(t, 1.5, dp.Sum())
+	assert.Empty(t, dp.ExplicitBounds())
+	assert.Equal(t, []uint64{2}, dp.BucketCounts())
+}
+
+func TestDecodeDistributionsSummary(t *testing.T) {
+	gauge := sfxpb.MetricType_GAUGE
+	cumulative := sfxpb.MetricType_CUMULATIVE_COUNTER
+	dps := []*sfxpb.DataPoint{
+		distributionDP("rpc", gauge, 0.9, "quantile", "0.99", "service", "api"),
+		distributionDP("rpc", gauge, 0.2, "service", "api", "quantile", "0.5"),
+		distributionDP("rpc_sum", cumulative, 30.5, "service", "api"),
+		distributionDP("rpc_count", cumulative, 100, "service", "api"),
+		distributionDP("other", gauge, 1),
+	}
+	remaining, decoded, metrics := decodeDistributions(dps)
+	assert.Equal(t, []*sfxpb.DataPoint{dps[4]}, remaining)
+	assert.Equal(t, dps[:4], decoded)
+	require.Equal(t, 1, metrics.Len())
+
+	m := metrics.At(0)
+	assert.Equal(t, "rpc", m.Name())
+	require.Equal(t, pdata.MetricDataTypeDoubleSummary, m.DataType())
+	dp := m.DoubleSummary().DataPoints().At(0)
+	assert.Equal(t, uint64(100), dp.Count())
+	assert.Equal(t, 30.5, dp.Sum())
+	assert.Equal(t, map[string]string{"service": "api"}, labelsAsMap(dp.LabelsMap()))
+	require.Equal(t, 2, dp.QuantileValues().Len())
+	assert.Equal(t, 0.5, dp.QuantileValues().At(0).Quantile())
+	assert.Equal(t, 0.2, dp.QuantileValues().At(0).Value())
+	assert.Equal(t, 0.99, dp.QuantileValues().At(1).Quantile())
+	assert.Equal(t, 0.9, dp.QuantileValues().At(1).Value())
+}
+
+func TestDecodeDistributionsLeftAsIs(t *testing.T) {
+	cumulative := sfxpb.MetricType_CUMULATIVE_COUNTER
+	tests := []struct {
+		name string
+		dps  []*sfxpb.DataPoint
+	}{
+		{
+			name: "no_distribution",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("requests_count", cumulative, 1),
+				distributionDP("requests", cumulative, 1),
+				nil,
+			},
+		},
+		{
+			name: "missing_count",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency", cumulative, 1),
+				distributionDP("latency_bucket", cumulative, 1, "upper_bound", "+Inf"),
+			},
+		},
+		{
+			name: "missing_infinity_bucket",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_count", cumulative, 1),
+				distributionDP("latency", cumulative, 1),
+				distributionDP("latency_bucket", cumulative, 1, "upper_bound", "10"),
+			},
+		},
+		{
+			name: "inconsistent_counts",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_count", cumulative, 5),
+				distributionDP("latency", cumulative, 1),
+				distributionDP("latency_bucket", cumulative, 3, "upper_bound", "1"),
+				distributionDP("latency_bucket", cumulative, 1, "upper_bound", "+Inf"),
+			},
+		},
+		{
+			name: "different_dimensions",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_count", cumulative, 1, "host", "b"),
+				distributionDP("latency", cumulative, 1, "host", "b"),
+				distributionDP("latency_bucket", cumulative, 1, "host", "a", "upper_bound", "+Inf"),
+			},
+		},
+		{
+			name: "duplicate_count",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_count", cumulative, 1),
+				distributionDP("latency_count", cumulative, 1),
+				distributionDP("latency", cumulative, 1),
+				distributionDP("latency_bucket", cumulative, 1, "upper_bound", "+Inf"),
+			},
+		},
+		{
+			name: "invalid_bound",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("latency_count", cumulative, 1),
+				distributionDP("latency", cumulative, 1),
+				distributionDP("latency_bucket", cumulative, 1, "upper_bound", "high"),
+			},
+		},
+		{
+			name: "invalid_quantile",
+			dps: []*sfxpb.DataPoint{
+				distributionDP("rpc_count", cumulative, 1),
+				distributionDP("rpc_sum", cumulative, 1),
+				distributionDP("rpc", cumulative, 1, "quantile", "99"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, decoded, metrics := decodeDistributions(tt.dps)
+			assert.Equal(t, tt.dps, remaining)
+			assert.Empty(t, decoded)
+			assert.Equal(t, 0, metrics.Len())
+		})
+	}
+}
+
+func labelsAsMap(labels pdata.StringMap) map[string]string {
+	out := map[string]string{}
+	labels.ForEach(func(k string, v string) {
+		out[k] = v
+	})
+	return out
+}
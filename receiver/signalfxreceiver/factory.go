@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"sync"
 
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
@@ -40,6 +41,8 @@ const (
 
 // NewFactory creates a factory for SignalFx receiver.
 func NewFactory() component.ReceiverFactory {
+	view.Register(MetricViews()...)
+
 	return receiverhelper.NewFactory(
 		typeStr,
 		createDefaultConfig,
@@ -0,0 +1,196 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxreceiver
+
+import (
+	"context"
+	"strings"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+// Reasons for which data points are refused.
+const (
+	refusalReasonInvalidDataPoint = "invalid_datapoint"
+	refusalReasonConsumerError    = "consumer_error"
+)
+
+// unknownDataPointType is the type of data points whose metric type is not a SignalFx one.
+const unknownDataPointType = "unknown"
+
+var (
+	tagKeyReceiver = tag.MustNewKey(obsreport.ReceiverKey)
+	tagKeyType     = tag.MustNewKey("type")
+	tagKeyReason   = tag.MustNewKey("reason")
+
+	mAcceptedDataPoints = stats.Int64(
+		"receiver/signalfx/accepted_datapoints",
+		"Number of datapoints accepted by the receiver, by datapoint type",
+		stats.UnitDimensionless)
+	mRefusedDataPoints = stats.Int64(
+		"receiver/signalfx/refused_datapoints",
+		"Number of datapoints refused by the receiver, by datapoint type and reason",
+		stats.UnitDimensionless)
+	mFailedRequests = stats.Int64(
+		"receiver/signalfx/failed_requests",
+		"Number of requests failed before their content could be decoded, by reason",
+		stats.UnitDimensionless)
+)
+
+// failureReasons are the reasons for which requests fail, by response body.
+var failureReasons = map[string]string{
+	string(invalidMethodRespBody):    "invalid_method",
+	string(invalidContentRespBody):   "invalid_content_type",
+	string(invalidEncodingRespBody):  "invalid_encoding",
+	string(errGzipReaderRespBody):    "gzip",
+	string(errReadBodyRespBody):      "read_body",
+	string(errUnmarshalBodyRespBody): "unmarshal",
+	string(errLogsNotConfigured):     "pipeline_not_configured",
+	string(errMetricsNotConfigured):  "pipeline_not_configured",
+}
+
+// MetricViews returns the metrics views of the receiver.
+func MetricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mAcceptedDataPoints.Name(),
+			Measure:     mAcceptedDataPoints,
+			Description: mAcceptedDataPoints.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyReceiver, tagKeyType},
+		},
+		{
+			Name:        mRefusedDataPoints.Name(),
+			Measure:     mRefusedDataPoints,
+			Description: mRefusedDataPoints.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyReceiver, tagKeyType, tagKeyReason},
+		},
+		{
+			Name:        mFailedRequests.Name(),
+			Measure:     mFailedRequests,
+			Description: mFailedRequests.Description(),
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{tagKeyReceiver, tagKeyReason},
+		},
+	}
+}
+
+// recordDataPoints records the data points of a request as accepted, or refused if the next consumer failed, and
+// the data points that could not be converted as refused. The decoded data points are the ones converted into
+// distributions, which are not counted by type from the metrics.
+func recordDataPoints(ctx context.Context, receiverName string, dps []*sfxpb.DataPoint, decoded []*sfxpb.DataPoint, md pdata.Metrics, err error) {
+	received := countDataPointsByType(dps)
+	converted := countMetricsByType(md)
+	for dpType, count := range countDataPointsByType(decoded) {
+		converted[dpType] += count
+	}
+	for dpType, count := range received {
+		if invalid := count - converted[dpType]; invalid > 0 {
+			recordRefusedDataPoints(ctx, receiverName, dpType, refusalReasonInvalidDataPoint, invalid)
+		}
+	}
+	for dpType, count := range converted {
+		if err != nil {
+			recordRefusedDataPoints(ctx, receiverName, dpType, refusalReasonConsumerError, count)
+			continue
+		}
+		_ = stats.RecordWithTags(
+			ctx,
+			[]tag.Mutator{tag.Upsert(tagKeyReceiver, receiverName), tag.Upsert(tagKeyType, dpType)},
+			mAcceptedDataPoints.M(int64(count)))
+	}
+}
+
+func recordRefusedDataPoints(ctx context.Context, receiverName string, dpType string, reason string, count int) {
+	_ = stats.RecordWithTags(
+		ctx,
+		[]tag.Mutator{
+			tag.Upsert(tagKeyReceiver, receiverName),
+			tag.Upsert(tagKeyType, dpType),
+			tag.Upsert(tagKeyReason, reason),
+		},
+		mRefusedDataPoints.M(int64(count)))
+}
+
+// recordFailedRequest records a request that failed with the given response body.
+func recordFailedRequest(ctx context.Context, receiverName string, jsonResponse []byte) {
+	reason, ok := failureReasons[string(jsonResponse)]
+	if !ok {
+		return
+	}
+	_ = stats.RecordWithTags(
+		ctx,
+		[]tag.Mutator{tag.Upsert(tagKeyReceiver, receiverName), tag.Upsert(tagKeyReason, reason)},
+		mFailedRequests.M(1))
+}
+
+// countDataPointsByType counts the SignalFx data points by type, e.g. "gauge" or "cumulative_counter".
+func countDataPointsByType(dps []*sfxpb.DataPoint) map[string]int {
+	counts := map[string]int{}
+	for _, dp := range dps {
+		if dp == nil {
+			continue
+		}
+		dpType := unknownDataPointType
+		if name, ok := sfxpb.MetricType_name[int32(dp.GetMetricType())]; ok {
+			dpType = strings.ToLower(name)
+		}
+		counts[dpType]++
+	}
+	return counts
+}
+
+// countMetricsByType counts the data points converted from SignalFx data points by their original type.
+func countMetricsByType(md pdata.Metrics) map[string]int {
+	counts := map[string]int{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				m := metrics.At(k)
+				var temporality pdata.AggregationTemporality
+				var count int
+				switch m.DataType() {
+				case pdata.MetricDataTypeIntGauge:
+					counts[strings.ToLower(sfxpb.MetricType_GAUGE.String())] += m.IntGauge().DataPoints().Len()
+					continue
+				case pdata.MetricDataTypeDoubleGauge:
+					counts[strings.ToLower(sfxpb.MetricType_GAUGE.String())] += m.DoubleGauge().DataPoints().Len()
+					continue
+				case pdata.MetricDataTypeIntSum:
+					temporality, count = m.IntSum().AggregationTemporality(), m.IntSum().DataPoints().Len()
+				case pdata.MetricDataTypeDoubleSum:
+					temporality, count = m.DoubleSum().AggregationTemporality(), m.DoubleSum().DataPoints().Len()
+				default:
+					continue
+				}
+				if temporality == pdata.AggregationTemporalityCumulative {
+					counts[strings.ToLower(sfxpb.MetricType_CUMULATIVE_COUNTER.String())] += count
+				} else {
+					counts[strings.ToLower(sfxpb.MetricType_COUNTER.String())] += count
+				}
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,155 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxreceiver
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.uber.org/zap"
+)
+
+// retrieveSums returns the sums of the view of the given measure for the receiver, by the values of the given tags.
+func retrieveSums(t *testing.T, measureName string, receiverName string, keys ...tag.Key) map[string]float64 {
+	rows, err := view.RetrieveData(measureName)
+	require.NoError(t, err)
+	sums := map[string]float64{}
+	for _, row := range rows {
+		values := map[tag.Key]string{}
+		for _, tg := range row.Tags {
+			values[tg.Key] = tg.Value
+		}
+		if values[tagKeyReceiver] != receiverName {
+			continue
+		}
+		var key string
+		for i, k := range keys {
+			if i > 0 {
+				key += "/"
+			}
+			key += values[k]
+		}
+		sums[key] = row.Data.(*view.SumData).Value
+	}
+	return sums
+}
+
+func TestDataPointMetrics(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	config := createDefaultConfig().(*Config)
+	config.NameVal = "signalfx/metrics"
+	rcv := newReceiver(zap.NewNop(), *config)
+	rcv.RegisterMetricsConsumer(consumertest.NewMetricsNop())
+
+	unknownType := sfxpb.MetricType(42)
+	msg := &sfxpb.DataPointUploadMessage{
+		Datapoints: []*sfxpb.DataPoint{
+			{Metric: "gauge", Value: sfxpb.Datum{IntValue: int64Ptr(1)}},
+			{Metric: "gauge", Value: sfxpb.Datum{DoubleValue: float64Ptr(1)}},
+			{Metric: "counter", MetricType: sfxpb.MetricType_COUNTER.Enum(), Value: sfxpb.Datum{IntValue: int64Ptr(1)}},
+			{Metric: "cumulative", MetricType: sfxpb.MetricType_CUMULATIVE_COUNTER.Enum(), Value: sfxpb.Datum{IntValue: int64Ptr(1)}},
+			{Metric: "cumulative", MetricType: sfxpb.MetricType_CUMULATIVE_COUNTER.Enum()},
+			{Metric: "unknown", MetricType: &unknownType, Value: sfxpb.Datum{IntValue: int64Ptr(1)}},
+		},
+	}
+	send := func(body []byte, contentType string) int {
+		req := httptest.NewRequest("POST", "http://localhost", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		rcv.handleDatapointReq(w, req)
+		return w.Code
+	}
+	body, err := msg.Marshal()
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, send(body, protobufContentType))
+	rcv.RegisterMetricsConsumer(consumertest.NewMetricsErr(errors.New("consumer failed")))
+	require.Equal(t, http.StatusInternalServerError, send(body, protobufContentType))
+	require.Equal(t, http.StatusUnsupportedMediaType, send(body, "application/json"))
+	require.Equal(t, http.StatusBadRequest, send([]byte("not protobuf"), protobufContentType))
+
+	assert.Equal(t, map[string]float64{
+		"gauge":              2,
+		"counter":            1,
+		"cumulative_counter": 1,
+	}, retrieveSums(t, mAcceptedDataPoints.Name(), "signalfx/metrics", tagKeyType))
+	assert.Equal(t, map[string]float64{
+		"gauge/consumer_error":                 2,
+		"counter/consumer_error":               1,
+		"cumulative_counter/consumer_error":    1,
+		"cumulative_counter/invalid_datapoint": 2,
+		"unknown/invalid_datapoint":            2,
+	}, retrieveSums(t, mRefusedDataPoints.Name(), "signalfx/metrics", tagKeyType, tagKeyReason))
+	assert.Equal(t, map[string]float64{
+		"invalid_content_type": 1,
+		"unmarshal":            1,
+	}, retrieveSums(t, mFailedRequests.Name(), "signalfx/metrics", tagKeyReason))
+}
+
+func TestDecodedDistributionMetrics(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	config := createDefaultConfig().(*Config)
+	config.NameVal = "signalfx/distributions"
+	config.DecodeDistributions = true
+	rcv := newReceiver(zap.NewNop(), *config)
+	sink := new(consumertest.MetricsSink)
+	rcv.RegisterMetricsConsumer(sink)
+
+	cumulative := sfxpb.MetricType_CUMULATIVE_COUNTER
+	msg := &sfxpb.DataPointUploadMessage{
+		Datapoints: []*sfxpb.DataPoint{
+			distributionDP("latency_count", cumulative, 1),
+			distributionDP("latency", cumulative, 3),
+			distributionDP("latency_bucket", cumulative, 1, "upper_bound", "+Inf"),
+			distributionDP("gauge", sfxpb.MetricType_GAUGE, 1),
+		},
+	}
+	body, err := msg.Marshal()
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "http://localhost", bytes.NewReader(body))
+	req.Header.Set("Content-Type", protobufContentType)
+	w := httptest.NewRecorder()
+	rcv.handleDatapointReq(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, sink.AllMetrics(), 1)
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	assert.Equal(t, "gauge", metrics.At(0).Name())
+	assert.Equal(t, "latency", metrics.At(1).Name())
+	assert.Equal(t, pdata.MetricDataTypeIntHistogram, metrics.At(1).DataType())
+
+	assert.Equal(t, map[string]float64{
+		"gauge":              1,
+		"cumulative_counter": 3,
+	}, retrieveSums(t, mAcceptedDataPoints.Name(), "signalfx/distributions", tagKeyType))
+	assert.Empty(t, retrieveSums(t, mRefusedDataPoints.Name(), "signalfx/distributions", tagKeyType, tagKeyReason))
+}
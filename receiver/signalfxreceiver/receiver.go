@@ -253,7 +253,14 @@ func (r *sfxReceiver) handleDatapointReq(resp http.ResponseWriter, req *http.Req
 		return
 	}
 
-	md, _ := signalFxV2ToMetrics(r.logger, msg.Datapoints)
+	dps := msg.Datapoints
+	var decoded []*sfxpb.DataPoint
+	distributions := pdata.NewMetricSlice()
+	if r.config.DecodeDistributions {
+		dps, decoded, distributions = decodeDistributions(msg.Datapoints)
+	}
+	md, _ := signalFxV2ToMetrics(r.logger, dps)
+	distributions.MoveAndAppendTo(md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics())
 
 	if r.config.AccessTokenPassthrough {
 		if accessToken := req.Header.Get(splunk.SFxAccessTokenHeader); accessToken != "" {
@@ -266,6 +273,7 @@ func (r *sfxReceiver) handleDatapointReq(resp http.ResponseWriter, req *http.Req
 	}
 
 	err := r.metricsConsumer.ConsumeMetrics(ctx, md)
+	recordDataPoints(ctx, r.config.Name(), msg.Datapoints, decoded, md, err)
 	obsreport.EndMetricsReceiveOp(
 		ctx,
 		typeStr,
@@ -339,6 +347,7 @@ func (r *sfxReceiver) failRequest(
 	jsonResponse []byte,
 	err error,
 ) {
+	recordFailedRequest(ctx, r.config.Name(), jsonResponse)
 	resp.WriteHeader(httpStatusCode)
 	if len(jsonResponse) > 0 {
 		// The response needs to be written as a JSON string.
@@ -0,0 +1,130 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecreceiver
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// auditWriter appends raw request bodies to a file, rotating it once it reaches the configured size.
+type auditWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newAuditWriter(settings AuditSettings) *auditWriter {
+	if settings.Path == "" {
+		return nil
+	}
+	return &auditWriter{
+		path:       settings.Path,
+		maxSize:    int64(settings.MaxSizeMiB) * 1024 * 1024,
+		maxBackups: settings.MaxBackups,
+	}
+}
+
+func (w *auditWriter) open() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.openLocked()
+}
+
+func (w *auditWriter) openLocked() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// write appends the payload followed by a newline, rotating the file first if it would exceed the maximum size.
+func (w *auditWriter) write(payload []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return fmt.Errorf("audit file %q is not open", w.path)
+	}
+
+	n := int64(len(payload)) + 1
+	if w.size > 0 && w.size+n > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := w.file.Write(payload); err != nil {
+		return err
+	}
+	if _, err := w.file.Write([]byte{'\n'}); err != nil {
+		return err
+	}
+	w.size += n
+	return nil
+}
+
+// rotate renames the current file to <path>.1, shifting the older backups and removing those beyond max_backups.
+func (w *auditWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	if w.maxBackups == 0 {
+		if err := os.Remove(w.path); err != nil {
+			return err
+		}
+		return w.openLocked()
+	}
+	if err := os.Remove(w.backupPath(w.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := w.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(w.backupPath(i), w.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+		return err
+	}
+	return w.openLocked()
+}
+
+func (w *auditWriter) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+func (w *auditWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,54 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecreceiver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditWriterRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "splunkhec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	auditPath := filepath.Join(dir, "audit.log")
+
+	w := newAuditWriter(AuditSettings{Path: auditPath, MaxSizeMiB: 1, MaxBackups: 2})
+	require.NoError(t, w.open())
+	payload := bytes.Repeat([]byte("a"), 600*1024)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, w.write(payload))
+	}
+	require.NoError(t, w.close())
+
+	// Each write rotates the file since two payloads exceed 1 MiB, only two backups are kept.
+	for _, name := range []string{auditPath, auditPath + ".1", auditPath + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.EqualValues(t, len(payload)+1, info.Size())
+	}
+	_, err = os.Stat(auditPath + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAuditWriterDisabled(t *testing.T) {
+	assert.Nil(t, newAuditWriter(AuditSettings{MaxSizeMiB: 1}))
+}
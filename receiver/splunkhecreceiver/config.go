@@ -15,13 +15,17 @@
 package splunkhecreceiver
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gobwas/glob"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configmodels"
+	"go.opentelemetry.io/collector/translator/conventions"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
 )
@@ -35,6 +39,86 @@ type Config struct {
 	// Path we will listen on, defaults to `*` (anything matches)
 	Path     string `mapstructure:"path"`
 	pathGlob glob.Glob
+
+	// TokenIDAttribute is the resource attribute set to a stable identifier of the HEC token of requests, a hash
+	// that never exposes the token itself, to tell tenants apart downstream. Disabled if empty.
+	TokenIDAttribute string `mapstructure:"token_id_attribute"`
+
+	// ResourceAttributes are static attributes set on the resource of everything the receiver ingests, e.g. the
+	// cluster, region or environment, unless the resource already has them.
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+
+	// Audit mirrors the raw bodies of accepted requests to disk before they are converted.
+	Audit AuditSettings `mapstructure:"audit"`
+
+	// PreserveHecMetadata keeps the host, source, sourcetype and index of events in the com.splunk.* attributes the
+	// splunkhecexporter re-emits unchanged, instead of mapping the host and source to host.name and service.name, so
+	// that the collector forwards HEC data transparently.
+	PreserveHecMetadata bool `mapstructure:"preserve_hec_metadata"`
+
+	// PrometheusRemoteWrite accepts Prometheus remote-write requests on a path of the same port, converted to metrics.
+	PrometheusRemoteWrite PrometheusRemoteWriteSettings `mapstructure:"prometheus_remote_write"`
+
+	// ReplayProtection drops the exact duplicates of events recently received on the same channel.
+	ReplayProtection ReplayProtectionSettings `mapstructure:"replay_protection"`
+}
+
+var (
+	// defaultHecToOtelAttrs maps the HEC metadata of events to the attributes of the unified model.
+	defaultHecToOtelAttrs = splunk.HecToOtelAttrs{
+		Source:     conventions.AttributeServiceName,
+		SourceType: splunk.SourcetypeLabel,
+		Index:      splunk.IndexLabel,
+		Host:       conventions.AttributeHostName,
+	}
+	// preservedHecToOtelAttrs maps the HEC metadata of events to the attributes the splunkhecexporter sends as is.
+	preservedHecToOtelAttrs = splunk.HecToOtelAttrs{
+		Source:     splunk.SourceLabel,
+		SourceType: splunk.SourcetypeLabel,
+		Index:      splunk.IndexLabel,
+		Host:       splunk.HostLabel,
+	}
+)
+
+// hecToOtelAttrs returns the attributes the HEC metadata of events is mapped to.
+func (c *Config) hecToOtelAttrs() splunk.HecToOtelAttrs {
+	if c.PreserveHecMetadata {
+		return preservedHecToOtelAttrs
+	}
+	return defaultHecToOtelAttrs
+}
+
+// PrometheusRemoteWriteSettings defines how Prometheus remote-write requests are received.
+type PrometheusRemoteWriteSettings struct {
+	// Path remote-write requests are sent to, e.g. "/api/v1/write". It takes precedence over the HEC path. Disabled
+	// if empty.
+	Path string `mapstructure:"path"`
+}
+
+// ReplayProtectionSettings defines how duplicate events, e.g. resent by forwarders retrying requests whose response
+// they missed, are dropped before reaching Splunk.
+type ReplayProtectionSettings struct {
+	// Enabled drops the events identical to one received on the same HEC channel within Window. Defaults to false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Window is how long an event is remembered after it was last received. Defaults to 5m.
+	Window time.Duration `mapstructure:"window"`
+
+	// MaxEvents is the maximum number of events remembered, the least recently received being forgotten first.
+	// Defaults to 100000.
+	MaxEvents int `mapstructure:"max_events"`
+}
+
+// AuditSettings defines how raw request bodies are mirrored to disk for audit purposes.
+type AuditSettings struct {
+	// Path of the file the decompressed request bodies are appended to, one per line. Auditing is disabled if empty.
+	Path string `mapstructure:"path"`
+
+	// MaxSizeMiB is the size in mebibytes at which the file is rotated. Defaults to 100.
+	MaxSizeMiB int `mapstructure:"max_size_mib"`
+
+	// MaxBackups is the number of rotated files kept next to the current one. Defaults to 5.
+	MaxBackups int `mapstructure:"max_backups"`
 }
 
 // initialize and initialize the configuration
@@ -48,6 +132,25 @@ func (c *Config) initialize() error {
 		return err
 	}
 	c.pathGlob = glob
+	if c.Audit.Path != "" {
+		if c.Audit.MaxSizeMiB <= 0 {
+			return errors.New(`"audit.max_size_mib" must be positive`)
+		}
+		if c.Audit.MaxBackups < 0 {
+			return errors.New(`"audit.max_backups" must not be negative`)
+		}
+	}
+	if c.ReplayProtection.Enabled {
+		if c.ReplayProtection.Window <= 0 {
+			return errors.New(`"replay_protection.window" must be positive`)
+		}
+		if c.ReplayProtection.MaxEvents <= 0 {
+			return errors.New(`"replay_protection.max_events" must be positive`)
+		}
+	}
+	if p := c.PrometheusRemoteWrite.Path; p != "" && !strings.HasPrefix(p, "/") {
+		return fmt.Errorf(`"prometheus_remote_write.path" %q must start with "/"`, p)
+	}
 	_, err = extractPortFromEndpoint(c.Endpoint)
 	return err
 }
@@ -17,6 +17,7 @@ package splunkhecreceiver
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -132,7 +133,26 @@ func TestLoadConfig(t *testing.T) {
 			AccessTokenPassthroughConfig: splunk.AccessTokenPassthroughConfig{
 				AccessTokenPassthrough: true,
 			},
+			TokenIDAttribute: "tenant.id",
+			ResourceAttributes: map[string]string{
+				"k8s.cluster.name":       "mycluster",
+				"deployment.environment": "prod",
+			},
 			Path: "/foo",
+			Audit: AuditSettings{
+				Path:       "/var/log/hec-audit.log",
+				MaxSizeMiB: 10,
+				MaxBackups: 2,
+			},
+			PreserveHecMetadata: true,
+			PrometheusRemoteWrite: PrometheusRemoteWriteSettings{
+				Path: "/api/v1/write",
+			},
+			ReplayProtection: ReplayProtectionSettings{
+				Enabled:   true,
+				Window:    time.Minute,
+				MaxEvents: 1000,
+			},
 		})
 
 	r2 := cfg.Receivers["splunk_hec/tls"].(*Config)
@@ -155,5 +175,43 @@ func TestLoadConfig(t *testing.T) {
 				AccessTokenPassthrough: false,
 			},
 			Path: "",
+			Audit: AuditSettings{
+				MaxSizeMiB: defaultAuditMaxSizeMiB,
+				MaxBackups: defaultAuditMaxBackups,
+			},
+			ReplayProtection: ReplayProtectionSettings{
+				Window:    defaultReplayWindow,
+				MaxEvents: defaultReplayMaxEvents,
+			},
 		})
 }
+
+func TestInvalidAuditSettings(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	c.Audit.Path = "/var/log/hec-audit.log"
+	c.Audit.MaxSizeMiB = 0
+	assert.EqualError(t, c.initialize(), `"audit.max_size_mib" must be positive`)
+
+	c.Audit.MaxSizeMiB = 1
+	c.Audit.MaxBackups = -1
+	assert.EqualError(t, c.initialize(), `"audit.max_backups" must not be negative`)
+}
+
+func TestInvalidReplayProtectionSettings(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	c.ReplayProtection.Window = 0
+	c.ReplayProtection.MaxEvents = 0
+	assert.NoError(t, c.initialize())
+
+	c.ReplayProtection.Enabled = true
+	assert.EqualError(t, c.initialize(), `"replay_protection.window" must be positive`)
+
+	c.ReplayProtection.Window = time.Minute
+	assert.EqualError(t, c.initialize(), `"replay_protection.max_events" must be positive`)
+}
+
+func TestInvalidPrometheusRemoteWritePath(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	c.PrometheusRemoteWrite.Path = "api/v1/write"
+	assert.EqualError(t, c.initialize(), `"prometheus_remote_write.path" "api/v1/write" must start with "/"`)
+}
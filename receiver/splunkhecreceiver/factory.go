@@ -16,6 +16,7 @@ package splunkhecreceiver
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configerror"
@@ -35,6 +36,14 @@ const (
 
 	// Default endpoints to bind to.
 	defaultEndpoint = ":8088"
+
+	// Default rotation settings of the audit file.
+	defaultAuditMaxSizeMiB = 100
+	defaultAuditMaxBackups = 5
+
+	// Default settings of the replay protection.
+	defaultReplayWindow    = 5 * time.Minute
+	defaultReplayMaxEvents = 100000
 )
 
 // NewFactory creates a factory for SignalFx receiver.
@@ -59,6 +68,14 @@ func createDefaultConfig() configmodels.Receiver {
 		},
 		AccessTokenPassthroughConfig: splunk.AccessTokenPassthroughConfig{},
 		Path:                         "",
+		Audit: AuditSettings{
+			MaxSizeMiB: defaultAuditMaxSizeMiB,
+			MaxBackups: defaultAuditMaxBackups,
+		},
+		ReplayProtection: ReplayProtectionSettings{
+			Window:    defaultReplayWindow,
+			MaxEvents: defaultReplayMaxEvents,
+		},
 	}
 }
 
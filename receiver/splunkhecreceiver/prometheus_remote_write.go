@@ -0,0 +1,146 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecreceiver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+const (
+	// metricNameLabel is the label holding the name of the metric of a Prometheus time series.
+	metricNameLabel = "__name__"
+
+	// maxRemoteWriteBodySize is the maximum size of the snappy-compressed body of a remote-write request, and
+	// maxRemoteWriteDecodedSize the maximum size it decodes to, so that a request cannot exhaust the memory of the
+	// collector. Prometheus sends requests of a few MiB at most.
+	maxRemoteWriteBodySize    = 16 * 1024 * 1024
+	maxRemoteWriteDecodedSize = 64 * 1024 * 1024
+)
+
+// handlePrometheusRemoteWrite converts the time series of a Prometheus remote-write request to metrics.
+func (r *splunkReceiver) handlePrometheusRemoteWrite(resp http.ResponseWriter, req *http.Request) {
+	transport := "http"
+	if r.config.TLSSetting != nil {
+		transport = "https"
+	}
+	ctx := obsreport.ReceiverContext(req.Context(), r.config.Name(), transport)
+	ctx = obsreport.StartMetricsReceiveOp(ctx, r.config.Name(), transport)
+
+	if req.Method != http.MethodPost {
+		r.failRequest(ctx, resp, http.StatusBadRequest, invalidMethodRespBody, nil)
+		return
+	}
+
+	compressed, err := ioutil.ReadAll(http.MaxBytesReader(resp, req.Body, maxRemoteWriteBodySize))
+	if err != nil {
+		if len(compressed) >= maxRemoteWriteBodySize {
+			r.failRequest(ctx, resp, http.StatusRequestEntityTooLarge, errRequestTooLarge, err)
+			return
+		}
+		r.failRequest(ctx, resp, http.StatusBadRequest, errUnmarshalBodyRespBody, err)
+		return
+	}
+	decodedSize, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		r.failRequest(ctx, resp, http.StatusBadRequest, errUnmarshalBodyRespBody, fmt.Errorf("invalid snappy body: %w", err))
+		return
+	}
+	if decodedSize > maxRemoteWriteDecodedSize {
+		r.failRequest(ctx, resp, http.StatusRequestEntityTooLarge, errRequestTooLarge,
+			fmt.Errorf("snappy body decodes to %d bytes, more than %d bytes", decodedSize, maxRemoteWriteDecodedSize))
+		return
+	}
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		r.failRequest(ctx, resp, http.StatusBadRequest, errUnmarshalBodyRespBody, fmt.Errorf("invalid snappy body: %w", err))
+		return
+	}
+	var wr prompb.WriteRequest
+	if err = proto.Unmarshal(body, &wr); err != nil {
+		r.failRequest(ctx, resp, http.StatusBadRequest, errUnmarshalBodyRespBody, err)
+		return
+	}
+
+	md, numPoints := prometheusToMetrics(wr.Timeseries, r.createResourceCustomizer(req))
+	err = r.metricsConsumer.ConsumeMetrics(ctx, md)
+	obsreport.EndMetricsReceiveOp(ctx, typeStr, numPoints, err)
+	if err != nil {
+		r.failRequest(ctx, resp, http.StatusInternalServerError, errInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// prometheusToMetrics converts Prometheus time series to metrics of a single resource, and returns the number of data
+// points. Remote-write requests do not carry the type of metrics, so all of them are converted to double gauges whose
+// data points have the labels of the series, the metric name excepted. Series without a name and staleness markers
+// are skipped.
+func prometheusToMetrics(series []prompb.TimeSeries, customizer func(pdata.Resource)) (pdata.Metrics, int) {
+	md := pdata.NewMetrics()
+	md.ResourceMetrics().Resize(1)
+	rm := md.ResourceMetrics().At(0)
+	if customizer != nil {
+		customizer(rm.Resource())
+	}
+	rm.InstrumentationLibraryMetrics().Resize(1)
+	metrics := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+
+	numPoints := 0
+	byName := map[string]pdata.Metric{}
+	for _, ts := range series {
+		name := ""
+		for _, l := range ts.Labels {
+			if l.Name == metricNameLabel {
+				name = l.Value
+			}
+		}
+		if name == "" || len(ts.Samples) == 0 {
+			continue
+		}
+		metric, ok := byName[name]
+		if !ok {
+			metric = pdata.NewMetric()
+			metric.SetName(name)
+			metric.SetDataType(pdata.MetricDataTypeDoubleGauge)
+			metrics.Append(metric)
+			byName[name] = metric
+		}
+		for _, sample := range ts.Samples {
+			if value.IsStaleNaN(sample.Value) {
+				continue
+			}
+			dp := pdata.NewDoubleDataPoint()
+			dp.SetValue(sample.Value)
+			dp.SetTimestamp(pdata.Timestamp(sample.Timestamp * 1e6))
+			for _, l := range ts.Labels {
+				if l.Name != metricNameLabel {
+					dp.LabelsMap().Insert(l.Name, l.Value)
+				}
+			}
+			metric.DoubleGauge().DataPoints().Append(dp)
+			numPoints++
+		}
+	}
+	return md, numPoints
+}
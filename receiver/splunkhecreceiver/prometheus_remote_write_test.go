@@ -0,0 +1,145 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecreceiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/consumer/pdata"
+	"go.opentelemetry.io/collector/testutil"
+	"go.uber.org/zap"
+)
+
+func TestPrometheusToMetrics(t *testing.T) {
+	series := []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: math.Float64frombits(value.StaleNaN), Timestamp: 2000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "job", Value: "node"}, {Name: "__name__", Value: "up"}},
+			Samples: []prompb.Sample{{Value: 0, Timestamp: 1000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+			Samples: []prompb.Sample{{Value: 21.5, Timestamp: 3000}},
+		},
+		{
+			// No name.
+			Labels:  []prompb.Label{{Name: "job", Value: "node"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	}
+	md, numPoints := prometheusToMetrics(series, func(resource pdata.Resource) {
+		resource.Attributes().InsertString("k8s.cluster.name", "mycluster")
+	})
+	assert.Equal(t, 3, numPoints)
+
+	rm := md.ResourceMetrics().At(0)
+	cluster, _ := rm.Resource().Attributes().Get("k8s.cluster.name")
+	assert.Equal(t, "mycluster", cluster.StringVal())
+	metrics := rm.InstrumentationLibraryMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+
+	up := metrics.At(0)
+	assert.Equal(t, "up", up.Name())
+	assert.Equal(t, pdata.MetricDataTypeDoubleGauge, up.DataType())
+	require.Equal(t, 2, up.DoubleGauge().DataPoints().Len())
+	dp := up.DoubleGauge().DataPoints().At(0)
+	assert.Equal(t, 1.0, dp.Value())
+	assert.Equal(t, pdata.Timestamp(1e9), dp.Timestamp())
+	assert.Equal(t, 1, dp.LabelsMap().Len())
+	job, _ := dp.LabelsMap().Get("job")
+	assert.Equal(t, "node", job)
+
+	temperature := metrics.At(1)
+	assert.Equal(t, "temperature", temperature.Name())
+	assert.Equal(t, 21.5, temperature.DoubleGauge().DataPoints().At(0).Value())
+}
+
+func TestPrometheusRemoteWrite(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.Endpoint = testutil.GetAvailableLocalAddress(t)
+	config.PrometheusRemoteWrite.Path = "/api/v1/write"
+	require.NoError(t, config.initialize())
+
+	sink := new(consumertest.MetricsSink)
+	r, err := NewMetricsReceiver(zap.NewNop(), *config, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer r.Shutdown(context.Background())
+
+	wr := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: time.Now().UnixNano() / 1e6}},
+	}}}
+	body, err := proto.Marshal(wr)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "http://"+config.Endpoint+"/api/v1/write", bytes.NewReader(snappy.Encode(nil, body)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.Equal(t, "up", sink.AllMetrics()[0].ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
+
+	// An invalid body is rejected.
+	resp, err = http.Post("http://"+config.Endpoint+"/api/v1/write", "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Bodies decoding to more than the maximum are rejected before being decoded.
+	huge := make([]byte, binary.MaxVarintLen64)
+	huge = append(huge[:binary.PutUvarint(huge, maxRemoteWriteDecodedSize+1)], 0)
+	resp, err = http.Post("http://"+config.Endpoint+"/api/v1/write", "application/x-protobuf", bytes.NewReader(huge))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// As are bodies larger than the maximum.
+	resp, err = http.Post("http://"+config.Endpoint+"/api/v1/write", "application/x-protobuf",
+		bytes.NewReader(make([]byte, maxRemoteWriteBodySize+1)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// HEC requests are still served on the other paths.
+	msgBytes, err := json.Marshal(buildSplunkHecMetricsMsg(float64(time.Now().UnixNano())/1e6, 1, 1))
+	require.NoError(t, err)
+	resp, err = http.Post("http://"+config.Endpoint+"/services/collector", "application/json", bytes.NewReader(msgBytes))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Len(t, sink.AllMetrics(), 2)
+}
@@ -15,13 +15,18 @@
 package splunkhecreceiver
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -49,6 +54,7 @@ const (
 	responseErrInternalServerError    = "Internal Server Error"
 	responseErrUnsupportedMetricEvent = "Unsupported metric event"
 	responseErrUnsupportedLogEvent    = "Unsupported log event"
+	responseErrRequestTooLarge        = "Request body too large"
 
 	// Centralizing some HTTP and related string constants.
 	gzipEncoding              = "gzip"
@@ -69,6 +75,7 @@ var (
 	errInternalServerError    = initJSONResponse(responseErrInternalServerError)
 	errUnsupportedMetricEvent = initJSONResponse(responseErrUnsupportedMetricEvent)
 	errUnsupportedLogEvent    = initJSONResponse(responseErrUnsupportedLogEvent)
+	errRequestTooLarge        = initJSONResponse(responseErrRequestTooLarge)
 )
 
 // splunkReceiver implements the component.MetricsReceiver for Splunk HEC metric protocol.
@@ -79,6 +86,8 @@ type splunkReceiver struct {
 	logsConsumer    consumer.Logs
 	metricsConsumer consumer.Metrics
 	server          *http.Server
+	audit           *auditWriter
+	replay          *replayCache
 }
 
 var _ component.MetricsReceiver = (*splunkReceiver)(nil)
@@ -101,6 +110,8 @@ func NewMetricsReceiver(
 		logger:          logger,
 		config:          &config,
 		metricsConsumer: nextConsumer,
+		audit:           newAuditWriter(config.Audit),
+		replay:          newReplayCache(config.ReplayProtection),
 		server: &http.Server{
 			Addr: config.Endpoint,
 			// TODO: Evaluate what properties should be configurable, for now
@@ -131,6 +142,8 @@ func NewLogsReceiver(
 		logger:       logger,
 		config:       &config,
 		logsConsumer: nextConsumer,
+		audit:        newAuditWriter(config.Audit),
+		replay:       newReplayCache(config.ReplayProtection),
 		server: &http.Server{
 			Addr: config.Endpoint,
 			// TODO: Evaluate what properties should be configurable, for now
@@ -150,6 +163,12 @@ func (r *splunkReceiver) Start(_ context.Context, host component.Host) error {
 	r.Lock()
 	defer r.Unlock()
 
+	if r.audit != nil {
+		if err := r.audit.open(); err != nil {
+			return fmt.Errorf("failed to open audit file: %w", err)
+		}
+	}
+
 	var ln net.Listener
 	// set up the listener
 	ln, err := r.config.HTTPServerSettings.ToListener()
@@ -158,6 +177,9 @@ func (r *splunkReceiver) Start(_ context.Context, host component.Host) error {
 	}
 
 	mx := mux.NewRouter()
+	if r.metricsConsumer != nil && r.config.PrometheusRemoteWrite.Path != "" {
+		mx.Path(r.config.PrometheusRemoteWrite.Path).HandlerFunc(r.handlePrometheusRemoteWrite)
+	}
 	mx.NewRoute().HandlerFunc(r.handleReq)
 
 	r.server = r.config.HTTPServerSettings.ToServer(mx)
@@ -183,6 +205,11 @@ func (r *splunkReceiver) Shutdown(context.Context) error {
 	defer r.Unlock()
 
 	err := r.server.Close()
+	if r.audit != nil {
+		if auditErr := r.audit.close(); err == nil {
+			err = auditErr
+		}
+	}
 
 	return err
 }
@@ -230,6 +257,17 @@ func (r *splunkReceiver) handleReq(resp http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	var body []byte
+	if r.audit != nil {
+		var err error
+		body, err = ioutil.ReadAll(bodyReader)
+		if err != nil {
+			r.failRequest(ctx, resp, http.StatusBadRequest, errUnmarshalBodyRespBody, err)
+			return
+		}
+		bodyReader = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
 	dec := json.NewDecoder(bodyReader)
 
 	var events []*splunk.Event
@@ -253,53 +291,113 @@ func (r *splunkReceiver) handleReq(resp http.ResponseWriter, req *http.Request)
 
 		events = append(events, &msg)
 	}
+
+	if r.audit != nil {
+		if err := r.audit.write(body); err != nil {
+			r.failRequest(ctx, resp, http.StatusInternalServerError, errInternalServerError, err)
+			return
+		}
+	}
+
+	var hashes []eventHash
+	if r.replay != nil {
+		received := len(events)
+		events, hashes = r.replay.filter(requestChannel(req), events, time.Now())
+		if dropped := received - len(events); dropped > 0 {
+			r.logger.Debug("Dropped duplicate events", zap.Int("count", dropped), zap.String("receiver", r.config.Name()))
+			if len(events) == 0 {
+				// The request is acknowledged like the original one, so that it is not resent again.
+				if r.logsConsumer == nil {
+					obsreport.EndMetricsReceiveOp(ctx, typeStr, 0, nil)
+				}
+				resp.WriteHeader(http.StatusAccepted)
+				resp.Write(okRespBody)
+				return
+			}
+		}
+	}
+
+	var consumed bool
 	if r.logsConsumer != nil {
-		r.consumeLogs(ctx, events, resp, req)
+		consumed = r.consumeLogs(ctx, events, resp, req)
 	} else {
-		r.consumeMetrics(ctx, events, resp, req)
+		consumed = r.consumeMetrics(ctx, events, resp, req)
+	}
+	if consumed {
+		r.replay.add(hashes, time.Now())
 	}
 }
 
 func (r *splunkReceiver) createResourceCustomizer(req *http.Request) func(pdata.Resource) {
+	var passthroughToken, tokenID string
 	if r.config.AccessTokenPassthrough {
-		if accessToken := req.Header.Get(splunk.HECTokenHeader); accessToken != "" {
-			return func(resource pdata.Resource) {
-				resource.Attributes().InsertString(splunk.HecTokenLabel, accessToken)
-			}
+		passthroughToken = req.Header.Get(splunk.HECTokenHeader)
+	}
+	if r.config.TokenIDAttribute != "" {
+		if token := requestToken(req); token != "" {
+			tokenID = hashToken(token)
+		}
+	}
+	return func(resource pdata.Resource) {
+		if passthroughToken != "" {
+			resource.Attributes().InsertString(splunk.HecTokenLabel, passthroughToken)
+		}
+		if tokenID != "" {
+			resource.Attributes().InsertString(r.config.TokenIDAttribute, tokenID)
+		}
+		for k, v := range r.config.ResourceAttributes {
+			resource.Attributes().InsertString(k, v)
 		}
 	}
-	return func(resource pdata.Resource) {}
 }
 
-func (r *splunkReceiver) consumeMetrics(ctx context.Context, events []*splunk.Event, resp http.ResponseWriter, req *http.Request) {
-	md, _ := SplunkHecToMetricsData(r.logger, events, r.createResourceCustomizer(req))
+// requestToken returns the HEC token of the request, sent as "Authorization: Splunk <token>" or in the Splunk header.
+func requestToken(req *http.Request) string {
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, splunk.HECTokenHeader+" ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, splunk.HECTokenHeader+" "))
+	}
+	return req.Header.Get(splunk.HECTokenHeader)
+}
+
+// hashToken returns a stable identifier of the token: the first 16 hexadecimal characters of its SHA-256 hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// consumeMetrics converts and consumes the events, and tells whether they were consumed.
+func (r *splunkReceiver) consumeMetrics(ctx context.Context, events []*splunk.Event, resp http.ResponseWriter, req *http.Request) bool {
+	md, _ := SplunkHecToMetricsData(r.logger, events, r.config.hecToOtelAttrs(), r.createResourceCustomizer(req))
 
 	decodeErr := r.metricsConsumer.ConsumeMetrics(ctx, md)
 	obsreport.EndMetricsReceiveOp(ctx, typeStr, len(events), decodeErr)
 
 	if decodeErr != nil {
 		r.failRequest(ctx, resp, http.StatusInternalServerError, errInternalServerError, decodeErr)
-	} else {
-		resp.WriteHeader(http.StatusAccepted)
-		resp.Write(okRespBody)
+		return false
 	}
+	resp.WriteHeader(http.StatusAccepted)
+	resp.Write(okRespBody)
+	return true
 }
 
-func (r *splunkReceiver) consumeLogs(ctx context.Context, events []*splunk.Event, resp http.ResponseWriter, req *http.Request) {
-	ld, err := SplunkHecToLogData(r.logger, events, r.createResourceCustomizer(req))
+// consumeLogs converts and consumes the events, and tells whether they were consumed.
+func (r *splunkReceiver) consumeLogs(ctx context.Context, events []*splunk.Event, resp http.ResponseWriter, req *http.Request) bool {
+	ld, err := SplunkHecToLogData(r.logger, events, r.config.hecToOtelAttrs(), r.createResourceCustomizer(req))
 	if err != nil {
 		r.failRequest(ctx, resp, http.StatusBadRequest, errUnmarshalBodyRespBody, err)
-		return
+		return false
 	}
 
 	decodeErr := r.logsConsumer.ConsumeLogs(ctx, ld)
 
 	if decodeErr != nil {
 		r.failRequest(ctx, resp, http.StatusInternalServerError, errInternalServerError, decodeErr)
-	} else {
-		resp.WriteHeader(http.StatusAccepted)
-		resp.Write(okRespBody)
+		return false
 	}
+	resp.WriteHeader(http.StatusAccepted)
+	resp.Write(okRespBody)
+	return true
 }
 
 func (r *splunkReceiver) failRequest(
@@ -27,6 +27,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -543,6 +545,92 @@ func Test_splunkhecReceiver_AccessTokenPassthrough(t *testing.T) {
 	}
 }
 
+func Test_splunkhecReceiver_TokenIDAttribute(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.Endpoint = "localhost:8088"
+	config.TokenIDAttribute = "tenant.id"
+	require.NoError(t, config.initialize())
+
+	sink := new(consumertest.LogsSink)
+	rcv, err := NewLogsReceiver(zap.NewNop(), *config, sink)
+	require.NoError(t, err)
+	r := rcv.(*splunkReceiver)
+
+	send := func(header, value string) pdata.AttributeMap {
+		msgBytes, _ := json.Marshal(buildSplunkHecMsg(float64(time.Now().UnixNano())/1e6, 1))
+		req := httptest.NewRequest("POST", "http://localhost", bytes.NewReader(msgBytes))
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		r.handleReq(w, req)
+		require.Equal(t, http.StatusAccepted, w.Result().StatusCode)
+		logs := sink.AllLogs()
+		return logs[len(logs)-1].ResourceLogs().At(0).Resource().Attributes()
+	}
+
+	attrs := send("Authorization", "Splunk 00000000-0000-0000-0000-000000000001")
+	tokenID, ok := attrs.Get("tenant.id")
+	require.True(t, ok)
+	assert.Len(t, tokenID.StringVal(), 16)
+	assert.NotContains(t, tokenID.StringVal(), "0000-0000")
+	_, ok = attrs.Get("com.splunk.hec.access_token")
+	assert.False(t, ok)
+
+	// The same token always gets the same identifier, whichever header it is sent in.
+	attrs = send("Splunk", "00000000-0000-0000-0000-000000000001")
+	sameID, _ := attrs.Get("tenant.id")
+	assert.Equal(t, tokenID.StringVal(), sameID.StringVal())
+
+	attrs = send("Splunk", "00000000-0000-0000-0000-000000000002")
+	otherID, _ := attrs.Get("tenant.id")
+	assert.NotEqual(t, tokenID.StringVal(), otherID.StringVal())
+}
+
+func Test_splunkhecReceiver_ResourceAttributes(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.Endpoint = "localhost:8088"
+	config.ResourceAttributes = map[string]string{
+		"k8s.cluster.name": "mycluster",
+		"host.name":        "defaulthost",
+	}
+	require.NoError(t, config.initialize())
+
+	logsSink := new(consumertest.LogsSink)
+	rcv, err := NewLogsReceiver(zap.NewNop(), *config, logsSink)
+	require.NoError(t, err)
+	logsReceiver := rcv.(*splunkReceiver)
+	metricsSink := new(consumertest.MetricsSink)
+	rcv, err = NewMetricsReceiver(zap.NewNop(), *config, metricsSink)
+	require.NoError(t, err)
+	metricsReceiver := rcv.(*splunkReceiver)
+
+	send := func(r *splunkReceiver, msg *splunk.Event) {
+		msgBytes, _ := json.Marshal(msg)
+		req := httptest.NewRequest("POST", "http://localhost", bytes.NewReader(msgBytes))
+		w := httptest.NewRecorder()
+		r.handleReq(w, req)
+		require.Equal(t, http.StatusAccepted, w.Result().StatusCode)
+	}
+
+	send(logsReceiver, buildSplunkHecMsg(float64(time.Now().UnixNano())/1e6, 1))
+	require.Len(t, logsSink.AllLogs(), 1)
+	attrs := logsSink.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes()
+	cluster, _ := attrs.Get("k8s.cluster.name")
+	assert.Equal(t, "mycluster", cluster.StringVal())
+	host, _ := attrs.Get("host.name")
+	assert.Equal(t, "defaulthost", host.StringVal())
+
+	// The host of the event takes precedence over the configured default.
+	metric := buildSplunkHecMetricsMsg(float64(time.Now().UnixNano())/1e6, 1, 1)
+	metric.Host = "myhost"
+	send(metricsReceiver, metric)
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	attrs = metricsSink.AllMetrics()[0].ResourceMetrics().At(0).Resource().Attributes()
+	cluster, _ = attrs.Get("k8s.cluster.name")
+	assert.Equal(t, "mycluster", cluster.StringVal())
+	host, _ = attrs.Get("host.name")
+	assert.Equal(t, "myhost", host.StringVal())
+}
+
 func Test_Logs_splunkhecReceiver_IndexSourceTypePassthrough(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -583,7 +671,6 @@ func Test_Logs_splunkhecReceiver_IndexSourceTypePassthrough(t *testing.T) {
 				Token:              "ignored",
 				SourceType:         "defaultsourcetype",
 				Index:              "defaultindex",
-				InsecureSkipVerify: true,
 				DisableCompression: true,
 				Endpoint:           endServer.URL,
 			}
@@ -681,7 +768,6 @@ func Test_Metrics_splunkhecReceiver_IndexSourceTypePassthrough(t *testing.T) {
 				Token:              "ignored",
 				SourceType:         "defaultsourcetype",
 				Index:              "defaultindex",
-				InsecureSkipVerify: true,
 				DisableCompression: true,
 				Endpoint:           endServer.URL,
 			}
@@ -780,3 +866,79 @@ func (b badReqBody) Read(p []byte) (n int, err error) {
 func (b badReqBody) Close() error {
 	return nil
 }
+
+func Test_splunkhecReceiver_Audit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "splunkhec")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	auditPath := filepath.Join(dir, "audit.log")
+
+	config := createDefaultConfig().(*Config)
+	config.Endpoint = testutil.GetAvailableLocalAddress(t)
+	config.Audit.Path = auditPath
+	require.NoError(t, config.initialize())
+	sink := new(consumertest.LogsSink)
+	rcv, err := NewLogsReceiver(zap.NewNop(), *config, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcv.Start(context.Background(), componenttest.NewNopHost()))
+
+	r := rcv.(*splunkReceiver)
+	msgBytes, err := json.Marshal(buildSplunkHecMsg(float64(time.Now().UnixNano())/1e6, 3))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.handleReq(w, httptest.NewRequest("POST", "http://localhost", bytes.NewReader(msgBytes)))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// Rejected requests are not audited.
+	w = httptest.NewRecorder()
+	r.handleReq(w, httptest.NewRequest("POST", "http://localhost", bytes.NewReader([]byte("{"))))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, rcv.Shutdown(context.Background()))
+
+	audited, err := ioutil.ReadFile(auditPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(msgBytes)+"\n", string(audited))
+	assert.Equal(t, 1, sink.LogRecordsCount())
+}
+
+func Test_splunkhecReceiver_ReplayProtection(t *testing.T) {
+	config := createDefaultConfig().(*Config)
+	config.Endpoint = "localhost:8088"
+	config.ReplayProtection.Enabled = true
+	require.NoError(t, config.initialize())
+	rcv, err := NewLogsReceiver(zap.NewNop(), *config, consumertest.NewLogsErr(errors.New("bad consumer")))
+	require.NoError(t, err)
+	r := rcv.(*splunkReceiver)
+
+	now := float64(time.Now().UnixNano()) / 1e6
+	a, b, c := buildSplunkHecMsg(now, 1), buildSplunkHecMsg(now, 2), buildSplunkHecMsg(now+1, 1)
+	send := func(url string, channel string, events ...*splunk.Event) int {
+		var body []byte
+		for _, event := range events {
+			msgBytes, err := json.Marshal(event)
+			require.NoError(t, err)
+			body = append(body, msgBytes...)
+		}
+		req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+		if channel != "" {
+			req.Header.Set("X-Splunk-Request-Channel", channel)
+		}
+		w := httptest.NewRecorder()
+		r.handleReq(w, req)
+		return w.Code
+	}
+
+	// Events failing to be consumed are not remembered, so that they are not dropped when resent.
+	assert.Equal(t, http.StatusInternalServerError, send("http://localhost", "c1", a, b))
+	sink := new(consumertest.LogsSink)
+	r.logsConsumer = sink
+	assert.Equal(t, http.StatusAccepted, send("http://localhost", "c1", a, b))
+	assert.Equal(t, 2, sink.LogRecordsCount())
+
+	assert.Equal(t, http.StatusAccepted, send("http://localhost", "c1", a, b))
+	assert.Equal(t, 2, sink.LogRecordsCount(), "duplicates are acknowledged and dropped")
+	assert.Equal(t, http.StatusAccepted, send("http://localhost?channel=c1", "", a, c))
+	assert.Equal(t, 3, sink.LogRecordsCount(), "only the duplicates are dropped")
+	assert.Equal(t, http.StatusAccepted, send("http://localhost", "c2", a))
+	assert.Equal(t, 4, sink.LogRecordsCount(), "duplicates are per channel")
+}
@@ -0,0 +1,151 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecreceiver
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+const (
+	// channelHeader and channelParam hold the HEC channel of requests.
+	channelHeader = "X-Splunk-Request-Channel"
+	channelParam  = "channel"
+)
+
+// eventHash identifies an event received on a channel.
+type eventHash [sha256.Size]byte
+
+// seenEvent is an entry of the replay cache.
+type seenEvent struct {
+	hash     eventHash
+	lastSeen time.Time
+}
+
+// replayCache remembers the hashes of the events recently received on each channel, the least recently seen being
+// evicted first, so that exact duplicates resent by forwarders retrying requests whose response they missed are
+// dropped. A nil replayCache drops nothing.
+type replayCache struct {
+	window    time.Duration
+	maxEvents int
+
+	mu sync.Mutex
+	// lru holds the seenEvents, the most recently seen first.
+	lru     *list.List
+	entries map[eventHash]*list.Element
+}
+
+func newReplayCache(settings ReplayProtectionSettings) *replayCache {
+	if !settings.Enabled {
+		return nil
+	}
+	return &replayCache{
+		window:    settings.Window,
+		maxEvents: settings.MaxEvents,
+		lru:       list.New(),
+		entries:   map[eventHash]*list.Element{},
+	}
+}
+
+// requestChannel returns the HEC channel of the request, sent in the X-Splunk-Request-Channel header or the channel
+// query parameter, empty if none.
+func requestChannel(req *http.Request) string {
+	if channel := req.Header.Get(channelHeader); channel != "" {
+		return channel
+	}
+	return req.URL.Query().Get(channelParam)
+}
+
+// hashEvent returns the hash of the event received on the channel.
+func hashEvent(channel string, event *splunk.Event) (eventHash, error) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return eventHash{}, err
+	}
+	h := sha256.New()
+	h.Write([]byte(channel))
+	h.Write([]byte{0})
+	h.Write(b)
+	var hash eventHash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// filter returns the events that were not received on the channel within the window, along with their hashes to
+// be added once the events are consumed, so that events failing to be consumed are not dropped when resent.
+func (c *replayCache) filter(channel string, events []*splunk.Event, now time.Time) ([]*splunk.Event, []eventHash) {
+	if c == nil {
+		return events, nil
+	}
+	kept := events[:0]
+	hashes := make([]eventHash, 0, len(events))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(now)
+	for _, event := range events {
+		hash, err := hashEvent(channel, event)
+		if err != nil {
+			// Events that cannot be hashed are never considered duplicates.
+			kept = append(kept, event)
+			continue
+		}
+		if elem, ok := c.entries[hash]; ok {
+			elem.Value.(*seenEvent).lastSeen = now
+			c.lru.MoveToFront(elem)
+			continue
+		}
+		kept = append(kept, event)
+		hashes = append(hashes, hash)
+	}
+	return kept, hashes
+}
+
+// add remembers the hashes of consumed events, evicting the least recently seen ones beyond max_events.
+func (c *replayCache) add(hashes []eventHash, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, hash := range hashes {
+		if elem, ok := c.entries[hash]; ok {
+			elem.Value.(*seenEvent).lastSeen = now
+			c.lru.MoveToFront(elem)
+			continue
+		}
+		c.entries[hash] = c.lru.PushFront(&seenEvent{hash: hash, lastSeen: now})
+		if c.lru.Len() > c.maxEvents {
+			c.remove(c.lru.Back())
+		}
+	}
+}
+
+// expire removes the events not seen within the window, which are the least recently seen ones.
+func (c *replayCache) expire(now time.Time) {
+	for elem := c.lru.Back(); elem != nil && now.Sub(elem.Value.(*seenEvent).lastSeen) >= c.window; elem = c.lru.Back() {
+		c.remove(elem)
+	}
+}
+
+func (c *replayCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*seenEvent).hash)
+}
@@ -0,0 +1,81 @@
+// Copyright 2020, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecreceiver
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+func TestReplayCacheDisabled(t *testing.T) {
+	c := newReplayCache(ReplayProtectionSettings{Window: time.Minute, MaxEvents: 10})
+	assert.Nil(t, c)
+	events := []*splunk.Event{buildSplunkHecMsg(1, 1)}
+	c.add([]eventHash{{}}, time.Now())
+	kept, hashes := c.filter("", events, time.Now())
+	assert.Equal(t, events, kept)
+	assert.Empty(t, hashes)
+}
+
+func TestReplayCacheWindow(t *testing.T) {
+	c := newReplayCache(ReplayProtectionSettings{Enabled: true, Window: time.Minute, MaxEvents: 10})
+	require.NotNil(t, c)
+	now := time.Now()
+	event := buildSplunkHecMsg(1, 1)
+
+	kept, hashes := c.filter("", []*splunk.Event{event}, now)
+	assert.Len(t, kept, 1)
+	c.add(hashes, now)
+
+	// Being received again keeps the event remembered for another window.
+	kept, _ = c.filter("", []*splunk.Event{event}, now.Add(50*time.Second))
+	assert.Empty(t, kept)
+	kept, _ = c.filter("", []*splunk.Event{event}, now.Add(100*time.Second))
+	assert.Empty(t, kept)
+	kept, _ = c.filter("", []*splunk.Event{event}, now.Add(160*time.Second))
+	assert.Len(t, kept, 1)
+	assert.Zero(t, c.lru.Len())
+}
+
+func TestReplayCacheMaxEvents(t *testing.T) {
+	c := newReplayCache(ReplayProtectionSettings{Enabled: true, Window: time.Minute, MaxEvents: 2})
+	now := time.Now()
+	events := []*splunk.Event{buildSplunkHecMsg(1, 1), buildSplunkHecMsg(2, 1), buildSplunkHecMsg(3, 1)}
+	_, hashes := c.filter("", events[:2], now)
+	c.add(hashes, now)
+	// The first event is received again, so the second one is the least recently received.
+	kept, _ := c.filter("", events[:1], now)
+	assert.Empty(t, kept)
+	_, hashes = c.filter("", events[2:], now)
+	c.add(hashes, now)
+	assert.Equal(t, 2, c.lru.Len())
+
+	kept, _ = c.filter("", []*splunk.Event{events[0], events[1], events[2]}, now)
+	assert.Equal(t, []*splunk.Event{events[1]}, kept)
+}
+
+func TestRequestChannel(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://localhost/services/collector?channel=param", nil)
+	assert.Equal(t, "param", requestChannel(req))
+	req.Header.Set("X-Splunk-Request-Channel", "header")
+	assert.Equal(t, "header", requestChannel(req))
+	assert.Empty(t, requestChannel(httptest.NewRequest("POST", "http://localhost", nil)))
+}
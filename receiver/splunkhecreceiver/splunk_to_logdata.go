@@ -19,7 +19,6 @@ import (
 	"sort"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
-	"go.opentelemetry.io/collector/translator/conventions"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
@@ -30,7 +29,7 @@ const (
 )
 
 // SplunkHecToLogData transforms splunk events into logs
-func SplunkHecToLogData(logger *zap.Logger, events []*splunk.Event, resourceCustomizer func(pdata.Resource)) (pdata.Logs, error) {
+func SplunkHecToLogData(logger *zap.Logger, events []*splunk.Event, hecToOtelAttrs splunk.HecToOtelAttrs, resourceCustomizer func(pdata.Resource)) (pdata.Logs, error) {
 	ld := pdata.NewLogs()
 	rls := ld.ResourceLogs()
 	rls.Resize(1)
@@ -55,18 +54,7 @@ func SplunkHecToLogData(logger *zap.Logger, events []*splunk.Event, resourceCust
 			logRecord.SetTimestamp(pdata.Timestamp(*event.Time * 1e9))
 		}
 
-		if event.Host != "" {
-			logRecord.Attributes().InsertString(conventions.AttributeHostName, event.Host)
-		}
-		if event.Source != "" {
-			logRecord.Attributes().InsertString(conventions.AttributeServiceName, event.Source)
-		}
-		if event.SourceType != "" {
-			logRecord.Attributes().InsertString(splunk.SourcetypeLabel, event.SourceType)
-		}
-		if event.Index != "" {
-			logRecord.Attributes().InsertString(splunk.IndexLabel, event.Index)
-		}
+		insertHecMetadata(logRecord.Attributes(), event, hecToOtelAttrs)
 		resourceCustomizer(rl.Resource())
 		keys := make([]string, 0, len(event.Fields))
 		for k := range event.Fields {
@@ -89,6 +77,22 @@ func SplunkHecToLogData(logger *zap.Logger, events []*splunk.Event, resourceCust
 	return ld, nil
 }
 
+// insertHecMetadata sets the attributes the HEC metadata of the event is mapped to, for the metadata it has.
+func insertHecMetadata(attrs pdata.AttributeMap, event *splunk.Event, hecToOtelAttrs splunk.HecToOtelAttrs) {
+	if event.Host != "" {
+		attrs.InsertString(hecToOtelAttrs.Host, event.Host)
+	}
+	if event.Source != "" {
+		attrs.InsertString(hecToOtelAttrs.Source, event.Source)
+	}
+	if event.SourceType != "" {
+		attrs.InsertString(hecToOtelAttrs.SourceType, event.SourceType)
+	}
+	if event.Index != "" {
+		attrs.InsertString(hecToOtelAttrs.Index, event.Index)
+	}
+}
+
 func convertInterfaceToAttributeValue(logger *zap.Logger, originalValue interface{}) (pdata.AttributeValue, error) {
 	if originalValue == nil {
 		return pdata.NewAttributeValueNull(), nil
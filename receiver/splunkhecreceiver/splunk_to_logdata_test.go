@@ -18,7 +18,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/pdata"
+	tracetranslator "go.opentelemetry.io/collector/translator/trace"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
@@ -151,7 +153,7 @@ func Test_SplunkHecToLogData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := SplunkHecToLogData(zap.NewNop(), []*splunk.Event{&tt.event}, func(resource pdata.Resource) {})
+			result, err := SplunkHecToLogData(zap.NewNop(), []*splunk.Event{&tt.event}, defaultHecToOtelAttrs, func(resource pdata.Resource) {})
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.output.Len(), result.ResourceLogs().Len())
 			assert.Equal(t, tt.output.At(0), result.ResourceLogs().At(0))
@@ -179,6 +181,29 @@ func createLogsSlice(nanoseconds int) pdata.ResourceLogsSlice {
 	return lrs
 }
 
+func Test_SplunkHecToLogData_preservedHecMetadata(t *testing.T) {
+	event := splunk.Event{
+		Host:       "localhost",
+		Source:     "mysource",
+		SourceType: "mysourcetype",
+		Index:      "myindex",
+		Event:      "value",
+		Fields: map[string]interface{}{
+			"foo": "bar",
+		},
+	}
+	result, err := SplunkHecToLogData(zap.NewNop(), []*splunk.Event{&event}, preservedHecToOtelAttrs, func(resource pdata.Resource) {})
+	require.NoError(t, err)
+	attrs := result.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Attributes()
+	assert.Equal(t, map[string]interface{}{
+		"com.splunk.host":       "localhost",
+		"com.splunk.source":     "mysource",
+		"com.splunk.sourcetype": "mysourcetype",
+		"com.splunk.index":      "myindex",
+		"foo":                   "bar",
+	}, tracetranslator.AttributeMapToMap(attrs))
+}
+
 func Test_ConvertAttributeValueNull(t *testing.T) {
 	value, err := convertInterfaceToAttributeValue(zap.NewNop(), nil)
 	assert.NoError(t, err)
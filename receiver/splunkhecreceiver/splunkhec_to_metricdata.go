@@ -21,7 +21,6 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/collector/consumer/pdata"
-	"go.opentelemetry.io/collector/translator/conventions"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
@@ -30,26 +29,14 @@ import (
 // SplunkHecToMetricsData converts Splunk HEC metric points to
 // pdata.Metrics. Returning the converted data and the number of
 // dropped time series.
-func SplunkHecToMetricsData(logger *zap.Logger, events []*splunk.Event, resourceCustomizer func(pdata.Resource)) (pdata.Metrics, int) {
+func SplunkHecToMetricsData(logger *zap.Logger, events []*splunk.Event, hecToOtelAttrs splunk.HecToOtelAttrs, resourceCustomizer func(pdata.Resource)) (pdata.Metrics, int) {
 
 	numDroppedTimeSeries := 0
 	md := pdata.NewMetrics()
 
 	for _, event := range events {
 		resourceMetrics := pdata.NewResourceMetrics()
-		attrs := resourceMetrics.Resource().Attributes()
-		if event.Host != "" {
-			attrs.InsertString(conventions.AttributeHostName, event.Host)
-		}
-		if event.Source != "" {
-			attrs.InsertString(conventions.AttributeServiceName, event.Source)
-		}
-		if event.SourceType != "" {
-			attrs.InsertString(splunk.SourcetypeLabel, event.SourceType)
-		}
-		if event.Index != "" {
-			attrs.InsertString(splunk.IndexLabel, event.Index)
-		}
+		insertHecMetadata(resourceMetrics.Resource().Attributes(), event, hecToOtelAttrs)
 		resourceCustomizer(resourceMetrics.Resource())
 
 		values := event.GetMetricValues()
@@ -275,7 +275,7 @@ func Test_splunkV2ToMetricsData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			md, numDroppedTimeseries := SplunkHecToMetricsData(zap.NewNop(), []*splunk.Event{tt.splunkDataPoint}, func(resource pdata.Resource) {})
+			md, numDroppedTimeseries := SplunkHecToMetricsData(zap.NewNop(), []*splunk.Event{tt.splunkDataPoint}, defaultHecToOtelAttrs, func(resource pdata.Resource) {})
 			assert.Equal(t, tt.wantDroppedTimeseries, numDroppedTimeseries)
 			assert.Equal(t, tt.wantMetricsData, md)
 		})